@@ -0,0 +1,21 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// AccountHistory fetches up to 'limit' history entries of the on-chain account, the latest first,
+// skipping 'offset' latest entries
+func (c *WaspClient) AccountHistory(chainID *coretypes.ChainID, agentID coretypes.AgentID, offset, limit uint32) (*model.AccountHistory, error) {
+	res := &model.AccountHistory{}
+	route := fmt.Sprintf("%s?offset=%d&limit=%d", routes.AccountHistory(chainID.String(), agentID.Base58()), offset, limit)
+	if err := c.do(http.MethodGet, route, nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
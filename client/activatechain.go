@@ -3,7 +3,9 @@ package client
 import (
 	"net/http"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
@@ -16,3 +18,12 @@ func (c *WaspClient) ActivateChain(chainid coretypes.ChainID) error {
 func (c *WaspClient) DeactivateChain(chainid coretypes.ChainID) error {
 	return c.do(http.MethodPost, routes.DeactivateChain(chainid.String()), nil, nil)
 }
+
+// SetNextCommittee sends a request to set the committee nodes which take over the chain
+// after the committee rotation and the address of their distributed key
+func (c *WaspClient) SetNextCommittee(chainid coretypes.ChainID, committeeNodes []string, addr *address.Address) error {
+	return c.do(http.MethodPost, routes.SetNextCommittee(chainid.String()), &model.NextCommittee{
+		CommitteeNodes: committeeNodes,
+		Address:        model.NewAddress(addr),
+	}, nil)
+}
@@ -32,3 +32,12 @@ func (c *WaspClient) HasBlob(hash hashing.HashValue) (bool, error) {
 	err := c.do(http.MethodGet, routes.HasBlob(hash.String()), nil, res)
 	return res.Exists, err
 }
+
+// BlobCacheStats fetches the size of the blob cache of the node and the statistics of cleanups
+func (c *WaspClient) BlobCacheStats() (*model.BlobCacheStats, error) {
+	res := &model.BlobCacheStats{}
+	if err := c.do(http.MethodGet, routes.BlobCacheStats(), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -16,3 +17,14 @@ func (c *WaspClient) CallView(contractID coretypes.ContractID, fname string, arg
 	}
 	return res, nil
 }
+
+// CallViewAtBlockIndex calls a view function of a given contract on the historical state of the chain,
+// as it was right after the block with the given index
+func (c *WaspClient) CallViewAtBlockIndex(contractID coretypes.ContractID, fname string, arguments dict.Dict, blockIndex uint32) (dict.Dict, error) {
+	var res dict.Dict
+	route := fmt.Sprintf("%s?blockIndex=%d", routes.CallView(contractID.Base58(), fname), blockIndex)
+	if err := c.do(http.MethodGet, route, arguments, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,11 @@
+package chainclient
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// AccountHistory fetches the credits and debits of the on-chain account of the agent, the latest first
+func (c *Client) AccountHistory(agentID coretypes.AgentID, offset, limit uint32) (*model.AccountHistory, error) {
+	return c.WaspClient.AccountHistory(&c.ChainID, agentID, offset, limit)
+}
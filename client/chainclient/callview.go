@@ -9,3 +9,8 @@ import (
 func (c *Client) CallView(contractHname coretypes.Hname, fname string, arguments dict.Dict) (dict.Dict, error) {
 	return c.WaspClient.CallView(coretypes.NewContractID(c.ChainID, contractHname), fname, arguments)
 }
+
+// CallViewAtBlockIndex calls a view function of a given contract on the state of the chain after the given block
+func (c *Client) CallViewAtBlockIndex(contractHname coretypes.Hname, fname string, arguments dict.Dict, blockIndex uint32) (dict.Dict, error) {
+	return c.WaspClient.CallViewAtBlockIndex(coretypes.NewContractID(c.ChainID, contractHname), fname, arguments, blockIndex)
+}
@@ -62,3 +62,25 @@ func (c *Client) PostRequest(
 		Post: true,
 	})
 }
+
+// PostOffLedgerRequest signs the off-ledger request and sends it to the node.
+// Fees are charged from the on-chain account of the sender. The nonce must be greater
+// than the nonce of the previous off-ledger request of the sender
+func (c *Client) PostOffLedgerRequest(
+	contractHname coretypes.Hname,
+	entryPoint coretypes.Hname,
+	nonce uint64,
+	args ...requestargs.RequestArgs,
+) (*sctransaction.OffLedgerRequest, error) {
+	req := sctransaction.NewOffLedgerRequest(coretypes.NewContractID(c.ChainID, contractHname), entryPoint, nonce)
+	if len(args) > 0 {
+		req.WithArgs(args[0])
+	}
+	if err := req.Sign(c.SigScheme); err != nil {
+		return nil, err
+	}
+	if _, err := c.WaspClient.PostOffLedgerRequest(&c.ChainID, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
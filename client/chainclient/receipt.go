@@ -0,0 +1,12 @@
+package chainclient
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// RequestReceipt fetches the receipt of the request processed by the chain: the block index,
+// the error returned by the call, if any, the result, the fees charged and the events emitted
+func (c *Client) RequestReceipt(reqID *coretypes.RequestID) (*model.RequestReceipt, error) {
+	return c.WaspClient.RequestReceipt(&c.ChainID, reqID)
+}
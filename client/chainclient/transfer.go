@@ -0,0 +1,20 @@
+package chainclient
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+)
+
+// TransferOnChain sends the request to move colored tokens from the on-chain account of the sender
+// to the on-chain account of the target agent. The tokens do not leave the chain
+func (c *Client) TransferOnChain(target coretypes.AgentID, amounts map[balance.Color]int64) (*sctransaction.Transaction, error) {
+	par := accounts.EncodeBalances(amounts)
+	par.Set(accounts.ParamAgentID, codec.EncodeAgentID(target))
+	return c.PostRequest(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncTransfer), PostRequestParams{
+		Args: requestargs.New().AddEncodeSimpleMany(par),
+	})
+}
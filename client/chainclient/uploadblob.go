@@ -1,10 +1,14 @@
 package chainclient
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/iotaledger/wasp/client/multiclient"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
@@ -46,3 +50,105 @@ func (c *Client) UploadBlob(fields dict.Dict, waspHosts []string, quorum int, op
 	)
 	return blobHash, reqTx, err
 }
+
+// UploadBlobChunked uploads the blob to the chain with the chunked upload protocol of the 'blob' contract.
+// It is used for blobs too big to fit into one request transaction
+// - posts a 'beginUpload' request with the expected hash of the blob
+// - posts one 'appendChunk' request per chunk of at most `chunkSize` bytes
+// - posts a 'finalizeUpload' request. The chain checks the hash and stores the blob
+// Each request is waited for until it is processed. If the upload was interrupted, the next call
+// resumes it: chunks already uploaded by the same sender are not uploaded again
+func (c *Client) UploadBlobChunked(fields dict.Dict, chunkSize int, timeout time.Duration) (hashing.HashValue, error) {
+	if chunkSize <= 0 || chunkSize > blob.MaxChunkSize {
+		chunkSize = blob.MaxChunkSize
+	}
+	blobHash := blob.MustGetBlobHash(fields)
+	info, err := c.CallView(blob.Interface.Hname(), blob.FuncGetBlobInfo, codec.MakeDict(map[string]interface{}{
+		blob.ParamHash: blobHash,
+	}))
+	if err != nil {
+		return blobHash, err
+	}
+	if len(info) > 0 {
+		// blob exists
+		return blobHash, nil
+	}
+	hashArgs := codec.MakeDict(map[string]interface{}{
+		blob.ParamHash: blobHash,
+	})
+	if err := c.postRequestSync(blob.FuncBeginUpload, hashArgs, timeout); err != nil {
+		return blobHash, err
+	}
+	res, err := c.CallView(blob.Interface.Hname(), blob.FuncGetUpload, codec.MakeDict(map[string]interface{}{
+		blob.ParamHash:     blobHash,
+		blob.ParamUploader: coretypes.NewAgentIDFromAddress(c.SigScheme.Address()),
+	}))
+	if err != nil {
+		return blobHash, err
+	}
+	upload, err := blob.DecodeUpload(res[blob.ParamUpload])
+	if err != nil {
+		return blobHash, err
+	}
+	for _, k := range fields.KeysSorted() {
+		value := fields[k]
+		if len(value) == 0 && !upload.HasField([]byte(k)) {
+			// the empty field is uploaded as the chunk without bytes
+			err := c.postRequestSync(blob.FuncAppendChunk, codec.MakeDict(map[string]interface{}{
+				blob.ParamHash:   blobHash,
+				blob.ParamField:  []byte(k),
+				blob.ParamOffset: 0,
+			}), timeout)
+			if err != nil {
+				return blobHash, err
+			}
+			continue
+		}
+		for offset := int(upload.FieldSize([]byte(k))); offset < len(value); offset += chunkSize {
+			end := offset + chunkSize
+			if end > len(value) {
+				end = len(value)
+			}
+			err := c.postRequestSync(blob.FuncAppendChunk, codec.MakeDict(map[string]interface{}{
+				blob.ParamHash:   blobHash,
+				blob.ParamField:  []byte(k),
+				blob.ParamOffset: offset,
+				blob.ParamBytes:  value[offset:end],
+			}), timeout)
+			if err != nil {
+				return blobHash, err
+			}
+		}
+	}
+	return blobHash, c.postRequestSync(blob.FuncFinalizeUpload, hashArgs, timeout)
+}
+
+// AbortBlobUpload removes the unfinished chunked upload of the blob by the sender together with its chunks
+func (c *Client) AbortBlobUpload(blobHash hashing.HashValue, timeout time.Duration) error {
+	return c.postRequestSync(blob.FuncAbortUpload, codec.MakeDict(map[string]interface{}{
+		blob.ParamHash: blobHash,
+	}), timeout)
+}
+
+// postRequestSync posts the request to the 'blob' contract, waits until it is processed
+// and returns the error of the call, if any
+func (c *Client) postRequestSync(fname string, args dict.Dict, timeout time.Duration) error {
+	tx, err := c.PostRequest(blob.Interface.Hname(), coretypes.Hn(fname), PostRequestParams{
+		Args: requestargs.New(nil).AddEncodeSimpleMany(args),
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.WaspClient.WaitUntilAllRequestsProcessed(tx, timeout); err != nil {
+		return err
+	}
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	receipt, err := c.RequestReceipt(&reqID)
+	if err != nil {
+		return err
+	}
+	if !receipt.Succeeded {
+		return fmt.Errorf("%s: %s", fname, receipt.Error)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// EventLogRecordsByRequest fetches up to 'limit' eventlog records emitted by the request, the latest first
+func (c *WaspClient) EventLogRecordsByRequest(chainID *coretypes.ChainID, reqID coretypes.RequestID, limit uint32) ([]*model.EventLogRecord, error) {
+	res := make([]*model.EventLogRecord, 0)
+	route := fmt.Sprintf("%s?limit=%d", routes.EventLogRecordsByRequest(chainID.String(), reqID.Base58()), limit)
+	if err := c.do(http.MethodGet, route, nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EventLogRecordsByBlock fetches up to 'limit' eventlog records emitted in the block, the latest first
+func (c *WaspClient) EventLogRecordsByBlock(chainID *coretypes.ChainID, blockIndex uint32, limit uint32) ([]*model.EventLogRecord, error) {
+	res := make([]*model.EventLogRecord, 0)
+	route := fmt.Sprintf("%s?limit=%d", routes.EventLogRecordsByBlock(chainID.String(), fmt.Sprintf("%d", blockIndex)), limit)
+	if err := c.do(http.MethodGet, route, nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -1,6 +1,7 @@
 package multiclient
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/client"
 	"github.com/iotaledger/wasp/packages/coretypes"
 )
@@ -18,3 +19,10 @@ func (m *MultiClient) DeactivateChain(chainid coretypes.ChainID) error {
 		return w.DeactivateChain(chainid)
 	})
 }
+
+// SetNextCommittee sets the committee which takes over the chain after the committee rotation in all wasp nodes
+func (m *MultiClient) SetNextCommittee(chainid coretypes.ChainID, committeeNodes []string, addr *address.Address) error {
+	return m.Do(func(i int, w *client.WaspClient) error {
+		return w.SetNextCommittee(chainid, committeeNodes, addr)
+	})
+}
@@ -0,0 +1,24 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+)
+
+// PostOffLedgerRequest sends the signed off-ledger request to the node, which gossips it to the committee
+func (c *WaspClient) PostOffLedgerRequest(chainID *coretypes.ChainID, req *sctransaction.OffLedgerRequest) (*coretypes.RequestID, error) {
+	res := &model.OffLedgerRequestResponse{}
+	body := &model.OffLedgerRequestBody{Request: model.NewBytes(req.Bytes())}
+	if err := c.do(http.MethodPost, routes.PostOffLedgerRequest(chainID.String()), body, res); err != nil {
+		return nil, err
+	}
+	reqID, err := coretypes.NewRequestIDFromBase58(res.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	return &reqID, nil
+}
@@ -19,6 +19,16 @@ func (c *WaspClient) RequestStatus(chainId *coretypes.ChainID, reqId *coretypes.
 	return res, nil
 }
 
+// RequestReceipt fetches the receipt of the request processed by the chain.
+// Returns the HTTP not found error if the request has not been processed
+func (c *WaspClient) RequestReceipt(chainId *coretypes.ChainID, reqId *coretypes.RequestID) (*model.RequestReceipt, error) {
+	res := &model.RequestReceipt{}
+	if err := c.do(http.MethodGet, routes.RequestReceipt(chainId.String(), reqId.Base58()), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // WaitUntilRequestProcessed blocks until the request has been processed by the node
 func (c *WaspClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId *coretypes.RequestID, timeout time.Duration) error {
 	if timeout == 0 {
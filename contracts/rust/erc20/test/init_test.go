@@ -23,7 +23,7 @@ func TestDeployErc20(t *testing.T) {
 	)
 	require.NoError(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 5, len(rec))
+	require.EqualValues(t, 7, len(rec))
 
 	_, err = chain.FindContract(ScName)
 	require.NoError(t, err)
@@ -35,7 +35,7 @@ func TestDeployErc20(t *testing.T) {
 	)
 	require.Error(t, err)
 	_, rec = chain.GetInfo()
-	require.EqualValues(t, 5, len(rec))
+	require.EqualValues(t, 7, len(rec))
 }
 
 func TestDeployErc20Fail1(t *testing.T) {
@@ -44,7 +44,7 @@ func TestDeployErc20Fail1(t *testing.T) {
 	err := chain.DeployWasmContract(nil, ScName, erc20file)
 	require.Error(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 4, len(rec))
+	require.EqualValues(t, 6, len(rec))
 }
 
 func TestDeployErc20Fail2(t *testing.T) {
@@ -55,7 +55,7 @@ func TestDeployErc20Fail2(t *testing.T) {
 	)
 	require.Error(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 4, len(rec))
+	require.EqualValues(t, 6, len(rec))
 }
 
 func TestDeployErc20Fail3(t *testing.T) {
@@ -68,7 +68,7 @@ func TestDeployErc20Fail3(t *testing.T) {
 	)
 	require.Error(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 4, len(rec))
+	require.EqualValues(t, 6, len(rec))
 }
 
 func TestDeployErc20Fail3Repeat(t *testing.T) {
@@ -81,7 +81,7 @@ func TestDeployErc20Fail3Repeat(t *testing.T) {
 	)
 	require.Error(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 4, len(rec))
+	require.EqualValues(t, 6, len(rec))
 
 	// repeat after failure
 	err = chain.DeployWasmContract(nil, ScName, erc20file,
@@ -90,7 +90,7 @@ func TestDeployErc20Fail3Repeat(t *testing.T) {
 	)
 	require.NoError(t, err)
 	_, rec = chain.GetInfo()
-	require.EqualValues(t, 5, len(rec))
+	require.EqualValues(t, 7, len(rec))
 
 	_, err = chain.FindContract(ScName)
 	require.NoError(t, err)
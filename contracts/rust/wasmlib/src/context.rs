@@ -294,6 +294,23 @@ impl ScFuncContext {
         ROOT.get_string(&KEY_EVENT).set_value(text)
     }
 
+    // signals a typed event which is stored in the event log of the chain
+    // the event can be queried by its name and by the value of any of its topics
+    pub fn event_typed(&self, name: &str, topics: &[&[u8]], payload: Option<ScMutableMap>) {
+        let mut encode = BytesEncoder::new();
+        encode.string(name);
+        encode.int64(topics.len() as i64);
+        for topic in topics {
+            encode.bytes(topic);
+        }
+        if let Some(payload) = payload {
+            encode.int64(payload.obj_id as i64);
+        } else {
+            encode.int64(0);
+        }
+        ROOT.get_bytes(&KEY_EVENT).set_value(&encode.data());
+    }
+
     // access the incoming balances for all token colors
     pub fn incoming(&self) -> ScBalances {
         ScBalances { balances: ROOT.get_map(&KEY_INCOMING).immutable() }
@@ -0,0 +1,156 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package apilib
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/level1"
+	"github.com/iotaledger/wasp/client/multiclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+type RotateCommitteeParams struct {
+	Node                      level1.Level1Client
+	ChainID                   coretypes.ChainID
+	CommitteeApiHosts         []string // nodes of the current committee
+	NextCommitteeApiHosts     []string
+	NextCommitteePeeringHosts []string
+	T                         uint16
+	OwnerSigScheme            signaturescheme.SignatureScheme
+	Textout                   io.Writer
+	Prefix                    string
+}
+
+// RotateCommittee performs all actions needed to hand over the chain to the new committee:
+// - runs DKG among the nodes of the new committee
+// - sets the new committee and its address in the chain records of the nodes which run the chain
+// - posts the 'rotateCommittee' request to the chain and waits until it is processed
+// - activates the chain on the nodes which join the chain with the new committee
+// Returns the address of the new committee
+func RotateCommittee(par RotateCommitteeParams) (*address.Address, error) {
+	var err error
+	textout := ioutil.Discard
+	if par.Textout != nil {
+		textout = par.Textout
+	}
+	fmt.Fprint(textout, par.Prefix)
+	fmt.Fprintf(textout, "rotating committee of the chain %s. Parameters N = %d, T = %d\n",
+		par.ChainID.String(), len(par.NextCommitteeApiHosts), par.T)
+
+	chainRecord, err := client.NewWaspClient(par.CommitteeApiHosts[0]).GetChainRecord(par.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if chainRecord == nil {
+		return nil, fmt.Errorf("chain record not found: %s", par.ChainID.String())
+	}
+
+	// ----------- run DKG on the nodes of the new committee
+	var dkgInitiatorIndex = rand.Intn(len(par.NextCommitteeApiHosts))
+	var dkShares *model.DKSharesInfo
+	dkShares, err = client.NewWaspClient(par.NextCommitteeApiHosts[dkgInitiatorIndex]).DKSharesPost(&model.DKSharesPostRequest{
+		PeerNetIDs:  par.NextCommitteePeeringHosts,
+		PeerPubKeys: nil,
+		Threshold:   par.T,
+		TimeoutMS:   60000, // 1 min
+	})
+	fmt.Fprint(textout, par.Prefix)
+	if err != nil {
+		fmt.Fprintf(textout, "generating distributed key set.. FAILED: %v\n", err)
+		return nil, err
+	}
+	fmt.Fprintf(textout, "generating distributed key set.. OK. Generated address = %s\n", dkShares.Address)
+	var nextAddr address.Address
+	if nextAddr, err = address.FromBase58(dkShares.Address); err != nil {
+		return nil, err
+	}
+
+	// ----------- nodes which run the chain take it over, the rest join it after the rotation
+	current := make(map[string]bool)
+	for _, host := range par.CommitteeApiHosts {
+		current[host] = true
+	}
+	staying := make([]string, 0, len(par.NextCommitteeApiHosts))
+	joining := make([]string, 0, len(par.NextCommitteeApiHosts))
+	for _, host := range par.NextCommitteeApiHosts {
+		if current[host] {
+			staying = append(staying, host)
+		} else {
+			joining = append(joining, host)
+		}
+	}
+	err = multiclient.New(staying).SetNextCommittee(par.ChainID, par.NextCommitteePeeringHosts, &nextAddr)
+	fmt.Fprint(textout, par.Prefix)
+	if err != nil {
+		fmt.Fprintf(textout, "setting next committee in Wasp nodes.. FAILED: %v\n", err)
+		return nil, err
+	}
+	fmt.Fprint(textout, "setting next committee in Wasp nodes.. OK.\n")
+
+	// ----------- post the rotation request and wait until the chain is moved
+	reqTx, err := CreateRequestTransaction(CreateRequestTransactionParams{
+		Level1Client:    par.Node,
+		SenderSigScheme: par.OwnerSigScheme,
+		RequestSectionParams: []RequestSectionParams{{
+			TargetContractID: coretypes.NewContractID(par.ChainID, root.Interface.Hname()),
+			EntryPointCode:   coretypes.Hn(root.FuncRotateCommittee),
+			Args:             requestargs.New().AddEncodeSimple(root.ParamChainAddress, codec.EncodeAddress(nextAddr)),
+		}},
+		Post:                true,
+		WaitForConfirmation: true,
+	})
+	fmt.Fprint(textout, par.Prefix)
+	if err != nil {
+		fmt.Fprintf(textout, "posting rotate committee request.. FAILED: %v\n", err)
+		return nil, err
+	}
+	fmt.Fprintf(textout, "posting rotate committee request.. OK. Txid = %s\n", reqTx.ID().String())
+
+	if err = multiclient.New(par.CommitteeApiHosts).WaitUntilAllRequestsProcessed(reqTx, 30*time.Second); err != nil {
+		fmt.Fprintf(textout, "waiting rotate committee request.. FAILED: %v\n", err)
+		return nil, err
+	}
+
+	// ------------ put chain records to the joining nodes and activate the chain
+	if len(joining) > 0 {
+		joiningNodes := multiclient.New(joining)
+		err = joiningNodes.PutChainRecord(&registry.ChainRecord{
+			ChainID:        par.ChainID,
+			Color:          chainRecord.Color,
+			CommitteeNodes: par.NextCommitteePeeringHosts,
+			StateAddress:   nextAddr,
+		})
+		fmt.Fprint(textout, par.Prefix)
+		if err != nil {
+			fmt.Fprintf(textout, "sending chain record to joining Wasp nodes.. FAILED: %v\n", err)
+			return nil, err
+		}
+		fmt.Fprint(textout, "sending chain record to joining Wasp nodes.. OK.\n")
+
+		err = joiningNodes.ActivateChain(par.ChainID)
+		fmt.Fprint(textout, par.Prefix)
+		if err != nil {
+			fmt.Fprintf(textout, "activating chain in joining Wasp nodes.. FAILED: %v\n", err)
+			return nil, err
+		}
+		fmt.Fprint(textout, "activating chain in joining Wasp nodes.. OK.\n")
+	}
+
+	fmt.Fprint(textout, par.Prefix)
+	fmt.Fprintf(textout, "committee of the chain %s has been rotated. New address: %s\n", par.ChainID.String(), nextAddr.String())
+	return &nextAddr, nil
+}
@@ -38,6 +38,8 @@ type Chain interface {
 	SetReadyConsensus()
 	Dismiss()
 	IsDismissed() bool
+	// RotateTo is called when the chain token has been moved to the address of another committee
+	RotateTo(addr address.Address)
 	// requests
 	GetRequestProcessingStatus(*coretypes.RequestID) RequestProcessingStatus
 	EventRequestProcessed() *events.Event
@@ -80,6 +82,7 @@ type Operator interface {
 	EventStateTransitionMsg(*StateTransitionMsg)
 	EventBalancesMsg(BalancesMsg)
 	EventRequestMsg(*RequestMsg)
+	EventOffLedgerRequestMsg(*OffLedgerRequestMsg)
 	EventNotifyReqMsg(*NotifyReqMsg)
 	EventStartProcessingBatchMsg(*StartProcessingBatchMsg)
 	EventResultCalculated(msg *VMResultMsg)
@@ -99,6 +102,7 @@ type chainConstructor func(
 	dksProvider tcrypto.RegistryProvider,
 	blobProvider coretypes.BlobCache,
 	onActivation func(),
+	onRotation func(address.Address),
 ) Chain
 
 var constructorNew chainConstructor
@@ -121,6 +125,7 @@ func New(
 	dksProvider tcrypto.RegistryProvider,
 	blobProvider coretypes.BlobCache,
 	onActivation func(),
+	onRotation func(address.Address),
 ) Chain {
-	return constructorNew(chr, log, netProvider, dksProvider, blobProvider, onActivation)
+	return constructorNew(chr, log, netProvider, dksProvider, blobProvider, onActivation, onRotation)
 }
@@ -34,8 +34,11 @@ type chainObj struct {
 	dismissed                    atomic.Bool
 	dismissOnce                  sync.Once
 	onActivation                 func()
+	onRotation                   func(address.Address)
+	rotateOnce                   sync.Once
 	//
 	chainID         coretypes.ChainID
+	address         address.Address
 	procset         *processors.ProcessorCache
 	color           balance.Color
 	peers           peering.GroupProvider
@@ -66,11 +69,12 @@ func newCommitteeObj(
 	dksProvider tcrypto.RegistryProvider,
 	blobProvider coretypes.BlobCache,
 	onActivation func(),
+	onRotation func(address.Address),
 ) chain.Chain {
 	var err error
 	log.Debugw("creating committee", "addr", chr.ChainID.String())
 
-	addr := address.Address(chr.ChainID)
+	addr := chr.Address()
 	if util.ContainsDuplicates(chr.CommitteeNodes) {
 		log.Errorf("can't create chain object for %s: chain record contains duplicate node addresses. Chain nodes: %+v",
 			addr.String(), chr.CommitteeNodes)
@@ -101,9 +105,11 @@ func newCommitteeObj(
 		procset:      processors.MustNew(),
 		chMsg:        make(chan interface{}, 100),
 		chainID:      chr.ChainID,
+		address:      addr,
 		color:        chr.Color,
 		peers:        peers,
 		onActivation: onActivation,
+		onRotation:   onRotation,
 		eventRequestProcessed: events.NewEvent(func(handler interface{}, params ...interface{}) {
 			handler.(func(_ coretypes.RequestID))(params[0].(coretypes.RequestID))
 		}),
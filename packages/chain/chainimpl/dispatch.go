@@ -5,9 +5,11 @@ package chainimpl
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/util"
 )
 
 func (c *chainObj) dispatchMessage(msg interface{}) {
@@ -48,6 +50,14 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 			c.operator.EventRequestMsg(msgt)
 		}
 
+	case *chain.OffLedgerRequestMsg:
+		// off-ledger request received from the web API. It is gossiped to the committee peers
+		msgt.SenderIndex = c.OwnPeerIndex()
+		c.SendMsgToCommitteePeers(chain.MsgOffLedgerRequest, util.MustBytes(msgt), time.Now().UnixNano())
+		if c.operator != nil {
+			c.operator.EventOffLedgerRequestMsg(msgt)
+		}
+
 	case chain.BalancesMsg:
 		if c.operator != nil {
 			c.operator.EventBalancesMsg(msgt)
@@ -181,6 +191,22 @@ func (c *chainObj) processPeerMessage(msg *peering.PeerMessage) {
 		msgt.SenderIndex = msg.SenderIndex
 		c.stateMgr.EventStateUpdateMsg(msgt)
 
+	case chain.MsgOffLedgerRequest:
+		msgt := &chain.OffLedgerRequestMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		if !msgt.Request.VerifySignature() {
+			c.log.Warnf("processPeerMessage: off-ledger request with invalid signature from peer #%d", msg.SenderIndex)
+			return
+		}
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventOffLedgerRequestMsg(msgt)
+		}
+
 	case chain.MsgTestTrace:
 		msgt := &chain.TestTraceMsg{}
 		if err := msgt.Read(rdr); err != nil {
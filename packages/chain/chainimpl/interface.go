@@ -148,7 +148,22 @@ func (c *chainObj) Color() *balance.Color {
 }
 
 func (c *chainObj) Address() address.Address {
-	return address.Address(c.chainID)
+	return c.address
+}
+
+// RotateTo hands over the chain to the committee of the new address. The committee object is dismissed:
+// the node either joins the new committee or stops serving the chain, depending on its chain record
+func (c *chainObj) RotateTo(addr address.Address) {
+	if addr == c.address {
+		return
+	}
+	c.rotateOnce.Do(func() {
+		c.log.Infof("committee of the chain %s rotated: %s --> %s", c.chainID.String(), c.address.String(), addr.String())
+		publisher.Publish("rotate", c.chainID.String(), c.address.String(), addr.String())
+		if c.onRotation != nil {
+			go c.onRotation(addr)
+		}
+	})
 }
 
 func (c *chainObj) Size() uint16 {
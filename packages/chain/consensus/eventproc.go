@@ -86,6 +86,27 @@ func (op *operator) eventRequestMsg(reqMsg *chain.RequestMsg) {
 	op.takeAction()
 }
 
+// EventOffLedgerRequestMsg triggered by the off-ledger request received from the web API or from the peer
+func (op *operator) EventOffLedgerRequestMsg(msg *chain.OffLedgerRequestMsg) {
+	op.eventOffLedgerRequestMsgCh <- msg
+}
+
+// eventOffLedgerRequestMsg internal handler
+func (op *operator) eventOffLedgerRequestMsg(msg *chain.OffLedgerRequestMsg) {
+	reqId := msg.Request.ID()
+	op.log.Debugw("EventOffLedgerRequestMsg",
+		"reqid", reqId.Short(),
+		"sender", msg.SenderIndex,
+		"backlog req", len(op.requests),
+	)
+	req, _ := op.requestFromOffLedgerMsg(msg)
+	if req == nil {
+		op.log.Warnf("received already processed off-ledger request id = %s", reqId.Short())
+		return
+	}
+	op.takeAction()
+}
+
 // EventNotifyReqMsg request notification received from the peer
 func (op *operator) EventNotifyReqMsg(msg *chain.NotifyReqMsg) {
 	op.eventNotifyReqMsgCh <- msg
@@ -8,6 +8,7 @@ import (
 	"github.com/iotaledger/wasp/packages/vm"
 	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/publisher"
@@ -96,12 +97,53 @@ func (op *operator) requestFromMsg(reqMsg *chain.RequestMsg) (*request, bool) {
 	return ret, msgFirstTime
 }
 
+// requestFromOffLedgerMsg request record retrieved (or created) by off-ledger request message
+func (op *operator) requestFromOffLedgerMsg(msg *chain.OffLedgerRequestMsg) (*request, bool) {
+	reqId := msg.Request.ID()
+	if op.isRequestProcessed(&reqId) {
+		return nil, false
+	}
+	ret, ok := op.requests[reqId]
+	msgFirstTime := !ok || !ret.hasMessage()
+	if !ok {
+		ret = op.newRequest(reqId)
+		op.requests[reqId] = ret
+		op.addRequestIdConcurrent(&reqId)
+	}
+	if msgFirstTime {
+		ret.offLedger = msg.Request
+		ret.whenMsgReceived = time.Now()
+		ok, err := ret.requestSection().SolidifyArgs(op.chain.BlobCache())
+		if err != nil {
+			ret.log.Errorf("inconsistency: can't solidify args: %v", err)
+		} else {
+			ret.argsSolid = ok
+		}
+		publisher.Publish("request_in",
+			op.chain.ID().String(),
+			reqId.TransactionID().String(),
+			fmt.Sprintf("%d", reqId.Index()),
+		)
+		ret.log.Infof("NEW OFF-LEDGER REQUEST from msg")
+	}
+	ret.notifications[op.peerIndex()] = true
+	return ret, msgFirstTime
+}
+
+// requestSection of the request. The message must be known
+func (req *request) requestSection() *sctransaction.RequestSection {
+	if req.offLedger != nil {
+		return req.offLedger.RequestSection()
+	}
+	return req.reqTx.Requests()[req.reqId.Index()]
+}
+
 func (req *request) requestCode() coretypes.Hname {
-	return req.reqTx.Requests()[req.reqId.Index()].EntryPointCode()
+	return req.requestSection().EntryPointCode()
 }
 
 func (req *request) timelock() uint32 {
-	return req.reqTx.Requests()[req.reqId.Index()].Timelock()
+	return req.requestSection().Timelock()
 }
 
 func (req *request) isTimeLocked(nowis time.Time) bool {
@@ -109,13 +151,23 @@ func (req *request) isTimeLocked(nowis time.Time) bool {
 }
 
 func (req *request) hasMessage() bool {
-	return req.reqTx != nil
+	return req.reqTx != nil || req.offLedger != nil
 }
 
 func (req *request) hasSolidArgs() bool {
 	return req.argsSolid
 }
 
+// hasRequestToken checks if the request token is in the balances of the committee address.
+// Requests are sent to the chain ID address. After the committee was rotated, the request token
+// reaches the address of the committee only when outputs of the chain ID address are migrated
+func (req *request) hasRequestToken(balancesByColor map[balance.Color]int64) bool {
+	if req.offLedger != nil {
+		return true
+	}
+	return balancesByColor[balance.Color(req.reqTx.ID())] > 0
+}
+
 func (op *operator) isRequestProcessed(reqid *coretypes.RequestID) bool {
 	processed, err := state.IsRequestCompleted(op.chain.ID(), reqid)
 	if err != nil {
@@ -178,8 +230,9 @@ func takeRefs(reqs []*request) []vm.RequestRefWithFreeTokens {
 	for i := range ret {
 		ret[i] = vm.RequestRefWithFreeTokens{
 			RequestRef: sctransaction.RequestRef{
-				Tx:    reqs[i].reqTx,
-				Index: reqs[i].reqId.Index(),
+				Tx:        reqs[i].reqTx,
+				Index:     reqs[i].reqId.Index(),
+				OffLedger: reqs[i].offLedger,
 			},
 			FreeTokens: reqs[i].freeTokens,
 		}
@@ -35,6 +35,7 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 		Processors:         op.chain.Processors(),
 		ChainID:            *op.chain.ID(),
 		Color:              *op.chain.Color(),
+		ChainAddress:       op.chain.Address(),
 		Entropy:            (hashing.HashValue)(op.stateTx.ID()),
 		Balances:           par.balances,
 		ValidatorFeeTarget: par.accrueFeesTo,
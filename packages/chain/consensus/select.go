@@ -6,6 +6,7 @@ package consensus
 
 import (
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/txutil"
 	"sort"
 	"time"
 )
@@ -56,12 +57,14 @@ func (op *operator) allRequests() []*request {
 // - has known messages
 // - has solid arguments
 // - are not timelocked
+// - have the request token in the balances of the committee address
 // sort by arrival time
 func (op *operator) requestCandidateList() []*request {
 	ret := op.allRequests()
 	nowis := time.Now()
+	balancesByColor, _ := txutil.BalancesByColor(op.balances)
 	ret = filterRequests(ret, func(r *request) bool {
-		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs()
+		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs() && r.hasRequestToken(balancesByColor)
 	})
 	sort.Slice(ret, func(i, j int) bool {
 		return ret[i].whenMsgReceived.Before(ret[j].whenMsgReceived)
@@ -74,7 +77,7 @@ func (op *operator) requestsTimeLocked() []*request {
 
 	nowis := time.Now()
 	for _, req := range op.requests {
-		if req.reqTx == nil {
+		if !req.hasMessage() {
 			continue
 		}
 		if !req.isTimeLocked(nowis) {
@@ -63,6 +63,7 @@ type operator struct {
 	eventStateTransitionMsgCh           chan *chain.StateTransitionMsg
 	eventBalancesMsgCh                  chan chain.BalancesMsg
 	eventRequestMsgCh                   chan *chain.RequestMsg
+	eventOffLedgerRequestMsgCh          chan *chain.OffLedgerRequestMsg
 	eventNotifyReqMsgCh                 chan *chain.NotifyReqMsg
 	eventStartProcessingBatchMsgCh      chan *chain.StartProcessingBatchMsg
 	eventResultCalculatedCh             chan *chain.VMResultMsg
@@ -95,6 +96,8 @@ type request struct {
 	reqId coretypes.RequestID
 	// from request message. nil if request message wasn't received yet
 	reqTx *sctransaction.Transaction
+	// from off-ledger request message. If not nil, reqTx is nil
+	offLedger *sctransaction.OffLedgerRequest
 	// from request message. Not nil only if free tokens were attached to the request
 	freeTokens coretypes.ColoredBalances
 	// time when request message was received by the operator
@@ -120,6 +123,7 @@ func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, log *logger.Lo
 		eventStateTransitionMsgCh:           make(chan *chain.StateTransitionMsg),
 		eventBalancesMsgCh:                  make(chan chain.BalancesMsg),
 		eventRequestMsgCh:                   make(chan *chain.RequestMsg),
+		eventOffLedgerRequestMsgCh:          make(chan *chain.OffLedgerRequestMsg),
 		eventNotifyReqMsgCh:                 make(chan *chain.NotifyReqMsg),
 		eventStartProcessingBatchMsgCh:      make(chan *chain.StartProcessingBatchMsg),
 		eventResultCalculatedCh:             make(chan *chain.VMResultMsg),
@@ -153,6 +157,10 @@ func (op *operator) recvLoop() {
 			if ok {
 				op.eventRequestMsg(msg)
 			}
+		case msg, ok := <-op.eventOffLedgerRequestMsgCh:
+			if ok {
+				op.eventOffLedgerRequestMsg(msg)
+			}
 		case msg, ok := <-op.eventNotifyReqMsgCh:
 			if ok {
 				op.eventNotifyReqMsg(msg)
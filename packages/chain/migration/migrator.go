@@ -0,0 +1,290 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migration moves outputs which arrive at the address of the previous committee of the chain
+// to the next committee, after the chain was handed over to it.
+// Requests are sent to the chain ID address, which is the address of the first committee of the chain.
+// The current committee receives the request sections from that address, but it can't spend the request tokens
+// and the transfers held there. The previous committee moves them to its successor, which moves them further
+// if the chain was rotated again.
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	// MaxInputsPerTransaction is the maximum number of outputs moved by one migration transaction.
+	// The rest is moved by the next one, after the first is confirmed
+	MaxInputsPerTransaction = 100
+	// maxPendingSigShares is the maximum number of transactions for which signature shares of peers are kept
+	maxPendingSigShares = 16
+)
+
+// Migrator moves all outputs of the address of the previous committee to the address of the next committee.
+// Each node of the previous committee builds the migration transaction from the outputs of the address
+// and sends the share of its signature to the peers. The transaction is deterministic, so the signature is
+// recovered from shares of the quorum of nodes which see the same outputs. A node never signs a transaction
+// it didn't build itself
+type Migrator struct {
+	chainID  coretypes.ChainID
+	from     address.Address
+	to       address.Address
+	dkshare  *tcrypto.DKShare
+	peers    peering.GroupProvider
+	attachID interface{}
+	postTx   func(tx *valuetransaction.Transaction)
+	log      *logger.Logger
+
+	mutex     sync.Mutex
+	tx        *valuetransaction.Transaction
+	posted    bool
+	sigShares map[hashing.HashValue]map[uint16]tbdn.SigShare
+}
+
+// New creates the migrator of the outputs of the address 'from' to the address 'to'.
+// committeeNodes are the nodes of the committee of 'from', in the order of indices of the distributed key.
+// postTx is called with the signed migration transaction
+func New(
+	chainID coretypes.ChainID,
+	from, to address.Address,
+	committeeNodes []string,
+	netProvider peering.NetworkProvider,
+	dksProvider tcrypto.RegistryProvider,
+	postTx func(tx *valuetransaction.Transaction),
+	log *logger.Logger,
+) (*Migrator, error) {
+	dkshare, err := dksProvider.LoadDKShare(&from)
+	if err != nil {
+		return nil, err
+	}
+	if dkshare.Index == nil || len(committeeNodes) != int(dkshare.N) || committeeNodes[*dkshare.Index] != netProvider.Self().NetID() {
+		return nil, fmt.Errorf("the own node %s is not in the committee of %s: %+v",
+			netProvider.Self().NetID(), from.String(), committeeNodes)
+	}
+	peers, err := netProvider.Group(committeeNodes)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Migrator{
+		chainID:   chainID,
+		from:      from,
+		to:        to,
+		dkshare:   dkshare,
+		peers:     peers,
+		postTx:    postTx,
+		log:       log.Named("m-" + util.Short(from.String())),
+		sigShares: make(map[hashing.HashValue]map[uint16]tbdn.SigShare),
+	}
+	pid := peeringID(chainID, from)
+	ret.attachID = peers.Attach(&pid, ret.receivePeerMessage)
+	return ret, nil
+}
+
+// From is the address of the previous committee
+func (m *Migrator) From() address.Address {
+	return m.from
+}
+
+// To is the address of the next committee
+func (m *Migrator) To() address.Address {
+	return m.to
+}
+
+// Close stops receiving messages from the peers
+func (m *Migrator) Close() {
+	m.peers.Detach(m.attachID)
+}
+
+// ReceiveBalances builds the migration transaction from the outputs of the address of the previous committee
+// and sends the share of its signature to the peers. The share is sent again on every update of outputs,
+// so the transaction is eventually signed if messages are lost
+func (m *Migrator) ReceiveBalances(bals map[valuetransaction.ID][]*balance.Balance) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tx, err := migrationTransaction(m.from, m.to, bals)
+	if err != nil {
+		m.log.Errorf("failed to build migration transaction: %v", err)
+		return
+	}
+	if tx == nil {
+		m.tx = nil
+		return
+	}
+	essence := tx.EssenceBytes()
+	if m.tx == nil || !bytes.Equal(m.tx.EssenceBytes(), essence) {
+		m.tx = tx
+		m.posted = false
+		m.log.Infof("migrating outputs of %s to %s", m.from.String(), m.to.String())
+	}
+	sigShare, err := m.dkshare.SignShare(essence)
+	if err != nil {
+		m.log.Errorf("failed to sign migration transaction: %v", err)
+		return
+	}
+	essenceHash := hashing.HashData(essence)
+	m.addSigShare(essenceHash, *m.dkshare.Index, sigShare)
+	m.peers.Broadcast(&peering.PeerMessage{
+		ChainID:     peeringID(m.chainID, m.from),
+		SenderIndex: *m.dkshare.Index,
+		MsgType:     chain.MsgMigrationSigShare,
+		MsgData:     util.MustBytes(&sigShareMsg{essenceHash: essenceHash, sigShare: sigShare}),
+	}, false)
+	m.checkQuorum()
+}
+
+func (m *Migrator) receivePeerMessage(recv *peering.RecvEvent) {
+	if recv.Msg.MsgType != chain.MsgMigrationSigShare {
+		return
+	}
+	msg := &sigShareMsg{}
+	if err := msg.Read(bytes.NewReader(recv.Msg.MsgData)); err != nil {
+		m.log.Warnf("wrong signature share from peer #%d: %v", recv.Msg.SenderIndex, err)
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.addSigShare(msg.essenceHash, recv.Msg.SenderIndex, msg.sigShare)
+	m.checkQuorum()
+}
+
+func (m *Migrator) addSigShare(essenceHash hashing.HashValue, senderIndex uint16, sigShare tbdn.SigShare) {
+	if _, ok := m.sigShares[essenceHash]; !ok {
+		if len(m.sigShares) >= maxPendingSigShares {
+			// shares of outdated transactions
+			m.sigShares = make(map[hashing.HashValue]map[uint16]tbdn.SigShare)
+		}
+		m.sigShares[essenceHash] = make(map[uint16]tbdn.SigShare)
+	}
+	m.sigShares[essenceHash][senderIndex] = sigShare
+}
+
+// checkQuorum recovers the signature of the own migration transaction and posts it,
+// if there are valid signature shares from the quorum of nodes
+func (m *Migrator) checkQuorum() {
+	if m.tx == nil || m.posted {
+		return
+	}
+	essence := m.tx.EssenceBytes()
+	shares := m.sigShares[hashing.HashData(essence)]
+	if len(shares) < int(m.dkshare.T) {
+		return
+	}
+	valid := make([][]byte, 0, len(shares))
+	for senderIndex, sigShare := range shares {
+		if idx, err := sigShare.Index(); err != nil || idx != int(senderIndex) {
+			m.log.Warnf("wrong index of the signature share from peer #%d", senderIndex)
+			continue
+		}
+		if err := m.dkshare.VerifySigShare(essence, sigShare); err != nil {
+			m.log.Warnf("invalid signature share from peer #%d: %v", senderIndex, err)
+			continue
+		}
+		valid = append(valid, sigShare)
+	}
+	if len(valid) < int(m.dkshare.T) {
+		return
+	}
+	signature, err := m.dkshare.RecoverFullSignature(valid, essence)
+	if err != nil {
+		m.log.Errorf("failed to recover signature of migration transaction: %v", err)
+		return
+	}
+	if err = m.tx.PutSignature(signature); err != nil {
+		m.log.Errorf("failed to sign migration transaction: %v", err)
+		return
+	}
+	m.posted = true
+	// each node of the quorum posts the same transaction
+	m.log.Infof("posting migration transaction %s", m.tx.ID().String())
+	m.postTx(m.tx)
+}
+
+// migrationTransaction builds the transaction which moves outputs of the address 'from' to the address 'to'.
+// Colors of tokens are preserved, so request tokens can be consumed by the next committee.
+// Returns nil if there are no outputs
+func migrationTransaction(from, to address.Address, bals map[valuetransaction.ID][]*balance.Balance) (*valuetransaction.Transaction, error) {
+	txids := make([]valuetransaction.ID, 0, len(bals))
+	for txid, b := range bals {
+		if len(b) > 0 {
+			txids = append(txids, txid)
+		}
+	}
+	if len(txids) == 0 {
+		return nil, nil
+	}
+	sort.Slice(txids, func(i, j int) bool {
+		return bytes.Compare(txids[i][:], txids[j][:]) < 0
+	})
+	if len(txids) > MaxInputsPerTransaction {
+		txids = txids[:MaxInputsPerTransaction]
+	}
+	inputs := make([]valuetransaction.OutputID, len(txids))
+	sums := make(map[balance.Color]int64)
+	for i, txid := range txids {
+		inputs[i] = valuetransaction.NewOutputID(from, txid)
+		for _, b := range bals[txid] {
+			sums[b.Color] += b.Value
+		}
+	}
+	outBals := make([]*balance.Balance, 0, len(sums))
+	for col, v := range sums {
+		if v > 0 {
+			outBals = append(outBals, balance.New(col, v))
+		}
+	}
+	sort.Slice(outBals, func(i, j int) bool {
+		return bytes.Compare(outBals[i].Color[:], outBals[j].Color[:]) < 0
+	})
+	return valuetransaction.New(
+		valuetransaction.NewInputs(inputs...),
+		valuetransaction.NewOutputs(map[address.Address][]*balance.Balance{to: outBals}),
+	), nil
+}
+
+// peeringID separates messages of the migration from messages of the chain among the same nodes
+func peeringID(chainID coretypes.ChainID, from address.Address) coretypes.ChainID {
+	h := hashing.HashData([]byte("migration"), chainID[:], from[:])
+	var ret coretypes.ChainID
+	copy(ret[:], h[:])
+	return ret
+}
+
+type sigShareMsg struct {
+	essenceHash hashing.HashValue
+	sigShare    tbdn.SigShare
+}
+
+func (msg *sigShareMsg) Write(w io.Writer) error {
+	if _, err := w.Write(msg.essenceHash[:]); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, msg.sigShare)
+}
+
+func (msg *sigShareMsg) Read(r io.Reader) error {
+	if err := util.ReadHashValue(r, &msg.essenceHash); err != nil {
+		return err
+	}
+	var err error
+	msg.sigShare, err = util.ReadBytes16(r)
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package migration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dkg"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/txutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestMigrationTransaction(t *testing.T) {
+	from := address.Random()
+	to := address.Random()
+	tx, err := migrationTransaction(from, to, nil)
+	require.NoError(t, err)
+	require.Nil(t, tx)
+
+	color := balance.Color(valuetransaction.RandomID())
+	bals := map[valuetransaction.ID][]*balance.Balance{
+		valuetransaction.RandomID(): {balance.New(balance.ColorIOTA, 10), balance.New(color, 1)},
+		valuetransaction.RandomID(): {balance.New(balance.ColorIOTA, 5)},
+	}
+	tx, err = migrationTransaction(from, to, bals)
+	require.NoError(t, err)
+	numOutputs := 0
+	tx.Outputs().ForEach(func(addr address.Address, outBals []*balance.Balance) bool {
+		require.EqualValues(t, to, addr)
+		require.EqualValues(t, 15, txutil.BalanceOfColor(outBals, balance.ColorIOTA))
+		require.EqualValues(t, 1, txutil.BalanceOfColor(outBals, color))
+		numOutputs++
+		return true
+	})
+	require.EqualValues(t, 1, numOutputs)
+
+	// the transaction doesn't depend on the order of outputs
+	tx2, err := migrationTransaction(from, to, bals)
+	require.NoError(t, err)
+	require.EqualValues(t, tx.EssenceBytes(), tx2.EssenceBytes())
+
+	// outputs of one transaction are moved by the next one
+	for i := 0; i < MaxInputsPerTransaction; i++ {
+		bals[valuetransaction.RandomID()] = []*balance.Balance{balance.New(balance.ColorIOTA, 1)}
+	}
+	tx, err = migrationTransaction(from, to, bals)
+	require.NoError(t, err)
+	numInputs := 0
+	tx.Inputs().ForEach(func(_ valuetransaction.OutputID) bool {
+		numInputs++
+		return true
+	})
+	require.EqualValues(t, MaxInputsPerTransaction, numInputs)
+}
+
+func TestMigrator(t *testing.T) {
+	log := testutil.NewLogger(t)
+	defer log.Sync()
+
+	const peerCount = 4
+	const threshold = 3
+	peerNetIDs := make([]string, peerCount)
+	peerPubs := make([]kyber.Point, peerCount)
+	peerSecs := make([]kyber.Scalar, peerCount)
+	suite := pairing.NewSuiteBn256()
+	for i := range peerNetIDs {
+		peerPair := key.NewKeyPair(suite)
+		peerNetIDs[i] = fmt.Sprintf("P%02d", i)
+		peerSecs[i] = peerPair.Private
+		peerPubs[i] = peerPair.Public
+	}
+	peeringNetwork := testutil.NewPeeringNetwork(
+		peerNetIDs, peerPubs, peerSecs, 10000,
+		testutil.NewPeeringNetReliable(),
+		testutil.WithLevel(log, logger.LevelWarn, false),
+	)
+	networkProviders := peeringNetwork.NetworkProviders()
+	registries := make([]*testutil.DkgRegistryProvider, peerCount)
+	dkgNodes := make([]*dkg.Node, peerCount)
+	for i := range peerNetIDs {
+		registries[i] = testutil.NewDkgRegistryProvider(suite)
+		dkgNodes[i] = dkg.NewNode(peerSecs[i], peerPubs[i], suite, networkProviders[i], registries[i],
+			testutil.WithLevel(log, logger.LevelWarn, false))
+	}
+	dkShare, err := dkgNodes[0].GenerateDistributedKey(peerNetIDs, peerPubs, threshold, 1*time.Second, 2*time.Second, 100*time.Second)
+	require.NoError(t, err)
+
+	chainID := coretypes.NewRandomChainID()
+	from := *dkShare.Address
+	to := address.Random()
+	posted := make(chan *valuetransaction.Transaction, peerCount)
+	migrators := make([]*Migrator, peerCount)
+	for i := range migrators {
+		migrators[i], err = New(chainID, from, to, peerNetIDs, networkProviders[i], registries[i], func(tx *valuetransaction.Transaction) {
+			posted <- tx
+		}, testutil.WithLevel(log, logger.LevelWarn, false))
+		require.NoError(t, err)
+		defer migrators[i].Close()
+	}
+	// the node must be in the committee
+	_, err = New(chainID, from, to, peerNetIDs, networkProviders[0], registries[1], nil, log)
+	require.Error(t, err)
+
+	bals := map[valuetransaction.ID][]*balance.Balance{
+		valuetransaction.RandomID(): {balance.New(balance.ColorIOTA, 10)},
+		valuetransaction.RandomID(): {balance.New(balance.Color(valuetransaction.RandomID()), 1)},
+	}
+	// one node is behind and sees only part of outputs: its share doesn't count
+	migrators[peerCount-1].ReceiveBalances(map[valuetransaction.ID][]*balance.Balance{
+		valuetransaction.RandomID(): {balance.New(balance.ColorIOTA, 10)},
+	})
+	for i := 0; i < threshold; i++ {
+		migrators[i].ReceiveBalances(bals)
+	}
+
+	select {
+	case tx := <-posted:
+		require.True(t, tx.SignaturesValid())
+		require.Len(t, tx.Signatures(), 1)
+		require.EqualValues(t, from, tx.Signatures()[0].Address())
+		expected, err := migrationTransaction(from, to, bals)
+		require.NoError(t, err)
+		require.EqualValues(t, expected.EssenceBytes(), tx.EssenceBytes())
+	case <-time.After(10 * time.Second):
+		t.Fatal("migration transaction was not posted")
+	}
+}
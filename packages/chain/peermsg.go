@@ -9,6 +9,7 @@ import (
 
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
 )
@@ -234,6 +235,15 @@ func (msg *StateUpdateMsg) Read(r io.Reader) error {
 	return nil
 }
 
+func (msg *OffLedgerRequestMsg) Write(w io.Writer) error {
+	return msg.Request.Write(w)
+}
+
+func (msg *OffLedgerRequestMsg) Read(r io.Reader) error {
+	msg.Request = &sctransaction.OffLedgerRequest{}
+	return msg.Request.Read(r)
+}
+
 func (msg *TestTraceMsg) Write(w io.Writer) error {
 	if !util.ValidPermutation(msg.Sequence) {
 		panic(fmt.Sprintf("Write: wrong permutation %+v", msg.Sequence))
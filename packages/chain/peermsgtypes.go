@@ -26,6 +26,8 @@ const (
 	MsgStateUpdate             = 6 + peering.FirstUserMsgCode
 	MsgBatchHeader             = 7 + peering.FirstUserMsgCode
 	MsgTestTrace               = 8 + peering.FirstUserMsgCode
+	MsgOffLedgerRequest        = 9 + peering.FirstUserMsgCode
+	MsgMigrationSigShare       = 10 + peering.FirstUserMsgCode
 )
 
 type TimerTick int
@@ -115,6 +117,13 @@ type StateUpdateMsg struct {
 	IndexInTheBlock uint16
 }
 
+// off-ledger request. It is sent to committee peers by the node
+// which received the request from the web API
+type OffLedgerRequestMsg struct {
+	PeerMsgHeader
+	Request *sctransaction.OffLedgerRequest
+}
+
 // used for testing of the communications
 type TestTraceMsg struct {
 	PeerMsgHeader
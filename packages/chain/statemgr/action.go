@@ -14,6 +14,7 @@ import (
 	"github.com/iotaledger/wasp/packages/publisher"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/plugins/nodeconn"
 )
 
@@ -87,6 +88,17 @@ func (sm *stateManager) checkStateApproval() bool {
 
 	// found a pending block which is approved by the nextStateTransaction
 
+	// the origin transaction and state sections written before the state root was introduced don't commit to it
+	if approved := sm.nextStateTransaction.MustState(); pending.nextState.BlockIndex() > 0 && approved.HasStateRoot() {
+		stateRoot := pending.nextState.StateRoot()
+		if approvedRoot := approved.StateRoot(); stateRoot != approvedRoot {
+			sm.log.Errorf("major inconsistency: state root %s of block #%d is not equal to the state root %s approved by tx %s",
+				stateRoot.String(), pending.nextState.BlockIndex(), approvedRoot.String(), sm.nextStateTransaction.ID().String())
+			delete(sm.pendingBlocks, varStateHash)
+			return false
+		}
+	}
+
 	if pending.block.StateTransactionID() == niltxid {
 		// not committed yet block. Link it to the transaction
 		pending.block.WithStateTransaction(sm.nextStateTransaction.ID())
@@ -100,6 +112,7 @@ func (sm *stateManager) checkStateApproval() bool {
 		}
 	}
 
+	var deployed []*root.ContractRecord
 	if sm.solidStateValid || sm.solidState == nil {
 		if sm.solidState == nil {
 			// pre-origin
@@ -110,6 +123,12 @@ func (sm *stateManager) checkStateApproval() bool {
 				return false
 			}
 		}
+		// contracts are found before committing, while the db still contains the previous state
+		var err error
+		deployed, err = deployedContracts(sm.solidState, pending.block)
+		if err != nil {
+			sm.log.Errorf("failed to find contracts deployed by block #%d: %v", pending.nextState.BlockIndex(), err)
+		}
 		if err := pending.nextState.CommitToDb(pending.block); err != nil {
 			sm.log.Errorw("failed to save state at index #%d", pending.nextState.BlockIndex())
 			return false
@@ -166,8 +185,14 @@ func (sm *stateManager) checkStateApproval() bool {
 			strconv.Itoa(int(sm.solidState.BlockIndex())),
 			strconv.Itoa(i),
 			strconv.Itoa(int(pending.block.Size())),
+			strconv.FormatBool(requestSucceeded(sm.solidState, reqid)),
 		)
 	}
+	publishDeployedContracts(sm.chain.ID(), sm.solidState.BlockIndex(), deployed)
+	// the chain token has been moved to another address: the committee was rotated by the chain owner
+	if chainAddress := *sm.approvingTransaction.MustProperties().MustChainAddress(); chainAddress != sm.chain.Address() {
+		sm.chain.RotateTo(chainAddress)
+	}
 	return true
 }
 
@@ -0,0 +1,60 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package statemgr
+
+import (
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// deployedContracts returns records of the contracts deployed by the block.
+// 'prev' is the state before the block is committed, nil before the origin
+func deployedContracts(prev state.VirtualState, block state.Block) ([]*root.ContractRecord, error) {
+	updated := dict.New()
+	block.ForEach(func(_ uint16, stateUpd state.StateUpdate) bool {
+		stateUpd.Mutations().ApplyTo(updated)
+		return true
+	})
+	var prevVars kv.KVStore = dict.New()
+	if prev != nil {
+		prevVars = prev.Variables()
+	}
+	rootPartition := kv.Key(root.Interface.Hname().Bytes())
+	return root.NewContractRecords(subrealm.New(prevVars, rootPartition), subrealm.New(updated, rootPartition))
+}
+
+// publishDeployedContracts publishes a 'deploy' message for each contract deployed by the block
+func publishDeployedContracts(chainID *coretypes.ChainID, blockIndex uint32, recs []*root.ContractRecord) {
+	for _, rec := range recs {
+		publisher.Publish("deploy",
+			chainID.String(),
+			strconv.Itoa(int(blockIndex)),
+			coretypes.Hn(rec.Name).String(),
+			rec.ProgramHash.String(),
+			rec.Name,
+		)
+	}
+}
+
+// requestSucceeded returns true if the receipt of the request in the solid state is successful
+func requestSucceeded(solidState state.VirtualState, reqid *coretypes.RequestID) bool {
+	blocklogPartition := subrealm.New(solidState.Variables(), kv.Key(blocklog.Interface.Hname().Bytes()))
+	data := blocklog.GetRequestReceiptsR(blocklogPartition).MustGetAt(reqid[:])
+	if data == nil {
+		return false
+	}
+	receipt, err := blocklog.DecodeRequestReceipt(data)
+	if err != nil {
+		return false
+	}
+	return receipt.Succeeded()
+}
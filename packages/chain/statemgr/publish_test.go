@@ -0,0 +1,75 @@
+package statemgr
+
+import (
+	"testing"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func setContractRecord(vars kv.KVStore, rec *root.ContractRecord) {
+	registry := collections.NewMap(subrealm.New(vars, kv.Key(root.Interface.Hname().Bytes())), root.VarContractRegistry)
+	registry.MustSetAt(coretypes.Hn(rec.Name).Bytes(), root.EncodeContractRecord(rec))
+}
+
+func stateUpdateWithRecords(reqIndex uint16, recs ...*root.ContractRecord) state.StateUpdate {
+	d := dict.New()
+	for _, rec := range recs {
+		setContractRecord(d, rec)
+	}
+	reqid := coretypes.NewRequestID(valuetransaction.ID{}, reqIndex)
+	ret := state.NewStateUpdate(&reqid)
+	d.ForEachDeterministic(func(key kv.Key, value []byte) bool {
+		ret.Mutations().Add(buffered.NewMutationSet(key, value))
+		return true
+	})
+	return ret
+}
+
+func TestDeployedContracts(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	prev := state.NewVirtualState(mapdb.NewMapDB(), &chainID)
+
+	existing := &root.ContractRecord{Name: "existing", ProgramHash: hashing.RandomHash(nil)}
+	setContractRecord(prev.Variables(), existing)
+
+	// the record of the existing contract is updated, e.g. by the fee change or the upgrade
+	updated := *existing
+	updated.OwnerFee = 10
+	new1 := &root.ContractRecord{Name: "new1", ProgramHash: hashing.RandomHash(nil)}
+	new2 := &root.ContractRecord{Name: "new2", ProgramHash: hashing.RandomHash(nil), Description: "second"}
+
+	block, err := state.NewBlock([]state.StateUpdate{
+		stateUpdateWithRecords(0, &updated, new2),
+		stateUpdateWithRecords(1, new1),
+	})
+	require.NoError(t, err)
+
+	recs, err := deployedContracts(prev, block)
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	names := map[string]bool{recs[0].Name: true, recs[1].Name: true}
+	require.EqualValues(t, map[string]bool{"new1": true, "new2": true}, names)
+	require.True(t, coretypes.Hn(recs[0].Name) < coretypes.Hn(recs[1].Name))
+	for _, rec := range recs {
+		if rec.Name == "new2" {
+			require.EqualValues(t, new2.ProgramHash, rec.ProgramHash)
+			require.EqualValues(t, "second", rec.Description)
+		}
+	}
+
+	// before the origin all contracts in the block are new
+	recs, err = deployedContracts(nil, block)
+	require.NoError(t, err)
+	require.Len(t, recs, 3)
+}
@@ -143,3 +143,12 @@ func ReadAgentID(r io.Reader, agentID *AgentID) error {
 func (a AgentID) Base58() string {
 	return base58.Encode(a[:])
 }
+
+// NewAgentIDFromBase58 decodes the base58 representation of the binary agent ID
+func NewAgentIDFromBase58(s string) (AgentID, error) {
+	data, err := base58.Decode(s)
+	if err != nil {
+		return AgentID{}, err
+	}
+	return NewAgentIDFromBytes(data)
+}
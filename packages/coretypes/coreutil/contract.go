@@ -12,6 +12,14 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/dict"
 )
 
+// CoreContractRoot is the name of the 'root' core contract.
+// Other core contracts use it to call the 'root' without importing the package
+const CoreContractRoot = "root"
+
+// CoreFuncRootCountProgramReferences is the view of the 'root' contract which returns the number of contracts
+// in the registry with the program hash. The 'blob' contract calls it before deleting the blob
+const CoreFuncRootCountProgramReferences = "countProgramReferences"
+
 // ContractInterface represents smart contract interface
 type ContractInterface struct {
 	Name        string
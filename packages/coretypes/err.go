@@ -6,5 +6,6 @@ package coretypes
 import "errors"
 
 var (
-	ErrWrongDataLength = errors.New("wrong data length")
+	ErrWrongDataLength    = errors.New("wrong data length")
+	ErrEntryPointNotFound = errors.New("entry point not found")
 )
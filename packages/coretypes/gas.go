@@ -0,0 +1,37 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import "errors"
+
+// Gas prices of the operations charged to the gas budget of the request.
+// Gas is only metered during the call to the target entry point of the request.
+// View calls are not charged, but the Wasm code of a view is bounded by DefaultGasBudget
+const (
+	// GasSandboxCall is the fixed price of the sandbox calls which have effect on the chain:
+	// Call, DeployContract, TransferToAddress, PostRequest and Event
+	GasSandboxCall = int64(100)
+	// GasStateRead is the price of reading one key (or iterating one key/value pair) of the state
+	GasStateRead = int64(1)
+	// GasStateWrite is the price of writing or deleting one key of the state
+	GasStateWrite = int64(10)
+	// GasPerByteWritten is the price of each byte of the value written to the state
+	GasPerByteWritten = int64(1)
+	// GasWasmHostCall is the price of each call from the Wasm code to the host
+	GasWasmHostCall = int64(1)
+	// GasPerWasmByte is the price of each byte passed between the Wasm code and the host
+	GasPerWasmByte = int64(1)
+	// WasmFuelPerGas is the number of units of Wasm fuel charged as one unit of gas.
+	// Each executed Wasm instruction consumes about one unit of fuel
+	WasmFuelPerGas = int64(100)
+
+	// DefaultGasBudget is the gas budget for one request if it is not set in the chain
+	DefaultGasBudget = int64(5000000)
+	// MinGasBudget is the smallest gas budget the chain owner can set.
+	// It prevents the chain from being locked by a budget too small for the owner to change it back
+	MinGasBudget = int64(10000)
+)
+
+// ErrGasBudgetExceeded is the panic value used to abort the request when the gas budget is exhausted
+var ErrGasBudgetExceeded = errors.New("gas budget exceeded")
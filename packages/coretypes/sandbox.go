@@ -16,6 +16,9 @@ import (
 type Sandbox interface {
 	// ChainOwnerID AgentID of the current owner of the chain
 	ChainOwnerID() AgentID
+	// CheckAuthorizationByChainOwner checks if the agent is authorized to act as the owner of the chain:
+	// the chain owner or, if the chain is governed by the M-of-N owner set, the 'root' executing the approved proposal
+	CheckAuthorizationByChainOwner(agentID AgentID) bool
 	// ContractCreator agentID which deployed contract
 	ContractCreator() AgentID
 	// ContractID is the ID of the current contract. Take chainID with ctx.ContractID().ChainID()
@@ -59,6 +62,14 @@ type Sandbox interface {
 	Log() LogInterface
 	// Event publishes "vmmsg" message through Publisher on nanomsg. It also logs locally, but it is not the same thing
 	Event(msg string)
+	// EmitEvent stores the typed event in the 'eventlog' and publishes it as Event does.
+	// The event is indexed by the contract, the name, each of the topics, the request ID and the block index
+	EmitEvent(name string, topics [][]byte, payload dict.Dict)
+	// BurnGas charges gas to the budget of the current request.
+	// If the budget is exhausted, the request is aborted and all its effects are rolled back
+	BurnGas(gas int64)
+	// GasRemaining returns gas remaining in the budget of the current request
+	GasRemaining() int64
 	//
 	Utils() Utils
 }
@@ -111,7 +111,7 @@ func fetchTotalAssets(chain chain.Chain) (map[balance.Color]int64, error) {
 	return accounts.DecodeBalances(bal)
 }
 
-func fetchBlobs(chain chain.Chain) (map[hashing.HashValue]uint32, error) {
+func fetchBlobs(chain chain.Chain) (map[hashing.HashValue]*blob.BlobRecord, error) {
 	ret, err := callView(chain, blob.Interface.Hname(), blob.FuncListBlobs, nil)
 	if err != nil {
 		return nil, err
@@ -130,7 +130,7 @@ type ChainTemplateParams struct {
 	RootInfo     RootInfo
 	Accounts     []coretypes.AgentID
 	TotalAssets  map[balance.Color]int64
-	Blobs        map[hashing.HashValue]uint32
+	Blobs        map[hashing.HashValue]*blob.BlobRecord
 	Committee    struct {
 		Size       uint16
 		Quorum     uint16
@@ -209,13 +209,15 @@ const tplChain = `
 						<tr>
 							<th style="flex: 2">Hash</th>
 							<th>Size (bytes)</th>
+							<th>References</th>
 						</tr>
 					</thead>
 					<tbody>
-					{{range $hash, $size := .Blobs}}
+					{{range $hash, $rec := .Blobs}}
 						<tr>
 							<td style="flex: 2"><a href="{{ uri "chainBlob" $chainid (hashref $hash) }}"><tt>{{ hashref $hash }}</tt></a></td>
-							<td>{{ $size }}</td>
+							<td>{{ $rec.Size }}</td>
+							<td>{{ $rec.References }}</td>
 						</tr>
 					{{end}}
 					</tbody>
@@ -69,6 +69,21 @@ func handleChainContract(c echo.Context) error {
 			}
 		}
 
+		r, err = callView(chain, eventlog.Interface.Hname(), eventlog.FuncGetEvents, codec.MakeDict(map[string]interface{}{
+			eventlog.ParamContractHname: codec.EncodeHname(hname),
+		}))
+		if err != nil {
+			return err
+		}
+		events := collections.NewArrayReadOnly(r, eventlog.ParamEvents)
+		result.Events = make([]*eventlog.Event, events.MustLen())
+		for i := uint16(0); i < events.MustLen(); i++ {
+			result.Events[i], err = eventlog.DecodeEvent(events.MustGetAt(i))
+			if err != nil {
+				return err
+			}
+		}
+
 		result.RootInfo, err = fetchRootInfo(chain)
 		if err != nil {
 			return err
@@ -86,6 +101,7 @@ type ChainContractTemplateParams struct {
 
 	ContractRecord *root.ContractRecord
 	Log            []*collections.TimestampedLogRecord
+	Events         []*eventlog.Event
 	RootInfo       RootInfo
 }
 
@@ -105,6 +121,10 @@ const tplChainContract = `
 				<dt>Description</dt><dd><tt>{{trim 50 $c.Description}}</tt></dd>
 				<dt>Program hash</dt><dd><tt>{{$c.ProgramHash.String}}</tt></dd>
 				{{if $c.HasCreator}}<dt>Creator</dt><dd>{{ template "agentid" (args $chainid $c.Creator) }}</dd>{{end}}
+				{{- if $c.FeeBasisPoints }}
+				<dt>Owner fee</dt><dd><tt>{{- $c.OwnerFee }} bp of {{ $rootinfo.FeeColor -}}</tt></dd>
+				<dt>Validator fee</dt><dd><tt>{{- $c.ValidatorFee }} bp of {{ $rootinfo.FeeColor -}}</tt></dd>
+				{{- else }}
 				<dt>Owner fee</dt><dd>
 					{{- if $c.OwnerFee -}}
 						<tt>{{- $c.OwnerFee }} {{ $rootinfo.FeeColor -}}</tt>
@@ -119,6 +139,12 @@ const tplChainContract = `
 						<tt>{{- $rootinfo.DefaultValidatorFee }} {{ $rootinfo.FeeColor }}</tt> (chain default)
 					{{- end -}}
 				</dd>
+				{{- end }}
+				{{- range $ep, $fee := $c.EntryPointFees }}
+				<dt>Fee of entry point <tt>{{ $ep }}</tt></dt><dd>
+					<tt>owner: {{ $fee.OwnerFee }}, validator: {{ $fee.ValidatorFee }}{{ if $fee.BasisPoints }} bp of{{ end }} {{ $rootinfo.FeeColor }}</tt>
+				</dd>
+				{{- end }}
 			</dl>
 		</div>
 
@@ -131,6 +157,16 @@ const tplChainContract = `
 				{{ end }}
 			</dl>
 		</div>
+
+		<div class="card fluid">
+			<h3 class="section">Events</h3>
+			<dl style="align-items: center">
+				{{ range $_, $e := .Events }}
+					<dt><tt>{{ formatTimestamp $e.Timestamp }}</tt> <tt>#{{ $e.BlockIndex }}</tt></dt>
+					<dd><pre>{{- trim 1000 $e.String -}}</pre></dd>
+				{{ end }}
+			</dl>
+		</div>
 		{{ template "ws" .ChainID }}
 	{{else}}
 		<div class="card fluid error">Not found.</div>
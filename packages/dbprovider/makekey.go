@@ -15,6 +15,8 @@ const (
 	ObjectTypeNodeIdentity
 	ObjectTypeBlobCache
 	ObjectTypeBlobCacheTTL
+	ObjectTypeBlobCacheAccess
+	ObjectTypeBlobCacheSize
 )
 
 // MakeKey makes key within the partition. It consists to one byte for object type
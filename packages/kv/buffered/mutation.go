@@ -46,6 +46,9 @@ type MutationSequence interface {
 
 	Add(mut Mutation)
 
+	// Truncate removes all mutations added after the first n
+	Truncate(n int)
+
 	ApplyTo(w kv.KVStoreWriter)
 }
 
@@ -155,6 +158,21 @@ func (ms *mutationSequence) Add(mut Mutation) {
 	ms.latestByKey[mut.Key()] = &mut
 }
 
+func (ms *mutationSequence) Truncate(n int) {
+	if n >= len(ms.muts) {
+		return
+	}
+	// copy, because the underlying array may be shared with clones
+	muts := make([]Mutation, n)
+	copy(muts, ms.muts[:n])
+	ms.muts = muts
+	ms.latestByKey = make(map[kv.Key]*Mutation)
+	for _, mut := range ms.muts {
+		mut := mut
+		ms.latestByKey[mut.Key()] = &mut
+	}
+}
+
 func (ms *mutationSequence) ApplyTo(w kv.KVStoreWriter) {
 	for _, mut := range ms.muts {
 		mut.ApplyTo(w)
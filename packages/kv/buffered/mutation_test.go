@@ -51,3 +51,22 @@ func TestMutationSequenceMarshaling(t *testing.T) {
 
 	assert.EqualValues(t, util.GetHashValue(ms), util.GetHashValue(ms2))
 }
+
+func TestMutationSequenceTruncate(t *testing.T) {
+	ms := NewMutationSequence()
+	ms.Add(NewMutationSet("k1", []byte("v1")))
+	ms.Add(NewMutationSet("k2", []byte("v2")))
+	clone := ms.Clone()
+
+	ms.Add(NewMutationSet("k1", []byte("v3")))
+	ms.Add(NewMutationDel("k2"))
+	ms.Add(NewMutationSet("k3", []byte("v4")))
+	assert.EqualValues(t, 5, ms.Len())
+
+	ms.Truncate(2)
+	assert.EqualValues(t, 2, ms.Len())
+	assert.EqualValues(t, []byte("v1"), ms.Latest("k1").Value())
+	assert.EqualValues(t, []byte("v2"), ms.Latest("k2").Value())
+	assert.Nil(t, ms.Latest("k3"))
+	assert.EqualValues(t, util.GetHashValue(clone), util.GetHashValue(ms))
+}
@@ -12,7 +12,8 @@ import (
 // TimestampedLog represents a dynamic append-only array of records, where each record
 // is indexed sequentially and consistently timestamped.
 // The sequence of timestamps is considered consistent if for any indices i<j, Ti<=Tj,
-// i.e. non-decreasing
+// i.e. non-decreasing.
+// The earliest records can be pruned. Indices of the remaining records do not change
 type TimestampedLog struct {
 	*ImmutableTimestampedLog
 	kvw kv.KVStoreWriter
@@ -54,6 +55,7 @@ func NewTimestampedLogReadOnly(kv kv.KVStoreReader, name kv.Key) *ImmutableTimes
 const (
 	tslSizeKeyCode = byte(iota)
 	tslElemKeyCode
+	tslFirstKeyCode
 )
 
 func (l *TimestampedLog) Immutable() *ImmutableTimestampedLog {
@@ -75,6 +77,13 @@ func (l *ImmutableTimestampedLog) getElemKey(idx uint32) kv.Key {
 	return kv.Key(buf.Bytes())
 }
 
+func (l *ImmutableTimestampedLog) getFirstKey() kv.Key {
+	var buf bytes.Buffer
+	buf.Write([]byte(l.name))
+	buf.WriteByte(tslFirstKeyCode)
+	return kv.Key(buf.Bytes())
+}
+
 func (l *TimestampedLog) setSize(size uint32) {
 	if size == 0 {
 		l.kvw.Del(l.getSizeKey())
@@ -114,6 +123,100 @@ func (l *ImmutableTimestampedLog) MustLen() uint32 {
 	return n
 }
 
+// FirstIndex returns the index of the earliest record which is not pruned.
+// It is equal to Len() if all records are pruned
+func (l *ImmutableTimestampedLog) FirstIndex() (uint32, error) {
+	v, err := l.kvr.Get(l.getFirstKey())
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 4 {
+		return 0, errors.New("corrupted data")
+	}
+	return util.MustUint32From4Bytes(v), nil
+}
+
+func (l *ImmutableTimestampedLog) MustFirstIndex() uint32 {
+	n, err := l.FirstIndex()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// NumRetained returns the number of records which are not pruned
+func (l *ImmutableTimestampedLog) NumRetained() (uint32, error) {
+	n, err := l.Len()
+	if err != nil {
+		return 0, err
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	return n - first, nil
+}
+
+func (l *ImmutableTimestampedLog) MustNumRetained() uint32 {
+	n, err := l.NumRetained()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// Prune deletes all records with indices less than toIdx
+func (l *TimestampedLog) Prune(toIdx uint32) error {
+	n, err := l.Len()
+	if err != nil {
+		return err
+	}
+	if toIdx > n {
+		return fmt.Errorf("TimestampedLog.Prune: index %d out of range, length %d", toIdx, n)
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return err
+	}
+	if toIdx <= first {
+		return nil
+	}
+	for i := first; i < toIdx; i++ {
+		l.kvw.Del(l.getElemKey(i))
+	}
+	l.kvw.Set(l.getFirstKey(), util.Uint32To4Bytes(toIdx))
+	return nil
+}
+
+func (l *TimestampedLog) MustPrune(toIdx uint32) {
+	if err := l.Prune(toIdx); err != nil {
+		panic(err)
+	}
+}
+
+// DelAt deletes the record at the index. Indices of other records are not changed,
+// the deleted record is loaded as nil, like the pruned one
+func (l *TimestampedLog) DelAt(idx uint32) error {
+	n, err := l.Len()
+	if err != nil {
+		return err
+	}
+	if idx >= n {
+		return fmt.Errorf("TimestampedLog.DelAt: index %d out of range, length %d", idx, n)
+	}
+	l.kvw.Del(l.getElemKey(idx))
+	return nil
+}
+
+func (l *TimestampedLog) MustDelAt(idx uint32) {
+	if err := l.DelAt(idx); err != nil {
+		panic(err)
+	}
+}
+
 // Append appends data with timestamp to the end of the log.
 // Returns error if timestamp is inconsistent, i.e. less than the latest timestamp
 func (l *TimestampedLog) Append(ts int64, data []byte) error {
@@ -157,13 +260,17 @@ func (l *ImmutableTimestampedLog) MustLatest() int64 {
 	return ts
 }
 
-// latest loads latest timestamp from the DB
+// latest loads latest timestamp from the DB. It is 0 if all records are pruned
 func (l *ImmutableTimestampedLog) latest() (int64, error) {
 	idx, err := l.Len()
 	if err != nil {
 		return 0, err
 	}
-	if idx == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if idx == first {
 		return 0, nil
 	}
 	data, err := l.kvr.Get(l.getElemKey(idx - 1))
@@ -176,16 +283,20 @@ func (l *ImmutableTimestampedLog) latest() (int64, error) {
 	return int64(util.MustUint64From8Bytes(data[:8])), nil
 }
 
-// Earliest returns timestamp of the first record in the log, if any, or otherwise it is 0
+// Earliest returns timestamp of the first record in the log which is not pruned, if any, or otherwise it is 0
 func (l *ImmutableTimestampedLog) Earliest() (int64, error) {
 	n, err := l.Len()
 	if err != nil {
 		return 0, err
 	}
-	if n == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if n == first {
 		return 0, nil
 	}
-	data, err := l.kvr.Get(l.getElemKey(0))
+	data, err := l.kvr.Get(l.getElemKey(first))
 	if err != nil {
 		return 0, err
 	}
@@ -211,6 +322,7 @@ func (l *ImmutableTimestampedLog) getRawRecordAtIndex(idx uint32) ([]byte, error
 	if idx >= n {
 		return nil, nil
 	}
+	// the record is nil if pruned
 	v, err := l.kvr.Get(l.getElemKey(idx))
 	if err != nil {
 		return nil, err
@@ -284,7 +396,11 @@ func (l *ImmutableTimestampedLog) TakeTimeSlice(fromTs, toTs int64) (*TimeSlice,
 	if err != nil {
 		return nil, err
 	}
-	if n == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	if n == first {
 		// empty slice
 		return nil, nil
 	}
@@ -305,14 +421,14 @@ func (l *ImmutableTimestampedLog) TakeTimeSlice(fromTs, toTs int64) (*TimeSlice,
 	if fromTs > toTs {
 		return nil, nil
 	}
-	lowerIdx, ok, err := l.findLowerIdx(fromTs, 0, n-1)
+	lowerIdx, ok, err := l.findLowerIdx(fromTs, first, n-1)
 	if err != nil {
 		return nil, err
 	}
 	if !ok {
 		return nil, nil
 	}
-	upperIdx, ok, err := l.findUpperIdx(toTs, 0, n-1)
+	upperIdx, ok, err := l.findUpperIdx(toTs, first, n-1)
 	if err != nil {
 		return nil, err
 	}
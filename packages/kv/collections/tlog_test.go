@@ -109,3 +109,67 @@ func TestTlogBig(t *testing.T) {
 	assert.EqualValues(t, tl.MustLen(), tslice.NumPoints())
 	assert.EqualValues(t, tl.MustLen(), tslice.NumPoints())
 }
+
+func TestTlogPrune(t *testing.T) {
+	vars := dict.New()
+	tl := NewTimestampedLog(vars, "testTlog")
+	for i := 0; i < 10; i++ {
+		tl.MustAppend(int64(100+i), util.Uint32To4Bytes(uint32(i)))
+	}
+	assert.Zero(t, tl.MustFirstIndex())
+
+	tl.MustPrune(4)
+	assert.EqualValues(t, 10, tl.MustLen())
+	assert.EqualValues(t, 4, tl.MustFirstIndex())
+	assert.EqualValues(t, 6, tl.MustNumRetained())
+	assert.EqualValues(t, 104, tl.MustEarliest())
+	assert.EqualValues(t, 109, tl.MustLatest())
+	assert.Nil(t, tl.MustLoadRecordsRaw(3, 3, false)[0])
+
+	// pruned records are not in the time slice
+	tts := tl.MustTakeTimeSlice(0, 0)
+	first, last := tts.FromToIndices()
+	assert.EqualValues(t, 4, first)
+	assert.EqualValues(t, 9, last)
+	tts = tl.MustTakeTimeSlice(100, 105)
+	first, last = tts.FromToIndices()
+	assert.EqualValues(t, 4, first)
+	assert.EqualValues(t, 5, last)
+
+	// pruning less does nothing
+	tl.MustPrune(2)
+	assert.EqualValues(t, 4, tl.MustFirstIndex())
+	assert.Panics(t, func() {
+		tl.MustPrune(11)
+	})
+
+	tl.MustPrune(10)
+	assert.Zero(t, tl.MustNumRetained())
+	assert.Zero(t, tl.MustEarliest())
+	assert.True(t, tl.MustTakeTimeSlice(0, 0).IsEmpty())
+
+	// indices continue after the pruned records
+	tl.MustAppend(200, nil)
+	assert.EqualValues(t, 11, tl.MustLen())
+	assert.EqualValues(t, 200, tl.MustEarliest())
+}
+
+func TestTlogDelAt(t *testing.T) {
+	vars := dict.New()
+	tl := NewTimestampedLog(vars, "testTlog")
+	for i := 0; i < 5; i++ {
+		tl.MustAppend(int64(100+i), util.Uint32To4Bytes(uint32(i)))
+	}
+	tl.MustDelAt(2)
+	assert.EqualValues(t, 5, tl.MustLen())
+	assert.Nil(t, tl.MustLoadRecordsRaw(2, 2, false)[0])
+	assert.NotNil(t, tl.MustLoadRecordsRaw(3, 3, false)[0])
+	assert.Panics(t, func() {
+		tl.MustDelAt(5)
+	})
+
+	// pruning over the deleted record
+	tl.MustPrune(3)
+	assert.EqualValues(t, 3, tl.MustFirstIndex())
+	assert.EqualValues(t, 103, tl.MustEarliest())
+}
@@ -183,6 +183,28 @@ func (p *decoder) MustGetAgentID(key kv.Key, def ...coretypes.AgentID) coretypes
 	return ret
 }
 
+func (p *decoder) GetRequestID(key kv.Key, def ...coretypes.RequestID) (coretypes.RequestID, error) {
+	v, exists, err := codec.DecodeRequestID(p.kv.MustGet(key))
+	if err != nil {
+		return coretypes.RequestID{}, fmt.Errorf("GetRequestID: decoding parameter '%s': %v", key, err)
+	}
+	if exists {
+		return v, nil
+	}
+	if len(def) == 0 {
+		return coretypes.RequestID{}, fmt.Errorf("GetRequestID: mandatory parameter '%s' does not exist", key)
+	}
+	return def[0], nil
+}
+
+func (p *decoder) MustGetRequestID(key kv.Key, def ...coretypes.RequestID) coretypes.RequestID {
+	ret, err := p.GetRequestID(key, def...)
+	if err != nil {
+		p.panic(err)
+	}
+	return ret
+}
+
 func (p *decoder) GetChainID(key kv.Key, def ...coretypes.ChainID) (coretypes.ChainID, error) {
 	v, exists, err := codec.DecodeChainID(p.kv.MustGet(key))
 	if err != nil {
@@ -0,0 +1,211 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package merkle implements sparse Merkle tree commitment to the key/value set of the chain state
+// and verification of the proofs of inclusion (and of non-inclusion) of the keys.
+// The verification is standalone: the proof can be checked offline, only against the state root
+// taken from the state section of the anchor transaction.
+//
+// The tree is a compact sparse Merkle tree of depth 256. The path of the key in the tree
+// is the hash of the key. The empty subtree is hashed to hashing.NilHash.
+// The subtree with exactly one leaf is collapsed to the hash of the leaf, so the proofs are
+// logarithmic in the number of keys
+package merkle
+
+import (
+	"github.com/iotaledger/wasp/packages/hashing"
+)
+
+const (
+	prefixLeaf = byte(0)
+	prefixNode = byte(1)
+)
+
+// Leaf is the committed key/value pair: the path of the key in the tree and the hash of the value
+type Leaf struct {
+	Path      hashing.HashValue
+	ValueHash hashing.HashValue
+}
+
+// Tree is the in-memory sparse Merkle tree over key/value pairs.
+// The tree is updated incrementally: Set rehashes only the nodes along the path of the key.
+// Nodes are never modified once created, so Clone is cheap and the clones are independent
+type Tree struct {
+	root *node
+	size int
+}
+
+// node is either the leaf or the inner node with at least two leaves in the subtree.
+// Inner node has one nil child when all leaves of the subtree share the next bit of the path
+type node struct {
+	leaf        *Leaf
+	left, right *node
+	hash        hashing.HashValue
+}
+
+// NewTree creates an empty tree
+func NewTree() *Tree {
+	return &Tree{}
+}
+
+// Clone returns the copy of the tree. Updates of the copy don't affect the original
+func (t *Tree) Clone() *Tree {
+	if t == nil {
+		return nil
+	}
+	return &Tree{root: t.root, size: t.size}
+}
+
+// Set adds or replaces the key/value pair. nil value removes the key
+func (t *Tree) Set(key, value []byte) {
+	path := KeyPath(key)
+	if value == nil {
+		var deleted bool
+		t.root, deleted = remove(t.root, path, 0)
+		if deleted {
+			t.size--
+		}
+		return
+	}
+	var added bool
+	t.root, added = insert(t.root, &Leaf{Path: path, ValueHash: ValueHash(value)}, 0)
+	if added {
+		t.size++
+	}
+}
+
+// Len is the number of keys in the tree
+func (t *Tree) Len() int {
+	return t.size
+}
+
+// Root returns the root of the tree. Root of the empty tree is hashing.NilHash
+func (t *Tree) Root() hashing.HashValue {
+	return t.root.getHash()
+}
+
+// Proof returns the proof of inclusion of the key.
+// If the key is not in the tree, it returns the proof of non-inclusion
+func (t *Tree) Proof(key []byte) *Proof {
+	path := KeyPath(key)
+	ret := &Proof{
+		Key:      key,
+		Siblings: make([]hashing.HashValue, 0),
+	}
+	n := t.root
+	for depth := 0; n != nil && n.leaf == nil; depth++ {
+		if bit(path, depth) {
+			ret.Siblings = append(ret.Siblings, n.left.getHash())
+			n = n.right
+		} else {
+			ret.Siblings = append(ret.Siblings, n.right.getHash())
+			n = n.left
+		}
+	}
+	if n != nil {
+		l := *n.leaf
+		ret.Leaf = &l
+	}
+	return ret
+}
+
+func newLeafNode(leaf *Leaf) *node {
+	return &node{leaf: leaf, hash: leaf.hash()}
+}
+
+func newInnerNode(left, right *node) *node {
+	return &node{left: left, right: right, hash: nodeHash(left.getHash(), right.getHash())}
+}
+
+func (n *node) getHash() hashing.HashValue {
+	if n == nil {
+		return hashing.NilHash
+	}
+	return n.hash
+}
+
+// insert returns the new subtree with the leaf. Returns false if the leaf replaced the one with the same path
+func insert(n *node, leaf *Leaf, depth int) (*node, bool) {
+	switch {
+	case n == nil:
+		return newLeafNode(leaf), true
+	case n.leaf != nil && n.leaf.Path == leaf.Path:
+		return newLeafNode(leaf), false
+	case n.leaf != nil:
+		// two leaves in the subtree: split by the bit of the path at the depth
+		existing := n
+		if bit(leaf.Path, depth) == bit(existing.leaf.Path, depth) {
+			child, _ := insert(existing, leaf, depth+1)
+			if bit(leaf.Path, depth) {
+				return newInnerNode(nil, child), true
+			}
+			return newInnerNode(child, nil), true
+		}
+		if bit(leaf.Path, depth) {
+			return newInnerNode(existing, newLeafNode(leaf)), true
+		}
+		return newInnerNode(newLeafNode(leaf), existing), true
+	}
+	if bit(leaf.Path, depth) {
+		right, added := insert(n.right, leaf, depth+1)
+		return newInnerNode(n.left, right), added
+	}
+	left, added := insert(n.left, leaf, depth+1)
+	return newInnerNode(left, n.right), added
+}
+
+// remove returns the new subtree without the leaf of the path. Returns false if there was no such leaf
+func remove(n *node, path hashing.HashValue, depth int) (*node, bool) {
+	switch {
+	case n == nil:
+		return nil, false
+	case n.leaf != nil:
+		if n.leaf.Path == path {
+			return nil, true
+		}
+		return n, false
+	}
+	left, right := n.left, n.right
+	var deleted bool
+	if bit(path, depth) {
+		right, deleted = remove(right, path, depth+1)
+	} else {
+		left, deleted = remove(left, path, depth+1)
+	}
+	if !deleted {
+		return n, false
+	}
+	// the subtree with exactly one leaf collapses to the leaf
+	switch {
+	case left == nil && right == nil:
+		return nil, true
+	case left == nil && right.leaf != nil:
+		return right, true
+	case right == nil && left.leaf != nil:
+		return left, true
+	}
+	return newInnerNode(left, right), true
+}
+
+// KeyPath is the path of the key in the tree
+func KeyPath(key []byte) hashing.HashValue {
+	return hashing.HashData(key)
+}
+
+// ValueHash is the hash of the value committed in the leaf
+func ValueHash(value []byte) hashing.HashValue {
+	return hashing.HashData(value)
+}
+
+func (l *Leaf) hash() hashing.HashValue {
+	return hashing.HashData([]byte{prefixLeaf}, l.Path[:], l.ValueHash[:])
+}
+
+func nodeHash(left, right hashing.HashValue) hashing.HashValue {
+	return hashing.HashData([]byte{prefixNode}, left[:], right[:])
+}
+
+// bit returns the bit of the path at the depth, starting from the most significant bit
+func bit(path hashing.HashValue, depth int) bool {
+	return path[depth/8]&(0x80>>uint(depth%8)) != 0
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyTree(t *testing.T) {
+	tr := NewTree()
+	require.EqualValues(t, hashing.NilHash, tr.Root())
+
+	proof := tr.Proof([]byte("a"))
+	require.False(t, proof.IsInclusion())
+	require.NoError(t, VerifyNonInclusion(tr.Root(), []byte("a"), proof))
+}
+
+func TestRootDoesNotDependOnOrder(t *testing.T) {
+	tr1 := NewTree()
+	tr2 := NewTree()
+	for i := 0; i < 100; i++ {
+		tr1.Set([]byte(fmt.Sprintf("k%d", i)), []byte(fmt.Sprintf("v%d", i)))
+		tr2.Set([]byte(fmt.Sprintf("k%d", 99-i)), []byte(fmt.Sprintf("v%d", 99-i)))
+	}
+	require.EqualValues(t, tr1.Root(), tr2.Root())
+
+	tr2.Set([]byte("k5"), []byte("other"))
+	require.NotEqual(t, tr1.Root(), tr2.Root())
+	tr2.Set([]byte("k5"), []byte("v5"))
+	require.EqualValues(t, tr1.Root(), tr2.Root())
+
+	tr2.Set([]byte("new"), []byte("v"))
+	require.NotEqual(t, tr1.Root(), tr2.Root())
+	tr2.Set([]byte("new"), nil)
+	require.EqualValues(t, tr1.Root(), tr2.Root())
+}
+
+func TestProofs(t *testing.T) {
+	tr := NewTree()
+	for i := 0; i < 100; i++ {
+		tr.Set([]byte(fmt.Sprintf("k%d", i)), []byte(fmt.Sprintf("v%d", i)))
+	}
+	root := tr.Root()
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("k%d", i))
+		proof := tr.Proof(key)
+		require.True(t, proof.IsInclusion())
+		require.NoError(t, VerifyInclusion(root, key, []byte(fmt.Sprintf("v%d", i)), proof))
+		require.Error(t, VerifyInclusion(root, key, []byte("wrong"), proof))
+		require.Error(t, VerifyNonInclusion(root, key, proof))
+
+		// serialization
+		proofBack, err := ProofFromBytes(proof.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, VerifyInclusion(root, key, []byte(fmt.Sprintf("v%d", i)), proofBack))
+	}
+	for i := 100; i < 200; i++ {
+		key := []byte(fmt.Sprintf("k%d", i))
+		proof := tr.Proof(key)
+		require.False(t, proof.IsInclusion())
+		require.NoError(t, VerifyNonInclusion(root, key, proof))
+		require.Error(t, VerifyInclusion(root, key, []byte(fmt.Sprintf("v%d", i)), proof))
+	}
+}
+
+func TestWrongProof(t *testing.T) {
+	tr := NewTree()
+	for i := 0; i < 10; i++ {
+		tr.Set([]byte(fmt.Sprintf("k%d", i)), []byte(fmt.Sprintf("v%d", i)))
+	}
+	root := tr.Root()
+	key := []byte("k1")
+	proof := tr.Proof(key)
+	require.NotEmpty(t, proof.Siblings)
+
+	proof.Siblings[0][0] ^= 0xFF
+	require.Error(t, VerifyInclusion(root, key, []byte("v1"), proof))
+
+	// proof of another key
+	proof = tr.Proof([]byte("k2"))
+	proof.Key = key
+	require.Error(t, VerifyInclusion(root, key, []byte("v2"), proof))
+	require.Error(t, VerifyNonInclusion(root, key, proof))
+}
+
+// rootFromLeaves computes the root from scratch by the definition of the tree
+func rootFromLeaves(leaves []Leaf, depth int) hashing.HashValue {
+	switch len(leaves) {
+	case 0:
+		return hashing.NilHash
+	case 1:
+		return leaves[0].hash()
+	}
+	i := sort.Search(len(leaves), func(i int) bool {
+		return bit(leaves[i].Path, depth)
+	})
+	return nodeHash(rootFromLeaves(leaves[:i], depth+1), rootFromLeaves(leaves[i:], depth+1))
+}
+
+func TestIncrementalRoot(t *testing.T) {
+	tr := NewTree()
+	values := make(map[string][]byte)
+	check := func() {
+		leaves := make([]Leaf, 0, len(values))
+		for k, v := range values {
+			leaves = append(leaves, Leaf{Path: KeyPath([]byte(k)), ValueHash: ValueHash(v)})
+		}
+		sort.Slice(leaves, func(i, j int) bool {
+			return bytes.Compare(leaves[i].Path[:], leaves[j].Path[:]) < 0
+		})
+		require.EqualValues(t, len(values), tr.Len())
+		require.EqualValues(t, rootFromLeaves(leaves, 0), tr.Root())
+	}
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("k%d", i%70)
+		switch {
+		case i%3 == 2:
+			tr.Set([]byte(key), nil)
+			delete(values, key)
+		default:
+			tr.Set([]byte(key), []byte(fmt.Sprintf("v%d", i)))
+			values[key] = []byte(fmt.Sprintf("v%d", i))
+		}
+		check()
+	}
+	for k := range values {
+		tr.Set([]byte(k), nil)
+		delete(values, k)
+		check()
+	}
+	require.EqualValues(t, hashing.NilHash, tr.Root())
+	// deleting the missing key does not change the tree
+	tr.Set([]byte("missing"), nil)
+	require.EqualValues(t, 0, tr.Len())
+}
+
+func TestClone(t *testing.T) {
+	tr := NewTree()
+	for i := 0; i < 10; i++ {
+		tr.Set([]byte(fmt.Sprintf("k%d", i)), []byte(fmt.Sprintf("v%d", i)))
+	}
+	root := tr.Root()
+	clone := tr.Clone()
+	clone.Set([]byte("k1"), []byte("other"))
+	clone.Set([]byte("k2"), nil)
+	clone.Set([]byte("new"), []byte("v"))
+	require.EqualValues(t, root, tr.Root())
+	require.EqualValues(t, 10, tr.Len())
+	require.EqualValues(t, 10, clone.Len())
+	require.NotEqual(t, root, clone.Root())
+	require.NoError(t, VerifyInclusion(root, []byte("k1"), []byte("v1"), tr.Proof([]byte("k1"))))
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+)
+
+// Proof is the proof of inclusion or non-inclusion of the key in the tree
+type Proof struct {
+	// Key is the proven key
+	Key []byte
+	// Siblings are hashes of the sibling subtrees along the path of the key, starting from the root
+	Siblings []hashing.HashValue
+	// Leaf is the only leaf in the subtree at the end of the path.
+	// It is the leaf of the Key in the proof of inclusion.
+	// In the proof of non-inclusion it is either nil (empty subtree) or the leaf of another key
+	Leaf *Leaf
+}
+
+// ErrWrongProof is returned when the proof is not valid against the root
+var ErrWrongProof = errors.New("merkle: wrong proof")
+
+// IsInclusion returns true if it is the proof of inclusion of the Key
+func (p *Proof) IsInclusion() bool {
+	return p.Leaf != nil && p.Leaf.Path == KeyPath(p.Key)
+}
+
+// Root computes the root of the tree from the proof
+func (p *Proof) Root() hashing.HashValue {
+	var ret hashing.HashValue
+	if p.Leaf != nil {
+		ret = p.Leaf.hash()
+	}
+	path := KeyPath(p.Key)
+	for depth := len(p.Siblings) - 1; depth >= 0; depth-- {
+		if bit(path, depth) {
+			ret = nodeHash(p.Siblings[depth], ret)
+		} else {
+			ret = nodeHash(ret, p.Siblings[depth])
+		}
+	}
+	return ret
+}
+
+// Validate checks if the proof is consistent with the root
+func (p *Proof) Validate(root hashing.HashValue) error {
+	if len(p.Siblings) > 8*hashing.HashSize {
+		return ErrWrongProof
+	}
+	if p.Leaf != nil {
+		// the leaf must be on the path of the key
+		path := KeyPath(p.Key)
+		for depth := range p.Siblings {
+			if bit(path, depth) != bit(p.Leaf.Path, depth) {
+				return ErrWrongProof
+			}
+		}
+	}
+	if p.Root() != root {
+		return ErrWrongProof
+	}
+	return nil
+}
+
+// VerifyInclusion checks the proof that the key has the value in the tree with the root
+func VerifyInclusion(root hashing.HashValue, key, value []byte, proof *Proof) error {
+	if !bytes.Equal(key, proof.Key) || !proof.IsInclusion() || proof.Leaf.ValueHash != ValueHash(value) {
+		return ErrWrongProof
+	}
+	return proof.Validate(root)
+}
+
+// VerifyNonInclusion checks the proof that the key is not in the tree with the root
+func VerifyNonInclusion(root hashing.HashValue, key []byte, proof *Proof) error {
+	if !bytes.Equal(key, proof.Key) || proof.IsInclusion() {
+		return ErrWrongProof
+	}
+	return proof.Validate(root)
+}
+
+// encoding
+
+func (p *Proof) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = p.Write(&buf)
+	return buf.Bytes()
+}
+
+func ProofFromBytes(data []byte) (*Proof, error) {
+	ret := new(Proof)
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (p *Proof) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(p.Key))); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Key); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(p.Siblings))); err != nil {
+		return err
+	}
+	for i := range p.Siblings {
+		if _, err := w.Write(p.Siblings[i][:]); err != nil {
+			return err
+		}
+	}
+	if p.Leaf == nil {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	if _, err := w.Write([]byte{1}); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Leaf.Path[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(p.Leaf.ValueHash[:])
+	return err
+}
+
+func (p *Proof) Read(r io.Reader) error {
+	var size uint16
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return err
+	}
+	p.Key = make([]byte, size)
+	if _, err := io.ReadFull(r, p.Key); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return err
+	}
+	if int(size) > 8*hashing.HashSize {
+		return ErrWrongProof
+	}
+	p.Siblings = make([]hashing.HashValue, size)
+	for i := range p.Siblings {
+		if _, err := io.ReadFull(r, p.Siblings[i][:]); err != nil {
+			return err
+		}
+	}
+	var flag [1]byte
+	if _, err := io.ReadFull(r, flag[:]); err != nil {
+		return err
+	}
+	if flag[0] == 0 {
+		p.Leaf = nil
+		return nil
+	}
+	p.Leaf = new(Leaf)
+	if _, err := io.ReadFull(r, p.Leaf.Path[:]); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(r, p.Leaf.ValueHash[:])
+	return err
+}
@@ -1,34 +1,81 @@
 package registry
 
 import (
+	"sort"
+	"time"
+
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/codec"
-	"time"
+	"github.com/iotaledger/wasp/packages/parameters"
 )
 
 // implements BlobCacheProvide interface
 
-// TODO blob cache cleanup
+const (
+	// CfgBlobCacheQuota defines the config flag of the maximum total size of the blob cache in bytes
+	CfgBlobCacheQuota = "blobcache.quota"
+	// CfgBlobCacheCleanupInterval defines the config flag of the interval of the blob cache cleanup in seconds
+	CfgBlobCacheCleanupInterval = "blobcache.cleanupInterval"
+	// DefaultBlobCacheCleanupInterval is used when the configured interval is not positive
+	DefaultBlobCacheCleanupInterval = 60
+)
+
+// BlobCacheStats is the state of the blob cache
+type BlobCacheStats struct {
+	NumBlobs  int
+	TotalSize int64
+	// Quota is the maximum total size used by the last cleanup. 0 means unlimited
+	Quota int64
+	// Expired is the number of blobs removed because of the expired TTL since the start of the node
+	Expired int64
+	// Evicted is the number of least recently used blobs removed because of the quota since the start of the node
+	Evicted     int64
+	LastCleanup time.Time
+}
+
+type blobCacheEntry struct {
+	hash hashing.HashValue
+	size int64
+	// expiration time in Unix nanoseconds
+	cleanAfter int64
+	// time of the last put or get in Unix nanoseconds
+	lastAccess int64
+}
 
 func dbKeyForBlob(h hashing.HashValue) []byte {
 	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCache, h[:])
 }
 
 func dbKeyForBlobTTL(h hashing.HashValue) []byte {
-	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheTTL)
+	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheTTL, h[:])
+}
+
+func dbKeyForBlobAccess(h hashing.HashValue) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheAccess, h[:])
+}
+
+func dbKeyForBlobSize(h hashing.HashValue) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheSize, h[:])
 }
 
 // PutBlob Writes data into the registry with the key of its hash
 // Also stores TTL if provided
 func (r *Impl) PutBlob(data []byte, ttl ...time.Duration) (hashing.HashValue, error) {
+	r.blobCacheMutex.Lock()
+	defer r.blobCacheMutex.Unlock()
+
 	h := hashing.HashData(data)
 	err := r.dbProvider.GetRegistryPartition().Set(dbKeyForBlob(h), data)
 	if err != nil {
 		return hashing.NilHash, err
 	}
+	err = r.dbProvider.GetRegistryPartition().Set(dbKeyForBlobSize(h), codec.EncodeInt64(int64(len(data))))
+	if err != nil {
+		return hashing.NilHash, err
+	}
 	nowis := time.Now()
 	cleanAfter := nowis.Add(coretypes.DefaultTTL).UnixNano()
 	if len(ttl) > 0 {
@@ -40,6 +87,9 @@ func (r *Impl) PutBlob(data []byte, ttl ...time.Duration) (hashing.HashValue, er
 			return hashing.NilHash, err
 		}
 	}
+	if err = r.touchBlob(h, nowis); err != nil {
+		return hashing.NilHash, err
+	}
 	r.log.Infof("data blob has been stored. size: %d bytes, hash: %s", len(data), h)
 	return h, nil
 }
@@ -50,9 +100,266 @@ func (r *Impl) GetBlob(h hashing.HashValue) ([]byte, bool, error) {
 	if err == kvstore.ErrKeyNotFound {
 		return nil, false, nil
 	}
+	if err == nil && ret != nil {
+		r.recordBlobAccess(h, time.Now())
+	}
 	return ret, ret != nil && err == nil, err
 }
 
 func (r *Impl) HasBlob(h hashing.HashValue) (bool, error) {
 	return r.dbProvider.GetRegistryPartition().Has(dbKeyForBlob(h))
 }
+
+// touchBlob records the time of the last access to the blob for the LRU eviction
+func (r *Impl) touchBlob(h hashing.HashValue, t time.Time) error {
+	return r.dbProvider.GetRegistryPartition().Set(dbKeyForBlobAccess(h), codec.EncodeInt64(t.UnixNano()))
+}
+
+// recordBlobAccess keeps the time of the read of the blob in memory, so reads don't write to the DB.
+// The access times are flushed by the cleanup
+func (r *Impl) recordBlobAccess(h hashing.HashValue, t time.Time) {
+	r.blobAccessMutex.Lock()
+	defer r.blobAccessMutex.Unlock()
+
+	if t.UnixNano() > r.blobAccess[h] {
+		r.blobAccess[h] = t.UnixNano()
+	}
+}
+
+// flushBlobAccess stores the access times recorded in memory. Blobs deleted since they were read are skipped.
+// Must be called with blobCacheMutex locked
+func (r *Impl) flushBlobAccess() error {
+	r.blobAccessMutex.Lock()
+	access := r.blobAccess
+	r.blobAccess = make(map[hashing.HashValue]int64)
+	r.blobAccessMutex.Unlock()
+
+	for h, t := range access {
+		exists, err := r.HasBlob(h)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		last, err := r.getInt64(dbKeyForBlobAccess(h))
+		if err != nil {
+			return err
+		}
+		if t <= last {
+			continue
+		}
+		if err = r.touchBlob(h, time.Unix(0, t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunBlobCacheCleanup periodically removes expired blobs from the blob cache and evicts least recently
+// used blobs when the cache exceeds the quota. It is run as a background worker until the shutdown signal
+func (r *Impl) RunBlobCacheCleanup(shutdownSignal <-chan struct{}) {
+	ticker := time.NewTicker(r.blobCacheCleanupInterval(parameters.GetInt(CfgBlobCacheCleanupInterval)))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-shutdownSignal:
+			return
+		case <-ticker.C:
+			expired, evicted, err := r.CleanupBlobCache(time.Now(), int64(parameters.GetInt(CfgBlobCacheQuota)))
+			if err != nil {
+				r.log.Warnf("blob cache cleanup failed: %v", err)
+				continue
+			}
+			if expired+evicted > 0 {
+				r.log.Infof("blob cache cleanup: %d expired and %d evicted blob(s) removed", expired, evicted)
+			}
+		}
+	}
+}
+
+// blobCacheCleanupInterval returns the configured interval in seconds as a duration.
+// Not positive values would make the ticker panic, so the default is used instead
+func (r *Impl) blobCacheCleanupInterval(seconds int) time.Duration {
+	if seconds <= 0 {
+		r.log.Warnf("invalid %s: %d. Using the default %d seconds", CfgBlobCacheCleanupInterval, seconds, DefaultBlobCacheCleanupInterval)
+		seconds = DefaultBlobCacheCleanupInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// CleanupBlobCache removes blobs which expired at the moment 'now'. Then, if the total size of the remaining
+// blobs exceeds the quota, removes least recently used blobs until it fits. Quota 0 means unlimited.
+// Returns numbers of expired and evicted blobs
+func (r *Impl) CleanupBlobCache(now time.Time, quota int64) (int, int, error) {
+	r.blobCacheMutex.Lock()
+	defer r.blobCacheMutex.Unlock()
+
+	if err := r.deleteOrphanBlobKeys(); err != nil {
+		return 0, 0, err
+	}
+	if err := r.flushBlobAccess(); err != nil {
+		return 0, 0, err
+	}
+	entries, err := r.listBlobCache()
+	if err != nil {
+		return 0, 0, err
+	}
+	remaining := make([]*blobCacheEntry, 0, len(entries))
+	totalSize := int64(0)
+	expired := 0
+	for _, e := range entries {
+		if e.cleanAfter > 0 && e.cleanAfter < now.UnixNano() {
+			if err = r.deleteBlob(e.hash); err != nil {
+				return expired, 0, err
+			}
+			expired++
+			continue
+		}
+		remaining = append(remaining, e)
+		totalSize += e.size
+	}
+	evicted := 0
+	if quota > 0 && totalSize > quota {
+		// least recently used first. The hash makes the order deterministic
+		sort.Slice(remaining, func(i, j int) bool {
+			if remaining[i].lastAccess != remaining[j].lastAccess {
+				return remaining[i].lastAccess < remaining[j].lastAccess
+			}
+			return string(remaining[i].hash[:]) < string(remaining[j].hash[:])
+		})
+		for _, e := range remaining {
+			if totalSize <= quota {
+				break
+			}
+			if err = r.deleteBlob(e.hash); err != nil {
+				return expired, evicted, err
+			}
+			totalSize -= e.size
+			evicted++
+		}
+	}
+	r.blobCacheStats.Expired += int64(expired)
+	r.blobCacheStats.Evicted += int64(evicted)
+	r.blobCacheStats.Quota = quota
+	r.blobCacheStats.LastCleanup = now
+	return expired, evicted, nil
+}
+
+// GetBlobCacheStats returns the current size of the blob cache and the statistics of cleanups
+func (r *Impl) GetBlobCacheStats() (*BlobCacheStats, error) {
+	r.blobCacheMutex.Lock()
+	defer r.blobCacheMutex.Unlock()
+
+	entries, err := r.listBlobCache()
+	if err != nil {
+		return nil, err
+	}
+	ret := r.blobCacheStats
+	ret.NumBlobs = len(entries)
+	for _, e := range entries {
+		ret.TotalSize += e.size
+	}
+	return &ret, nil
+}
+
+// listBlobCache returns the entries of all cached blobs without reading the blobs themselves.
+// The size of the blobs stored before the sizes were recorded is taken from the blob once and stored
+func (r *Impl) listBlobCache() ([]*blobCacheEntry, error) {
+	partition := r.dbProvider.GetRegistryPartition()
+	ret := make([]*blobCacheEntry, 0)
+	err := partition.IterateKeys([]byte{dbprovider.ObjectTypeBlobCache}, func(key kvstore.Key) bool {
+		e := &blobCacheEntry{}
+		copy(e.hash[:], key[1:])
+		ret = append(ret, e)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range ret {
+		if e.size, err = r.getBlobSize(e.hash); err != nil {
+			return nil, err
+		}
+		if e.cleanAfter, err = r.getInt64(dbKeyForBlobTTL(e.hash)); err != nil {
+			return nil, err
+		}
+		if e.lastAccess, err = r.getInt64(dbKeyForBlobAccess(e.hash)); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func (r *Impl) getBlobSize(h hashing.HashValue) (int64, error) {
+	partition := r.dbProvider.GetRegistryPartition()
+	data, err := partition.Get(dbKeyForBlobSize(h))
+	if err == nil {
+		ret, _, err := codec.DecodeInt64(data)
+		return ret, err
+	}
+	if err != kvstore.ErrKeyNotFound {
+		return 0, err
+	}
+	blob, err := partition.Get(dbKeyForBlob(h))
+	if err != nil {
+		return 0, err
+	}
+	size := int64(len(blob))
+	return size, partition.Set(dbKeyForBlobSize(h), codec.EncodeInt64(size))
+}
+
+// deleteOrphanBlobKeys deletes TTL, access and size keys without the blob, including the shared TTL key
+// written by earlier versions
+func (r *Impl) deleteOrphanBlobKeys() error {
+	partition := r.dbProvider.GetRegistryPartition()
+	keys := make([][]byte, 0)
+	for _, prefix := range []byte{dbprovider.ObjectTypeBlobCacheTTL, dbprovider.ObjectTypeBlobCacheAccess, dbprovider.ObjectTypeBlobCacheSize} {
+		err := partition.IterateKeys([]byte{prefix}, func(key kvstore.Key) bool {
+			keys = append(keys, append([]byte{}, key...))
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range keys {
+		if len(key) == 1+hashing.HashSize {
+			var h hashing.HashValue
+			copy(h[:], key[1:])
+			exists, err := partition.Has(dbKeyForBlob(h))
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+		}
+		if err := partition.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Impl) deleteBlob(h hashing.HashValue) error {
+	partition := r.dbProvider.GetRegistryPartition()
+	for _, key := range [][]byte{dbKeyForBlob(h), dbKeyForBlobTTL(h), dbKeyForBlobAccess(h), dbKeyForBlobSize(h)} {
+		if err := partition.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Impl) getInt64(key []byte) (int64, error) {
+	data, err := r.dbProvider.GetRegistryPartition().Get(key)
+	if err == kvstore.ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	ret, _, err := codec.DecodeInt64(data)
+	return ret, err
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestBlobPutGet(t *testing.T) {
@@ -25,3 +26,145 @@ func TestBlobPutGet(t *testing.T) {
 	require.True(t, ok)
 	require.EqualValues(t, data, back)
 }
+
+func TestBlobCacheExpiry(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	h1, err := reg.PutBlob([]byte("data-1"), time.Minute)
+	require.NoError(t, err)
+	h2, err := reg.PutBlob([]byte("data-2"), time.Hour)
+	require.NoError(t, err)
+
+	expired, evicted, err := reg.CleanupBlobCache(time.Now(), 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, expired)
+	require.EqualValues(t, 0, evicted)
+
+	// each blob has its own TTL
+	expired, _, err = reg.CleanupBlobCache(time.Now().Add(2*time.Minute), 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, expired)
+	ok, err := reg.HasBlob(h1)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = reg.HasBlob(h2)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	stats, err := reg.GetBlobCacheStats()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.NumBlobs)
+	require.EqualValues(t, len("data-2"), stats.TotalSize)
+	require.EqualValues(t, 1, stats.Expired)
+}
+
+func TestBlobCacheQuota(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	data := [][]byte{[]byte("data-0"), []byte("data-1"), []byte("data-2")}
+	hashes := make([]hashing.HashValue, len(data))
+	for i, d := range data {
+		var err error
+		hashes[i], err = reg.PutBlob(d)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+	// the first blob is used recently
+	_, ok, err := reg.GetBlob(hashes[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	expired, evicted, err := reg.CleanupBlobCache(time.Now(), int64(2*len(data[0])))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, expired)
+	require.EqualValues(t, 1, evicted)
+	for i, exists := range []bool{true, false, true} {
+		ok, err := reg.HasBlob(hashes[i])
+		require.NoError(t, err)
+		require.EqualValues(t, exists, ok)
+	}
+
+	stats, err := reg.GetBlobCacheStats()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stats.NumBlobs)
+	require.EqualValues(t, 2*len(data[0]), stats.Quota)
+	require.EqualValues(t, 1, stats.Evicted)
+}
+
+func TestBlobCacheAccessFlush(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	h, err := reg.PutBlob([]byte("data"), time.Minute)
+	require.NoError(t, err)
+	put, err := reg.getInt64(dbKeyForBlobAccess(h))
+	require.NoError(t, err)
+
+	// reads don't write to the DB until the cleanup
+	time.Sleep(2 * time.Millisecond)
+	_, ok, err := reg.GetBlob(h)
+	require.NoError(t, err)
+	require.True(t, ok)
+	access, err := reg.getInt64(dbKeyForBlobAccess(h))
+	require.NoError(t, err)
+	require.EqualValues(t, put, access)
+
+	_, _, err = reg.CleanupBlobCache(time.Now(), 0)
+	require.NoError(t, err)
+	access, err = reg.getInt64(dbKeyForBlobAccess(h))
+	require.NoError(t, err)
+	require.Greater(t, access, put)
+
+	// the read which raced with the removal of the blob doesn't leave the access key behind
+	expired, _, err := reg.CleanupBlobCache(time.Now().Add(2*time.Minute), 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, expired)
+	reg.recordBlobAccess(h, time.Now())
+	_, _, err = reg.CleanupBlobCache(time.Now(), 0)
+	require.NoError(t, err)
+	ok, err = db.GetRegistryPartition().Has(dbKeyForBlobAccess(h))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestBlobCacheLegacySize(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	// blob stored without the size key
+	data := []byte("legacy-data")
+	h := hashing.HashData(data)
+	require.NoError(t, db.GetRegistryPartition().Set(dbKeyForBlob(h), data))
+	_, err := reg.PutBlob([]byte("data-1"))
+	require.NoError(t, err)
+
+	stats, err := reg.GetBlobCacheStats()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stats.NumBlobs)
+	require.EqualValues(t, len(data)+len("data-1"), stats.TotalSize)
+	ok, err := db.GetRegistryPartition().Has(dbKeyForBlobSize(h))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, evicted, err := reg.CleanupBlobCache(time.Now(), int64(len("data-1")))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, evicted)
+	ok, err = db.GetRegistryPartition().Has(dbKeyForBlobSize(h))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestBlobCacheCleanupInterval(t *testing.T) {
+	log := testutil.NewLogger(t)
+	reg := NewRegistry(nil, log, dbprovider.NewInMemoryDBProvider(log))
+
+	require.EqualValues(t, 10*time.Second, reg.blobCacheCleanupInterval(10))
+	require.EqualValues(t, DefaultBlobCacheCleanupInterval*time.Second, reg.blobCacheCleanupInterval(0))
+	require.EqualValues(t, DefaultBlobCacheCleanupInterval*time.Second, reg.blobCacheCleanupInterval(-1))
+}
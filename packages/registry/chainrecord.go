@@ -6,6 +6,7 @@ import (
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"io"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -22,6 +23,26 @@ type ChainRecord struct {
 	Color          balance.Color // origin tx hash
 	CommitteeNodes []string      // "host_addr:port"
 	Active         bool
+	// StateAddress is the address of the committee which holds the chain token.
+	// Empty address means the chain ID, i.e. the committee was never rotated
+	StateAddress address.Address
+	// NextCommitteeNodes is the committee which takes over the chain when the chain
+	// is moved to the address of the distributed key of this node. Empty if not set
+	NextCommitteeNodes []string
+	// NextStateAddress is the address of the distributed key generated by the DKG among NextCommitteeNodes.
+	// The node hands over the chain only if the chain is moved to this address
+	NextStateAddress address.Address
+	// Migrations are handovers of the chain from the committees of this node to the next committees
+	Migrations []*Migration
+}
+
+// Migration is the handover of the chain from the committee of the node to the next committee.
+// Outputs which arrive at the address of the previous committee after the handover are moved to the next committee
+type Migration struct {
+	From address.Address
+	To   address.Address
+	// CommitteeNodes are nodes of the committee of 'From', in the order of indices of the distributed key
+	CommitteeNodes []string
 }
 
 func dbkeyChainRecord(chainID *coretypes.ChainID) []byte {
@@ -114,6 +135,15 @@ func GetChainRecords() ([]*ChainRecord, error) {
 	return ret, err
 }
 
+// Address returns the address of the committee which holds the chain token.
+// It is equal to the chain ID unless the committee was rotated
+func (bd *ChainRecord) Address() address.Address {
+	if bd.StateAddress == (address.Address{}) {
+		return address.Address(bd.ChainID)
+	}
+	return bd.StateAddress
+}
+
 func (bd *ChainRecord) Write(w io.Writer) error {
 	if err := bd.ChainID.Write(w); err != nil {
 		return err
@@ -127,6 +157,23 @@ func (bd *ChainRecord) Write(w io.Writer) error {
 	if err := util.WriteBoolByte(w, bd.Active); err != nil {
 		return err
 	}
+	if _, err := w.Write(bd.StateAddress[:]); err != nil {
+		return err
+	}
+	if err := util.WriteStrings16(w, bd.NextCommitteeNodes); err != nil {
+		return err
+	}
+	if _, err := w.Write(bd.NextStateAddress[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(bd.Migrations))); err != nil {
+		return err
+	}
+	for _, m := range bd.Migrations {
+		if err := m.Write(w); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -144,6 +191,34 @@ func (bd *ChainRecord) Read(r io.Reader) error {
 	if err = util.ReadBoolByte(r, &bd.Active); err != nil {
 		return err
 	}
+	n, err := r.Read(bd.StateAddress[:])
+	if err == io.EOF {
+		// the record was saved before the committee rotation was introduced
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if n != address.Length {
+		return fmt.Errorf("error while reading state address")
+	}
+	if bd.NextCommitteeNodes, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if err = util.ReadAddress(r, &bd.NextStateAddress); err != nil {
+		return err
+	}
+	var numMigrations uint16
+	if err = util.ReadUint16(r, &numMigrations); err != nil {
+		return err
+	}
+	bd.Migrations = make([]*Migration, numMigrations)
+	for i := range bd.Migrations {
+		bd.Migrations[i] = new(Migration)
+		if err = bd.Migrations[i].Read(r); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -151,5 +226,35 @@ func (bd *ChainRecord) String() string {
 	ret := "      Target: " + bd.ChainID.String() + "\n"
 	ret += "      Color: " + bd.Color.String() + "\n"
 	ret += fmt.Sprintf("      Committee nodes: %+v\n", bd.CommitteeNodes)
+	ret += "      State address: " + bd.Address().String() + "\n"
+	if len(bd.NextCommitteeNodes) > 0 {
+		ret += fmt.Sprintf("      Next committee nodes: %+v\n", bd.NextCommitteeNodes)
+		ret += "      Next state address: " + bd.NextStateAddress.String() + "\n"
+	}
+	for _, m := range bd.Migrations {
+		ret += fmt.Sprintf("      Migration: %s --> %s, committee nodes: %+v\n", m.From.String(), m.To.String(), m.CommitteeNodes)
+	}
 	return ret
 }
+
+func (m *Migration) Write(w io.Writer) error {
+	if _, err := w.Write(m.From[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(m.To[:]); err != nil {
+		return err
+	}
+	return util.WriteStrings16(w, m.CommitteeNodes)
+}
+
+func (m *Migration) Read(r io.Reader) error {
+	var err error
+	if err = util.ReadAddress(r, &m.From); err != nil {
+		return err
+	}
+	if err = util.ReadAddress(r, &m.To); err != nil {
+		return err
+	}
+	m.CommitteeNodes, err = util.ReadStrings16(r)
+	return err
+}
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"bytes"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestChainRecordAddress(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	rec := &ChainRecord{
+		ChainID:        chainID,
+		Color:          balance.Color{1, 2, 3},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000"},
+		Active:         true,
+	}
+	require.EqualValues(t, address.Address(chainID), rec.Address())
+
+	back := new(ChainRecord)
+	require.NoError(t, back.Read(bytes.NewReader(util.MustBytes(rec))))
+	require.EqualValues(t, rec.CommitteeNodes, back.CommitteeNodes)
+	require.EqualValues(t, rec.Address(), back.Address())
+	require.Len(t, back.NextCommitteeNodes, 0)
+
+	rec.StateAddress = address.Random()
+	rec.NextCommitteeNodes = []string{"wasp3:4000"}
+	rec.NextStateAddress = address.Random()
+	rec.Migrations = []*Migration{{
+		From:           address.Address(chainID),
+		To:             rec.StateAddress,
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000"},
+	}}
+	require.EqualValues(t, rec.StateAddress, rec.Address())
+
+	back = new(ChainRecord)
+	require.NoError(t, back.Read(bytes.NewReader(util.MustBytes(rec))))
+	require.EqualValues(t, rec, back)
+}
+
+func TestChainRecordReadOldFormat(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	var buf bytes.Buffer
+	require.NoError(t, chainID.Write(&buf))
+	buf.Write(balance.ColorIOTA[:])
+	require.NoError(t, util.WriteStrings16(&buf, []string{"wasp1:4000"}))
+	require.NoError(t, util.WriteBoolByte(&buf, true))
+
+	rec := new(ChainRecord)
+	require.NoError(t, rec.Read(bytes.NewReader(buf.Bytes())))
+	require.True(t, rec.Active)
+	require.EqualValues(t, address.Address(chainID), rec.Address())
+}
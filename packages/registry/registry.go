@@ -4,8 +4,11 @@
 package registry
 
 import (
+	"sync"
+
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/plugins/database"
 )
@@ -16,13 +19,20 @@ type Impl struct {
 	suite      tcrypto.Suite
 	log        *logger.Logger
 	dbProvider *dbprovider.DBProvider
+
+	blobCacheMutex sync.Mutex
+	blobCacheStats BlobCacheStats
+	// access times of the blobs read since the last cleanup, flushed to the DB by the cleanup
+	blobAccessMutex sync.Mutex
+	blobAccess      map[hashing.HashValue]int64
 }
 
 // New creates new instance of the registry implementation.
 func NewRegistry(suite tcrypto.Suite, log *logger.Logger, dbp ...*dbprovider.DBProvider) *Impl {
 	ret := &Impl{
-		suite: suite,
-		log:   log.Named("registry"),
+		suite:      suite,
+		log:        log.Named("registry"),
+		blobAccess: make(map[hashing.HashValue]int64),
 	}
 	if len(dbp) == 0 {
 		ret.dbProvider = database.GetInstance()
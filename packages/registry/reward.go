@@ -13,6 +13,8 @@ const (
 
 func InitFlags() {
 	flag.String(CfgRewardAddress, "", "reward address for this Wasp node. Empty (default) means no rewards are collected")
+	flag.Int(CfgBlobCacheQuota, 100*1024*1024, "maximum total size of the blob cache in bytes. 0 means unlimited")
+	flag.Int(CfgBlobCacheCleanupInterval, DefaultBlobCacheCleanupInterval, "interval of the blob cache cleanup in seconds")
 }
 
 func GetFeeDestination(scaddr *address.Address) address.Address {
@@ -0,0 +1,173 @@
+package sctransaction
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// OffLedgerRequest is a request which is not carried by the value transaction.
+// It is signed by the sender and posted directly to the committee nodes, which gossip it to each other.
+// Off-ledger request can't transfer tokens, the fees are paid from the on-chain account of the sender.
+// Each request of the sender must have a unique nonce. Requests may be processed out of order,
+// the chain accepts unused nonces in the window below the greatest one (see accounts.NonceWindowSize)
+type OffLedgerRequest struct {
+	targetContractID coretypes.ContractID
+	entryPoint       coretypes.Hname
+	nonce            uint64
+	args             requestargs.RequestArgs
+	signature        *signaturescheme.ED25519Signature
+	// request section is created lazily and cached: it keeps solidified args
+	section *RequestSection
+}
+
+// NewOffLedgerRequest creates new unsigned off-ledger request
+func NewOffLedgerRequest(targetContract coretypes.ContractID, entryPointCode coretypes.Hname, nonce uint64) *OffLedgerRequest {
+	return &OffLedgerRequest{
+		targetContractID: targetContract,
+		entryPoint:       entryPointCode,
+		nonce:            nonce,
+		args:             requestargs.New(nil),
+	}
+}
+
+// OffLedgerRequestFromBytes a constructor
+func OffLedgerRequestFromBytes(data []byte) (*OffLedgerRequest, error) {
+	ret := &OffLedgerRequest{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// WithArgs sets encoded args
+func (req *OffLedgerRequest) WithArgs(args requestargs.RequestArgs) *OffLedgerRequest {
+	req.args = args
+	req.section = nil
+	return req
+}
+
+// Sign signs the essence of the request. Only ED25519 signature scheme is supported
+func (req *OffLedgerRequest) Sign(sigScheme signaturescheme.SignatureScheme) error {
+	sig, ok := sigScheme.Sign(req.essenceBytes()).(*signaturescheme.ED25519Signature)
+	if !ok {
+		return fmt.Errorf("off-ledger request: only ED25519 signatures are supported")
+	}
+	req.signature = sig
+	return nil
+}
+
+// VerifySignature returns true if the request is signed and the signature is valid
+func (req *OffLedgerRequest) VerifySignature() bool {
+	return req.signature != nil && req.signature.IsValid(req.essenceBytes())
+}
+
+// ID of the off-ledger request. The transaction ID part is the hash of the signed request, the index is always 0
+func (req *OffLedgerRequest) ID() coretypes.RequestID {
+	return coretypes.NewRequestID(valuetransaction.ID(hashing.HashData(req.Bytes())), 0)
+}
+
+func (req *OffLedgerRequest) Target() coretypes.ContractID {
+	return req.targetContractID
+}
+
+func (req *OffLedgerRequest) EntryPointCode() coretypes.Hname {
+	return req.entryPoint
+}
+
+func (req *OffLedgerRequest) Nonce() uint64 {
+	return req.nonce
+}
+
+// SenderAddress is the address of the signer. Request must be signed
+func (req *OffLedgerRequest) SenderAddress() address.Address {
+	return req.signature.Address()
+}
+
+// RequestSection represents the off-ledger request as a request section without transfer,
+// so that it is processed by the VM the same way as requests carried by transactions
+func (req *OffLedgerRequest) RequestSection() *RequestSection {
+	if req.section == nil {
+		req.section = NewRequestSectionByWallet(req.targetContractID, req.entryPoint).WithArgs(req.args)
+	}
+	return req.section
+}
+
+func (req *OffLedgerRequest) String() string {
+	return fmt.Sprintf("[[off-ledger target: %s, entry point: '%s', nonce: %d, args: %s]]",
+		req.targetContractID.String(), req.entryPoint.String(), req.nonce, req.args.String())
+}
+
+func (req *OffLedgerRequest) Bytes() []byte {
+	return util.MustBytes(req)
+}
+
+func (req *OffLedgerRequest) essenceBytes() []byte {
+	var buf bytes.Buffer
+	if err := req.writeEssence(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// encoding
+
+func (req *OffLedgerRequest) writeEssence(w io.Writer) error {
+	if err := req.targetContractID.Write(w); err != nil {
+		return err
+	}
+	if err := req.entryPoint.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint64(w, req.nonce); err != nil {
+		return err
+	}
+	return req.args.Write(w)
+}
+
+func (req *OffLedgerRequest) Write(w io.Writer) error {
+	if err := req.writeEssence(w); err != nil {
+		return err
+	}
+	if req.signature == nil {
+		return util.WriteBytes16(w, nil)
+	}
+	return util.WriteBytes16(w, req.signature.Bytes())
+}
+
+func (req *OffLedgerRequest) Read(r io.Reader) error {
+	if err := req.targetContractID.Read(r); err != nil {
+		return err
+	}
+	if err := req.entryPoint.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint64(r, &req.nonce); err != nil {
+		return err
+	}
+	req.args = requestargs.New(nil)
+	if err := req.args.Read(r); err != nil {
+		return err
+	}
+	sigBytes, err := util.ReadBytes16(r)
+	if err != nil {
+		return err
+	}
+	req.section = nil
+	req.signature = nil
+	if len(sigBytes) == 0 {
+		return nil
+	}
+	if req.signature, _, err = signaturescheme.Ed25519SignatureFromBytes(sigBytes); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package sctransaction
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffLedgerWriteRead(t *testing.T) {
+	sigScheme := signaturescheme.RandBLS()
+	cid := coretypes.NewContractID(coretypes.ChainID{1, 2, 3}, root.Interface.Hname())
+	args := requestargs.New(nil).AddEncodeSimpleMany(dict.Dict{"a": codec.EncodeInt64(42)})
+
+	req := NewOffLedgerRequest(cid, coretypes.Hn("func"), 1).WithArgs(args)
+	require.Error(t, req.Sign(sigScheme))
+	require.False(t, req.VerifySignature())
+
+	sigScheme = signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	require.NoError(t, req.Sign(sigScheme))
+	require.True(t, req.VerifySignature())
+	require.EqualValues(t, sigScheme.Address(), req.SenderAddress())
+
+	back, err := OffLedgerRequestFromBytes(req.Bytes())
+	require.NoError(t, err)
+	require.True(t, back.VerifySignature())
+	require.EqualValues(t, req.Bytes(), back.Bytes())
+	require.EqualValues(t, req.ID(), back.ID())
+	require.EqualValues(t, 1, back.Nonce())
+
+	// different nonce means different request and the signature is not valid anymore
+	back.nonce = 2
+	require.False(t, back.VerifySignature())
+	require.NotEqual(t, req.ID(), back.ID())
+}
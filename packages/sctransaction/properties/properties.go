@@ -32,7 +32,7 @@ type properties struct {
 	isOrigin bool
 	// if isState == true: chainID
 	chainID coretypes.ChainID
-	// chainAddress == chainID
+	// address which holds the chain token. It is equal to chainID unless the committee of the chain was rotated
 	chainAddress address.Address
 	// if isState == true: smart contract color
 	stateColor balance.Color
@@ -149,6 +149,9 @@ func (prop *properties) analyzeStateBlock(tx *sctransaction.Transaction) error {
 	if err != nil {
 		return err
 	}
+	if chainID := stateSection.ChainID(); chainID != coretypes.NilChainID {
+		prop.chainID = chainID
+	}
 	if prop.isOrigin {
 		prop.stateColor = balance.Color(prop.txid)
 	} else {
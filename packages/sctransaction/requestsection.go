@@ -43,9 +43,12 @@ type RequestSection struct {
 	transfer coretypes.ColoredBalances
 }
 
+// RequestRef references the request either in the request transaction or, if OffLedger != nil,
+// the off-ledger request. In the latter case Tx is nil
 type RequestRef struct {
-	Tx    *Transaction
-	Index uint16
+	Tx        *Transaction
+	Index     uint16
+	OffLedger *OffLedgerRequest
 }
 
 // RequestSection creates new request block
@@ -195,23 +198,42 @@ func (req *RequestSection) Read(r io.Reader) error {
 // request ref
 
 func (ref *RequestRef) RequestSection() *RequestSection {
+	if ref.OffLedger != nil {
+		return ref.OffLedger.RequestSection()
+	}
 	return ref.Tx.Requests()[ref.Index]
 }
 
 func (ref *RequestRef) RequestID() *coretypes.RequestID {
+	if ref.OffLedger != nil {
+		ret := ref.OffLedger.ID()
+		return &ret
+	}
 	ret := coretypes.NewRequestID(ref.Tx.ID(), ref.Index)
 	return &ret
 }
 
+func (ref *RequestRef) IsOffLedger() bool {
+	return ref.OffLedger != nil
+}
+
 func (ref *RequestRef) SenderContractHname() coretypes.Hname {
 	return ref.RequestSection().senderContractHname
 }
 
 func (ref *RequestRef) SenderAddress() *address.Address {
+	if ref.OffLedger != nil {
+		ret := ref.OffLedger.SenderAddress()
+		return &ret
+	}
 	return ref.Tx.MustProperties().SenderAddress()
 }
 
 func (ref *RequestRef) SenderContractID() (ret coretypes.ContractID, err error) {
+	if ref.OffLedger != nil {
+		err = fmt.Errorf("off-ledger request wasn't sent by the smart contract: %s", ref.RequestID().String())
+		return
+	}
 	if _, ok := ref.Tx.State(); !ok {
 		err = fmt.Errorf("request wasn't sent by the smart contract: %s", ref.RequestID().String())
 		return
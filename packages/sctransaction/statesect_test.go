@@ -0,0 +1,65 @@
+package sctransaction
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSectionWriteRead(t *testing.T) {
+	ssec := NewStateSection(NewStateSectionParams{
+		Color:      balance.Color(hashing.RandomHash(nil)),
+		BlockIndex: 5,
+		StateHash:  hashing.RandomHash(nil),
+		StateRoot:  hashing.RandomHash(nil),
+		Timestamp:  1000,
+	})
+	var buf bytes.Buffer
+	require.NoError(t, ssec.Write(&buf))
+	lenVersion1 := buf.Len()
+
+	back := &StateSection{}
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, ssec, back)
+	require.True(t, back.HasStateRoot())
+
+	// the version byte follows the state hash
+	data := buf.Bytes()
+	data[balance.ColorLength+4+8+hashing.HashSize] = StateSectionVersion2 + 1
+	require.Error(t, back.Read(bytes.NewReader(data)))
+
+	// the chain ID is written only by the rotated chain
+	ssec.WithChainID(coretypes.NewRandomChainID())
+	buf.Reset()
+	require.NoError(t, ssec.Write(&buf))
+	require.EqualValues(t, lenVersion1+coretypes.ChainIDLength, buf.Len())
+
+	back = &StateSection{}
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, ssec, back)
+}
+
+func TestStateSectionReadVersion0(t *testing.T) {
+	color := balance.Color(hashing.RandomHash(nil))
+	stateHash := hashing.RandomHash(nil)
+	// the original layout of the state section
+	var buf bytes.Buffer
+	buf.Write(color[:])
+	require.NoError(t, util.WriteUint32(&buf, 7))
+	require.NoError(t, util.WriteUint64(&buf, 1000))
+	buf.Write(stateHash[:])
+
+	back := &StateSection{}
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, color, back.Color())
+	require.EqualValues(t, 7, back.BlockIndex())
+	require.EqualValues(t, 1000, back.Timestamp())
+	require.EqualValues(t, stateHash, back.StateHash())
+	require.EqualValues(t, coretypes.NilChainID, back.ChainID())
+	require.False(t, back.HasStateRoot())
+}
@@ -3,11 +3,28 @@ package sctransaction
 import (
 	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/util"
 	"io"
 )
 
+// Versions of the encoding of the state section.
+// Version 0 is the original layout: color, block index, timestamp, state hash.
+// The timestamp is never negative, so later versions set the highest bit of the timestamp
+// and write the version byte and the fields of the version after it. Unknown versions are rejected
+const (
+	// StateSectionVersion0: color, block index, timestamp, state hash. It has no state root
+	StateSectionVersion0 = byte(0)
+	// StateSectionVersion1: version 0 followed by the state root
+	StateSectionVersion1 = byte(1)
+	// StateSectionVersion2: version 0 followed by the chain ID and the state root.
+	// It is written only when the chain ID is set, i.e. after the committee of the chain was rotated
+	StateSectionVersion2 = byte(2)
+
+	versionedTimestampFlag = uint64(1) << 63
+)
+
 // StateSection of the SC transaction. Represents SC state update
 // previous state block can be determined by the chain transfer of the SC token in the UTXO part of the
 // transaction
@@ -15,6 +32,9 @@ type StateSection struct {
 	// color of the chain which is updated
 	// color contains balance.NEW_COLOR for the origin transaction
 	color balance.Color
+	// chainID is the ID of the chain. It is nil for the origin transaction and for chains
+	// which have never rotated the committee: then the chain ID is the address of the chain token output
+	chainID coretypes.ChainID
 	// blockIndex is 0 for the origin transaction
 	// consensus maintains incremental sequence of state indexes
 	blockIndex uint32
@@ -22,20 +42,28 @@ type StateSection struct {
 	timestamp int64
 	// stateHash is hash of the state it is locked in the transaction
 	stateHash hashing.HashValue
+	// stateRoot is the root of the sparse Merkle tree of all key/value pairs of the state.
+	// Proofs of inclusion into the state are verified against it.
+	// It is nil in sections of version 0, written before the state root was introduced
+	stateRoot hashing.HashValue
 }
 
 type NewStateSectionParams struct {
 	Color      balance.Color
+	ChainID    coretypes.ChainID
 	BlockIndex uint32
 	StateHash  hashing.HashValue
+	StateRoot  hashing.HashValue
 	Timestamp  int64
 }
 
 func NewStateSection(par NewStateSectionParams) *StateSection {
 	return &StateSection{
 		color:      par.Color,
+		chainID:    par.ChainID,
 		blockIndex: par.BlockIndex,
 		stateHash:  par.StateHash,
+		stateRoot:  par.StateRoot,
 		timestamp:  par.Timestamp,
 	}
 }
@@ -46,8 +74,10 @@ func (sb *StateSection) Clone() *StateSection {
 	}
 	return NewStateSection(NewStateSectionParams{
 		Color:      sb.color,
+		ChainID:    sb.chainID,
 		BlockIndex: sb.blockIndex,
 		StateHash:  sb.stateHash,
+		StateRoot:  sb.stateRoot,
 		Timestamp:  sb.timestamp,
 	})
 }
@@ -60,6 +90,16 @@ func (sb *StateSection) Color() balance.Color {
 	return sb.color
 }
 
+// ChainID returns chain ID stored in the state section or NilChainID if it is not present
+func (sb *StateSection) ChainID() coretypes.ChainID {
+	return sb.chainID
+}
+
+func (sb *StateSection) WithChainID(chainID coretypes.ChainID) *StateSection {
+	sb.chainID = chainID
+	return sb
+}
+
 func (sb *StateSection) BlockIndex() uint32 {
 	return sb.blockIndex
 }
@@ -72,6 +112,15 @@ func (sb *StateSection) StateHash() hashing.HashValue {
 	return sb.stateHash
 }
 
+func (sb *StateSection) StateRoot() hashing.HashValue {
+	return sb.stateRoot
+}
+
+func (sb *StateSection) WithStateRoot(root hashing.HashValue) *StateSection {
+	sb.stateRoot = root
+	return sb
+}
+
 func (sb *StateSection) WithStateParams(stateIndex uint32, h hashing.HashValue, ts int64) *StateSection {
 	sb.blockIndex = stateIndex
 	sb.stateHash = h
@@ -79,22 +128,42 @@ func (sb *StateSection) WithStateParams(stateIndex uint32, h hashing.HashValue,
 	return sb
 }
 
+// HasStateRoot is false for sections of version 0, written before the state root was introduced
+func (sb *StateSection) HasStateRoot() bool {
+	return sb.stateRoot != hashing.NilHash
+}
+
 // encoding
 
 func (sb *StateSection) Write(w io.Writer) error {
+	if sb.timestamp < 0 {
+		return fmt.Errorf("negative timestamp in the state section")
+	}
+	version := StateSectionVersion1
+	if sb.chainID != coretypes.NilChainID {
+		version = StateSectionVersion2
+	}
 	if _, err := w.Write(sb.color[:]); err != nil {
 		return err
 	}
 	if err := util.WriteUint32(w, sb.blockIndex); err != nil {
 		return err
 	}
-	if err := util.WriteUint64(w, uint64(sb.timestamp)); err != nil {
+	if err := util.WriteUint64(w, uint64(sb.timestamp)|versionedTimestampFlag); err != nil {
 		return err
 	}
 	if err := sb.stateHash.Write(w); err != nil {
 		return err
 	}
-	return nil
+	if err := util.WriteByte(w, version); err != nil {
+		return err
+	}
+	if version == StateSectionVersion2 {
+		if err := sb.chainID.Write(w); err != nil {
+			return err
+		}
+	}
+	return sb.stateRoot.Write(w)
 }
 
 func (sb *StateSection) Read(r io.Reader) error {
@@ -108,9 +177,30 @@ func (sb *StateSection) Read(r io.Reader) error {
 	if err := util.ReadUint64(r, &timestamp); err != nil {
 		return err
 	}
-	sb.timestamp = int64(timestamp)
+	sb.timestamp = int64(timestamp &^ versionedTimestampFlag)
 	if err := sb.stateHash.Read(r); err != nil {
 		return err
 	}
-	return nil
+	sb.chainID = coretypes.NilChainID
+	sb.stateRoot = hashing.NilHash
+	if timestamp&versionedTimestampFlag == 0 {
+		return nil
+	}
+	version, err := util.ReadByte(r)
+	if err != nil {
+		return err
+	}
+	switch version {
+	case StateSectionVersion1:
+	case StateSectionVersion2:
+		if err := sb.chainID.Read(r); err != nil {
+			return err
+		}
+		if sb.chainID == coretypes.NilChainID {
+			return fmt.Errorf("nil chain ID in the state section of version %d", version)
+		}
+	default:
+		return fmt.Errorf("unsupported version of the state section: %d", version)
+	}
+	return sb.stateRoot.Read(r)
 }
@@ -44,13 +44,13 @@
 // Example test
 //
 // The following example deploys chain and retrieves basic info from the deployed chain.
-// It is expected 4 core contracts deployed on it by default and the test prints them.
+// It is expected 5 core contracts deployed on it by default and the test prints them.
 //  func TestSolo1(t *testing.T) {
 //    env := solo.New(t, false, false)
 //    chain := env.NewChain(nil, "ex1")
 //
 //    chainInfo, coreContracts := chain.GetInfo()   // calls view root::GetInfo
-//    require.EqualValues(t, 4, len(coreContracts)) // 4 core contracts deployed by default
+//    require.EqualValues(t, 5, len(coreContracts)) // 5 core contracts deployed by default
 //
 //    t.Logf("chainID: %s", chainInfo.ChainID)
 //    t.Logf("chain owner ID: %s", chainInfo.ChainOwnerID)
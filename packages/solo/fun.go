@@ -17,9 +17,12 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/merkle"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/plugins/wasmtimevm"
 	"github.com/stretchr/testify/require"
@@ -99,10 +102,11 @@ func (ch *Chain) UploadBlob(sigScheme signaturescheme.SignatureScheme, params ..
 	}
 
 	req := NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, params...)
-	feeColor, ownerFee, validatorFee := ch.GetFeeInfo(blob.Interface.Name)
+	feeColor, ownerFee, validatorFee, feeMode := ch.GetEntryPointFeeInfo(blob.Interface.Name, blob.FuncStoreBlob)
 	require.EqualValues(ch.Env.T, feeColor, balance.ColorIOTA)
 	totalFee := ownerFee + validatorFee
-	if totalFee > 0 {
+	// fees in basis points are taken from the transfer, nothing to add
+	if feeMode == root.FeeModeFlat && totalFee > 0 {
 		req.WithTransfer(balance.ColorIOTA, totalFee)
 	}
 	res, err := ch.PostRequestSync(req, sigScheme)
@@ -142,10 +146,11 @@ func (ch *Chain) UploadBlobOptimized(optimalSize int, sigScheme signaturescheme.
 	for _, v := range toUpload {
 		ch.Env.PutBlobDataIntoRegistry(v)
 	}
-	feeColor, ownerFee, validatorFee := ch.GetFeeInfo(blob.Interface.Name)
+	feeColor, ownerFee, validatorFee, feeMode := ch.GetEntryPointFeeInfo(blob.Interface.Name, blob.FuncStoreBlob)
 	require.EqualValues(ch.Env.T, feeColor, balance.ColorIOTA)
 	totalFee := ownerFee + validatorFee
-	if totalFee > 0 {
+	// fees in basis points are taken from the transfer, nothing to add
+	if feeMode == root.FeeModeFlat && totalFee > 0 {
 		req.WithTransfer(balance.ColorIOTA, totalFee)
 	}
 	res, err := ch.PostRequestSync(req, sigScheme)
@@ -165,6 +170,71 @@ func (ch *Chain) UploadBlobOptimized(optimalSize int, sigScheme signaturescheme.
 	return
 }
 
+// UploadBlobChunked does the same as UploadBlob with the chunked upload protocol of the 'blob' contract:
+// 'beginUpload', 'appendChunk' with chunks of at most chunkSize bytes and 'finalizeUpload'.
+// Each request is posted synchronously
+func (ch *Chain) UploadBlobChunked(chunkSize int, sigScheme signaturescheme.SignatureScheme, params ...interface{}) (ret hashing.HashValue, err error) {
+	fields := codec.MakeDict(toMap(params...))
+	expectedHash := blob.MustGetBlobHash(fields)
+	if _, ok := ch.GetBlobInfo(expectedHash); ok {
+		// blob exists, return hash of existing
+		return expectedHash, nil
+	}
+	post := func(funName string, params ...interface{}) (dict.Dict, error) {
+		req := NewCallParams(blob.Interface.Name, funName, params...)
+		feeColor, ownerFee, validatorFee, feeMode := ch.GetEntryPointFeeInfo(blob.Interface.Name, funName)
+		require.EqualValues(ch.Env.T, feeColor, balance.ColorIOTA)
+		totalFee := ownerFee + validatorFee
+		if feeMode == root.FeeModeFlat && totalFee > 0 {
+			req.WithTransfer(balance.ColorIOTA, totalFee)
+		}
+		return ch.PostRequestSync(req, sigScheme)
+	}
+	if _, err = post(blob.FuncBeginUpload, blob.ParamHash, expectedHash); err != nil {
+		return
+	}
+	for _, k := range fields.KeysSorted() {
+		value := fields[k]
+		if len(value) == 0 {
+			// the empty field is uploaded as the chunk without bytes
+			_, err = post(blob.FuncAppendChunk,
+				blob.ParamHash, expectedHash,
+				blob.ParamField, []byte(k),
+				blob.ParamOffset, 0,
+			)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		for offset := 0; offset < len(value); offset += chunkSize {
+			end := offset + chunkSize
+			if end > len(value) {
+				end = len(value)
+			}
+			_, err = post(blob.FuncAppendChunk,
+				blob.ParamHash, expectedHash,
+				blob.ParamField, []byte(k),
+				blob.ParamOffset, offset,
+				blob.ParamBytes, value[offset:end],
+			)
+			if err != nil {
+				return
+			}
+		}
+	}
+	res, err := post(blob.FuncFinalizeUpload, blob.ParamHash, expectedHash)
+	if err != nil {
+		return
+	}
+	ret, _, err = codec.DecodeHashValue(res.MustGet(blob.ParamHash))
+	if err != nil {
+		return
+	}
+	require.EqualValues(ch.Env.T, expectedHash, ret)
+	return
+}
+
 const (
 	OptimizeUpload  = true
 	OptimalBlobSize = 512
@@ -245,11 +315,48 @@ func (ch *Chain) DeployWasmContract(sigScheme signaturescheme.SignatureScheme, n
 	return ch.DeployContract(sigScheme, name, hprog, params...)
 }
 
+// UpgradeContract replaces the program of the contract with the given name by 'programHash'.
+// The state of the contract is preserved. The 'params' are passed to the 'migrate' entry point
+// of the new program, if it has one. 'sigScheme' must belong either to the creator of the contract
+// or to the chain owner (nil defaults to chain originator)
+func (ch *Chain) UpgradeContract(sigScheme signaturescheme.SignatureScheme, name string, programHash hashing.HashValue, params ...interface{}) error {
+	par := []interface{}{root.ParamProgramHash, programHash, root.ParamHname, coretypes.Hn(name)}
+	par = append(par, params...)
+	req := NewCallParams(root.Interface.Name, root.FuncUpgradeContract, par...)
+	_, err := ch.PostRequestSync(req, sigScheme)
+	return err
+}
+
+// RotateCommittee moves the chain to the new committee. In Solo the committee is represented by
+// the signature scheme 'newChainSigScheme', which becomes the ChainSigScheme of the chain.
+// The 'rotateCommittee' request is posted to the 'root' contract on behalf of 'sigScheme',
+// which must be the chain owner (nil defaults to chain originator). The anchor transaction of the
+// request is still signed by the current committee, then chain token and all assets are held by the new address
+func (ch *Chain) RotateCommittee(sigScheme signaturescheme.SignatureScheme, newChainSigScheme signaturescheme.SignatureScheme) error {
+	req := NewCallParams(root.Interface.Name, root.FuncRotateCommittee, root.ParamChainAddress, newChainSigScheme.Address())
+	if _, err := ch.PostRequestSync(req, sigScheme); err != nil {
+		return err
+	}
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ch.ChainSigScheme = newChainSigScheme
+	ch.ChainAddress = newChainSigScheme.Address()
+	return nil
+}
+
 type ChainInfo struct {
 	ChainID      coretypes.ChainID
 	ChainOwnerID coretypes.AgentID
 	ChainColor   balance.Color
 	ChainAddress address.Address
+	GasBudget    int64
+	// policy of handling requests which don't carry enough fees and the penalty of the 'penalty' policy
+	FeeShortfallPolicy  string
+	FeeShortfallPenalty int64
+	// M-of-N owner set of the chain and M. Empty if the chain is governed by the single chain owner
+	ChainOwners []coretypes.AgentID
+	OwnerQuorum int64
 }
 
 // GetInfo return main parameters of the chain:
@@ -276,16 +383,57 @@ func (ch *Chain) GetInfo() (ChainInfo, map[coretypes.Hname]*root.ContractRecord)
 	require.NoError(ch.Env.T, err)
 	require.True(ch.Env.T, ok)
 
+	gasBudget, ok, err := codec.DecodeInt64(res.MustGet(root.VarGasBudget))
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	feeShortfallPolicy, ok, err := codec.DecodeString(res.MustGet(root.VarFeeShortfallPolicy))
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	feeShortfallPenalty, _, err := codec.DecodeInt64(res.MustGet(root.VarFeeShortfallPenalty))
+	require.NoError(ch.Env.T, err)
+
+	chainOwners, ownerQuorum := root.GetChainOwners(res)
+
 	contracts, err := root.DecodeContractRegistry(collections.NewMapReadOnly(res, root.VarContractRegistry))
 	require.NoError(ch.Env.T, err)
 	return ChainInfo{
-		ChainID:      chainID,
-		ChainOwnerID: chainOwnerID,
-		ChainColor:   chainColor,
-		ChainAddress: chainAddress,
+		ChainID:             chainID,
+		ChainOwnerID:        chainOwnerID,
+		ChainColor:          chainColor,
+		ChainAddress:        chainAddress,
+		GasBudget:           gasBudget,
+		FeeShortfallPolicy:  feeShortfallPolicy,
+		FeeShortfallPenalty: feeShortfallPenalty,
+		ChainOwners:         chainOwners,
+		OwnerQuorum:         ownerQuorum,
 	}, contracts
 }
 
+// GetOwnerProposals returns pending proposals of the M-of-N owner set of the chain
+func (ch *Chain) GetOwnerProposals() []*root.OwnerProposal {
+	res, err := ch.CallView(root.Interface.Name, root.FuncGetOwnerProposals)
+	require.NoError(ch.Env.T, err)
+
+	arr := collections.NewArrayReadOnly(res, root.ParamProposals)
+	ret := make([]*root.OwnerProposal, arr.MustLen())
+	for i := range ret {
+		ret[i], err = root.DecodeOwnerProposal(arr.MustGetAt(uint16(i)))
+		require.NoError(ch.Env.T, err)
+	}
+	return ret
+}
+
+// GetGovernanceProposal returns the proposal of the 'governance' contract
+func (ch *Chain) GetGovernanceProposal(id int64) *governance.Proposal {
+	res, err := ch.CallView(governance.Interface.Name, governance.FuncGetProposal, governance.ParamProposalID, id)
+	require.NoError(ch.Env.T, err)
+	ret, err := governance.DecodeProposal(res.MustGet(governance.ParamProposal))
+	require.NoError(ch.Env.T, err)
+	return ret
+}
+
 // GetAddressBalance returns number of tokens of given color contained in the given address
 // on the UTXODB ledger
 func (env *Solo) GetAddressBalance(addr address.Address, col balance.Color) int64 {
@@ -349,14 +497,106 @@ func (ch *Chain) GetTotalAssets() coretypes.ColoredBalances {
 	)
 }
 
+// TransferOnChain moves colored tokens from the on-chain account controlled by the 'sigScheme'
+// (nil defaults to the chain originator) to the on-chain account of 'target' by posting the
+// 'transfer' request to the 'accounts' contract. No tokens leave the chain
+func (ch *Chain) TransferOnChain(sigScheme signaturescheme.SignatureScheme, target coretypes.AgentID, amounts map[balance.Color]int64) error {
+	par := accounts.EncodeBalances(amounts)
+	par.Set(accounts.ParamAgentID, codec.EncodeAgentID(target))
+	_, err := ch.PostRequestSync(NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncTransfer, par), sigScheme)
+	return err
+}
+
+// GetAllowance returns colored amounts which the 'spender' is allowed to take from
+// the on-chain account of the 'owner'
+func (ch *Chain) GetAllowance(owner, spender coretypes.AgentID) coretypes.ColoredBalances {
+	return ch.getAccountBalance(
+		ch.CallView(accounts.Interface.Name, accounts.FuncGetAllowance,
+			accounts.ParamOwner, owner,
+			accounts.ParamSpender, spender,
+		),
+	)
+}
+
+// GetLockedBalance returns colored amounts of the on-chain account locked by vesting schedules
+// at the current logical time of the solo environment
+func (ch *Chain) GetLockedBalance(agentID coretypes.AgentID) coretypes.ColoredBalances {
+	return ch.getAccountBalance(
+		ch.CallView(accounts.Interface.Name, accounts.FuncGetLockedBalance,
+			accounts.ParamAgentID, agentID,
+			accounts.ParamTimestamp, ch.Env.LogicalTime().Unix(),
+		),
+	)
+}
+
+// GetUnlockedBalance returns colored balances of the on-chain account which can be withdrawn or transferred
+// at the current logical time of the solo environment
+func (ch *Chain) GetUnlockedBalance(agentID coretypes.AgentID) coretypes.ColoredBalances {
+	return ch.getAccountBalance(
+		ch.CallView(accounts.Interface.Name, accounts.FuncGetUnlockedBalance,
+			accounts.ParamAgentID, agentID,
+			accounts.ParamTimestamp, ch.Env.LogicalTime().Unix(),
+		),
+	)
+}
+
+// GetVestingSchedules returns vesting schedules of the on-chain account
+func (ch *Chain) GetVestingSchedules(agentID coretypes.AgentID) []*accounts.VestingSchedule {
+	res, err := ch.CallView(accounts.Interface.Name, accounts.FuncGetVestingSchedules, accounts.ParamAgentID, agentID)
+	require.NoError(ch.Env.T, err)
+	arr := collections.NewArrayReadOnly(res, accounts.ParamVestingSchedules)
+	ret := make([]*accounts.VestingSchedule, arr.MustLen())
+	for i := range ret {
+		ret[i], err = accounts.DecodeVestingSchedule(arr.MustGetAt(uint16(i)))
+		require.NoError(ch.Env.T, err)
+	}
+	return ret
+}
+
+// GetAccountHistory returns up to 'maxRecords' credits and debits of the on-chain account of the agent,
+// the latest first, skipping 'offset' latest entries. It also returns the total number of entries
+func (ch *Chain) GetAccountHistory(agentID coretypes.AgentID, offset, maxRecords int) ([]*accounts.HistoryEntry, int) {
+	res, err := ch.CallView(accounts.Interface.Name, accounts.FuncGetAccountHistory,
+		accounts.ParamAgentID, agentID,
+		accounts.ParamOffset, offset,
+		accounts.ParamMaxRecords, maxRecords,
+	)
+	require.NoError(ch.Env.T, err)
+	n, _, err := codec.DecodeInt64(res.MustGet(accounts.ParamNumRecords))
+	require.NoError(ch.Env.T, err)
+	entries := collections.NewArrayReadOnly(res, accounts.ParamHistory)
+	ret := make([]*accounts.HistoryEntry, entries.MustLen())
+	for i := range ret {
+		ret[i], err = accounts.DecodeHistoryEntry(entries.MustGetAt(uint16(i)))
+		require.NoError(ch.Env.T, err)
+	}
+	return ret, int(n)
+}
+
 // GetFeeInfo returns the fee info for the specific chain and smart contract
 //  - color of the fee tokens in the chain
 //  - chain owner part of the fee (number of tokens)
 //  - validator part of the fee (number of tokens)
 // Total fee is sum of owner fee and validator fee
 func (ch *Chain) GetFeeInfo(contactName string) (balance.Color, int64, int64) {
-	hname := coretypes.Hn(contactName)
-	ret, err := ch.CallView(root.Interface.Name, root.FuncGetFeeInfo, root.ParamHname, hname)
+	feeColor, ownerFee, validatorFee, _ := ch.getFeeInfo(root.ParamHname, coretypes.Hn(contactName))
+	return feeColor, ownerFee, validatorFee
+}
+
+// GetEntryPointFeeInfo returns the fee info for the entry point of the smart contract
+//  - color of the fee tokens in the chain
+//  - chain owner part of the fee
+//  - validator part of the fee
+//  - fee mode: root.FeeModeFlat (number of tokens) or root.FeeModeBasisPoints (basis points of the transferred fee tokens)
+func (ch *Chain) GetEntryPointFeeInfo(contactName string, funName string) (balance.Color, int64, int64, string) {
+	return ch.getFeeInfo(
+		root.ParamHname, coretypes.Hn(contactName),
+		root.ParamEntryPoint, coretypes.Hn(funName),
+	)
+}
+
+func (ch *Chain) getFeeInfo(params ...interface{}) (balance.Color, int64, int64, string) {
+	ret, err := ch.CallView(root.Interface.Name, root.FuncGetFeeInfo, params...)
 	require.NoError(ch.Env.T, err)
 	require.NotEqualValues(ch.Env.T, 0, len(ret))
 
@@ -375,7 +615,11 @@ func (ch *Chain) GetFeeInfo(contactName string) (balance.Color, int64, int64) {
 	require.True(ch.Env.T, ok)
 	require.True(ch.Env.T, ownerFee >= 0)
 
-	return feeColor, ownerFee, validatorFee
+	feeMode, ok, err := codec.DecodeString(ret.MustGet(root.ParamFeeMode))
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	return feeColor, ownerFee, validatorFee, feeMode
 }
 
 // GetEventLogRecords calls the view in the  'eventlog' core smart contract to retrieve
@@ -414,7 +658,8 @@ func (ch *Chain) GetEventLogRecordsString(name string) (string, error) {
 	return ret, nil
 }
 
-// GetEventLogNumRecords returns total number of eventlog records for the given contact.
+// GetEventLogNumRecords returns number of eventlog records for the given contact, not including
+// records pruned according to the retention policy
 func (ch *Chain) GetEventLogNumRecords(name string) int {
 	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetNumRecords,
 		eventlog.ParamContractHname, coretypes.Hn(name),
@@ -425,3 +670,87 @@ func (ch *Chain) GetEventLogNumRecords(name string) int {
 	require.True(ch.Env.T, ok)
 	return int(ret)
 }
+
+// GetEventLogRecordsByRequest calls the view in the 'eventlog' core smart contract to retrieve
+// latest up to 50 records emitted by the request, in time-descending order
+func (ch *Chain) GetEventLogRecordsByRequest(reqID coretypes.RequestID) []*eventlog.Record {
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetRecordsByRequest, eventlog.ParamRequestID, reqID)
+	require.NoError(ch.Env.T, err)
+	return ch.decodeEventLogRecords(res)
+}
+
+// GetEventLogRecordsByBlock calls the view in the 'eventlog' core smart contract to retrieve
+// latest up to 50 records emitted in the block, in time-descending order
+func (ch *Chain) GetEventLogRecordsByBlock(blockIndex uint32) []*eventlog.Record {
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetRecordsByBlock, eventlog.ParamBlockIndex, int64(blockIndex))
+	require.NoError(ch.Env.T, err)
+	return ch.decodeEventLogRecords(res)
+}
+
+func (ch *Chain) decodeEventLogRecords(res dict.Dict) []*eventlog.Record {
+	recs := collections.NewArrayReadOnly(res, eventlog.ParamRecords)
+	ret := make([]*eventlog.Record, recs.MustLen())
+	for i := range ret {
+		rec, err := eventlog.DecodeRecord(recs.MustGetAt(uint16(i)))
+		require.NoError(ch.Env.T, err)
+		ret[i] = rec
+	}
+	return ret
+}
+
+// GetEvents calls the view in the 'eventlog' core smart contract to retrieve typed events
+// selected by the filter params, such as eventlog.ParamContractHname, eventlog.ParamEventName,
+// eventlog.ParamTopic, eventlog.ParamRequestID, eventlog.ParamFromBlock and eventlog.ParamToBlock.
+// It returns latest up to 50 events in time-descending order
+func (ch *Chain) GetEvents(params ...interface{}) []*eventlog.Event {
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents, params...)
+	require.NoError(ch.Env.T, err)
+	events := collections.NewArrayReadOnly(res, eventlog.ParamEvents)
+	ret := make([]*eventlog.Event, events.MustLen())
+	for i := range ret {
+		ret[i], err = eventlog.DecodeEvent(events.MustGetAt(uint16(i)))
+		require.NoError(ch.Env.T, err)
+	}
+	return ret
+}
+
+// GetStateRoot returns the root of the Merkle tree of the chain state, committed in the anchor transaction
+func (ch *Chain) GetStateRoot() hashing.HashValue {
+	return ch.StateTx.MustState().StateRoot()
+}
+
+// GetMerkleProof returns the value of the key in the state of the contract and the proof of its inclusion
+// into the chain state. The proof can be verified against the GetStateRoot with the 'merkle' package.
+// If the key does not exist, it returns nil and the proof of non-inclusion
+func (ch *Chain) GetMerkleProof(scName string, key kv.Key) ([]byte, *merkle.Proof) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	stateKey := kv.Key(coretypes.Hn(scName).Bytes()) + key
+	value, err := ch.State.Variables().Get(stateKey)
+	require.NoError(ch.Env.T, err)
+	return value, ch.State.GetMerkleProof(stateKey)
+}
+
+// GetNonce returns the greatest nonce of the off-ledger requests of the agent processed by the chain
+func (ch *Chain) GetNonce(agentID coretypes.AgentID) uint64 {
+	ret, err := ch.CallView(accounts.Interface.Name, accounts.FuncGetNonce, accounts.ParamAgentID, agentID)
+	require.NoError(ch.Env.T, err)
+	nonce, _, err := codec.DecodeInt64(ret.MustGet(accounts.ParamNonce))
+	require.NoError(ch.Env.T, err)
+	return uint64(nonce)
+}
+
+// GetRequestReceipt returns the receipt of the request processed by the chain, or false if the request
+// has not been processed
+func (ch *Chain) GetRequestReceipt(reqID coretypes.RequestID) (*blocklog.RequestReceipt, bool) {
+	ret, err := ch.CallView(blocklog.Interface.Name, blocklog.FuncGetRequestReceipt, blocklog.ParamRequestID, reqID)
+	require.NoError(ch.Env.T, err)
+	data := ret.MustGet(blocklog.ParamReceipt)
+	if data == nil {
+		return nil, false
+	}
+	receipt, err := blocklog.DecodeRequestReceipt(data)
+	require.NoError(ch.Env.T, err)
+	return receipt, true
+}
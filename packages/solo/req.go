@@ -19,6 +19,7 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/sctransaction/txbuilder"
+	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/stretchr/testify/require"
 )
@@ -174,6 +175,8 @@ func (ch *Chain) PostRequestSync(req *CallParams, sigScheme signaturescheme.Sign
 	return ret, err
 }
 
+// PostRequestSyncTx is the same as PostRequestSync, but also returns the request transaction.
+// The transaction is returned even if the request fails, so that its receipt can be retrieved
 func (ch *Chain) PostRequestSyncTx(req *CallParams, sigScheme signaturescheme.SignatureScheme) (*sctransaction.Transaction, dict.Dict, error) {
 	tx := ch.RequestFromParamsToLedger(req, sigScheme)
 
@@ -185,11 +188,36 @@ func (ch *Chain) PostRequestSyncTx(req *CallParams, sigScheme signaturescheme.Si
 	ch.reqCounter.Add(1)
 	ret, err := ch.runBatch([]vm.RequestRefWithFreeTokens{r}, "post")
 	if err != nil {
-		return nil, nil, err
+		return tx, nil, err
 	}
 	return tx, ret, nil
 }
 
+// PostOffLedgerRequestSync runs the off-ledger request synchronously on the chain.
+// The request is signed by the sigScheme (OriginatorSigScheme if nil) and doesn't go through the ledger,
+// therefore the transfer and the minting part of CallParams are ignored.
+// Fees are charged from the on-chain account of the sender.
+// The nonce must be greater than the nonce of the previous off-ledger request of the sender, otherwise
+// the request is rejected
+func (ch *Chain) PostOffLedgerRequestSync(req *CallParams, sigScheme signaturescheme.SignatureScheme, nonce uint64) (dict.Dict, error) {
+	if sigScheme == nil {
+		sigScheme = ch.OriginatorSigScheme
+	}
+	offLedgerReq := sctransaction.NewOffLedgerRequest(coretypes.NewContractID(ch.ChainID, req.target), req.entryPoint, nonce).
+		WithArgs(req.args)
+	err := offLedgerReq.Sign(sigScheme)
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, offLedgerReq.VerifySignature())
+
+	reqID := offLedgerReq.ID()
+	ch.Log.Infof("PostOffLedgerRequestSync: %s::%s -- %s", req.targetName, req.epName, reqID.String())
+
+	r := vm.RequestRefWithFreeTokens{}
+	r.OffLedger = offLedgerReq
+	ch.reqCounter.Add(1)
+	return ch.runBatch([]vm.RequestRefWithFreeTokens{r}, "post off-ledger")
+}
+
 // callViewFull calls the view entry point of the smart contract
 // with params wrapped into the CallParams object. The transfer part, fs any, is ignored
 func (ch *Chain) callViewFull(req *CallParams) (dict.Dict, error) {
@@ -219,6 +247,28 @@ func (ch *Chain) CallView(scName string, funName string, params ...interface{})
 	return vctx.CallView(coretypes.Hn(scName), coretypes.Hn(funName), p)
 }
 
+// CallViewAtBlockIndex calls the view entry point of the smart contract on the historical state of the chain,
+// as it was right after the block with the given index had been committed.
+// The state is reconstructed by replaying blocks from the origin
+func (ch *Chain) CallViewAtBlockIndex(blockIndex uint32, scName string, funName string, params ...interface{}) (dict.Dict, error) {
+	ch.Log.Infof("callViewAtBlockIndex: #%d %s::%s", blockIndex, scName, funName)
+
+	p := codec.MakeDict(toMap(params...))
+
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	vs, ok, err := state.ReconstructState(ch.db, &ch.ChainID, blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("state #%d not found in chain %s", blockIndex, ch.Name)
+	}
+	vctx := viewcontext.New(ch.ChainID, vs.Variables(), vs.Timestamp(), ch.proc, ch.Log)
+	return vctx.CallView(coretypes.Hn(scName), coretypes.Hn(funName), p)
+}
+
 // WaitForEmptyBacklog waits until the backlog queue of the chain becomes empty.
 // It is useful when smart contract(s) in the test are posting asynchronous requests
 // between chains.
@@ -20,6 +20,10 @@ import (
 
 func (ch *Chain) validateBatch(batch []vm.RequestRefWithFreeTokens) {
 	for _, reqRef := range batch {
+		if reqRef.IsOffLedger() {
+			require.True(ch.Env.T, reqRef.OffLedger.VerifySignature())
+			continue
+		}
 		_, err := reqRef.Tx.Properties()
 		require.NoError(ch.Env.T, err)
 	}
@@ -44,6 +48,7 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 		Processors:         ch.proc,
 		ChainID:            ch.ChainID,
 		Color:              ch.ChainColor,
+		ChainAddress:       ch.ChainAddress,
 		Entropy:            hashing.RandomHash(nil),
 		ValidatorFeeTarget: ch.ValidatorFeeTarget,
 		Balances:           waspconn.OutputsToBalances(ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress)),
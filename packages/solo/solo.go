@@ -14,6 +14,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
 	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/kvstore/mapdb"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -73,10 +74,10 @@ type Chain struct {
 	// It is a default signature scheme in many of 'solo' calls which require private key.
 	OriginatorSigScheme signaturescheme.SignatureScheme
 
-	// ChainID is the ID of the chain (in this version alias of the ChainAddress)
+	// ChainID is the ID of the chain. It is equal to the ChainAddress unless the committee was rotated
 	ChainID coretypes.ChainID
 
-	// ChainAddress is the alias of ChainSigScheme.Address()
+	// ChainAddress is the alias of ChainSigScheme.Address(). It holds the chain token and all assets of the chain
 	ChainAddress address.Address
 
 	// ChainColor is the color of the non-fungible token of the chain.
@@ -98,6 +99,9 @@ type Chain struct {
 	// State ia an interface to access virtual state of the chain: the collection of key/value pairs
 	State state.VirtualState
 
+	// db is the store where the solid state and blocks of the chain are committed
+	db kvstore.KVStore
+
 	// Log is the named logger of the chain
 	Log *logger.Logger
 
@@ -176,6 +180,7 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	if len(validatorFeeTarget) > 0 {
 		feeTarget = validatorFeeTarget[0]
 	}
+	db := mapdb.NewMapDB()
 	ret := &Chain{
 		Env:                 env,
 		Name:                name,
@@ -186,7 +191,8 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 		OriginatorAgentID:   originatorAgentID,
 		ValidatorFeeTarget:  feeTarget,
 		ChainID:             chainID,
-		State:               state.NewVirtualState(mapdb.NewMapDB(), &chainID),
+		State:               state.NewVirtualState(db, &chainID),
+		db:                  db,
 		proc:                processors.MustNew(),
 		Log:                 env.logger.Named(name),
 		//
@@ -183,7 +183,11 @@ func dbkeyBatch(stateIndex uint32) []byte {
 }
 
 func LoadBlock(chainID *coretypes.ChainID, stateIndex uint32) (Block, error) {
-	data, err := database.GetPartition(chainID).Get(dbkeyBatch(stateIndex))
+	return loadBlock(database.GetPartition(chainID), stateIndex)
+}
+
+func loadBlock(db kvstore.KVStore, stateIndex uint32) (Block, error) {
+	data, err := db.Get(dbkeyBatch(stateIndex))
 	if err == kvstore.ErrKeyNotFound {
 		return nil, nil
 	}
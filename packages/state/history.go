@@ -0,0 +1,210 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	// HistorySnapshotInterval is the distance between block indices of the checkpoints kept while replaying blocks.
+	// It bounds the number of blocks replayed for the historical state near an earlier reconstructed one
+	HistorySnapshotInterval = 100
+	// HistoryMaxCheckpoints is the maximum number of checkpoints kept from one replay, besides the requested state.
+	// The checkpoints closest to the requested state are kept
+	HistoryMaxCheckpoints = 4
+	// HistoryMaxReplayBlocks is the maximum number of blocks replayed for one historical state.
+	// States further from the closest snapshot, or from the origin, are rejected
+	HistoryMaxReplayBlocks = 1000
+	// HistoryMaxSnapshotBytes is the maximum total size of the keys and values of the snapshots of historical states
+	// kept for all chains. The least recently used snapshot is evicted first
+	HistoryMaxSnapshotBytes = 64 * 1024 * 1024
+)
+
+// ErrReplayTooLong is returned when the historical state is too far from the closest snapshot to be reconstructed
+var ErrReplayTooLong = errors.New("too many blocks to replay")
+
+// stateSnapshot is a copy of all variables of the historical state of the chain
+type stateSnapshot struct {
+	chainID    coretypes.ChainID
+	blockIndex uint32
+	timestamp  int64
+	stateHash  hashing.HashValue
+	variables  dict.Dict
+	size       int
+}
+
+// snapshotCache keeps snapshots of the historical states reconstructed from blocks.
+// Blocks never change once committed, so snapshots never have to be invalidated
+type snapshotCache struct {
+	mutex     sync.Mutex
+	maxBytes  int
+	size      int
+	snapshots []*stateSnapshot // ordered from the least recently used
+}
+
+var historySnapshots = newSnapshotCache(HistoryMaxSnapshotBytes)
+
+func newSnapshotCache(maxBytes int) *snapshotCache {
+	return &snapshotCache{
+		maxBytes:  maxBytes,
+		snapshots: make([]*stateSnapshot, 0),
+	}
+}
+
+// closest returns the snapshot of the chain with the largest block index not greater than blockIndex, or nil
+func (c *snapshotCache) closest(chainID *coretypes.ChainID, blockIndex uint32) *stateSnapshot {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	found := -1
+	for i, snap := range c.snapshots {
+		if snap.chainID != *chainID || snap.blockIndex > blockIndex {
+			continue
+		}
+		if found < 0 || snap.blockIndex > c.snapshots[found].blockIndex {
+			found = i
+		}
+	}
+	if found < 0 {
+		return nil
+	}
+	ret := c.snapshots[found]
+	// move to the end as the most recently used
+	c.snapshots = append(append(c.snapshots[:found:found], c.snapshots[found+1:]...), ret)
+	return ret
+}
+
+func (c *snapshotCache) add(snap *stateSnapshot) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if snap.size > c.maxBytes {
+		return
+	}
+	for _, s := range c.snapshots {
+		if s.chainID == snap.chainID && s.blockIndex == snap.blockIndex {
+			return
+		}
+	}
+	c.snapshots = append(c.snapshots, snap)
+	c.size += snap.size
+	evict := 0
+	for ; c.size > c.maxBytes; evict++ {
+		c.size -= c.snapshots[evict].size
+	}
+	c.snapshots = c.snapshots[evict:]
+}
+
+func newStateSnapshot(vs *virtualState) *stateSnapshot {
+	ret := &stateSnapshot{
+		chainID:    vs.chainID,
+		blockIndex: vs.blockIndex,
+		timestamp:  vs.timestamp,
+		stateHash:  vs.stateHash,
+		variables:  dict.New(),
+	}
+	vs.variables.MustIterate("", func(key kv.Key, value []byte) bool {
+		ret.variables.Set(key, value)
+		ret.size += len(key) + len(value)
+		return true
+	})
+	return ret
+}
+
+// restore creates the new in-memory virtual state from the snapshot
+func (s *stateSnapshot) restore(chainID *coretypes.ChainID) (*virtualState, error) {
+	db := mapdb.NewMapDB()
+	keys := make([][]byte, 0, len(s.variables))
+	values := make([][]byte, 0, len(s.variables))
+	for key, value := range s.variables {
+		keys = append(keys, dbkeyStateVariable(key))
+		values = append(values, value)
+	}
+	if err := util.DbSetMulti(db, keys, values); err != nil {
+		return nil, err
+	}
+	ret := NewVirtualState(db, chainID)
+	ret.blockIndex = s.blockIndex
+	ret.timestamp = s.timestamp
+	ret.stateHash = s.stateHash
+	ret.empty = false
+	return ret, nil
+}
+
+// LoadStateAtBlockIndex reconstructs the state of the chain as it was right after the block
+// with the given index was committed. Blocks are replayed into an in-memory store, starting from
+// the closest snapshot of the earlier reconstructed states, or from the origin.
+// At most HistoryMaxReplayBlocks blocks are replayed, otherwise ErrReplayTooLong is returned.
+// The solid state in the database is not touched.
+// Returns false if the block index is beyond the solid state
+func LoadStateAtBlockIndex(chainID *coretypes.ChainID, blockIndex uint32) (VirtualState, bool, error) {
+	return reconstructState(getSCPartition(chainID), chainID, blockIndex, historySnapshots, HistoryMaxReplayBlocks)
+}
+
+// ReconstructState replays blocks #0..blockIndex stored in the db into a new in-memory virtual state
+func ReconstructState(db kvstore.KVStore, chainID *coretypes.ChainID, blockIndex uint32) (VirtualState, bool, error) {
+	return reconstructState(db, chainID, blockIndex, nil, 0)
+}
+
+// reconstructState replays at most maxReplay blocks (0 means no limit). Besides the requested state,
+// checkpoints at multiples of HistorySnapshotInterval are added to the snapshots,
+// at most HistoryMaxCheckpoints closest to the requested state
+func reconstructState(db kvstore.KVStore, chainID *coretypes.ChainID, blockIndex uint32, snapshots *snapshotCache, maxReplay uint32) (VirtualState, bool, error) {
+	stateIndexBin, err := db.Get(dbprovider.MakeKey(dbprovider.ObjectTypeSolidStateIndex))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if blockIndex > util.MustUint32From4Bytes(stateIndexBin) {
+		return nil, false, nil
+	}
+	from := uint32(0)
+	var snap *stateSnapshot
+	if snapshots != nil {
+		if snap = snapshots.closest(chainID, blockIndex); snap != nil {
+			from = snap.blockIndex + 1
+		}
+	}
+	if replay := blockIndex + 1 - from; maxReplay > 0 && replay > maxReplay {
+		return nil, false, fmt.Errorf("%w: state #%d needs %d blocks, maximum is %d",
+			ErrReplayTooLong, blockIndex, replay, maxReplay)
+	}
+	vs := NewVirtualState(mapdb.NewMapDB(), chainID)
+	if snap != nil {
+		if vs, err = snap.restore(chainID); err != nil {
+			return nil, false, err
+		}
+	}
+	for i := from; i <= blockIndex; i++ {
+		block, err := loadBlock(db, i)
+		if err != nil {
+			return nil, false, err
+		}
+		if block == nil {
+			return nil, false, fmt.Errorf("inconsistent solid state: block #%d not found", i)
+		}
+		if err = vs.ApplyBlock(block); err != nil {
+			return nil, false, fmt.Errorf("replaying block #%d: %v", i, err)
+		}
+		if snapshots == nil {
+			continue
+		}
+		checkpoint := i%HistorySnapshotInterval == 0 && blockIndex-i < HistorySnapshotInterval*HistoryMaxCheckpoints
+		if i == blockIndex || checkpoint {
+			snapshots.add(newStateSnapshot(vs))
+		}
+	}
+	return vs, true, nil
+}
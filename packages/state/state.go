@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/merkle"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/plugins/database"
 )
@@ -23,6 +24,9 @@ type virtualState struct {
 	empty      bool
 	stateHash  hashing.HashValue
 	variables  buffered.BufferedKVStore
+	// tree is the Merkle tree of the variables, built on first use. It includes first treeMuts mutations of the variables
+	tree     *merkle.Tree
+	treeMuts int
 }
 
 func NewVirtualState(db kvstore.KVStore, chainID *coretypes.ChainID) *virtualState {
@@ -58,6 +62,8 @@ func (vs *virtualState) Clone() VirtualState {
 		empty:      vs.empty,
 		stateHash:  vs.stateHash,
 		variables:  vs.variables.Clone(),
+		tree:       vs.tree.Clone(),
+		treeMuts:   vs.treeMuts,
 	}
 }
 
@@ -123,6 +129,39 @@ func (vs *virtualState) Hash() hashing.HashValue {
 	return vs.stateHash
 }
 
+func (vs *virtualState) StateRoot() hashing.HashValue {
+	return vs.merkleTree().Root()
+}
+
+func (vs *virtualState) GetMerkleProof(key kv.Key) *merkle.Proof {
+	return vs.merkleTree().Proof([]byte(key))
+}
+
+// merkleTree returns the Merkle tree of all variables of the state.
+// The tree is built from all variables once, then only new mutations are applied to it
+func (vs *virtualState) merkleTree() *merkle.Tree {
+	if vs.tree == nil {
+		vs.tree = merkle.NewTree()
+		vs.variables.MustIterate("", func(key kv.Key, value []byte) bool {
+			vs.tree.Set([]byte(key), value)
+			return true
+		})
+		vs.treeMuts = vs.variables.Mutations().Len()
+		return vs.tree
+	}
+	i := 0
+	vs.variables.Mutations().Iterate(func(mut buffered.Mutation) bool {
+		if i >= vs.treeMuts {
+			// if mutation is MutationDel, mut.Value() = nil and the key is deleted
+			vs.tree.Set([]byte(mut.Key()), mut.Value())
+		}
+		i++
+		return true
+	})
+	vs.treeMuts = i
+	return vs.tree
+}
+
 func (vs *virtualState) Write(w io.Writer) error {
 	if _, err := w.Write(util.Uint32To4Bytes(vs.blockIndex)); err != nil {
 		return err
@@ -193,7 +232,12 @@ func (vs *virtualState) CommitToDb(b Block) error {
 	if err != nil {
 		return err
 	}
+	if vs.tree != nil {
+		// the tree must include all mutations before they are cleared
+		vs.merkleTree()
+	}
 	vs.variables.ClearMutations()
+	vs.treeMuts = 0
 	return nil
 }
 
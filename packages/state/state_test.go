@@ -1,6 +1,8 @@
 package state
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
@@ -228,3 +230,150 @@ func TestCommit(t *testing.T) {
 	v, _ = partition.Get(dbkeyStateVariable(kv.Key([]byte("x"))))
 	assert.Nil(t, v)
 }
+
+func TestReconstructState(t *testing.T) {
+	db := mapdb.NewMapDB()
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	vs := NewVirtualState(db, &chainID)
+
+	hashes := make([]hashing.HashValue, 0)
+	for i := uint32(0); i < 3; i++ {
+		txid := (transaction.ID)(hashing.HashStrings("test string", string(rune('a'+i))))
+		reqid := coretypes.NewRequestID(txid, 0)
+		su := NewStateUpdate(&reqid)
+		su.Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(i))))
+		block, err := NewBlock([]StateUpdate{su})
+		assert.NoError(t, err)
+		block.WithBlockIndex(i)
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+		hashes = append(hashes, vs.Hash())
+	}
+
+	for i := uint32(0); i < 3; i++ {
+		vsi, ok, err := ReconstructState(db, &chainID, i)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.EqualValues(t, i, vsi.BlockIndex())
+		assert.EqualValues(t, hashes[i], vsi.Hash())
+		v, _ := vsi.Variables().Get("x")
+		assert.EqualValues(t, codec.EncodeInt64(int64(i)), v)
+	}
+
+	// solid state in the db is not affected
+	v, _ := db.Get(dbkeyStateVariable("x"))
+	assert.EqualValues(t, codec.EncodeInt64(2), v)
+
+	_, ok, err := ReconstructState(db, &chainID, 3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestReconstructStateSnapshots(t *testing.T) {
+	db := mapdb.NewMapDB()
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	vs := NewVirtualState(db, &chainID)
+
+	const numBlocks = 5
+	hashes := make([]hashing.HashValue, 0)
+	for i := uint32(0); i < numBlocks; i++ {
+		txid := (transaction.ID)(hashing.HashStrings("test string", string(rune('a'+i))))
+		reqid := coretypes.NewRequestID(txid, 0)
+		su := NewStateUpdate(&reqid)
+		su.Mutations().Add(buffered.NewMutationSet("x", codec.EncodeInt64(int64(i))))
+		su.Mutations().Add(buffered.NewMutationSet(kv.Key(fmt.Sprintf("y%d", i)), []byte{byte(i)}))
+		if i > 0 {
+			su.Mutations().Add(buffered.NewMutationDel(kv.Key(fmt.Sprintf("y%d", i-1))))
+		}
+		block, err := NewBlock([]StateUpdate{su})
+		assert.NoError(t, err)
+		block.WithBlockIndex(i)
+		assert.NoError(t, vs.ApplyBlock(block))
+		assert.NoError(t, vs.CommitToDb(block))
+		hashes = append(hashes, vs.Hash())
+	}
+
+	// every snapshot holds the same variables: the cache fits two of them
+	snapshots := newSnapshotCache(2 * newStateSnapshot(vs).size)
+	checkState := func(i uint32) {
+		vsi, ok, err := reconstructState(db, &chainID, i, snapshots, 3)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.EqualValues(t, i, vsi.BlockIndex())
+		assert.EqualValues(t, hashes[i], vsi.Hash())
+		assert.EqualValues(t, codec.EncodeInt64(int64(i)), vsi.Variables().MustGet("x"))
+		assert.EqualValues(t, []byte{byte(i)}, vsi.Variables().MustGet(kv.Key(fmt.Sprintf("y%d", i))))
+		if i > 0 {
+			assert.False(t, vsi.Variables().MustHas(kv.Key(fmt.Sprintf("y%d", i-1))))
+		}
+	}
+	// snapshots of #0 (interval) and #2 (requested) are kept
+	checkState(2)
+	checkState(2)
+
+	// blocks before the snapshot are not needed anymore
+	assert.NoError(t, db.Delete(dbkeyBatch(0)))
+	assert.NoError(t, db.Delete(dbkeyBatch(1)))
+	checkState(3)
+	checkState(4)
+
+	// snapshots of #0 and #2 are evicted as the least recently used: deleted blocks are needed again
+	_, _, err := reconstructState(db, &chainID, 1, snapshots, 3)
+	assert.Error(t, err)
+	_, _, err = reconstructState(db, &chainID, 2, snapshots, 3)
+	assert.Error(t, err)
+
+	// too far from the closest snapshot
+	_, _, err = reconstructState(db, &chainID, 4, newSnapshotCache(HistoryMaxSnapshotBytes), 3)
+	assert.True(t, errors.Is(err, ErrReplayTooLong))
+
+	_, ok, err := reconstructState(db, &chainID, numBlocks, snapshots, 3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStateRootIncremental(t *testing.T) {
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	db := mapdb.NewMapDB()
+	vs := NewVirtualState(db, &chainID)
+	for i := 0; i < 10; i++ {
+		vs.Variables().Set(kv.Key(fmt.Sprintf("k%d", i)), []byte{byte(i)})
+	}
+	root1 := vs.StateRoot()
+
+	// the clone shares the tree but is updated independently
+	vs2 := vs.Clone()
+	vs2.Variables().Set("k1", []byte("other"))
+	vs2.Variables().Del("k2")
+	vs2.Variables().Set("new", []byte("v"))
+	root2 := vs2.StateRoot()
+	assert.EqualValues(t, root1, vs.StateRoot())
+	assert.NotEqual(t, root1, root2)
+
+	// the root of the tree built from scratch is the same
+	vsFull := NewVirtualState(mapdb.NewMapDB(), &chainID)
+	vs2.Variables().MustIterate("", func(key kv.Key, value []byte) bool {
+		vsFull.Variables().Set(key, value)
+		return true
+	})
+	assert.EqualValues(t, root2, vsFull.StateRoot())
+
+	// mutations are cleared by the commit, the tree is kept
+	reqid := coretypes.NewRequestID(transaction.ID{}, 0)
+	block, err := NewBlock([]StateUpdate{NewStateUpdate(&reqid)})
+	assert.NoError(t, err)
+	vs2.ApplyBlockIndex(0)
+	assert.NoError(t, vs2.CommitToDb(block))
+	assert.EqualValues(t, root2, vs2.StateRoot())
+	vs2.Variables().Set("k1", []byte{1})
+	vs2.Variables().Set("k2", []byte{2})
+	vs2.Variables().Del("new")
+	assert.EqualValues(t, root1, vs2.StateRoot())
+
+	// the state loaded from the db builds the tree from all variables
+	vs2.Variables().Set("after", []byte("commit"))
+	loaded, _, ok, err := loadSolidState(db, &chainID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.EqualValues(t, root2, loaded.StateRoot())
+}
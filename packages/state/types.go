@@ -6,7 +6,9 @@ import (
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/merkle"
 )
 
 // represents an interface to the mutable state of the smart contract
@@ -26,6 +28,11 @@ type VirtualState interface {
 	// return hash of the variable state. It is a root of the Merkle chain of all
 	// state updates starting from the origin
 	Hash() hashing.HashValue
+	// StateRoot is the root of the sparse Merkle tree of all variable/value pairs of the state
+	StateRoot() hashing.HashValue
+	// GetMerkleProof returns proof of inclusion (or non-inclusion) of the variable into the state.
+	// It is verified against the StateRoot with the 'merkle' package
+	GetMerkleProof(key kv.Key) *merkle.Proof
 	// the storage of variable/value pairs
 	Variables() buffered.BufferedKVStore
 	Clone() VirtualState
@@ -68,7 +68,7 @@ func TestCreditDebit1(t *testing.T) {
 	require.EqualValues(t, 4, GetBalance(state, agentID1, color))
 	checkLedger(t, state, "cp2")
 
-	DebitFromAccount(state, agentID1, expected)
+	DebitFromAccount(state, agentID1, expected, 0)
 	total = checkLedger(t, state, "cp3")
 	expected = cbalances.Nil
 	require.True(t, expected.Equal(total))
@@ -95,7 +95,7 @@ func TestCreditDebit2(t *testing.T) {
 	transfer = cbalances.NewFromMap(map[balance.Color]int64{
 		color: 2,
 	})
-	DebitFromAccount(state, agentID1, transfer)
+	DebitFromAccount(state, agentID1, transfer, 0)
 	total = checkLedger(t, state, "cp2")
 	require.EqualValues(t, 1, total.Len())
 	expected = cbalances.NewFromMap(map[balance.Color]int64{
@@ -130,7 +130,7 @@ func TestCreditDebit3(t *testing.T) {
 	transfer = cbalances.NewFromMap(map[balance.Color]int64{
 		color: 100,
 	})
-	ok := DebitFromAccount(state, agentID1, transfer)
+	ok := DebitFromAccount(state, agentID1, transfer, 0)
 	require.False(t, ok)
 	total = checkLedger(t, state, "cp2")
 
@@ -169,7 +169,7 @@ func TestCreditDebit4(t *testing.T) {
 	transfer = cbalances.NewFromMap(map[balance.Color]int64{
 		balance.ColorIOTA: 20,
 	})
-	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer)
+	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer, 0)
 	require.True(t, ok)
 	total = checkLedger(t, state, "cp2")
 
@@ -225,7 +225,7 @@ func TestCreditDebit5(t *testing.T) {
 	transfer = cbalances.NewFromMap(map[balance.Color]int64{
 		balance.ColorIOTA: 50,
 	})
-	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer)
+	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer, 0)
 	require.False(t, ok)
 	total = checkLedger(t, state, "cp2")
 
@@ -263,7 +263,7 @@ func TestCreditDebit6(t *testing.T) {
 	agentID2 := coretypes.NewRandomAgentID()
 	require.NotEqualValues(t, agentID1, agentID2)
 
-	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer)
+	ok := MoveBetweenAccounts(state, agentID1, agentID2, transfer, 0)
 	require.True(t, ok)
 	total = checkLedger(t, state, "cp2")
 
@@ -295,9 +295,235 @@ func TestCreditDebit7(t *testing.T) {
 		balance.ColorIOTA: 1,
 	})
 	// debit must fail
-	ok := DebitFromAccount(state, agentID1, debitTransfer)
+	ok := DebitFromAccount(state, agentID1, debitTransfer, 0)
 	require.False(t, ok)
 
 	total = checkLedger(t, state, "cp1")
 	require.True(t, transfer.Equal(total))
 }
+
+func TestHistory(t *testing.T) {
+	curTest = "TestHistory"
+	state := dict.New()
+
+	agentID1 := coretypes.NewRandomAgentID()
+	agentID2 := coretypes.NewRandomAgentID()
+	h := &HistoryContext{
+		Timestamp:    1,
+		Reason:       ReasonDeposit,
+		Counterparty: agentID2,
+	}
+	CreditToAccountWithHistory(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{
+		balance.ColorIOTA: 10,
+		color:             2,
+	}), h)
+	checkLedger(t, state, "cp1")
+
+	h = &HistoryContext{Timestamp: 2, Reason: ReasonTransfer}
+	ok := MoveBetweenAccountsWithHistory(state, agentID1, agentID2, cbalances.NewFromMap(map[balance.Color]int64{
+		balance.ColorIOTA: 3,
+	}), h)
+	require.True(t, ok)
+
+	// failed debit is not recorded
+	h = &HistoryContext{Timestamp: 3, Reason: ReasonWithdrawal, Counterparty: agentID2}
+	ok = DebitFromAccountWithHistory(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{
+		color: 5,
+	}), h)
+	require.False(t, ok)
+	ok = DebitFromAccountWithHistory(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{
+		color: 1,
+	}), h)
+	require.True(t, ok)
+
+	// no history context, nothing recorded
+	CreditToAccount(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{
+		balance.ColorIOTA: 1,
+	}))
+	checkLedger(t, state, "cp2")
+
+	require.EqualValues(t, 3, GetHistoryLen(state, agentID1))
+	require.EqualValues(t, 1, GetHistoryLen(state, agentID2))
+
+	entries, err := GetHistory(state, agentID1, 0, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, len(entries))
+
+	require.False(t, entries[0].Credit)
+	require.EqualValues(t, ReasonWithdrawal, entries[0].Reason)
+	require.EqualValues(t, map[balance.Color]int64{color: 1}, entries[0].Balances)
+
+	require.False(t, entries[1].Credit)
+	require.EqualValues(t, ReasonTransfer, entries[1].Reason)
+	require.EqualValues(t, agentID2, entries[1].Counterparty)
+	require.EqualValues(t, 2, entries[1].Timestamp)
+
+	require.True(t, entries[2].Credit)
+	require.EqualValues(t, ReasonDeposit, entries[2].Reason)
+	require.EqualValues(t, agentID2, entries[2].Counterparty)
+	require.EqualValues(t, 10, entries[2].Balances[balance.ColorIOTA])
+	require.EqualValues(t, 2, entries[2].Balances[color])
+
+	entries, err = GetHistory(state, agentID2, 0, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(entries))
+	require.True(t, entries[0].Credit)
+	require.EqualValues(t, agentID1, entries[0].Counterparty)
+
+	// pagination
+	entries, err = GetHistory(state, agentID1, 1, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(entries))
+	require.EqualValues(t, ReasonTransfer, entries[0].Reason)
+
+	entries, err = GetHistory(state, agentID1, 2, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(entries))
+	require.EqualValues(t, ReasonDeposit, entries[0].Reason)
+
+	entries, err = GetHistory(state, agentID1, 3, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(entries))
+}
+
+func TestHistoryRetention(t *testing.T) {
+	curTest = "TestHistoryRetention"
+	state := dict.New()
+
+	agentID := coretypes.NewRandomAgentID()
+	for i := 0; i < MaxHistoryRecords+10; i++ {
+		CreditToAccountWithHistory(state, agentID, cbalances.NewFromMap(map[balance.Color]int64{
+			balance.ColorIOTA: int64(i + 1),
+		}), &HistoryContext{Timestamp: int64(i), Reason: ReasonDeposit})
+	}
+	checkLedger(t, state, "cp1")
+	require.EqualValues(t, MaxHistoryRecords, GetHistoryLen(state, agentID))
+
+	entries, err := GetHistory(state, agentID, 0, MaxHistoryRecords+10)
+	require.NoError(t, err)
+	require.EqualValues(t, MaxHistoryRecords, len(entries))
+	require.EqualValues(t, MaxHistoryRecords+10, entries[0].Balances[balance.ColorIOTA])
+	require.EqualValues(t, 11, entries[MaxHistoryRecords-1].Balances[balance.ColorIOTA])
+
+	entries, err = GetHistory(state, agentID, MaxHistoryRecords-1, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(entries))
+	require.EqualValues(t, 11, entries[0].Balances[balance.ColorIOTA])
+
+	entries, err = GetHistory(state, agentID, MaxHistoryRecords, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(entries))
+}
+
+func TestVestingSchedule(t *testing.T) {
+	s := &VestingSchedule{Color: color, Amount: 1000, Start: 100, Cliff: 10, Duration: 100}
+	require.EqualValues(t, 0, s.Unlocked(50))
+	require.EqualValues(t, 0, s.Unlocked(109))
+	require.EqualValues(t, 100, s.Unlocked(110))
+	require.EqualValues(t, 500, s.Unlocked(150))
+	require.EqualValues(t, 1000, s.Unlocked(200))
+	require.EqualValues(t, 1000, s.Unlocked(1000))
+	require.EqualValues(t, 200, s.End())
+
+	s = &VestingSchedule{Color: color, Amount: 1000, Start: 100}
+	require.EqualValues(t, 1000, s.Locked(99))
+	require.EqualValues(t, 0, s.Locked(100))
+
+	// no overflow
+	s = &VestingSchedule{Color: color, Amount: 1 << 60, Start: 0, Duration: 1 << 20}
+	require.EqualValues(t, 1<<59, s.Unlocked(1<<19))
+
+	data := EncodeVestingSchedule(s)
+	back, err := DecodeVestingSchedule(data)
+	require.NoError(t, err)
+	require.EqualValues(t, s, back)
+}
+
+func TestVestingDebit(t *testing.T) {
+	curTest = "TestVestingDebit"
+	state := dict.New()
+
+	agentID1 := coretypes.NewRandomAgentID()
+	agentID2 := coretypes.NewRandomAgentID()
+	CreditToAccount(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{
+		color:             100,
+		balance.ColorIOTA: 5,
+	}))
+	AddVestingSchedule(state, agentID1, &VestingSchedule{Color: color, Amount: 80, Start: 10, Duration: 80}, 0)
+	require.EqualValues(t, map[balance.Color]int64{color: 20, balance.ColorIOTA: 5}, GetUnlockedBalances(state, agentID1, 10))
+	require.EqualValues(t, map[balance.Color]int64{color: 40, balance.ColorIOTA: 5}, GetUnlockedBalances(state, agentID1, 30))
+
+	h := &HistoryContext{Timestamp: 30 * 1e9, Reason: ReasonTransfer}
+	require.False(t, MoveBetweenAccountsWithHistory(state, agentID1, agentID2, cbalances.NewFromMap(map[balance.Color]int64{color: 41}), h))
+	require.True(t, MoveBetweenAccountsWithHistory(state, agentID1, agentID2, cbalances.NewFromMap(map[balance.Color]int64{color: 40}), h))
+	checkLedger(t, state, "cp1")
+
+	h = &HistoryContext{Timestamp: 90 * 1e9, Reason: ReasonWithdrawal}
+	require.True(t, DebitFromAccountWithHistory(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{color: 60}), h))
+	checkLedger(t, state, "cp2")
+
+	// released schedules are removed
+	require.True(t, AddVestingSchedule(state, agentID1, &VestingSchedule{Color: color, Amount: 1, Start: 100}, 90))
+	require.EqualValues(t, 1, len(GetVestingSchedules(state, agentID1)))
+
+	// the moment of the debit without the history context
+	CreditToAccount(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{color: 1}))
+	require.False(t, DebitFromAccount(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{color: 1}), 99*1e9))
+	require.True(t, DebitFromAccount(state, agentID1, cbalances.NewFromMap(map[balance.Color]int64{color: 1}), 100*1e9))
+	checkLedger(t, state, "cp3")
+}
+
+func TestVestingSchedulesLimit(t *testing.T) {
+	state := dict.New()
+	agentID := coretypes.NewRandomAgentID()
+
+	for i := 0; i < MaxVestingSchedules; i++ {
+		require.True(t, AddVestingSchedule(state, agentID, &VestingSchedule{Color: color, Amount: 10, Start: int64(100 + i)}, 0))
+	}
+	require.False(t, AddVestingSchedule(state, agentID, &VestingSchedule{Color: color, Amount: 10, Start: 1000}, 0))
+	// the same schedule is merged
+	require.True(t, AddVestingSchedule(state, agentID, &VestingSchedule{Color: color, Amount: 10, Start: 100}, 0))
+	schedules := GetVestingSchedules(state, agentID)
+	require.EqualValues(t, MaxVestingSchedules, len(schedules))
+	require.EqualValues(t, 20, schedules[0].Amount)
+	// released schedules make room for new ones
+	require.True(t, AddVestingSchedule(state, agentID, &VestingSchedule{Color: color, Amount: 10, Start: 1000}, 100))
+	require.EqualValues(t, MaxVestingSchedules, len(GetVestingSchedules(state, agentID)))
+}
+
+func TestNonceOutOfOrder(t *testing.T) {
+	state := dict.New()
+	agentID := coretypes.NewRandomAgentID()
+
+	require.False(t, CheckAndUpdateNonce(state, agentID, 0))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 5))
+	require.EqualValues(t, 5, GetNonce(state, agentID))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 5))
+
+	// smaller nonces are accepted once
+	require.True(t, CheckAndUpdateNonce(state, agentID, 3))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 1))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 3))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 1))
+	require.EqualValues(t, 5, GetNonce(state, agentID))
+
+	// used nonces move with the window
+	require.True(t, CheckAndUpdateNonce(state, agentID, 10))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 5))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 3))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 4))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 2))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 4))
+
+	// nonces below the window are rejected
+	require.True(t, CheckAndUpdateNonce(state, agentID, 10+NonceWindowSize))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 9))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 10))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 11))
+
+	// the whole window is dropped when the nonce jumps over it
+	require.True(t, CheckAndUpdateNonce(state, agentID, 1000))
+	require.True(t, CheckAndUpdateNonce(state, agentID, 1000-NonceWindowSize))
+	require.False(t, CheckAndUpdateNonce(state, agentID, 1000-NonceWindowSize-1))
+	require.EqualValues(t, 1000, GetNonce(state, agentID))
+}
@@ -0,0 +1,213 @@
+package accounts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// reasons of the changes of the account
+const (
+	// tokens arrived to the chain with the request, including the request token and refunds
+	ReasonDeposit = "deposit"
+	// fees charged for the request
+	ReasonFee = "fee"
+	// tokens moved between accounts on the chain
+	ReasonTransfer = "transfer"
+	// tokens left the chain to the address or to another chain
+	ReasonWithdrawal = "withdrawal"
+)
+
+// HistoryContext is the context of the change of the account.
+// It is needed to record the change in the history of the account
+type HistoryContext struct {
+	RequestID coretypes.RequestID
+	Timestamp int64
+	Reason    string
+	// the agent on the other side of the credit or debit.
+	// Not used by MoveBetweenAccountsWithHistory, where each account is the counterparty of the other
+	Counterparty coretypes.AgentID
+}
+
+// NewHistoryContext creates the context of the change of the account in the current call
+func NewHistoryContext(ctx coretypes.Sandbox, reason string, counterparty coretypes.AgentID) *HistoryContext {
+	return &HistoryContext{
+		RequestID:    ctx.RequestID(),
+		Timestamp:    ctx.GetTimestamp(),
+		Reason:       reason,
+		Counterparty: counterparty,
+	}
+}
+
+// HistoryEntry is the record of one credit or debit of the account
+type HistoryEntry struct {
+	Counterparty coretypes.AgentID
+	// true if tokens were credited to the account, false if debited
+	Credit    bool
+	Balances  map[balance.Color]int64
+	RequestID coretypes.RequestID
+	Reason    string
+	Timestamp int64
+}
+
+func (e *HistoryEntry) String() string {
+	op := "debit"
+	if e.Credit {
+		op = "credit"
+	}
+	return fmt.Sprintf("%s %s (%s), counterparty: %s, request: %s",
+		op, cbalances.NewFromMap(e.Balances).String(), e.Reason, e.Counterparty.String(), e.RequestID.Short())
+}
+
+// serde
+func (e *HistoryEntry) Write(w io.Writer) error {
+	if _, err := w.Write(e.Counterparty[:]); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, e.Credit); err != nil {
+		return err
+	}
+	bals := cbalances.NewFromMap(e.Balances)
+	if err := util.WriteUint16(w, bals.Len()); err != nil {
+		return err
+	}
+	var err error
+	bals.IterateDeterministic(func(col balance.Color, bal int64) bool {
+		if _, err = w.Write(col[:]); err != nil {
+			return false
+		}
+		err = util.WriteInt64(w, bal)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.RequestID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, e.Reason); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, e.Timestamp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *HistoryEntry) Read(r io.Reader) error {
+	var err error
+	if err := coretypes.ReadAgentID(r, &e.Counterparty); err != nil {
+		return err
+	}
+	if err := util.ReadBoolByte(r, &e.Credit); err != nil {
+		return err
+	}
+	var numColors uint16
+	if err := util.ReadUint16(r, &numColors); err != nil {
+		return err
+	}
+	e.Balances = make(map[balance.Color]int64)
+	for i := uint16(0); i < numColors; i++ {
+		var col balance.Color
+		if err := util.ReadColor(r, &col); err != nil {
+			return err
+		}
+		var bal int64
+		if err := util.ReadInt64(r, &bal); err != nil {
+			return err
+		}
+		e.Balances[col] = bal
+	}
+	if err := e.RequestID.Read(r); err != nil {
+		return err
+	}
+	if e.Reason, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &e.Timestamp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EncodeHistoryEntry(e *HistoryEntry) []byte {
+	return util.MustBytes(e)
+}
+
+func DecodeHistoryEntry(data []byte) (*HistoryEntry, error) {
+	ret := new(HistoryEntry)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+func getHistory(state kv.KVStore, agentID coretypes.AgentID) *collections.TimestampedLog {
+	return collections.NewTimestampedLog(state, kv.Key(varStateHistory+string(agentID[:])))
+}
+
+func getHistoryR(state kv.KVStoreReader, agentID coretypes.AgentID) *collections.ImmutableTimestampedLog {
+	return collections.NewTimestampedLogReadOnly(state, kv.Key(varStateHistory+string(agentID[:])))
+}
+
+// appendToHistory records the change of the account in its history.
+// Only the latest MaxHistoryRecords entries of the account are retained
+func appendToHistory(state kv.KVStore, agentID, counterparty coretypes.AgentID, credit bool, transfer coretypes.ColoredBalances, h *HistoryContext) {
+	if transfer == nil || transfer.Len() == 0 {
+		return
+	}
+	bals := make(map[balance.Color]int64)
+	transfer.AddToMap(bals)
+	history := getHistory(state, agentID)
+	history.MustAppend(h.Timestamp, EncodeHistoryEntry(&HistoryEntry{
+		Counterparty: counterparty,
+		Credit:       credit,
+		Balances:     bals,
+		RequestID:    h.RequestID,
+		Reason:       h.Reason,
+		Timestamp:    h.Timestamp,
+	}))
+	if n := history.MustLen(); n-history.MustFirstIndex() > MaxHistoryRecords {
+		history.MustPrune(n - MaxHistoryRecords)
+	}
+}
+
+// GetHistoryLen returns the number of retained history entries of the account
+func GetHistoryLen(state kv.KVStoreReader, agentID coretypes.AgentID) uint32 {
+	return getHistoryR(state, agentID).MustNumRetained()
+}
+
+// GetHistory returns up to 'maxRecords' history entries of the account, the latest first,
+// skipping 'offset' latest entries. Returns at most MaxHistoryRecords entries
+func GetHistory(state kv.KVStoreReader, agentID coretypes.AgentID, offset, maxRecords uint32) ([]*HistoryEntry, error) {
+	history := getHistoryR(state, agentID)
+	n := history.MustLen()
+	first := history.MustFirstIndex()
+	if offset >= n-first || maxRecords == 0 {
+		return nil, nil
+	}
+	if maxRecords > MaxHistoryRecords {
+		maxRecords = MaxHistoryRecords
+	}
+	last := n - 1 - offset
+	if last+1-first > maxRecords {
+		first = last + 1 - maxRecords
+	}
+	recs := history.MustLoadRecordsRaw(first, last, true)
+	ret := make([]*HistoryEntry, len(recs))
+	for i, data := range recs {
+		rec, err := collections.ParseRawLogRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		if ret[i], err = DecodeHistoryEntry(rec.Data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
@@ -2,10 +2,14 @@ package accounts
 
 import (
 	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/assert"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
 )
@@ -40,6 +44,64 @@ func getAccounts(ctx coretypes.SandboxView) (dict.Dict, error) {
 	return getAccountsIntern(ctx.State()), nil
 }
 
+// getNonce returns the greatest nonce of the off-ledger requests of the agent processed by the chain.
+// The next off-ledger request of the agent must have greater nonce, or an unused one in the window
+// of NonceWindowSize nonces below it
+// Params:
+// - ParamAgentID
+func getNonce(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	aid, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamNonce, codec.EncodeInt64(int64(GetNonce(ctx.State(), aid))))
+	return ret, nil
+}
+
+// getAccountHistory returns history of credits and debits of the account, the latest first
+// Params:
+// - ParamAgentID
+// - ParamOffset number of the latest entries to skip. Default is 0
+// - ParamMaxRecords max number of entries to return. Default is DefaultMaxHistoryRecords, capped at MaxHistoryRecords
+// Returns array ParamHistory of encoded entries and ParamNumRecords, the number of retained entries of the account
+func getAccountHistory(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	aid, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := params.GetInt64(ParamOffset, 0)
+	if err != nil {
+		return nil, err
+	}
+	maxRecords, err := params.GetInt64(ParamMaxRecords, DefaultMaxHistoryRecords)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || maxRecords < 0 {
+		return nil, fmt.Errorf("getAccountHistory: wrong pagination parameters")
+	}
+	if offset > MaxHistoryRecords {
+		offset = MaxHistoryRecords
+	}
+	if maxRecords > MaxHistoryRecords {
+		maxRecords = MaxHistoryRecords
+	}
+	entries, err := GetHistory(ctx.State(), aid, uint32(offset), uint32(maxRecords))
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamNumRecords, codec.EncodeInt64(int64(GetHistoryLen(ctx.State(), aid))))
+	a := collections.NewArray(ret, ParamHistory)
+	for _, e := range entries {
+		a.MustPush(EncodeHistoryEntry(e))
+	}
+	return ret, nil
+}
+
 // deposit moves transfer to the specified account on the chain
 // can be send as request or can be called
 // Params:
@@ -56,7 +118,8 @@ func deposit(ctx coretypes.Sandbox) (dict.Dict, error) {
 	targetAgentID := params.MustGetAgentID(ParamAgentID, ctx.Caller())
 
 	// funds currently are at the disposition of accounts, they are moved to the target
-	succ := MoveBetweenAccounts(state, coretypes.NewAgentIDFromContractID(ctx.ContractID()), targetAgentID, ctx.IncomingTransfer())
+	succ := MoveBetweenAccountsWithHistory(state, coretypes.NewAgentIDFromContractID(ctx.ContractID()), targetAgentID, ctx.IncomingTransfer(),
+		NewHistoryContext(ctx, ReasonDeposit, ctx.Caller()))
 	assert.NewAssert(ctx.Log()).Require(succ, "internal error: failed to deposit to %s", ctx.Caller().String())
 
 	ctx.Log().Debugf("accounts.deposit.success: target: %s\n%s", targetAgentID, ctx.IncomingTransfer().String())
@@ -65,6 +128,8 @@ func deposit(ctx coretypes.Sandbox) (dict.Dict, error) {
 
 // withdrawToAddress sends caller's funds to the caller, the address on L1.
 // caller must be an address
+// Params:
+// - optional color: amount pairs, encoded as in EncodeBalances. Default is all funds of the caller
 func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 	state := ctx.State()
 	mustCheckLedger(state, "accounts.withdrawToAddress.begin")
@@ -74,7 +139,7 @@ func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 
 	a.Require(ctx.Caller().IsAddress(), "caller must be an address")
 
-	bals, ok := GetAccountBalances(state, ctx.Caller())
+	sendTokens, ok := getWithdrawAmounts(ctx, a)
 	if !ok {
 		// empty balance, nothing to withdraw
 		return nil, nil
@@ -83,11 +148,10 @@ func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("accounts.withdrawToAddress.begin: caller agentID: %s myContractId: %s",
 		ctx.Caller().String(), cid.String())
 
-	sendTokens := cbalances.NewFromMap(bals)
 	addr := ctx.Caller().MustAddress()
 
 	// remove tokens from the chain ledger
-	a.Require(DebitFromAccount(state, ctx.Caller(), sendTokens),
+	a.Require(DebitFromAccountWithHistory(state, ctx.Caller(), sendTokens, NewHistoryContext(ctx, ReasonWithdrawal, ctx.Caller())),
 		"accounts.withdrawToAddress.inconsistency. failed to remove tokens from the chain")
 	// send tokens to address
 	a.Require(ctx.TransferToAddress(addr, sendTokens),
@@ -99,6 +163,8 @@ func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 }
 
 // withdrawToChain sends caller's funds to the caller via account::deposit.
+// Params:
+// - optional color: amount pairs, encoded as in EncodeBalances. Default is all funds of the caller
 func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	state := ctx.State()
 	mustCheckLedger(state, "accounts.withdrawToChain.begin")
@@ -113,12 +179,11 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 
 	a.Require(!caller.IsAddress(), "caller must be a smart contract")
 
-	bals, ok := GetAccountBalances(state, caller)
+	toWithdraw, ok := getWithdrawAmounts(ctx, a)
 	if !ok {
 		// empty balance, nothing to withdraw
 		return nil, nil
 	}
-	toWithdraw := cbalances.NewFromMap(bals)
 	callerContract := caller.MustContractID()
 	if callerContract.ChainID() == ctx.ContractID().ChainID() {
 		// no need to move anything on the same chain
@@ -126,7 +191,8 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	}
 
 	// take to tokens here to 'accounts' from the caller
-	succ := MoveBetweenAccounts(ctx.State(), caller, coretypes.NewAgentIDFromContractID(ctx.ContractID()), toWithdraw)
+	succ := MoveBetweenAccountsWithHistory(ctx.State(), caller, coretypes.NewAgentIDFromContractID(ctx.ContractID()), toWithdraw,
+		NewHistoryContext(ctx, ReasonWithdrawal, caller))
 	a.Require(succ, "accounts.withdrawToChain.inconsistency to move tokens between accounts")
 
 	succ = ctx.PostRequest(coretypes.PostRequestParams{
@@ -140,3 +206,276 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a.Require(succ, "accounts.withdrawToChain.inconsistency: failed to post 'deposit' request")
 	return nil, nil
 }
+
+// transfer moves specified colored amounts from the caller's account to the target account on the same chain
+// Params:
+// - ParamAgentID the target account
+// - all other params are color: amount pairs, encoded as in EncodeBalances
+func transfer(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.transfer.begin")
+	defer mustCheckLedger(state, "accounts.transfer.exit")
+
+	a := assert.NewAssert(ctx.Log())
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	targetAgentID := params.MustGetAgentID(ParamAgentID)
+
+	amounts, ok := mustGetAmountsParam(ctx, a, ParamAgentID)
+	a.Require(ok, "accounts.transfer: no amounts specified")
+	toTransfer := cbalances.NewFromMap(amounts)
+
+	caller := ctx.Caller()
+	a.Require(MoveBetweenAccountsWithHistory(state, caller, targetAgentID, toTransfer, NewHistoryContext(ctx, ReasonTransfer, caller)),
+		"accounts.transfer: not enough funds in the account of %s", caller.String())
+
+	ctx.EmitEvent(FuncTransfer, [][]byte{caller[:], targetAgentID[:]}, EncodeBalances(amounts))
+	ctx.Log().Debugf("accounts.transfer.success: %s -> %s\n%s", caller.String(), targetAgentID.String(), toTransfer.String())
+	return nil, nil
+}
+
+// approve allows the spender to take specified colored amounts from the account of the caller with transferFrom.
+// Replaces the previous allowance of the spender for the specified colors
+// Params:
+// - ParamSpender the agent ID of the spender
+// - all other params are color: amount pairs, encoded as in EncodeBalances
+func approve(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert.NewAssert(ctx.Log())
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	spender := params.MustGetAgentID(ParamSpender)
+
+	amounts, ok := mustGetAmountsParam(ctx, a, ParamSpender)
+	a.Require(ok, "accounts.approve: no amounts specified")
+
+	caller := ctx.Caller()
+	SetAllowance(ctx.State(), caller, spender, cbalances.NewFromMap(amounts))
+
+	ctx.EmitEvent(FuncApprove, [][]byte{caller[:], spender[:]}, EncodeBalances(amounts))
+	ctx.Log().Debugf("accounts.approve.success: owner: %s spender: %s", caller.String(), spender.String())
+	return nil, nil
+}
+
+// revoke removes all allowances of the spender to take funds from the account of the caller
+// Params:
+// - ParamSpender the agent ID of the spender
+func revoke(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	spender := params.MustGetAgentID(ParamSpender)
+
+	caller := ctx.Caller()
+	RevokeAllowance(ctx.State(), caller, spender)
+
+	ctx.EmitEvent(FuncRevoke, [][]byte{caller[:], spender[:]}, nil)
+	ctx.Log().Debugf("accounts.revoke.success: owner: %s spender: %s", caller.String(), spender.String())
+	return nil, nil
+}
+
+// getAllowance returns colored amounts which the spender is allowed to take from the account of the owner
+// Params:
+// - ParamOwner
+// - ParamSpender
+func getAllowance(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	owner, err := params.GetAgentID(ParamOwner)
+	if err != nil {
+		return nil, err
+	}
+	spender, err := params.GetAgentID(ParamSpender)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeBalances(GetAllowance(ctx.State(), owner, spender)), nil
+}
+
+// transferFrom moves specified colored amounts from the account of the owner to the target account
+// within the allowance of the caller. The allowance is decremented by the amounts.
+// The caller must be a smart contract
+// Params:
+// - ParamOwner the account to take the funds from
+// - ParamAgentID the target account. Default is the caller
+// - all other params are color: amount pairs, encoded as in EncodeBalances
+func transferFrom(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.transferFrom.begin")
+	defer mustCheckLedger(state, "accounts.transferFrom.exit")
+
+	a := assert.NewAssert(ctx.Log())
+
+	caller := ctx.Caller()
+	a.Require(!caller.IsAddress(), "caller must be a smart contract")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	owner := params.MustGetAgentID(ParamOwner)
+	targetAgentID := params.MustGetAgentID(ParamAgentID, caller)
+
+	amounts, ok := mustGetAmountsParam(ctx, a, ParamOwner, ParamAgentID)
+	a.Require(ok, "accounts.transferFrom: no amounts specified")
+	toTransfer := cbalances.NewFromMap(amounts)
+
+	// in case of failure the whole call is rolled back, so the allowance and the accounts change atomically
+	a.Require(SpendAllowance(state, owner, caller, toTransfer),
+		"accounts.transferFrom: allowance of %s is too low", caller.String())
+	a.Require(MoveBetweenAccountsWithHistory(state, owner, targetAgentID, toTransfer, NewHistoryContext(ctx, ReasonTransfer, owner)),
+		"accounts.transferFrom: not enough funds in the account of %s", owner.String())
+
+	ctx.EmitEvent(FuncTransferFrom, [][]byte{owner[:], targetAgentID[:], caller[:]}, EncodeBalances(amounts))
+	ctx.Log().Debugf("accounts.transferFrom.success: %s -> %s by %s\n%s",
+		owner.String(), targetAgentID.String(), caller.String(), toTransfer.String())
+	return nil, nil
+}
+
+// depositLocked moves the incoming transfer to the target account and locks it there with the vesting schedule.
+// The locked tokens can't be withdrawn or transferred until released by the schedule. All times are Unix seconds
+// Params:
+// - ParamAgentID the target account. Default is the caller
+// - ParamVestingStart the start of the vesting. Default is the current timestamp
+// - ParamVestingCliff nothing is released before start + cliff. Default is 0
+// - ParamVestingDuration the tokens are released linearly from start to start + duration.
+//   Default is 0, i.e. all tokens are released at once at start + cliff
+// Each color must be locked with at least MinVestingAmount tokens and the target account
+// can't have more than MaxVestingSchedules active schedules, so the account can't be flooded
+func depositLocked(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.depositLocked.begin")
+	defer mustCheckLedger(state, "accounts.depositLocked.exit")
+
+	a := assert.NewAssert(ctx.Log())
+
+	now := unixSeconds(ctx.GetTimestamp())
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	targetAgentID := params.MustGetAgentID(ParamAgentID, ctx.Caller())
+	start := params.MustGetInt64(ParamVestingStart, now)
+	cliff := params.MustGetInt64(ParamVestingCliff, 0)
+	duration := params.MustGetInt64(ParamVestingDuration, 0)
+	a.Require(start >= 0 && cliff >= 0 && duration >= 0, "accounts.depositLocked: wrong vesting schedule")
+
+	incoming := ctx.IncomingTransfer()
+	a.Require(incoming != nil && incoming.Len() > 0, "accounts.depositLocked: no tokens to lock")
+	incoming.Iterate(func(col balance.Color, amount int64) bool {
+		a.Require(amount >= MinVestingAmount, "accounts.depositLocked: amount of %s is less than %d",
+			col.String(), MinVestingAmount)
+		return true
+	})
+
+	succ := MoveBetweenAccountsWithHistory(state, coretypes.NewAgentIDFromContractID(ctx.ContractID()), targetAgentID, incoming,
+		NewHistoryContext(ctx, ReasonDeposit, ctx.Caller()))
+	a.Require(succ, "internal error: failed to deposit to %s", targetAgentID.String())
+
+	incoming.IterateDeterministic(func(col balance.Color, amount int64) bool {
+		added := AddVestingSchedule(state, targetAgentID, &VestingSchedule{
+			Color:    col,
+			Amount:   amount,
+			Start:    start,
+			Cliff:    cliff,
+			Duration: duration,
+		}, now)
+		a.Require(added, "accounts.depositLocked: too many vesting schedules in the account %s", targetAgentID.String())
+		return true
+	})
+
+	amounts := make(map[balance.Color]int64)
+	incoming.AddToMap(amounts)
+	caller := ctx.Caller()
+	ctx.EmitEvent(FuncDepositLocked, [][]byte{caller[:], targetAgentID[:]}, EncodeBalances(amounts))
+	ctx.Log().Debugf("accounts.depositLocked.success: target: %s start: %d cliff: %d duration: %d\n%s",
+		targetAgentID.String(), start, cliff, duration, incoming.String())
+	return nil, nil
+}
+
+// getLockedBalance returns colored amounts of the account locked by vesting schedules
+// Params:
+// - ParamAgentID
+// - ParamTimestamp the moment in Unix seconds. Default is the timestamp of the current state
+func getLockedBalance(ctx coretypes.SandboxView) (dict.Dict, error) {
+	aid, ts, err := getVestingViewParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeBalances(GetLockedBalances(ctx.State(), aid, ts)), nil
+}
+
+// getUnlockedBalance returns colored balances of the account which can be withdrawn or transferred
+// Params:
+// - ParamAgentID
+// - ParamTimestamp the moment in Unix seconds. Default is the timestamp of the current state
+func getUnlockedBalance(ctx coretypes.SandboxView) (dict.Dict, error) {
+	aid, ts, err := getVestingViewParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeBalances(GetUnlockedBalances(ctx.State(), aid, ts)), nil
+}
+
+// getVestingSchedules returns array ParamVestingSchedules of the encoded vesting schedules of the account
+// Params:
+// - ParamAgentID
+func getVestingSchedules(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	aid, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamVestingSchedules)
+	for _, s := range GetVestingSchedules(ctx.State(), aid) {
+		a.MustPush(EncodeVestingSchedule(s))
+	}
+	return ret, nil
+}
+
+func getVestingViewParams(ctx coretypes.SandboxView) (coretypes.AgentID, int64, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	aid, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return coretypes.AgentID{}, 0, err
+	}
+	ts, err := params.GetInt64(ParamTimestamp, unixSeconds(ctx.GetTimestamp()))
+	if err != nil {
+		return coretypes.AgentID{}, 0, err
+	}
+	return aid, ts, nil
+}
+
+// mustGetAmountsParam decodes colored amounts from the params of the call. All params except the 'exclude'
+// ones are color: amount pairs, encoded as in EncodeBalances. Returns false if no amounts are specified
+func mustGetAmountsParam(ctx coretypes.Sandbox, a assert.Assert, exclude ...kv.Key) (map[balance.Color]int64, bool) {
+	amounts := ctx.Params().Clone()
+	for _, key := range exclude {
+		amounts.Del(key)
+	}
+	if len(amounts) == 0 {
+		return nil, false
+	}
+	ret := make(map[balance.Color]int64)
+	// deterministic order, so the error is the same on all nodes
+	amounts.ForEachDeterministic(func(key kv.Key, value []byte) bool {
+		col, _, err := codec.DecodeColor([]byte(key))
+		a.RequireNoError(err)
+		amount, _, err := codec.DecodeInt64(value)
+		a.RequireNoError(err)
+		a.Require(amount > 0, "wrong amount %d of color %s", amount, col.String())
+		ret[col] = amount
+		return true
+	})
+	return ret, true
+}
+
+// getWithdrawAmounts returns the amounts specified in params or all unlocked funds of the caller if not specified.
+// Panics if the caller doesn't have enough unlocked funds. Returns false if there's nothing to withdraw
+func getWithdrawAmounts(ctx coretypes.Sandbox, a assert.Assert) (coretypes.ColoredBalances, bool) {
+	bals := GetUnlockedBalances(ctx.State(), ctx.Caller(), unixSeconds(ctx.GetTimestamp()))
+	amounts, specified := mustGetAmountsParam(ctx, a)
+	if !specified {
+		if len(bals) == 0 {
+			return nil, false
+		}
+		return cbalances.NewFromMap(bals), true
+	}
+	ret := cbalances.NewFromMap(amounts)
+	ret.IterateDeterministic(func(col balance.Color, amount int64) bool {
+		a.Require(bals[col] >= amount, "not enough unlocked funds of color %s: %d < %d", col.String(), bals[col], amount)
+		return true
+	})
+	return ret, true
+}
@@ -23,19 +23,65 @@ func init() {
 		coreutil.ViewFunc(FuncBalance, getBalance),
 		coreutil.ViewFunc(FuncTotalAssets, getTotalAssets),
 		coreutil.ViewFunc(FuncAccounts, getAccounts),
+		coreutil.ViewFunc(FuncGetNonce, getNonce),
 		coreutil.Func(FuncDeposit, deposit),
 		coreutil.Func(FuncWithdrawToAddress, withdrawToAddress),
 		coreutil.Func(FuncWithdrawToChain, withdrawToChain),
+		coreutil.Func(FuncTransfer, transfer),
+		coreutil.Func(FuncApprove, approve),
+		coreutil.Func(FuncRevoke, revoke),
+		coreutil.ViewFunc(FuncGetAllowance, getAllowance),
+		coreutil.Func(FuncTransferFrom, transferFrom),
+		coreutil.ViewFunc(FuncGetAccountHistory, getAccountHistory),
+		coreutil.Func(FuncDepositLocked, depositLocked),
+		coreutil.ViewFunc(FuncGetLockedBalance, getLockedBalance),
+		coreutil.ViewFunc(FuncGetUnlockedBalance, getUnlockedBalance),
+		coreutil.ViewFunc(FuncGetVestingSchedules, getVestingSchedules),
 	})
 }
 
 const (
-	FuncBalance           = "balance"
-	FuncTotalAssets       = "totalAssets"
-	FuncDeposit           = "deposit"
-	FuncWithdrawToAddress = "withdrawToAddress"
-	FuncWithdrawToChain   = "withdrawToChain"
-	FuncAccounts          = "accounts"
+	FuncBalance             = "balance"
+	FuncTotalAssets         = "totalAssets"
+	FuncDeposit             = "deposit"
+	FuncWithdrawToAddress   = "withdrawToAddress"
+	FuncWithdrawToChain     = "withdrawToChain"
+	FuncTransfer            = "transfer"
+	FuncApprove             = "approve"
+	FuncRevoke              = "revoke"
+	FuncGetAllowance        = "getAllowance"
+	FuncTransferFrom        = "transferFrom"
+	FuncGetAccountHistory   = "getAccountHistory"
+	FuncDepositLocked       = "depositLocked"
+	FuncGetLockedBalance    = "getLockedBalance"
+	FuncGetUnlockedBalance  = "getUnlockedBalance"
+	FuncGetVestingSchedules = "getVestingSchedules"
+	FuncAccounts            = "accounts"
+	FuncGetNonce            = "getNonce"
 
 	ParamAgentID = "a"
+	ParamNonce   = "n"
+	ParamOwner   = "o"
+	ParamSpender = "s"
+
+	ParamOffset     = "f"
+	ParamMaxRecords = "m"
+	ParamNumRecords = "c"
+	ParamHistory    = "h"
+
+	DefaultMaxHistoryRecords = 50
+	// MaxHistoryRecords is the number of the latest history entries retained for each account.
+	// It is also the maximum number of entries returned by one call of getAccountHistory
+	MaxHistoryRecords = 1000
+
+	ParamVestingStart     = "vs"
+	ParamVestingCliff     = "vc"
+	ParamVestingDuration  = "vd"
+	ParamTimestamp        = "t"
+	ParamVestingSchedules = "v"
+
+	// MinVestingAmount is the minimum amount of each color locked by one deposit
+	MinVestingAmount = 10
+	// MaxVestingSchedules is the maximum number of active vesting schedules of one account
+	MaxVestingSchedules = 16
 )
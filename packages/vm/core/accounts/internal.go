@@ -16,6 +16,10 @@ import (
 const (
 	varStateAccounts    = "a"
 	varStateTotalAssets = "t"
+	varStateNonces      = "n"
+	varStateAllowances  = "l"
+	varStateHistory     = "h"
+	varStateVesting     = "v"
 )
 
 func getAccountsMap(state kv.KVStore) *collections.Map {
@@ -49,6 +53,12 @@ func CreditToAccount(state kv.KVStore, agentID coretypes.AgentID, transfer coret
 	mustCheckLedger(state, "CreditToAccount")
 }
 
+// CreditToAccountWithHistory brings new funds to the on chain ledger and records the credit in the history of the account
+func CreditToAccountWithHistory(state kv.KVStore, agentID coretypes.AgentID, transfer coretypes.ColoredBalances, h *HistoryContext) {
+	CreditToAccount(state, agentID, transfer)
+	appendToHistory(state, agentID, h.Counterparty, true, transfer, h)
+}
+
 // creditToAccount internal
 func creditToAccount(state kv.KVStore, account *collections.Map, transfer coretypes.ColoredBalances) {
 	if transfer == nil || transfer.Len() == 0 {
@@ -68,7 +78,11 @@ func creditToAccount(state kv.KVStore, account *collections.Map, transfer corety
 }
 
 // DebitFromAccount removes funds from the chain ledger.
-func DebitFromAccount(state kv.KVStore, agentID coretypes.AgentID, transfer coretypes.ColoredBalances) bool {
+// Fails if the funds are locked by vesting schedules at the moment ts (timestamp of the request)
+func DebitFromAccount(state kv.KVStore, agentID coretypes.AgentID, transfer coretypes.ColoredBalances, ts int64) bool {
+	if !isUnlocked(state, agentID, transfer, unixSeconds(ts)) {
+		return false
+	}
 	if !debitFromAccount(state, getAccount(state, agentID), transfer) {
 		return false
 	}
@@ -79,6 +93,16 @@ func DebitFromAccount(state kv.KVStore, agentID coretypes.AgentID, transfer core
 	return true
 }
 
+// DebitFromAccountWithHistory removes funds from the chain ledger at the moment of the history context
+// and records the debit in the history of the account
+func DebitFromAccountWithHistory(state kv.KVStore, agentID coretypes.AgentID, transfer coretypes.ColoredBalances, h *HistoryContext) bool {
+	if !DebitFromAccount(state, agentID, transfer, h.Timestamp) {
+		return false
+	}
+	appendToHistory(state, agentID, h.Counterparty, false, transfer, h)
+	return true
+}
+
 // debitFromAccount internal
 func debitFromAccount(state kv.KVStore, account *collections.Map, transfer coretypes.ColoredBalances) bool {
 	if transfer == nil || transfer.Len() == 0 {
@@ -112,11 +136,16 @@ func debitFromAccount(state kv.KVStore, account *collections.Map, transfer coret
 	return true
 }
 
-func MoveBetweenAccounts(state kv.KVStore, fromAgentID, toAgentID coretypes.AgentID, transfer coretypes.ColoredBalances) bool {
+// MoveBetweenAccounts moves funds between accounts on the chain.
+// Fails if the funds are locked by vesting schedules at the moment ts (timestamp of the request)
+func MoveBetweenAccounts(state kv.KVStore, fromAgentID, toAgentID coretypes.AgentID, transfer coretypes.ColoredBalances, ts int64) bool {
 	if fromAgentID == toAgentID {
 		// no need to move
 		return true
 	}
+	if !isUnlocked(state, fromAgentID, transfer, unixSeconds(ts)) {
+		return false
+	}
 	// total assets account doesn't change
 	if !debitFromAccount(state, getAccount(state, fromAgentID), transfer) {
 		return false
@@ -125,6 +154,129 @@ func MoveBetweenAccounts(state kv.KVStore, fromAgentID, toAgentID coretypes.Agen
 	return true
 }
 
+// MoveBetweenAccountsWithHistory moves funds between accounts on the chain at the moment of the history context
+// and records the move in the history of both accounts
+func MoveBetweenAccountsWithHistory(state kv.KVStore, fromAgentID, toAgentID coretypes.AgentID, transfer coretypes.ColoredBalances, h *HistoryContext) bool {
+	if fromAgentID == toAgentID {
+		return true
+	}
+	if !MoveBetweenAccounts(state, fromAgentID, toAgentID, transfer, h.Timestamp) {
+		return false
+	}
+	appendToHistory(state, fromAgentID, toAgentID, false, transfer, h)
+	appendToHistory(state, toAgentID, fromAgentID, true, transfer, h)
+	return true
+}
+
+// NonceWindowSize is the number of nonces below the greatest one, which are still accepted if not used yet.
+// It allows off-ledger requests of the same sender to be processed in a different order than they were signed
+const NonceWindowSize = 64
+
+// nonces of the agent are stored as the greatest nonce (8 bytes) followed by the bitmap (8 bytes)
+// of used nonces in the window below it: bit i is set if nonce (greatest - 1 - i) was used
+func getNonceRecord(state kv.KVStoreReader, agentID coretypes.AgentID) (uint64, uint64) {
+	v := collections.NewMapReadOnly(state, varStateNonces).MustGetAt(agentID[:])
+	if v == nil {
+		return 0, 0
+	}
+	return util.MustUint64From8Bytes(v[:8]), util.MustUint64From8Bytes(v[8:])
+}
+
+// GetNonce returns the greatest nonce of the off-ledger requests of the agent processed by the chain.
+// 0 means no off-ledger requests of the agent were processed yet
+func GetNonce(state kv.KVStoreReader, agentID coretypes.AgentID) uint64 {
+	ret, _ := getNonceRecord(state, agentID)
+	return ret
+}
+
+// CheckAndUpdateNonce marks the nonce of the off-ledger request as used.
+// The nonce is accepted if it is greater than the greatest one, or if it is in the window of
+// NonceWindowSize nonces below it and was not used yet.
+// Returns false if the nonce was already used or is too old, i.e. the request may be a replay
+func CheckAndUpdateNonce(state kv.KVStore, agentID coretypes.AgentID, nonce uint64) bool {
+	greatest, used := getNonceRecord(state, agentID)
+	switch {
+	case nonce > greatest:
+		shift := nonce - greatest
+		// the previous greatest nonce becomes bit shift-1 of the window
+		if shift > NonceWindowSize {
+			used = 0
+		} else {
+			used = used<<shift | 1<<(shift-1)
+		}
+		greatest = nonce
+	case greatest-nonce > NonceWindowSize || nonce == greatest:
+		return false
+	default:
+		bit := uint64(1) << (greatest - nonce - 1)
+		if used&bit != 0 {
+			return false
+		}
+		used |= bit
+	}
+	collections.NewMap(state, varStateNonces).MustSetAt(agentID[:],
+		append(util.Uint64To8Bytes(greatest), util.Uint64To8Bytes(used)...))
+	return true
+}
+
+// getAllowances is the map color: amount, which the spender is allowed to take from the account of the owner
+func getAllowances(state kv.KVStore, owner, spender coretypes.AgentID) *collections.Map {
+	return collections.NewMap(state, varStateAllowances+string(owner[:])+string(spender[:]))
+}
+
+func getAllowancesR(state kv.KVStoreReader, owner, spender coretypes.AgentID) *collections.ImmutableMap {
+	return collections.NewMapReadOnly(state, varStateAllowances+string(owner[:])+string(spender[:]))
+}
+
+// GetAllowance returns colored amounts which the spender is allowed to take from the account of the owner
+func GetAllowance(state kv.KVStoreReader, owner, spender coretypes.AgentID) map[balance.Color]int64 {
+	return getAccountBalances(getAllowancesR(state, owner, spender))
+}
+
+// SetAllowance replaces the allowance of the spender for the specified colors
+func SetAllowance(state kv.KVStore, owner, spender coretypes.AgentID, amounts coretypes.ColoredBalances) {
+	allowances := getAllowances(state, owner, spender)
+	amounts.Iterate(func(col balance.Color, amount int64) bool {
+		allowances.MustSetAt(col[:], util.Uint64To8Bytes(uint64(amount)))
+		return true
+	})
+}
+
+// RevokeAllowance removes all allowances of the spender
+func RevokeAllowance(state kv.KVStore, owner, spender coretypes.AgentID) {
+	allowances := getAllowances(state, owner, spender)
+	for col := range getAccountBalances(allowances.Immutable()) {
+		allowances.MustDelAt(col[:])
+	}
+}
+
+// SpendAllowance decrements allowances of the spender by the amounts.
+// Returns false and changes nothing if the allowance of any color is too low
+func SpendAllowance(state kv.KVStore, owner, spender coretypes.AgentID, amounts coretypes.ColoredBalances) bool {
+	allowances := getAllowances(state, owner, spender)
+	current := getAccountBalances(allowances.Immutable())
+	ok := true
+	amounts.Iterate(func(col balance.Color, amount int64) bool {
+		if current[col] < amount {
+			ok = false
+			return false
+		}
+		return true
+	})
+	if !ok {
+		return false
+	}
+	amounts.Iterate(func(col balance.Color, amount int64) bool {
+		if rem := current[col] - amount; rem > 0 {
+			allowances.MustSetAt(col[:], util.Uint64To8Bytes(uint64(rem)))
+		} else {
+			allowances.MustDelAt(col[:])
+		}
+		return true
+	})
+	return true
+}
+
 func touchAccount(state kv.KVStore, account *collections.Map) {
 	if account.Name() == varStateTotalAssets {
 		return
@@ -0,0 +1,213 @@
+package accounts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// VestingSchedule locks the amount of colored tokens in the account and releases it over time.
+// All times are Unix seconds.
+//  - fixed unlock timestamp: Cliff = 0, Duration = 0. Everything is released at Start
+//  - cliff: nothing is released before Start + Cliff
+//  - linear vesting: the amount is released linearly from Start to Start + Duration.
+//    With the cliff, the part vested before Start + Cliff is released at Start + Cliff
+type VestingSchedule struct {
+	Color    balance.Color
+	Amount   int64
+	Start    int64
+	Cliff    int64
+	Duration int64
+}
+
+// Unlocked returns the part of the amount released at the moment ts (Unix seconds)
+func (s *VestingSchedule) Unlocked(ts int64) int64 {
+	if ts < s.Start+s.Cliff {
+		return 0
+	}
+	if s.Duration == 0 || ts >= s.Start+s.Duration {
+		return s.Amount
+	}
+	// amount * elapsed / duration may overflow int64
+	ret := big.NewInt(s.Amount)
+	ret.Mul(ret, big.NewInt(ts-s.Start))
+	ret.Quo(ret, big.NewInt(s.Duration))
+	return ret.Int64()
+}
+
+// Locked returns the part of the amount still locked at the moment ts (Unix seconds)
+func (s *VestingSchedule) Locked(ts int64) int64 {
+	return s.Amount - s.Unlocked(ts)
+}
+
+// End returns the moment when the whole amount is released
+func (s *VestingSchedule) End() int64 {
+	if s.Cliff > s.Duration {
+		return s.Start + s.Cliff
+	}
+	return s.Start + s.Duration
+}
+
+func (s *VestingSchedule) String() string {
+	return fmt.Sprintf("%s: %d, start: %d, cliff: %ds, duration: %ds",
+		s.Color.String(), s.Amount, s.Start, s.Cliff, s.Duration)
+}
+
+// serde
+func (s *VestingSchedule) Write(w io.Writer) error {
+	if _, err := w.Write(s.Color[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, s.Amount); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, s.Start); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, s.Cliff); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, s.Duration); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *VestingSchedule) Read(r io.Reader) error {
+	if err := util.ReadColor(r, &s.Color); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &s.Amount); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &s.Start); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &s.Cliff); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &s.Duration); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EncodeVestingSchedule(s *VestingSchedule) []byte {
+	return util.MustBytes(s)
+}
+
+func DecodeVestingSchedule(data []byte) (*VestingSchedule, error) {
+	ret := new(VestingSchedule)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// unixSeconds converts the timestamp of the sandbox to Unix seconds
+func unixSeconds(ts int64) int64 {
+	return ts / int64(time.Second)
+}
+
+func getVestingArray(state kv.KVStore, agentID coretypes.AgentID) *collections.Array {
+	return collections.NewArray(state, varStateVesting+string(agentID[:]))
+}
+
+func getVestingArrayR(state kv.KVStoreReader, agentID coretypes.AgentID) *collections.ImmutableArray {
+	return collections.NewArrayReadOnly(state, varStateVesting+string(agentID[:]))
+}
+
+// GetVestingSchedules returns all vesting schedules of the account
+func GetVestingSchedules(state kv.KVStoreReader, agentID coretypes.AgentID) []*VestingSchedule {
+	arr := getVestingArrayR(state, agentID)
+	ret := make([]*VestingSchedule, arr.MustLen())
+	for i := range ret {
+		s, err := DecodeVestingSchedule(arr.MustGetAt(uint16(i)))
+		if err != nil {
+			panic(err)
+		}
+		ret[i] = s
+	}
+	return ret
+}
+
+// AddVestingSchedule locks the amount in the account with the schedule.
+// The schedules released completely at the moment ts (Unix seconds) are removed.
+// The amount is added to the existing schedule with the same color and times.
+// Returns false if the account already has MaxVestingSchedules other schedules
+func AddVestingSchedule(state kv.KVStore, agentID coretypes.AgentID, s *VestingSchedule, ts int64) bool {
+	schedules := make([]*VestingSchedule, 0)
+	merged := false
+	for _, old := range GetVestingSchedules(state, agentID) {
+		if old.End() <= ts {
+			continue
+		}
+		if !merged && old.Color == s.Color && old.Start == s.Start && old.Cliff == s.Cliff && old.Duration == s.Duration {
+			old.Amount += s.Amount
+			merged = true
+		}
+		schedules = append(schedules, old)
+	}
+	if !merged {
+		if len(schedules) >= MaxVestingSchedules {
+			return false
+		}
+		schedules = append(schedules, s)
+	}
+	arr := getVestingArray(state, agentID)
+	arr.MustErase()
+	for _, sch := range schedules {
+		arr.MustPush(EncodeVestingSchedule(sch))
+	}
+	return true
+}
+
+// GetLockedBalances returns colored amounts of the account which are still locked
+// by vesting schedules at the moment ts (Unix seconds)
+func GetLockedBalances(state kv.KVStoreReader, agentID coretypes.AgentID, ts int64) map[balance.Color]int64 {
+	ret := make(map[balance.Color]int64)
+	for _, s := range GetVestingSchedules(state, agentID) {
+		if locked := s.Locked(ts); locked > 0 {
+			ret[s.Color] += locked
+		}
+	}
+	return ret
+}
+
+// GetUnlockedBalances returns colored balances of the account which are not locked
+// by vesting schedules at the moment ts (Unix seconds), i.e. can be withdrawn or transferred
+func GetUnlockedBalances(state kv.KVStoreReader, agentID coretypes.AgentID, ts int64) map[balance.Color]int64 {
+	ret, _ := GetAccountBalances(state, agentID)
+	if ret == nil {
+		ret = make(map[balance.Color]int64)
+	}
+	for col, locked := range GetLockedBalances(state, agentID, ts) {
+		if ret[col] <= locked {
+			delete(ret, col)
+			continue
+		}
+		ret[col] -= locked
+	}
+	return ret
+}
+
+// isUnlocked checks if the transfer can be debited from the account without touching
+// the amounts locked at the moment ts (Unix seconds)
+func isUnlocked(state kv.KVStoreReader, agentID coretypes.AgentID, transfer coretypes.ColoredBalances, ts int64) bool {
+	if transfer == nil || getVestingArrayR(state, agentID).MustLen() == 0 {
+		return true
+	}
+	unlocked := GetUnlockedBalances(state, agentID, ts)
+	ok := true
+	transfer.Iterate(func(col balance.Color, amount int64) bool {
+		ok = unlocked[col] >= amount
+		return ok
+	})
+	return ok
+}
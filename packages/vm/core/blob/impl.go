@@ -2,8 +2,12 @@ package blob
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/assert"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
 
 	"github.com/iotaledger/wasp/packages/kv"
@@ -21,10 +25,18 @@ func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
 // Returns hash of the blob
 func storeBlob(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("blob.storeBlob.begin")
+	blobHash := storeBlobIntern(ctx, ctx.Params())
+
+	ret := dict.New()
+	ret.Set(ParamHash, codec.EncodeHashValue(blobHash))
+	return ret, nil
+}
+
+// storeBlobIntern stores the fields as the blob uploaded by the caller and returns its hash
+func storeBlobIntern(ctx coretypes.Sandbox, fields dict.Dict) hashing.HashValue {
 	state := ctx.State()
-	params := ctx.Params()
 	// calculate a deterministic hash of all blob fields
-	blobHash, kSorted, values := mustGetBlobHash(params)
+	blobHash, kSorted, values := mustGetBlobHash(fields)
 
 	directory := GetDirectory(state)
 	assert.NewAssert(ctx.Log()).Require(!directory.MustHasAt(blobHash[:]),
@@ -47,12 +59,211 @@ func storeBlob(ctx coretypes.Sandbox) (dict.Dict, error) {
 		totalSize += size
 	}
 
-	ret := dict.New()
-	ret.Set(ParamHash, codec.EncodeHashValue(blobHash))
-
 	directory.MustSetAt(blobHash[:], EncodeSize(totalSize))
+	storeBlobRecord(state, blobHash, &BlobRecord{
+		Size:      totalSize,
+		Uploader:  ctx.Caller(),
+		Timestamp: ctx.GetTimestamp(),
+	})
 
 	ctx.Event(fmt.Sprintf("[blob] hash: %s, field sizes: %+v", blobHash.String(), sizes))
+	return blobHash
+}
+
+// deleteBlob removes the blob from the registry.
+// Only the chain owner or the uploader of the blob can delete it.
+// The blob can't be deleted while it is a program of any contract in the 'root' registry
+// Params:
+// - ParamHash hash of the blob
+func deleteBlob(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	blobHash := params.MustGetHashValue(ParamHash)
+
+	rec, ok := GetBlobRecord(ctx.State(), blobHash)
+	a.Require(ok, "blob.deleteBlob.fail: blob with hash %s does not exist", blobHash.String())
+	a.Require(ctx.CheckAuthorizationByChainOwner(ctx.Caller()) || ctx.Caller() == rec.Uploader, "blob.deleteBlob.fail: not authorized")
+	a.Require(rec.References == 0, "blob.deleteBlob.fail: blob %s is referenced by %d contract(s)", blobHash.String(), rec.References)
+	// contracts deployed before the references were counted are found in the registry
+	refs := countProgramReferences(ctx, blobHash)
+	a.Require(refs == 0, "blob.deleteBlob.fail: blob %s is referenced by %d contract(s)", blobHash.String(), refs)
+
+	deleteBlobIntern(ctx.State(), blobHash)
+	ctx.Event(fmt.Sprintf("[blob] deleted: %s", blobHash.String()))
+	return nil, nil
+}
+
+// countProgramReferences returns the number of contracts in the 'root' registry with the blob as program
+func countProgramReferences(ctx coretypes.Sandbox, blobHash hashing.HashValue) int64 {
+	params := dict.New()
+	params.Set(ParamHash, codec.EncodeHashValue(blobHash))
+	a := assert.NewAssert(ctx.Log())
+	res, err := ctx.Call(coretypes.Hn(coreutil.CoreContractRoot), coretypes.Hn(coreutil.CoreFuncRootCountProgramReferences), params, nil)
+	a.RequireNoError(err)
+	ret, _, err := codec.DecodeInt64(res.MustGet(ParamReferences))
+	a.RequireNoError(err)
+	return ret
+}
+
+// addReference increments the reference count of the blob when the contract with the blob as program
+// is added to the 'root' registry. Can be called by the 'root' contract only.
+// Program hashes which are not blobs (core and native contracts) are ignored
+// Params:
+// - ParamHash hash of the blob
+func addReference(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return nil, updateReferences(ctx, 1)
+}
+
+// removeReference decrements the reference count of the blob when the contract record in the 'root' registry
+// stops referring to it. Can be called by the 'root' contract only
+// Params:
+// - ParamHash hash of the blob
+func removeReference(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return nil, updateReferences(ctx, -1)
+}
+
+func updateReferences(ctx coretypes.Sandbox, delta int) error {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	rootAgentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(ctx.ContractID().ChainID(), coretypes.Hn(coreutil.CoreContractRoot)))
+	a.Require(ctx.Caller() == rootAgentID, "blob: references can be updated by the 'root' contract only")
+	blobHash := params.MustGetHashValue(ParamHash)
+
+	rec, ok := GetBlobRecord(ctx.State(), blobHash)
+	if !ok {
+		return nil
+	}
+	switch {
+	case delta > 0:
+		rec.References++
+	case rec.References > 0:
+		// references to blobs stored before the counting are not known
+		rec.References--
+	}
+	storeBlobRecord(ctx.State(), blobHash, rec)
+	return nil
+}
+
+// beginUpload starts the chunked upload of the blob with the expected hash by the caller.
+// If the upload of the caller already exists, it is resumed: chunks uploaded so far are kept.
+// Uploads abandoned for more than UploadTTL are removed
+// Params:
+// - ParamHash expected hash of the blob
+func beginUpload(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	blobHash := params.MustGetHashValue(ParamHash)
+	a.Require(!GetDirectory(state).MustHasAt(blobHash[:]), "blob.beginUpload.fail: blob with hash %s already exist", blobHash.String())
+
+	pruneUploads(state, ctx.GetTimestamp())
+
+	u, ok := GetUpload(state, ctx.Caller(), blobHash)
+	if !ok {
+		u = &Upload{Uploader: ctx.Caller(), Hash: blobHash}
+	}
+	prevDeadline := u.Deadline
+	u.Deadline = ctx.GetTimestamp() + UploadTTL*int64(time.Second)
+	storeUpload(state, u, prevDeadline)
+	ctx.Event(fmt.Sprintf("[blob] begin %s", u.String()))
+	return nil, nil
+}
+
+// appendUploadChunk appends the chunk to the field of the chunked upload of the caller.
+// The offset must be equal to the size of the field uploaded so far, so repeated chunks are rejected
+// Params:
+// - ParamHash expected hash of the blob
+// - ParamField name of the field
+// - ParamOffset int64 offset of the chunk in the field
+// - ParamBytes the chunk, not larger than MaxChunkSize. The empty chunk (or no chunk) is accepted only
+//   as the first chunk of the field, to upload empty fields
+func appendUploadChunk(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	blobHash := params.MustGetHashValue(ParamHash)
+	field := params.MustGetBytes(ParamField)
+	offset := params.MustGetInt64(ParamOffset)
+	data := params.MustGetBytes(ParamBytes, []byte{})
+	a.Require(len(data) <= MaxChunkSize, "blob.appendChunk.fail: wrong chunk size %d", len(data))
+
+	u, ok := GetUpload(state, ctx.Caller(), blobHash)
+	a.Require(ok, "blob.appendChunk.fail: upload of %s not found", blobHash.String())
+	a.Require(u.Deadline >= ctx.GetTimestamp(), "blob.appendChunk.fail: upload of %s expired", blobHash.String())
+	size := u.FieldSize(field)
+	a.Require(offset == int64(size), "blob.appendChunk.fail: wrong offset %d of field '%s', expected %d", offset, string(field), size)
+	a.Require(len(data) > 0 || !u.HasField(field), "blob.appendChunk.fail: empty chunk of field '%s'", string(field))
+
+	appendChunk(state, u, field, data)
+	prevDeadline := u.Deadline
+	u.Deadline = ctx.GetTimestamp() + UploadTTL*int64(time.Second)
+	storeUpload(state, u, prevDeadline)
+	return nil, nil
+}
+
+// abortUpload removes the chunked upload of the caller together with its chunks
+// Params:
+// - ParamHash expected hash of the blob
+func abortUpload(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	blobHash := params.MustGetHashValue(ParamHash)
+
+	u, ok := GetUpload(state, ctx.Caller(), blobHash)
+	a.Require(ok, "blob.abortUpload.fail: upload of %s not found", blobHash.String())
+	deleteUpload(state, u)
+	ctx.Event(fmt.Sprintf("[blob] abort %s", u.String()))
+	return nil, nil
+}
+
+// finalizeUpload assembles the chunked upload of the caller and stores it as the blob.
+// The hash of the assembled blob must be equal to the expected one
+// Params:
+// - ParamHash expected hash of the blob
+// Returns hash of the blob
+func finalizeUpload(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+	blobHash := params.MustGetHashValue(ParamHash)
+
+	u, ok := GetUpload(state, ctx.Caller(), blobHash)
+	a.Require(ok, "blob.finalizeUpload.fail: upload of %s not found", blobHash.String())
+	a.Require(u.Deadline >= ctx.GetTimestamp(), "blob.finalizeUpload.fail: upload of %s expired", blobHash.String())
+
+	fields := assembleUpload(state, u)
+	h := MustGetBlobHash(fields)
+	a.Require(h == blobHash, "blob.finalizeUpload.fail: hash mismatch: expected %s, got %s", blobHash.String(), h.String())
+	deleteUpload(state, u)
+	storeBlobIntern(ctx, fields)
+
+	ret := dict.New()
+	ret.Set(ParamHash, codec.EncodeHashValue(blobHash))
+	return ret, nil
+}
+
+// getUpload returns the state of the chunked upload. The uploader resumes the upload with it
+// Params:
+// - ParamHash expected hash of the blob
+// - ParamUploader agentID of the uploader
+// Returns ParamUpload with the encoded Upload, or nothing if the upload does not exist
+func getUpload(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	blobHash, err := params.GetHashValue(ParamHash)
+	if err != nil {
+		return nil, err
+	}
+	uploader, err := params.GetAgentID(ParamUploader)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := GetUpload(ctx.State(), uploader, blobHash)
+	if !ok {
+		return nil, nil
+	}
+	ret := dict.New()
+	ret.Set(ParamUpload, EncodeUpload(u))
 	return ret, nil
 }
 
@@ -93,11 +304,18 @@ func getBlobField(ctx coretypes.SandboxView) (dict.Dict, error) {
 	return ret, nil
 }
 
+// listBlobs returns the directory of blobs: hash -> encoded BlobRecord with the total size,
+// the number of references, the uploader and the timestamp
 func listBlobs(ctx coretypes.SandboxView) (dict.Dict, error) {
 	ctx.Log().Debugf("blob.listBlobs.begin")
 	ret := dict.New()
-	GetDirectoryR(ctx.State()).MustIterate(func(hash []byte, totalSize []byte) bool {
-		ret.Set(kv.Key(hash), totalSize)
+	GetDirectoryR(ctx.State()).MustIterateKeys(func(hash []byte) bool {
+		h, err := hashing.HashValueFromBytes(hash)
+		if err != nil {
+			panic(err)
+		}
+		rec, _ := GetBlobRecord(ctx.State(), h)
+		ret.Set(kv.Key(hash), EncodeBlobRecord(rec))
 		return true
 	})
 	return ret, nil
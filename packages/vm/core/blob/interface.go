@@ -24,6 +24,14 @@ func init() {
 		coreutil.ViewFunc(FuncGetBlobInfo, getBlobInfo),
 		coreutil.ViewFunc(FuncGetBlobField, getBlobField),
 		coreutil.ViewFunc(FuncListBlobs, listBlobs),
+		coreutil.Func(FuncBeginUpload, beginUpload),
+		coreutil.Func(FuncAppendChunk, appendUploadChunk),
+		coreutil.Func(FuncFinalizeUpload, finalizeUpload),
+		coreutil.Func(FuncAbortUpload, abortUpload),
+		coreutil.ViewFunc(FuncGetUpload, getUpload),
+		coreutil.Func(FuncDeleteBlob, deleteBlob),
+		coreutil.Func(FuncAddReference, addReference),
+		coreutil.Func(FuncRemoveReference, removeReference),
 	})
 }
 
@@ -32,6 +40,12 @@ const (
 	ParamHash  = "hash"
 	ParamField = "field"
 	ParamBytes = "bytes"
+	// chunked upload
+	ParamUploader = "uploader"
+	ParamOffset   = "offset"
+	ParamUpload   = "upload"
+	// number of contracts with the blob as program
+	ParamReferences = "references"
 
 	// variable names of standard blob's field
 	// user-defined field must be different
@@ -44,4 +58,21 @@ const (
 	FuncGetBlobField = "getBlobField"
 	FuncStoreBlob    = "storeBlob"
 	FuncListBlobs    = "listBlobs"
+
+	FuncBeginUpload    = "beginUpload"
+	FuncAppendChunk    = "appendChunk"
+	FuncFinalizeUpload = "finalizeUpload"
+	FuncAbortUpload    = "abortUpload"
+	FuncGetUpload      = "getUpload"
+
+	FuncDeleteBlob      = "deleteBlob"
+	FuncAddReference    = "addReference"
+	FuncRemoveReference = "removeReference"
+)
+
+const (
+	// MaxChunkSize is the maximum size of one chunk of the chunked upload
+	MaxChunkSize = 32 * 1024
+	// UploadTTL is the time in seconds after the last chunk when the unfinished upload is abandoned
+	UploadTTL = 24 * 60 * 60
 )
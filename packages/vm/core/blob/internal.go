@@ -5,7 +5,6 @@ import (
 
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
-	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/util"
@@ -103,19 +102,3 @@ func DecodeSizesMap(sizes dict.Dict) (map[string]uint32, error) {
 	}
 	return ret, nil
 }
-
-func DecodeDirectory(blobs dict.Dict) (map[hashing.HashValue]uint32, error) {
-	ret := make(map[hashing.HashValue]uint32)
-	for hash, size := range blobs {
-		v, err := DecodeSize(size)
-		if err != nil {
-			return nil, err
-		}
-		h, _, err := codec.DecodeHashValue([]byte(hash))
-		if err != nil {
-			return nil, err
-		}
-		ret[h] = v
-	}
-	return ret, nil
-}
@@ -0,0 +1,129 @@
+package blob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const varStateRecords = "m"
+
+// BlobRecord is the metadata of the blob kept in the directory
+type BlobRecord struct {
+	// total size of all fields
+	Size uint32
+	// number of contract records in the 'root' registry with the blob as program
+	References uint32
+	// agent which stored the blob. Zero for blobs stored before the uploader was recorded
+	Uploader coretypes.AgentID
+	// timestamp (nanoseconds) of the block in which the blob was stored
+	Timestamp int64
+}
+
+func (r *BlobRecord) String() string {
+	return fmt.Sprintf("size: %d, references: %d, uploader: %s, timestamp: %d",
+		r.Size, r.References, r.Uploader.String(), r.Timestamp)
+}
+
+// serde
+func (r *BlobRecord) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, r.Size); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, r.References); err != nil {
+		return err
+	}
+	if _, err := w.Write(r.Uploader[:]); err != nil {
+		return err
+	}
+	return util.WriteInt64(w, r.Timestamp)
+}
+
+func (r *BlobRecord) Read(rd io.Reader) error {
+	if err := util.ReadUint32(rd, &r.Size); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(rd, &r.References); err != nil {
+		return err
+	}
+	if err := coretypes.ReadAgentID(rd, &r.Uploader); err != nil {
+		return err
+	}
+	return util.ReadInt64(rd, &r.Timestamp)
+}
+
+func EncodeBlobRecord(r *BlobRecord) []byte {
+	return util.MustBytes(r)
+}
+
+func DecodeBlobRecord(data []byte) (*BlobRecord, error) {
+	ret := new(BlobRecord)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// GetBlobRecord returns the record of the blob in the directory
+func GetBlobRecord(state kv.KVStoreReader, blobHash hashing.HashValue) (*BlobRecord, bool) {
+	size := GetDirectoryR(state).MustGetAt(blobHash[:])
+	if size == nil {
+		return nil, false
+	}
+	ret := &BlobRecord{}
+	if data := collections.NewMapReadOnly(state, varStateRecords).MustGetAt(blobHash[:]); data != nil {
+		var err error
+		if ret, err = DecodeBlobRecord(data); err != nil {
+			panic(err)
+		}
+	}
+	var err error
+	if ret.Size, err = DecodeSize(size); err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+func storeBlobRecord(state kv.KVStore, blobHash hashing.HashValue, rec *BlobRecord) {
+	collections.NewMap(state, varStateRecords).MustSetAt(blobHash[:], EncodeBlobRecord(rec))
+}
+
+// deleteBlobIntern removes all fields of the blob together with its record
+func deleteBlobIntern(state kv.KVStore, blobHash hashing.HashValue) {
+	fields := make([][]byte, 0)
+	GetBlobSizesR(state, blobHash).MustIterateKeys(func(field []byte) bool {
+		fields = append(fields, field)
+		return true
+	})
+	values := GetBlobValues(state, blobHash)
+	sizes := GetBlobSizes(state, blobHash)
+	for _, field := range fields {
+		values.MustDelAt(field)
+		sizes.MustDelAt(field)
+	}
+	GetDirectory(state).MustDelAt(blobHash[:])
+	collections.NewMap(state, varStateRecords).MustDelAt(blobHash[:])
+}
+
+// DecodeDirectory decodes the result of the 'listBlobs' view
+func DecodeDirectory(blobs dict.Dict) (map[hashing.HashValue]*BlobRecord, error) {
+	ret := make(map[hashing.HashValue]*BlobRecord)
+	for hash, data := range blobs {
+		rec, err := DecodeBlobRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		h, _, err := codec.DecodeHashValue([]byte(hash))
+		if err != nil {
+			return nil, err
+		}
+		ret[h] = rec
+	}
+	return ret, nil
+}
@@ -0,0 +1,265 @@
+package blob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	varStateUploads = "u"
+	// index of uploads by deadline: one map of upload keys per bucket of UploadTTL seconds
+	varStateUploadDeadlines = "e"
+	// the earliest bucket of the index which may contain uploads
+	varStateUploadsPruned = "p"
+)
+
+// Upload is the state of the chunked upload of the blob by the uploader.
+// The upload is identified by the uploader and the expected hash of the blob
+type Upload struct {
+	Uploader coretypes.AgentID
+	Hash     hashing.HashValue
+	// timestamp (nanoseconds) after which the upload is abandoned. Extended with each chunk
+	Deadline int64
+	// fields in the order of the first chunk
+	Fields []*UploadField
+}
+
+// UploadField is the state of one field of the chunked upload
+type UploadField struct {
+	Name      []byte
+	Size      uint32
+	NumChunks uint32
+}
+
+// FieldSize returns the size of the field uploaded so far
+func (u *Upload) FieldSize(name []byte) uint32 {
+	if f, _ := u.findField(name); f != nil {
+		return f.Size
+	}
+	return 0
+}
+
+// HasField returns true if the upload contains the field, maybe empty
+func (u *Upload) HasField(name []byte) bool {
+	f, _ := u.findField(name)
+	return f != nil
+}
+
+func (u *Upload) findField(name []byte) (*UploadField, uint16) {
+	for i, f := range u.Fields {
+		if bytes.Equal(f.Name, name) {
+			return f, uint16(i)
+		}
+	}
+	return nil, 0
+}
+
+func (u *Upload) String() string {
+	return fmt.Sprintf("upload of %s by %s, fields: %d", u.Hash.String(), u.Uploader.String(), len(u.Fields))
+}
+
+// serde
+func (u *Upload) Write(w io.Writer) error {
+	if _, err := w.Write(u.Uploader[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(u.Hash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, u.Deadline); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(u.Fields))); err != nil {
+		return err
+	}
+	for _, f := range u.Fields {
+		if err := util.WriteBytes16(w, f.Name); err != nil {
+			return err
+		}
+		if err := util.WriteUint32(w, f.Size); err != nil {
+			return err
+		}
+		if err := util.WriteUint32(w, f.NumChunks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *Upload) Read(r io.Reader) error {
+	var err error
+	if err = coretypes.ReadAgentID(r, &u.Uploader); err != nil {
+		return err
+	}
+	if err = util.ReadHashValue(r, &u.Hash); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &u.Deadline); err != nil {
+		return err
+	}
+	var numFields uint16
+	if err = util.ReadUint16(r, &numFields); err != nil {
+		return err
+	}
+	u.Fields = make([]*UploadField, numFields)
+	for i := range u.Fields {
+		f := &UploadField{}
+		if f.Name, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+		if err = util.ReadUint32(r, &f.Size); err != nil {
+			return err
+		}
+		if err = util.ReadUint32(r, &f.NumChunks); err != nil {
+			return err
+		}
+		u.Fields[i] = f
+	}
+	return nil
+}
+
+func EncodeUpload(u *Upload) []byte {
+	return util.MustBytes(u)
+}
+
+func DecodeUpload(data []byte) (*Upload, error) {
+	ret := new(Upload)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+func uploadKey(uploader coretypes.AgentID, blobHash hashing.HashValue) []byte {
+	return append(append(make([]byte, 0, len(uploader)+len(blobHash)), uploader[:]...), blobHash[:]...)
+}
+
+func chunksKey(uploader coretypes.AgentID, blobHash hashing.HashValue) string {
+	return "c" + string(uploadKey(uploader, blobHash))
+}
+
+func chunkKey(fieldIndex uint16, chunkIndex uint32) []byte {
+	return append(util.Uint16To2Bytes(fieldIndex), util.Uint32To4Bytes(chunkIndex)...)
+}
+
+// GetUpload returns the chunked upload of the blob by the uploader
+func GetUpload(state kv.KVStoreReader, uploader coretypes.AgentID, blobHash hashing.HashValue) (*Upload, bool) {
+	data := collections.NewMapReadOnly(state, varStateUploads).MustGetAt(uploadKey(uploader, blobHash))
+	if data == nil {
+		return nil, false
+	}
+	ret, err := DecodeUpload(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+// storeUpload saves the upload. The deadline of the previously saved upload is prevDeadline, 0 if it is new
+func storeUpload(state kv.KVStore, u *Upload, prevDeadline int64) {
+	key := uploadKey(u.Uploader, u.Hash)
+	collections.NewMap(state, varStateUploads).MustSetAt(key, EncodeUpload(u))
+	if prevDeadline != 0 {
+		getDeadlineBucket(state, prevDeadline).MustDelAt(key)
+	}
+	getDeadlineBucket(state, u.Deadline).MustSetAt(key, []byte{0xFF})
+}
+
+func deadlineBucket(deadline int64) uint64 {
+	return uint64(deadline / (UploadTTL * int64(time.Second)))
+}
+
+func getDeadlineBucketByIndex(state kv.KVStore, bucket uint64) *collections.Map {
+	return collections.NewMap(state, varStateUploadDeadlines+string(util.Uint64To8Bytes(bucket)))
+}
+
+func getDeadlineBucket(state kv.KVStore, deadline int64) *collections.Map {
+	return getDeadlineBucketByIndex(state, deadlineBucket(deadline))
+}
+
+// appendChunk stores the chunk of the field and updates the upload
+func appendChunk(state kv.KVStore, u *Upload, field []byte, data []byte) {
+	f, idx := u.findField(field)
+	if f == nil {
+		f = &UploadField{Name: field}
+		idx = uint16(len(u.Fields))
+		u.Fields = append(u.Fields, f)
+	}
+	if len(data) == 0 {
+		// empty field
+		return
+	}
+	collections.NewMap(state, chunksKey(u.Uploader, u.Hash)).MustSetAt(chunkKey(idx, f.NumChunks), data)
+	f.NumChunks++
+	f.Size += uint32(len(data))
+}
+
+// assembleUpload concatenates chunks of all fields of the upload
+func assembleUpload(state kv.KVStoreReader, u *Upload) dict.Dict {
+	chunks := collections.NewMapReadOnly(state, chunksKey(u.Uploader, u.Hash))
+	ret := dict.New()
+	for i, f := range u.Fields {
+		value := make([]byte, 0, f.Size)
+		for j := uint32(0); j < f.NumChunks; j++ {
+			value = append(value, chunks.MustGetAt(chunkKey(uint16(i), j))...)
+		}
+		ret.Set(kv.Key(f.Name), value)
+	}
+	return ret
+}
+
+// deleteUpload removes the upload together with its chunks
+func deleteUpload(state kv.KVStore, u *Upload) {
+	chunks := collections.NewMap(state, chunksKey(u.Uploader, u.Hash))
+	for i, f := range u.Fields {
+		for j := uint32(0); j < f.NumChunks; j++ {
+			chunks.MustDelAt(chunkKey(uint16(i), j))
+		}
+	}
+	key := uploadKey(u.Uploader, u.Hash)
+	collections.NewMap(state, varStateUploads).MustDelAt(key)
+	getDeadlineBucket(state, u.Deadline).MustDelAt(key)
+}
+
+// pruneUploads removes uploads abandoned at the moment ts.
+// Only the buckets of the deadline index since the previous pruning are visited
+func pruneUploads(state kv.KVStore, ts int64) {
+	current := deadlineBucket(ts)
+	pruned, ok, err := codec.DecodeInt64(state.MustGet(varStateUploadsPruned))
+	if err != nil {
+		panic(err)
+	}
+	from := uint64(pruned)
+	if !ok {
+		// pruning precedes every new upload, so there is nothing to prune yet
+		from = current
+	}
+	uploads := collections.NewMap(state, varStateUploads)
+	for b := from; b <= current; b++ {
+		bucket := getDeadlineBucketByIndex(state, b)
+		expired := make([]*Upload, 0)
+		bucket.MustIterateKeys(func(key []byte) bool {
+			u, err := DecodeUpload(uploads.MustGetAt(key))
+			if err != nil {
+				panic(err)
+			}
+			if u.Deadline < ts {
+				expired = append(expired, u)
+			}
+			return true
+		})
+		// all uploads of the earlier buckets expired, so the buckets become empty
+		for _, u := range expired {
+			deleteUpload(state, u)
+		}
+	}
+	state.Set(varStateUploadsPruned, codec.EncodeInt64(int64(current)))
+}
@@ -0,0 +1,32 @@
+package blocklog
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+)
+
+// initialize is mandatory
+func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("blocklog.initialize.success hname = %s", Interface.Hname().String())
+	return nil, nil
+}
+
+// getRequestReceipt returns the receipt of the processed request
+// Parameters:
+//	- ParamRequestID ID of the request
+// Returns ParamReceipt with the encoded receipt, or nothing if the request has not been processed by the chain
+func getRequestReceipt(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	reqID, err := params.GetRequestID(ParamRequestID)
+	if err != nil {
+		return nil, err
+	}
+	data := GetRequestReceiptsR(ctx.State()).MustGetAt(reqID[:])
+	if data == nil {
+		return nil, nil
+	}
+	ret := dict.New()
+	ret.Set(ParamReceipt, data)
+	return ret, nil
+}
@@ -0,0 +1,181 @@
+package blocklog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	Name        = "blocklog"
+	description = "Block log Contract"
+)
+
+var (
+	Interface = &coreutil.ContractInterface{
+		Name:        Name,
+		Description: description,
+		ProgramHash: hashing.HashStrings(Name),
+	}
+)
+
+func init() {
+	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
+		coreutil.ViewFunc(FuncGetRequestReceipt, getRequestReceipt),
+	})
+}
+
+const (
+	// request parameters
+	ParamRequestID = "requestID"
+	ParamReceipt   = "receipt"
+
+	// function names
+	FuncGetRequestReceipt = "getRequestReceipt"
+
+	// state variables
+	VarRequestReceipts = "r"
+)
+
+// RequestReceipt is the outcome of the processing of one request by the VM.
+// The receipt is stored in the state for every request in the block, including failed ones
+type RequestReceipt struct {
+	RequestID coretypes.RequestID
+	// index of the block which contains the request
+	BlockIndex uint32
+	// index of the request in the block
+	RequestIndex uint16
+	// error returned or panic raised by the call. Empty if the request succeeded
+	Error string
+	// dictionary returned by the call. Nil if the request failed
+	Result dict.Dict
+	// total fee charged for the request, in the fee color of the chain
+	FeeColor balance.Color
+	Fee      int64
+	// the fee shortfall policy applied if the request did not carry enough fees and was not called.
+	// Empty if the fees were enough
+	FeeShortfall string
+	// events emitted by the contracts through Sandbox.Event while processing the request
+	Events []*Event
+}
+
+// Event is an event emitted by the contract
+type Event struct {
+	Contract coretypes.Hname
+	Message  string
+}
+
+func (r *RequestReceipt) Succeeded() bool {
+	return r.Error == ""
+}
+
+func (r *RequestReceipt) String() string {
+	status := "ok"
+	if !r.Succeeded() {
+		status = "failed: " + r.Error
+	}
+	return fmt.Sprintf("request %s, block %d/%d, fee %d, events %d: %s",
+		r.RequestID.Short(), r.BlockIndex, r.RequestIndex, r.Fee, len(r.Events), status)
+}
+
+// serde
+func (r *RequestReceipt) Write(w io.Writer) error {
+	if err := r.RequestID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, r.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, r.RequestIndex); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, r.Error); err != nil {
+		return err
+	}
+	if err := r.Result.Write(w); err != nil {
+		return err
+	}
+	if _, err := w.Write(r.FeeColor[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, r.Fee); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, r.FeeShortfall); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(r.Events))); err != nil {
+		return err
+	}
+	for _, e := range r.Events {
+		if err := e.Contract.Write(w); err != nil {
+			return err
+		}
+		if err := util.WriteString16(w, e.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RequestReceipt) Read(rd io.Reader) error {
+	var err error
+	if err := r.RequestID.Read(rd); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(rd, &r.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(rd, &r.RequestIndex); err != nil {
+		return err
+	}
+	if r.Error, err = util.ReadString16(rd); err != nil {
+		return err
+	}
+	r.Result = dict.New()
+	if err := r.Result.Read(rd); err != nil {
+		return err
+	}
+	if err := util.ReadColor(rd, &r.FeeColor); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(rd, &r.Fee); err != nil {
+		return err
+	}
+	if r.FeeShortfall, err = util.ReadString16(rd); err != nil {
+		return err
+	}
+	var numEvents uint16
+	if err := util.ReadUint16(rd, &numEvents); err != nil {
+		return err
+	}
+	r.Events = make([]*Event, numEvents)
+	for i := range r.Events {
+		e := &Event{}
+		if err := e.Contract.Read(rd); err != nil {
+			return err
+		}
+		if e.Message, err = util.ReadString16(rd); err != nil {
+			return err
+		}
+		r.Events[i] = e
+	}
+	return nil
+}
+
+func EncodeRequestReceipt(r *RequestReceipt) []byte {
+	return util.MustBytes(r)
+}
+
+func DecodeRequestReceipt(data []byte) (*RequestReceipt, error) {
+	ret := new(RequestReceipt)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
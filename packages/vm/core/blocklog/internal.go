@@ -0,0 +1,35 @@
+package blocklog
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+)
+
+// GetRequestReceipts returns the map of receipts: request ID -> encoded RequestReceipt
+func GetRequestReceipts(state kv.KVStore) *collections.Map {
+	return collections.NewMap(state, VarRequestReceipts)
+}
+
+// GetRequestReceiptsR returns the read-only map of receipts
+func GetRequestReceiptsR(state kv.KVStoreReader) *collections.ImmutableMap {
+	return collections.NewMapReadOnly(state, VarRequestReceipts)
+}
+
+// SaveRequestReceipt stores the receipt of the processed request in the state
+func SaveRequestReceipt(state kv.KVStore, receipt *RequestReceipt) {
+	GetRequestReceipts(state).MustSetAt(receipt.RequestID[:], EncodeRequestReceipt(receipt))
+}
+
+// GetRequestReceipt returns the receipt of the request, or false if the request has not been processed
+func GetRequestReceipt(state kv.KVStoreReader, reqID *coretypes.RequestID) (*RequestReceipt, bool, error) {
+	data := GetRequestReceiptsR(state).MustGetAt(reqID[:])
+	if data == nil {
+		return nil, false, nil
+	}
+	ret, err := DecodeRequestReceipt(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return ret, true, nil
+}
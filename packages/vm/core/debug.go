@@ -5,7 +5,9 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
@@ -20,6 +22,9 @@ func printWellKnownHnames() {
 	fmt.Printf("    %10s: '%s'\n", accounts.Interface.Hname().String(), accounts.Interface.Name)
 	fmt.Printf("    %10s: '%s'\n", blob.Interface.Hname().String(), blob.Interface.Name)
 	fmt.Printf("    %10s: '%s'\n", eventlog.Interface.Hname().String(), eventlog.Interface.Name)
+	fmt.Printf("    %10s: '%s'\n", blocklog.Interface.Hname().String(), blocklog.Interface.Name)
+	fmt.Printf("    %10s: '%s'\n", governance.Interface.Hname().String(), governance.Interface.Name)
 	fmt.Printf("    %10s: '%s'\n", coretypes.EntryPointInit.String(), coretypes.FuncInit)
+	fmt.Printf("    %10s: '%s'\n", coretypes.EntryPointMigrate.String(), coretypes.FuncMigrate)
 	fmt.Printf("--------------- well known hnames ------------------\n")
 }
@@ -0,0 +1,159 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Event is the typed event emitted by the contract with Sandbox.EmitEvent.
+// Events are indexed by contract, name, each of the topics, request ID and block index
+type Event struct {
+	Contract   coretypes.Hname
+	Name       string
+	Topics     [][]byte
+	Payload    dict.Dict
+	RequestID  coretypes.RequestID
+	BlockIndex uint32
+	Timestamp  int64
+}
+
+func (e *Event) String() string {
+	topics := make([]string, len(e.Topics))
+	for i, t := range e.Topics {
+		topics[i] = valueString(t)
+	}
+	payload := make([]string, 0, len(e.Payload))
+	e.Payload.ForEachDeterministic(func(key kv.Key, value []byte) bool {
+		payload = append(payload, fmt.Sprintf("%s: %s", valueString([]byte(key)), valueString(value)))
+		return true
+	})
+	return fmt.Sprintf("[%s] contract: %s, topics: [%s], payload: {%s}",
+		e.Name, e.Contract.String(), strings.Join(topics, ", "), strings.Join(payload, ", "))
+}
+
+// valueString shows the printable value as is, otherwise hex-encoded
+func valueString(t []byte) string {
+	if utf8.Valid(t) && strings.IndexFunc(string(t), func(r rune) bool { return r < 0x20 }) < 0 {
+		return fmt.Sprintf("'%s'", string(t))
+	}
+	return fmt.Sprintf("0x%x", t)
+}
+
+// serde
+func (e *Event) Write(w io.Writer) error {
+	if err := e.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, e.Name); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(e.Topics))); err != nil {
+		return err
+	}
+	for _, t := range e.Topics {
+		if err := util.WriteBytes16(w, t); err != nil {
+			return err
+		}
+	}
+	if err := e.Payload.Write(w); err != nil {
+		return err
+	}
+	if err := e.RequestID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, e.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, e.Timestamp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *Event) Read(r io.Reader) error {
+	var err error
+	if err := e.Contract.Read(r); err != nil {
+		return err
+	}
+	if e.Name, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	var numTopics uint16
+	if err := util.ReadUint16(r, &numTopics); err != nil {
+		return err
+	}
+	e.Topics = make([][]byte, numTopics)
+	for i := range e.Topics {
+		if e.Topics[i], err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	e.Payload = dict.New()
+	if err := e.Payload.Read(r); err != nil {
+		return err
+	}
+	if err := e.RequestID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &e.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &e.Timestamp); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EncodeEvent(e *Event) []byte {
+	return util.MustBytes(e)
+}
+
+func DecodeEvent(data []byte) (*Event, error) {
+	ret := new(Event)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// EventFilter selects events by the conditions. Zero value of the field means no condition
+type EventFilter struct {
+	Contract   coretypes.Hname
+	Name       string
+	Topic      []byte
+	RequestID  *coretypes.RequestID
+	FromBlock  uint32
+	ToBlock    uint32 // 0 means up to the latest block
+	// MaxRecords is the max number of returned events, capped by MaxNumberOfRecords. 0 means MaxNumberOfRecords
+	MaxRecords uint32
+}
+
+func (f *EventFilter) accepts(e *Event) bool {
+	if f.Contract != 0 && e.Contract != f.Contract {
+		return false
+	}
+	if f.Name != "" && e.Name != f.Name {
+		return false
+	}
+	if f.RequestID != nil && e.RequestID != *f.RequestID {
+		return false
+	}
+	if e.BlockIndex < f.FromBlock || (f.ToBlock != 0 && e.BlockIndex > f.ToBlock) {
+		return false
+	}
+	if f.Topic == nil {
+		return true
+	}
+	for _, t := range e.Topics {
+		if bytes.Equal(t, f.Topic) {
+			return true
+		}
+	}
+	return false
+}
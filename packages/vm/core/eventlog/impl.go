@@ -1,7 +1,11 @@
 package eventlog
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/assert"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
 
@@ -16,9 +20,10 @@ func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
-// getNumRecords gets the number of eventlog records for contarct
+// getNumRecords gets the number of eventlog records for contarct, not including pruned records
 // Parameters:
 //	- ParamContractHname Hname of the contract to view the logs
+// Returns ParamNumRecords and ParamEarliestTs, the timestamp of the earliest retained record
 func getNumRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	params := kvdecoder.New(ctx.Params())
 	contractHname, err := params.GetHname(ParamContractHname)
@@ -27,7 +32,8 @@ func getNumRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 	ret := dict.New()
 	thelog := collections.NewTimestampedLogReadOnly(ctx.State(), kv.Key(contractHname.Bytes()))
-	ret.Set(ParamNumRecords, codec.EncodeInt64(int64(thelog.MustLen())))
+	ret.Set(ParamNumRecords, codec.EncodeInt64(int64(thelog.MustNumRetained())))
+	ret.Set(ParamEarliestTs, codec.EncodeInt64(thelog.MustEarliest()))
 	return ret, nil
 }
 
@@ -38,6 +44,8 @@ func getNumRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 //  - ParamFromTs From interval. Defaults to 0
 //  - ParamToTs To Interval. Defaults to now (if both are missing means all)
 //  - ParamMaxLastRecords Max amount of records that you want to return. Defaults to 50
+// Returns array ParamRecords and ParamEarliestTs, the timestamp of the earliest retained record.
+// Records pruned according to the retention policy are not returned
 func getRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	params := kvdecoder.New(ctx.Params())
 
@@ -59,12 +67,13 @@ func getRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 
 	theLog := collections.NewTimestampedLogReadOnly(ctx.State(), kv.Key(contractHname.Bytes()))
+	ret := dict.New()
+	ret.Set(ParamEarliestTs, codec.EncodeInt64(theLog.MustEarliest()))
 	tts := theLog.MustTakeTimeSlice(fromTs, toTs)
 	if tts.IsEmpty() {
 		// empty time slice
-		return nil, nil
+		return ret, nil
 	}
-	ret := dict.New()
 	first, last := tts.FromToIndicesCapped(uint32(maxLast))
 	data := theLog.MustLoadRecordsRaw(first, last, true) // descending
 	a := collections.NewArray(ret, ParamRecords)
@@ -73,3 +82,186 @@ func getRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 	return ret, nil
 }
+
+// getEvents returns typed events selected by the filter, the latest first
+// Parameters:
+//	- ParamContractHname Filter param, Hname of the contract which emitted the events
+//	- ParamEventName Filter param, name of the event
+//	- ParamTopic Filter param, value of one of the topics of the event
+//	- ParamRequestID Filter param, ID of the request which emitted the events
+//	- ParamFromBlock Filter param, index of the first block. Defaults to 0
+//	- ParamToBlock Filter param, index of the last block. Defaults to the latest block.
+//	  The range must not be longer than MaxBlockRange blocks and must end not later than the latest block
+//	- ParamMaxLastRecords Max amount of events that you want to return. Defaults to 50, capped by MaxNumberOfRecords
+// Returns array ParamEvents of encoded events
+func getEvents(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	f := &EventFilter{}
+	var err error
+	if f.Contract, err = params.GetHname(ParamContractHname, 0); err != nil {
+		return nil, err
+	}
+	if f.Name, err = params.GetString(ParamEventName, ""); err != nil {
+		return nil, err
+	}
+	if f.Topic, err = params.GetBytes(ParamTopic, nil); err != nil {
+		return nil, err
+	}
+	if ctx.Params().MustHas(ParamRequestID) {
+		reqID, err := params.GetRequestID(ParamRequestID)
+		if err != nil {
+			return nil, err
+		}
+		f.RequestID = &reqID
+	}
+	fromBlock, err := params.GetInt64(ParamFromBlock, 0)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := params.GetInt64(ParamToBlock, 0)
+	if err != nil {
+		return nil, err
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock < 0 || toBlock < 0 || fromBlock > math.MaxUint32 || toBlock > math.MaxUint32 {
+		return nil, fmt.Errorf("wrong block range [%d, %d]", fromBlock, toBlock)
+	}
+	f.FromBlock, f.ToBlock, f.MaxRecords = uint32(fromBlock), uint32(toBlock), capMaxNumberOfRecords(maxLast)
+
+	events, err := GetEvents(ctx.State(), f)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamEvents)
+	for _, e := range events {
+		a.MustPush(EncodeEvent(e))
+	}
+	return ret, nil
+}
+
+// setRetention sets the retention policy of event records and typed events. Can be called by the chain owner only.
+// The policy of the contract overrides the default one. The affected logs are pruned immediately,
+// afterwards all logs are pruned once per block
+// Parameters:
+//	- ParamContractHname Hname of the contract. If not present, the default policy is set
+//	- ParamMaxRecords int64 max number of records of the contract. Defaults to 0 (unlimited)
+//	- ParamMaxAge int64 max age of records in seconds. Defaults to 0 (unlimited)
+// If the contract is present and both limits are not, the policy of the contract is removed, so the default applies
+func setRetention(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert.NewAssert(ctx.Log())
+	a.Require(ctx.CheckAuthorizationByChainOwner(ctx.Caller()), "eventlog.setRetention: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	maxRecords := params.MustGetInt64(ParamMaxRecords, 0)
+	maxAge := params.MustGetInt64(ParamMaxAge, 0)
+	a.Require(maxRecords >= 0 && maxRecords <= math.MaxUint32 && maxAge >= 0, "eventlog.setRetention: wrong parameters")
+	p := &RetentionPolicy{MaxRecords: uint32(maxRecords), MaxAge: maxAge}
+
+	if !ctx.Params().MustHas(ParamContractHname) {
+		ctx.State().Set(VarRetention, EncodeRetentionPolicy(p))
+		PruneLogs(ctx.State(), ctx.GetTimestamp())
+		return nil, nil
+	}
+	contract := params.MustGetHname(ParamContractHname)
+	policies := collections.NewMap(ctx.State(), VarContractRetention)
+	if !ctx.Params().MustHas(ParamMaxRecords) && !ctx.Params().MustHas(ParamMaxAge) {
+		policies.MustDelAt(contract.Bytes())
+	} else {
+		policies.MustSetAt(contract.Bytes(), EncodeRetentionPolicy(p))
+	}
+	pruneLog(ctx.State(), ctx.GetTimestamp(), contract)
+	pruneEvents(ctx.State(), ctx.GetTimestamp(), contract)
+	return nil, nil
+}
+
+// getRetention returns the retention policy of event records in effect for the contract
+// Parameters:
+//	- ParamContractHname Hname of the contract. If not present, the default policy is returned
+// Returns ParamMaxRecords, ParamMaxAge and ParamOwnPolicy = 0xFF if the contract has its own policy
+func getRetention(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	p := GetDefaultRetention(ctx.State())
+	own := false
+	if ctx.Params().MustHas(ParamContractHname) {
+		contract, err := params.GetHname(ParamContractHname)
+		if err != nil {
+			return nil, err
+		}
+		p, own = GetRetention(ctx.State(), contract)
+	}
+	ret := dict.New()
+	ret.Set(ParamMaxRecords, codec.EncodeInt64(int64(p.MaxRecords)))
+	ret.Set(ParamMaxAge, codec.EncodeInt64(p.MaxAge))
+	if own {
+		ret.Set(ParamOwnPolicy, []byte{0xFF})
+	}
+	return ret, nil
+}
+
+// getRecordsByRequest returns eventlog records emitted by the request, the latest first
+// Parameters:
+//	- ParamRequestID ID of the request
+//	- ParamMaxLastRecords Max amount of records that you want to return. Defaults to 50, capped by MaxNumberOfRecords
+// Returns array ParamRecords of encoded records. Records pruned according to the retention policy are not returned
+func getRecordsByRequest(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	reqID, err := params.GetRequestID(ParamRequestID)
+	if err != nil {
+		return nil, err
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := GetRecordsByRequest(ctx.State(), reqID, capMaxNumberOfRecords(maxLast))
+	if err != nil {
+		return nil, err
+	}
+	return encodeRecords(recs), nil
+}
+
+// getRecordsByBlock returns eventlog records emitted in the block, the latest first
+// Parameters:
+//	- ParamBlockIndex index of the block
+//	- ParamMaxLastRecords Max amount of records that you want to return. Defaults to 50, capped by MaxNumberOfRecords
+// Returns array ParamRecords of encoded records. Records pruned according to the retention policy are not returned
+func getRecordsByBlock(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	blockIndex, err := params.GetInt64(ParamBlockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if blockIndex < 0 || blockIndex > math.MaxUint32 {
+		return nil, fmt.Errorf("wrong block index %d", blockIndex)
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := GetRecordsByBlock(ctx.State(), uint32(blockIndex), capMaxNumberOfRecords(maxLast))
+	if err != nil {
+		return nil, err
+	}
+	return encodeRecords(recs), nil
+}
+
+// capMaxNumberOfRecords caps the requested number of records by MaxNumberOfRecords. Not positive value means the maximum
+func capMaxNumberOfRecords(maxLast int64) uint32 {
+	if maxLast <= 0 || maxLast > MaxNumberOfRecords {
+		return MaxNumberOfRecords
+	}
+	return uint32(maxLast)
+}
+
+func encodeRecords(recs []*Record) dict.Dict {
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamRecords)
+	for _, rec := range recs {
+		a.MustPush(EncodeRecord(rec))
+	}
+	return ret
+}
@@ -22,6 +22,11 @@ func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.ViewFunc(FuncGetRecords, getRecords),
 		coreutil.ViewFunc(FuncGetNumRecords, getNumRecords),
+		coreutil.ViewFunc(FuncGetEvents, getEvents),
+		coreutil.Func(FuncSetRetention, setRetention),
+		coreutil.ViewFunc(FuncGetRetention, getRetention),
+		coreutil.ViewFunc(FuncGetRecordsByRequest, getRecordsByRequest),
+		coreutil.ViewFunc(FuncGetRecordsByBlock, getRecordsByBlock),
 	})
 }
 
@@ -33,10 +38,49 @@ const (
 	ParamMaxLastRecords = "maxLastRecords"
 	ParamNumRecords     = "numRecords"
 	ParamRecords        = "records"
+	ParamEventName      = "eventName"
+	ParamTopic          = "topic"
+	ParamRequestID      = "requestID"
+	ParamFromBlock      = "fromBlock"
+	ParamToBlock        = "toBlock"
+	ParamEvents         = "events"
+	ParamEarliestTs     = "earliestTs"
+	ParamMaxRecords     = "maxRecords"
+	ParamMaxAge         = "maxAge"
+	ParamOwnPolicy      = "ownPolicy"
+	ParamBlockIndex     = "blockIndex"
 
 	// function names
-	FuncGetRecords    = "getRecords"
-	FuncGetNumRecords = "getNumRecords"
+	FuncGetRecords          = "getRecords"
+	FuncGetNumRecords       = "getNumRecords"
+	FuncGetEvents           = "getEvents"
+	FuncSetRetention        = "setRetention"
+	FuncGetRetention        = "getRetention"
+	FuncGetRecordsByRequest = "getRecordsByRequest"
+	FuncGetRecordsByBlock   = "getRecordsByBlock"
+
+	// state variables
+	VarEvents           = "e"
+	VarEventIndexPrefix = "i"
+	// default retention policy
+	VarRetention = "r"
+	// map of retention policies of contracts
+	VarContractRetention = "rc"
+	// set of contracts with records or typed events, pruned once per block
+	VarLoggedContracts = "lc"
+	// flag of the set containing contracts which logged before the set was introduced
+	VarLoggedContractsIndexed = "li"
+	// map of request IDs and block indices of indexed records
+	VarRecordLocations = "rl"
+	// index of the latest block which stored a record or an event
+	VarLatestBlockIndex = "lb"
 
 	DefaultMaxNumberOfRecords = 50
+	// MaxNumberOfRecords is the limit of records and events returned by the views which select them by index
+	MaxNumberOfRecords = 1000
+	// MaxBlockRange is the max number of blocks in the range selected by getEvents
+	MaxBlockRange = 1000
+	// MaxScannedEvents is the max number of index entries and events checked by getEvents,
+	// whether they are selected by the filter or not
+	MaxScannedEvents = 10000
 )
@@ -1,11 +1,190 @@
 package eventlog
 
 import (
+	"bytes"
+	"fmt"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// AppendToLog appends the record to the log of the contract, indexes it by the request ID and the block index
+// and prunes the log according to the retention policy of the contract
+func AppendToLog(state kv.KVStore, ts int64, contract coretypes.Hname, data []byte, reqID coretypes.RequestID, blockIndex uint32) {
+	theLog := collections.NewTimestampedLog(state, kv.Key(contract.Bytes()))
+	idx := theLog.MustLen()
+	theLog.MustAppend(ts, data)
+	addLoggedContract(state, contract)
+	indexRecord(state, ts, &recordRef{contract: contract, index: idx}, &recordLocation{requestID: reqID, blockIndex: blockIndex})
+	pruneLog(state, ts, contract)
+}
+
+// kinds of the event indices
+const (
+	indexByContract = byte(iota)
+	indexByName
+	indexByTopic
+	indexByRequest
+	indexByBlock
 )
 
-func AppendToLog(state kv.KVStore, ts int64, contract coretypes.Hname, data []byte) {
-	collections.NewTimestampedLog(state, kv.Key(contract.Bytes())).MustAppend(ts, data)
+// indexName is the name of the index log of events for the key. The name is the fixed size hash,
+// so it never collides with the logs of the contracts
+func indexName(kind byte, key []byte) kv.Key {
+	h := hashing.HashData([]byte{kind}, key)
+	return kv.Key(VarEventIndexPrefix + string(h[:]))
+}
+
+// AppendEvent stores the typed event in the log of all events and indexes it.
+// Each index is a timestamped log of indices in the log of all events.
+// Events of the contract are pruned according to its retention policy
+func AppendEvent(state kv.KVStore, e *Event) {
+	events := collections.NewTimestampedLog(state, VarEvents)
+	idx := util.Uint32To4Bytes(events.MustLen())
+	events.MustAppend(e.Timestamp, EncodeEvent(e))
+	addLoggedContract(state, e.Contract)
+
+	collections.NewTimestampedLog(state, indexName(indexByContract, e.Contract.Bytes())).MustAppend(e.Timestamp, idx)
+	for _, name := range eventIndexNames(e) {
+		collections.NewTimestampedLog(state, name).MustAppend(e.Timestamp, idx)
+	}
+	state.Set(VarLatestBlockIndex, util.Uint32To4Bytes(e.BlockIndex))
+	pruneEvents(state, e.Timestamp, e.Contract)
+}
+
+// eventIndexNames returns names of the indices of the event, except the index by contract
+func eventIndexNames(e *Event) []kv.Key {
+	ret := []kv.Key{indexName(indexByName, []byte(e.Name))}
+	for i, t := range e.Topics {
+		if containsTopic(e.Topics[:i], t) {
+			continue
+		}
+		ret = append(ret, indexName(indexByTopic, t))
+	}
+	loc := &recordLocation{requestID: e.RequestID, blockIndex: e.BlockIndex}
+	return append(ret, loc.indexNames()...)
+}
+
+func mustParseEventIndex(raw []byte) uint32 {
+	rec, err := collections.ParseRawLogRecord(raw)
+	if err != nil {
+		panic(err)
+	}
+	return util.MustUint32From4Bytes(rec.Data)
+}
+
+func mustParseEvent(raw []byte) *Event {
+	rec, err := collections.ParseRawLogRecord(raw)
+	if err != nil {
+		panic(err)
+	}
+	e, err := DecodeEvent(rec.Data)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func containsTopic(topics [][]byte, t []byte) bool {
+	for _, t1 := range topics {
+		if bytes.Equal(t1, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEvents returns the events selected by the filter, the latest first.
+// The most selective index is used to find the candidates, then all conditions are checked on each candidate.
+// The block range must end not later than the latest block and must not be longer than MaxBlockRange.
+// At most MaxScannedEvents index entries and candidates are checked, so fewer events may be returned
+// if the filter selects only few of them
+func GetEvents(state kv.KVStoreReader, f *EventFilter) ([]*Event, error) {
+	latest := latestBlockIndex(state)
+	if f.FromBlock > latest || f.ToBlock > latest {
+		return nil, fmt.Errorf("block range is beyond the latest block #%d", latest)
+	}
+	if f.ToBlock != 0 && (f.FromBlock > f.ToBlock || f.ToBlock-f.FromBlock >= MaxBlockRange) {
+		return nil, fmt.Errorf("wrong block range [%d, %d]. Max %d blocks", f.FromBlock, f.ToBlock, MaxBlockRange)
+	}
+	events := collections.NewTimestampedLogReadOnly(state, VarEvents)
+	ret := make([]*Event, 0)
+	maxRecords := capMaxNumberOfRecords(int64(f.MaxRecords))
+	scanned := 0
+
+	// collect checks the event at the index in the log of all events. Returns false if enough events are collected
+	// or too many candidates are checked
+	collect := func(eventIdx uint32) (bool, error) {
+		raw := events.MustLoadRecordsRaw(eventIdx, eventIdx, false)[0]
+		if raw == nil {
+			// pruned
+			return true, nil
+		}
+		rec, err := collections.ParseRawLogRecord(raw)
+		if err != nil {
+			return false, err
+		}
+		e, err := DecodeEvent(rec.Data)
+		if err != nil {
+			return false, err
+		}
+		if f.accepts(e) {
+			ret = append(ret, e)
+		}
+		return uint32(len(ret)) < maxRecords, nil
+	}
+	collectFromIndex := func(name kv.Key) (bool, error) {
+		index := collections.NewTimestampedLogReadOnly(state, name)
+		for i := index.MustLen(); i > index.MustFirstIndex(); i-- {
+			if scanned++; scanned > MaxScannedEvents {
+				return false, nil
+			}
+			rec, err := collections.ParseRawLogRecord(index.MustLoadRecordsRaw(i-1, i-1, false)[0])
+			if err != nil {
+				return false, err
+			}
+			if len(rec.Data) != 4 {
+				// eventlog record
+				continue
+			}
+			if more, err := collect(util.MustUint32From4Bytes(rec.Data)); err != nil || !more {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	var err error
+	switch {
+	case f.RequestID != nil:
+		_, err = collectFromIndex(indexName(indexByRequest, f.RequestID[:]))
+	case f.Topic != nil:
+		_, err = collectFromIndex(indexName(indexByTopic, f.Topic))
+	case f.Name != "":
+		_, err = collectFromIndex(indexName(indexByName, []byte(f.Name)))
+	case f.Contract != 0:
+		_, err = collectFromIndex(indexName(indexByContract, f.Contract.Bytes()))
+	case f.ToBlock != 0:
+		for b := f.ToBlock; b >= f.FromBlock; b-- {
+			var more bool
+			if more, err = collectFromIndex(indexName(indexByBlock, util.Uint32To4Bytes(b))); err != nil || !more || b == 0 {
+				break
+			}
+		}
+	default:
+		for i := events.MustLen(); i > events.MustFirstIndex() && scanned < MaxScannedEvents; i-- {
+			scanned++
+			var more bool
+			if more, err = collect(i - 1); err != nil || !more {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
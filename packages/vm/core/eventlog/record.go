@@ -0,0 +1,226 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Record is the eventlog record of the contract together with the request and the block which emitted it
+type Record struct {
+	Contract   coretypes.Hname
+	RequestID  coretypes.RequestID
+	BlockIndex uint32
+	Timestamp  int64
+	Data       []byte
+}
+
+func (rec *Record) String() string {
+	return fmt.Sprintf("block #%d req %s contract %s: %s", rec.BlockIndex, rec.RequestID.Short(), rec.Contract.String(), string(rec.Data))
+}
+
+// serde
+func (rec *Record) Write(w io.Writer) error {
+	if err := rec.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := rec.RequestID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, rec.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, rec.Timestamp); err != nil {
+		return err
+	}
+	return util.WriteBytes32(w, rec.Data)
+}
+
+func (rec *Record) Read(r io.Reader) error {
+	var err error
+	if err = rec.Contract.Read(r); err != nil {
+		return err
+	}
+	if err = rec.RequestID.Read(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &rec.BlockIndex); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &rec.Timestamp); err != nil {
+		return err
+	}
+	rec.Data, err = util.ReadBytes32(r)
+	return err
+}
+
+func EncodeRecord(rec *Record) []byte {
+	return util.MustBytes(rec)
+}
+
+func DecodeRecord(data []byte) (*Record, error) {
+	ret := new(Record)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// recordRef is the entry of the indices by request and by block which points to the record in the log of the contract.
+// Entries of typed events in the same indices are 4 bytes long, the index of the event in the log of all events
+type recordRef struct {
+	contract coretypes.Hname
+	index    uint32
+}
+
+const recordRefSize = 8
+
+func (ref *recordRef) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(ref.contract.Bytes())
+	buf.Write(util.Uint32To4Bytes(ref.index))
+	return buf.Bytes()
+}
+
+// recordRefFromBytes returns false if the index entry is not a reference to the record
+func recordRefFromBytes(data []byte) (*recordRef, bool) {
+	if len(data) != recordRefSize {
+		return nil, false
+	}
+	ret := &recordRef{}
+	if err := ret.contract.Read(bytes.NewReader(data[:4])); err != nil {
+		return nil, false
+	}
+	ret.index = util.MustUint32From4Bytes(data[4:])
+	return ret, true
+}
+
+// recordLocation is the request and the block of the record, stored by the reference to the record
+// until the record is pruned
+type recordLocation struct {
+	requestID  coretypes.RequestID
+	blockIndex uint32
+}
+
+func (loc *recordLocation) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(loc.requestID[:])
+	buf.Write(util.Uint32To4Bytes(loc.blockIndex))
+	return buf.Bytes()
+}
+
+func recordLocationFromBytes(data []byte) (*recordLocation, error) {
+	ret := &recordLocation{}
+	r := bytes.NewReader(data)
+	if err := ret.requestID.Read(r); err != nil {
+		return nil, err
+	}
+	if err := util.ReadUint32(r, &ret.blockIndex); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// indexNames returns the names of the indices of the records and the events of the request and the block
+func (loc *recordLocation) indexNames() []kv.Key {
+	return []kv.Key{
+		indexName(indexByRequest, loc.requestID[:]),
+		indexName(indexByBlock, util.Uint32To4Bytes(loc.blockIndex)),
+	}
+}
+
+// indexRecord indexes the record of the contract by the request ID and the block index,
+// in the same indices as typed events
+func indexRecord(state kv.KVStore, ts int64, ref *recordRef, loc *recordLocation) {
+	data := ref.bytes()
+	for _, name := range loc.indexNames() {
+		collections.NewTimestampedLog(state, name).MustAppend(ts, data)
+	}
+	collections.NewMap(state, VarRecordLocations).MustSetAt(data, loc.bytes())
+	state.Set(VarLatestBlockIndex, util.Uint32To4Bytes(loc.blockIndex))
+}
+
+// latestBlockIndex returns the index of the latest block which stored a record or an event.
+// Each request stores its record, so it is the latest block of the chain
+func latestBlockIndex(state kv.KVStoreReader) uint32 {
+	data := state.MustGet(VarLatestBlockIndex)
+	if data == nil {
+		return 0
+	}
+	return util.MustUint32From4Bytes(data)
+}
+
+// unindexRecords deletes locations of the records of the contract with indices in [fromIdx, toIdx)
+// and returns names of the indices which contain the records
+func unindexRecords(state kv.KVStore, contract coretypes.Hname, fromIdx, toIdx uint32) []kv.Key {
+	locations := collections.NewMap(state, VarRecordLocations)
+	ret := make([]kv.Key, 0)
+	for i := fromIdx; i < toIdx; i++ {
+		key := (&recordRef{contract: contract, index: i}).bytes()
+		data := locations.MustGetAt(key)
+		if data == nil {
+			// stored before the records were indexed
+			continue
+		}
+		loc, err := recordLocationFromBytes(data)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, loc.indexNames()...)
+		locations.MustDelAt(key)
+	}
+	return ret
+}
+
+// GetRecordsByRequest returns up to maxRecords eventlog records emitted by the request, the latest first.
+// Records pruned according to the retention policy are skipped
+func GetRecordsByRequest(state kv.KVStoreReader, reqID coretypes.RequestID, maxRecords uint32) ([]*Record, error) {
+	return getIndexedRecords(state, indexName(indexByRequest, reqID[:]), maxRecords)
+}
+
+// GetRecordsByBlock returns up to maxRecords eventlog records emitted in the block, the latest first.
+// Records pruned according to the retention policy are skipped
+func GetRecordsByBlock(state kv.KVStoreReader, blockIndex uint32, maxRecords uint32) ([]*Record, error) {
+	return getIndexedRecords(state, indexName(indexByBlock, util.Uint32To4Bytes(blockIndex)), maxRecords)
+}
+
+func getIndexedRecords(state kv.KVStoreReader, name kv.Key, maxRecords uint32) ([]*Record, error) {
+	ret := make([]*Record, 0)
+	index := collections.NewTimestampedLogReadOnly(state, name)
+	locations := collections.NewMapReadOnly(state, VarRecordLocations)
+	for i := index.MustLen(); i > index.MustFirstIndex() && uint32(len(ret)) < maxRecords; i-- {
+		entry, err := collections.ParseRawLogRecord(index.MustLoadRecordsRaw(i-1, i-1, false)[0])
+		if err != nil {
+			return nil, err
+		}
+		ref, ok := recordRefFromBytes(entry.Data)
+		if !ok {
+			// typed event
+			continue
+		}
+		theLog := collections.NewTimestampedLogReadOnly(state, kv.Key(ref.contract.Bytes()))
+		if ref.index < theLog.MustFirstIndex() {
+			// pruned
+			continue
+		}
+		loc, err := recordLocationFromBytes(locations.MustGetAt(entry.Data))
+		if err != nil {
+			return nil, err
+		}
+		rec, err := collections.ParseRawLogRecord(theLog.MustLoadRecordsRaw(ref.index, ref.index, false)[0])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Record{
+			Contract:   ref.contract,
+			RequestID:  loc.requestID,
+			BlockIndex: loc.blockIndex,
+			Timestamp:  rec.Timestamp,
+			Data:       rec.Data,
+		})
+	}
+	return ret, nil
+}
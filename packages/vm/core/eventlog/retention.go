@@ -0,0 +1,249 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// RetentionPolicy limits the event records and typed events of the contract kept in the state.
+// Older records are pruned once per block. Zero value of the field means no limit
+type RetentionPolicy struct {
+	MaxRecords uint32
+	// max age of the record in seconds
+	MaxAge int64
+}
+
+func (p *RetentionPolicy) String() string {
+	return fmt.Sprintf("max records: %d, max age: %ds", p.MaxRecords, p.MaxAge)
+}
+
+// serde
+func (p *RetentionPolicy) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, p.MaxRecords); err != nil {
+		return err
+	}
+	return util.WriteInt64(w, p.MaxAge)
+}
+
+func (p *RetentionPolicy) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &p.MaxRecords); err != nil {
+		return err
+	}
+	return util.ReadInt64(r, &p.MaxAge)
+}
+
+func EncodeRetentionPolicy(p *RetentionPolicy) []byte {
+	return util.MustBytes(p)
+}
+
+func DecodeRetentionPolicy(data []byte) (*RetentionPolicy, error) {
+	ret := new(RetentionPolicy)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+func mustDecodeRetentionPolicy(data []byte) *RetentionPolicy {
+	if data == nil {
+		return &RetentionPolicy{}
+	}
+	ret, err := DecodeRetentionPolicy(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// GetDefaultRetention returns the retention policy of contracts without their own policy
+func GetDefaultRetention(state kv.KVStoreReader) *RetentionPolicy {
+	return mustDecodeRetentionPolicy(state.MustGet(VarRetention))
+}
+
+// GetRetention returns the retention policy in effect for the contract and
+// whether it is the own policy of the contract
+func GetRetention(state kv.KVStoreReader, contract coretypes.Hname) (*RetentionPolicy, bool) {
+	data := collections.NewMapReadOnly(state, VarContractRetention).MustGetAt(contract.Bytes())
+	if data == nil {
+		return GetDefaultRetention(state), false
+	}
+	return mustDecodeRetentionPolicy(data), true
+}
+
+// retainedFrom returns the index of the earliest record of the log which is retained by the policy at the moment ts
+func retainedFrom(theLog *collections.TimestampedLog, p *RetentionPolicy, ts int64) uint32 {
+	n := theLog.MustLen()
+	toIdx := theLog.MustFirstIndex()
+	if p.MaxRecords > 0 && n-toIdx > p.MaxRecords {
+		toIdx = n - p.MaxRecords
+	}
+	if cutoff := ts - p.MaxAge*int64(time.Second); p.MaxAge > 0 && cutoff > 0 && toIdx < n {
+		tts := theLog.MustTakeTimeSlice(cutoff, theLog.MustLatest())
+		switch {
+		case tts.IsEmpty():
+			// all records are older
+			toIdx = n
+		default:
+			if first, _ := tts.FromToIndices(); first > toIdx {
+				toIdx = first
+			}
+		}
+	}
+	return toIdx
+}
+
+// pruneLog deletes the earliest records of the contract which exceed its retention policy at the moment ts
+func pruneLog(state kv.KVStore, ts int64, contract coretypes.Hname) {
+	p, _ := GetRetention(state, contract)
+	if p.MaxRecords == 0 && p.MaxAge == 0 {
+		return
+	}
+	theLog := collections.NewTimestampedLog(state, kv.Key(contract.Bytes()))
+	toIdx := retainedFrom(theLog, p, ts)
+	first := theLog.MustFirstIndex()
+	if toIdx <= first {
+		return
+	}
+	touched := unindexRecords(state, contract, first, toIdx)
+	theLog.MustPrune(toIdx)
+	pruneIndices(state, touched)
+}
+
+// pruneEvents deletes the earliest typed events of the contract which exceed its retention policy at the moment ts.
+// The events are deleted from the log of all events, then the leading entries of indices which point to
+// deleted events are pruned
+func pruneEvents(state kv.KVStore, ts int64, contract coretypes.Hname) {
+	p, _ := GetRetention(state, contract)
+	if p.MaxRecords == 0 && p.MaxAge == 0 {
+		return
+	}
+	byContract := collections.NewTimestampedLog(state, indexName(indexByContract, contract.Bytes()))
+	first := byContract.MustFirstIndex()
+	toIdx := retainedFrom(byContract, p, ts)
+	if toIdx <= first {
+		return
+	}
+	events := collections.NewTimestampedLog(state, VarEvents)
+	touched := make([]kv.Key, 0)
+	for _, raw := range byContract.MustLoadRecordsRaw(first, toIdx-1, false) {
+		eventIdx := mustParseEventIndex(raw)
+		data := events.MustLoadRecordsRaw(eventIdx, eventIdx, false)[0]
+		if data == nil {
+			continue
+		}
+		touched = append(touched, eventIndexNames(mustParseEvent(data))...)
+		events.MustDelAt(eventIdx)
+	}
+	byContract.MustPrune(toIdx)
+
+	pruneIndices(state, touched)
+
+	// the log of all events is pruned up to the earliest retained event
+	n := events.MustLen()
+	idx := events.MustFirstIndex()
+	for idx < n && events.MustLoadRecordsRaw(idx, idx, false)[0] == nil {
+		idx++
+	}
+	events.MustPrune(idx)
+}
+
+// pruneIndices prunes the leading entries of the indices which point to deleted events or pruned records
+func pruneIndices(state kv.KVStore, names []kv.Key) {
+	unique := make(map[kv.Key]struct{})
+	for _, name := range names {
+		unique[name] = struct{}{}
+	}
+	sorted := make([]kv.Key, 0, len(unique))
+	for name := range unique {
+		sorted = append(sorted, name)
+	}
+	// deterministic order of the mutations
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, name := range sorted {
+		index := collections.NewTimestampedLog(state, name)
+		n := index.MustLen()
+		idx := index.MustFirstIndex()
+		for idx < n && !isRetained(state, index.MustLoadRecordsRaw(idx, idx, false)[0]) {
+			idx++
+		}
+		index.MustPrune(idx)
+	}
+}
+
+// isRetained checks if the event or the record which the index entry points to is not pruned
+func isRetained(state kv.KVStoreReader, entry []byte) bool {
+	rec, err := collections.ParseRawLogRecord(entry)
+	if err != nil {
+		panic(err)
+	}
+	if ref, ok := recordRefFromBytes(rec.Data); ok {
+		return ref.index >= collections.NewTimestampedLogReadOnly(state, kv.Key(ref.contract.Bytes())).MustFirstIndex()
+	}
+	eventIdx := util.MustUint32From4Bytes(rec.Data)
+	return collections.NewTimestampedLogReadOnly(state, VarEvents).MustLoadRecordsRaw(eventIdx, eventIdx, false)[0] != nil
+}
+
+// PruneLogs deletes eventlog records and typed events of all contracts which exceed their retention policies
+// at the moment ts. It is called by the VM once per block, so logs of the contracts which don't emit new records
+// are pruned as well
+func PruneLogs(state kv.KVStore, ts int64) {
+	if state.MustGet(VarLoggedContractsIndexed) == nil {
+		indexLoggedContracts(state)
+	}
+	contracts := make([]coretypes.Hname, 0)
+	collections.NewMapReadOnly(state, VarLoggedContracts).MustIterateKeys(func(key []byte) bool {
+		contract, err := coretypes.NewHnameFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		contracts = append(contracts, contract)
+		return true
+	})
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i] < contracts[j] })
+	for _, contract := range contracts {
+		pruneLog(state, ts, contract)
+		pruneEvents(state, ts, contract)
+	}
+}
+
+func addLoggedContract(state kv.KVStore, contract coretypes.Hname) {
+	collections.NewMap(state, VarLoggedContracts).MustSetAt(contract.Bytes(), []byte{0xFF})
+}
+
+// indexLoggedContracts adds the contracts with records and events stored before the contracts were
+// tracked in VarLoggedContracts. The log of the contract is the only one named by the 4 bytes of the hname,
+// so its keys are the only keys of the partition 5 bytes long
+func indexLoggedContracts(state kv.KVStore) {
+	found := make(map[coretypes.Hname]struct{})
+	state.MustIterateKeys("", func(key kv.Key) bool {
+		if len(key) == 5 {
+			if contract, err := coretypes.NewHnameFromBytes([]byte(key[:4])); err == nil {
+				found[contract] = struct{}{}
+			}
+		}
+		return true
+	})
+	events := collections.NewTimestampedLogReadOnly(state, VarEvents)
+	n := events.MustLen()
+	for i := events.MustFirstIndex(); i < n; i++ {
+		if data := events.MustLoadRecordsRaw(i, i, false)[0]; data != nil {
+			found[mustParseEvent(data).Contract] = struct{}{}
+		}
+	}
+	// deterministic order of the mutations
+	contracts := make([]coretypes.Hname, 0, len(found))
+	for contract := range found {
+		contracts = append(contracts, contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i] < contracts[j] })
+	for _, contract := range contracts {
+		addLoggedContract(state, contract)
+	}
+	state.Set(VarLoggedContractsIndexed, []byte{0xFF})
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
@@ -27,6 +29,12 @@ func GetProcessor(programHash hashing.HashValue) (coretypes.Processor, error) {
 
 	case eventlog.Interface.ProgramHash:
 		return eventlog.Interface, nil
+
+	case blocklog.Interface.ProgramHash:
+		return blocklog.Interface, nil
+
+	case governance.Interface.ProgramHash:
+		return governance.Interface, nil
 	}
 	return nil, fmt.Errorf("can't find builtin processor with hash %s", programHash.String())
 }
@@ -0,0 +1,340 @@
+// 'governance' is a core contract on the chain. It is responsible for on-chain voting
+// on changes of chain parameters. The proposal is a call to the entry point of the 'root' contract
+// or to the entry point of the 'governance' which changes the voting configuration.
+// When the proposal passes the vote, the 'governance' makes the call itself. To be authorized
+// by the 'root', the chain ownership must be delegated to the 'governance' contract
+package governance
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	assert2 "github.com/iotaledger/wasp/packages/coretypes/assert"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+)
+
+// initialize is mandatory
+func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("governance.initialize.success hname = %s", Interface.Hname().String())
+	return nil, nil
+}
+
+// setVotingConfig sets the voting configuration. Only the chain owner or the passed proposal can call it.
+// The configuration applies to proposals created after the change
+// Input:
+// - ParamVotingPower string VotingPowerBalance or VotingPowerCommittee
+// - ParamVotingColor balance.Color color of the voting tokens. Default is IOTA color
+// - ParamQuorum int64 0..100. Default is DefaultQuorum
+// - ParamThreshold int64 0..99. Default is DefaultThreshold
+// - ParamVotingPeriod int64 seconds. Default is DefaultVotingPeriod
+func setVotingConfig(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(isAuthorized(ctx), "governance.setVotingConfig: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	cfg := &VotingConfig{
+		VotingPower:  params.MustGetString(ParamVotingPower),
+		VotingColor:  params.MustGetColor(ParamVotingColor, balance.ColorIOTA),
+		Quorum:       params.MustGetInt64(ParamQuorum, DefaultQuorum),
+		Threshold:    params.MustGetInt64(ParamThreshold, DefaultThreshold),
+		VotingPeriod: params.MustGetInt64(ParamVotingPeriod, DefaultVotingPeriod),
+	}
+	a.Require(cfg.VotingPower == VotingPowerBalance || cfg.VotingPower == VotingPowerCommittee,
+		"governance.setVotingConfig: wrong voting power '%s'", cfg.VotingPower)
+	a.Require(cfg.Quorum >= 0 && cfg.Quorum <= 100, "governance.setVotingConfig: wrong quorum %d", cfg.Quorum)
+	a.Require(cfg.Threshold >= 0 && cfg.Threshold < 100, "governance.setVotingConfig: wrong threshold %d", cfg.Threshold)
+	a.Require(cfg.VotingPeriod > 0 && cfg.VotingPeriod <= MaxVotingPeriod,
+		"governance.setVotingConfig: wrong voting period %d", cfg.VotingPeriod)
+
+	setVotingConfigIntern(ctx.State(), cfg)
+	ctx.Event(fmt.Sprintf("[governance] voting config: %s, color: %s, quorum: %d%%, threshold: %d%%, period: %ds",
+		cfg.VotingPower, cfg.VotingColor.String(), cfg.Quorum, cfg.Threshold, cfg.VotingPeriod))
+	return nil, nil
+}
+
+// addCommitteeMember adds the agent to the voting committee. Only the chain owner or the passed proposal can call it
+// Input:
+// - ParamAgentID coretypes.AgentID
+func addCommitteeMember(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(isAuthorized(ctx), "governance.addCommitteeMember: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	member := params.MustGetAgentID(ParamAgentID)
+	collections.NewMap(ctx.State(), VarCommittee).MustSetAt(member[:], []byte{0xFF})
+	ctx.Event(fmt.Sprintf("[governance] committee member added: %s", member))
+	return nil, nil
+}
+
+// removeCommitteeMember removes the agent from the voting committee. Only the chain owner or the passed proposal can call it
+// Input:
+// - ParamAgentID coretypes.AgentID
+func removeCommitteeMember(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(isAuthorized(ctx), "governance.removeCommitteeMember: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	member := params.MustGetAgentID(ParamAgentID)
+	committee := collections.NewMap(ctx.State(), VarCommittee)
+	a.Require(committee.MustHasAt(member[:]), "governance.removeCommitteeMember: %s is not a committee member", member)
+	committee.MustDelAt(member[:])
+	ctx.Event(fmt.Sprintf("[governance] committee member removed: %s", member))
+	return nil, nil
+}
+
+// createProposal creates the proposal to call the entry point of the 'root' or the 'governance' contract.
+// The proposer must have voting power and less than MaxOpenProposals pending proposals.
+// All parameters other than listed below are passed to the call
+// Input:
+// - ParamContract coretypes.Hname the 'root' or the 'governance'. Default is the 'root'
+// - ParamTarget coretypes.Hname entry point of the contract
+// - ParamDescription string. Optional
+// Output:
+// - ParamProposalID int64 ID of the proposal
+func createProposal(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	state := ctx.State()
+	cfg := GetVotingConfig(state)
+	a.Require(cfg.VotingPower != "", "governance.createProposal: voting is not configured")
+
+	power, err := getVotingPower(ctx, cfg, ctx.Caller())
+	a.RequireNoError(err)
+	a.Require(power > 0, "governance.createProposal: %s has no voting power", ctx.Caller())
+	a.Require(getNumOpenProposals(state, ctx.Caller()) < MaxOpenProposals,
+		"governance.createProposal: %s has %d pending proposals", ctx.Caller(), MaxOpenProposals)
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	contract := params.MustGetHname(ParamContract, coretypes.Hn(coreutil.CoreContractRoot))
+	target := params.MustGetHname(ParamTarget)
+	a.Require(isProposalTarget(contract, target), "governance.createProposal: wrong target entry point %s::%s", contract, target)
+	description := params.MustGetString(ParamDescription, "")
+
+	callParams := ctx.Params().Clone()
+	callParams.Del(ParamContract)
+	callParams.Del(ParamTarget)
+	callParams.Del(ParamDescription)
+
+	stateDecoder := kvdecoder.New(state, ctx.Log())
+	id := stateDecoder.MustGetInt64(VarProposalCounter, 0)
+	state.Set(VarProposalCounter, codec.EncodeInt64(id+1))
+	p := &Proposal{
+		ID:          uint32(id),
+		Proposer:    ctx.Caller(),
+		Description: description,
+		Contract:    contract,
+		Target:      target,
+		Params:      callParams,
+		Config:      *cfg,
+		Deadline:    ctx.GetTimestamp() + cfg.VotingPeriod*int64(time.Second),
+		Status:      ProposalPending,
+	}
+	storeProposal(state, p)
+	addOpenProposals(state, p.Proposer, 1)
+	emitProposalEvent(ctx, p)
+
+	ret := dict.New()
+	ret.Set(ParamProposalID, codec.EncodeInt64(id))
+	return ret, nil
+}
+
+// vote casts the vote of the caller on the pending proposal. Each agent can vote once.
+// In VotingPowerBalance mode the weight of the vote is the amount of tokens of the voting color transferred
+// with the vote. The tokens are locked until the voting is over, then they can be reclaimed with reclaimVotingTokens.
+// Other tokens transferred with the vote are returned to the on-chain account of the caller.
+// The proposal is executed immediately when the remaining votes can't change the outcome
+// Input:
+// - ParamProposalID int64
+// - ParamVote string VoteYes or VoteNo
+func vote(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	state := ctx.State()
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id := params.MustGetInt64(ParamProposalID)
+	v := params.MustGetString(ParamVote)
+	a.Require(v == VoteYes || v == VoteNo, "governance.vote: wrong vote '%s'", v)
+
+	p, ok := GetProposal(state, uint32(id))
+	a.Require(ok, "governance.vote: proposal #%d not found", id)
+	a.Require(p.Status == ProposalPending, "governance.vote: proposal #%d is %s", id, p.Status)
+	a.Require(ctx.GetTimestamp() <= p.Deadline, "governance.vote: voting on proposal #%d is over", id)
+
+	caller := ctx.Caller()
+	votes := getVotesMap(state, p.ID)
+	a.Require(!votes.MustHasAt(caller[:]), "governance.vote: %s already voted on proposal #%d", caller, id)
+
+	var power int64
+	refund := make(map[balance.Color]int64)
+	if ctx.IncomingTransfer() != nil {
+		ctx.IncomingTransfer().AddToMap(refund)
+	}
+	if p.Config.VotingPower == VotingPowerBalance {
+		// the tokens stay in the account of the 'governance' until reclaimed
+		power = refund[p.Config.VotingColor]
+		delete(refund, p.Config.VotingColor)
+		if power > 0 {
+			getVoteDepositsMap(state, p.ID).MustSetAt(caller[:], codec.EncodeInt64(power))
+		}
+	} else {
+		var err error
+		power, err = getVotingPower(ctx, &p.Config, caller)
+		a.RequireNoError(err)
+	}
+	a.Require(power > 0, "governance.vote: %s has no voting power", caller)
+	a.RequireNoError(accounts.Accrue(ctx, caller, cbalances.NewFromMap(refund)))
+
+	votes.MustSetAt(caller[:], codec.EncodeString(v))
+	if v == VoteYes {
+		p.VotesYes += power
+	} else {
+		p.VotesNo += power
+	}
+	p.NumVoters++
+	ctx.Event(fmt.Sprintf("[governance] vote '%s' on proposal #%d by %s with power %d", v, id, caller, power))
+
+	total, err := getTotalVotingPower(ctx, &p.Config)
+	a.RequireNoError(err)
+	if isDecided(p, total) {
+		executeProposal(ctx, p)
+		return nil, nil
+	}
+	storeProposal(state, p)
+	return nil, nil
+}
+
+// finalizeProposal counts the votes of the pending proposal after the voting period is over.
+// If the proposal passes, the call is made. Anyone can call it
+// Input:
+// - ParamProposalID int64
+func finalizeProposal(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id := params.MustGetInt64(ParamProposalID)
+
+	p, ok := GetProposal(ctx.State(), uint32(id))
+	a.Require(ok, "governance.finalizeProposal: proposal #%d not found", id)
+	a.Require(p.Status == ProposalPending, "governance.finalizeProposal: proposal #%d is %s", id, p.Status)
+	a.Require(ctx.GetTimestamp() > p.Deadline, "governance.finalizeProposal: voting on proposal #%d is not over", id)
+
+	total, err := getTotalVotingPower(ctx, &p.Config)
+	a.RequireNoError(err)
+	if isPassed(p, total) {
+		executeProposal(ctx, p)
+		return nil, nil
+	}
+	p.Status = ProposalRejected
+	closeProposal(ctx, p)
+	return nil, nil
+}
+
+// reclaimVotingTokens returns the tokens locked by the vote of the caller to its on-chain account.
+// The tokens can be reclaimed when the proposal is not pending anymore or its voting period is over.
+// Tokens which are not reclaimed are returned when the proposal is pruned
+// Input:
+// - ParamProposalID int64
+func reclaimVotingTokens(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id := params.MustGetInt64(ParamProposalID)
+
+	p, ok := GetProposal(ctx.State(), uint32(id))
+	a.Require(ok, "governance.reclaimVotingTokens: proposal #%d not found", id)
+	a.Require(p.Status != ProposalPending || ctx.GetTimestamp() > p.Deadline,
+		"governance.reclaimVotingTokens: voting on proposal #%d is not over", id)
+
+	caller := ctx.Caller()
+	deposits := getVoteDepositsMap(ctx.State(), p.ID)
+	amount, _, err := codec.DecodeInt64(deposits.MustGetAt(caller[:]))
+	a.RequireNoError(err)
+	a.Require(amount > 0, "governance.reclaimVotingTokens: %s has no tokens locked by proposal #%d", caller, id)
+	deposits.MustDelAt(caller[:])
+
+	tokens := cbalances.NewFromMap(map[balance.Color]int64{p.Config.VotingColor: amount})
+	a.RequireNoError(accounts.Accrue(ctx, caller, tokens))
+	ctx.Event(fmt.Sprintf("[governance] %d voting tokens of proposal #%d reclaimed by %s", amount, id, caller))
+	return nil, nil
+}
+
+// getVotingConfig returns the voting configuration and the voting committee
+// Output:
+// - ParamVotingPower string. Empty if voting is not configured
+// - ParamVotingColor balance.Color
+// - ParamQuorum int64
+// - ParamThreshold int64
+// - ParamVotingPeriod int64
+// - ParamCommittee array of coretypes.AgentID
+func getVotingConfig(ctx coretypes.SandboxView) (dict.Dict, error) {
+	cfg := GetVotingConfig(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamVotingPower, codec.EncodeString(cfg.VotingPower))
+	ret.Set(ParamVotingColor, codec.EncodeColor(cfg.VotingColor))
+	ret.Set(ParamQuorum, codec.EncodeInt64(cfg.Quorum))
+	ret.Set(ParamThreshold, codec.EncodeInt64(cfg.Threshold))
+	ret.Set(ParamVotingPeriod, codec.EncodeInt64(cfg.VotingPeriod))
+	arr := collections.NewArray(ret, ParamCommittee)
+	for _, m := range GetCommittee(ctx.State()) {
+		arr.MustPush(codec.EncodeAgentID(m))
+	}
+	return ret, nil
+}
+
+// getProposal returns the proposal
+// Input:
+// - ParamProposalID int64
+// Output:
+// - ParamProposal encoded Proposal
+func getProposal(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id, err := params.GetInt64(ParamProposalID)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := GetProposal(ctx.State(), uint32(id))
+	if !ok {
+		return nil, fmt.Errorf("governance.getProposal: proposal #%d not found", id)
+	}
+	ret := dict.New()
+	ret.Set(ParamProposal, EncodeProposal(p))
+	return ret, nil
+}
+
+// getProposals returns the proposals with IDs in the range [from, from + maxRecords).
+// Finished proposals pruned from the state are skipped
+// Input:
+// - ParamFrom int64 ID of the first proposal. Default is 0
+// - ParamMaxRecords int64 length of the range. Default is DefaultMaxNumberOfProposals, capped by MaxNumberOfProposals
+// Output:
+// - ParamProposals array of encoded Proposal, ordered by ID
+// - ParamNumProposals int64 number of proposals created on the chain
+func getProposals(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	from, err := params.GetInt64(ParamFrom, 0)
+	if err != nil {
+		return nil, err
+	}
+	maxRecords, err := params.GetInt64(ParamMaxRecords, DefaultMaxNumberOfProposals)
+	if err != nil {
+		return nil, err
+	}
+	if from < 0 || from > math.MaxUint32 {
+		return nil, fmt.Errorf("governance.getProposals: wrong proposal ID %d", from)
+	}
+	if maxRecords <= 0 || maxRecords > MaxNumberOfProposals {
+		maxRecords = MaxNumberOfProposals
+	}
+	ret := dict.New()
+	arr := collections.NewArray(ret, ParamProposals)
+	for _, p := range GetProposals(ctx.State(), uint32(from), uint32(maxRecords)) {
+		arr.MustPush(EncodeProposal(p))
+	}
+	ret.Set(ParamNumProposals, codec.EncodeInt64(int64(GetNumProposals(ctx.State()))))
+	return ret, nil
+}
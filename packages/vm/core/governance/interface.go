@@ -0,0 +1,320 @@
+package governance
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	Name        = "governance"
+	description = "Governance Contract"
+)
+
+var (
+	Interface = &coreutil.ContractInterface{
+		Name:        Name,
+		Description: description,
+		ProgramHash: hashing.HashStrings(Name),
+	}
+)
+
+func init() {
+	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
+		coreutil.Func(FuncSetVotingConfig, setVotingConfig),
+		coreutil.Func(FuncAddCommitteeMember, addCommitteeMember),
+		coreutil.Func(FuncRemoveCommitteeMember, removeCommitteeMember),
+		coreutil.Func(FuncCreateProposal, createProposal),
+		coreutil.Func(FuncVote, vote),
+		coreutil.Func(FuncFinalizeProposal, finalizeProposal),
+		coreutil.Func(FuncReclaimVotingTokens, reclaimVotingTokens),
+		coreutil.ViewFunc(FuncGetVotingConfig, getVotingConfig),
+		coreutil.ViewFunc(FuncGetProposal, getProposal),
+		coreutil.ViewFunc(FuncGetProposals, getProposals),
+	})
+}
+
+const (
+	// request parameters
+	ParamVotingPower  = "votingPower"
+	ParamVotingColor  = "votingColor"
+	ParamQuorum       = "quorum"
+	ParamThreshold    = "threshold"
+	ParamVotingPeriod = "votingPeriod"
+	ParamAgentID      = "agentID"
+	ParamContract     = "contract"
+	ParamTarget       = "target"
+	ParamDescription  = "description"
+	ParamProposalID   = "proposalID"
+	ParamVote         = "vote"
+	ParamProposal     = "proposal"
+	ParamProposals    = "proposals"
+	ParamCommittee    = "committee"
+	ParamFrom         = "from"
+	ParamMaxRecords   = "maxRecords"
+	ParamNumProposals = "numProposals"
+
+	// function names
+	FuncSetVotingConfig       = "setVotingConfig"
+	FuncAddCommitteeMember    = "addCommitteeMember"
+	FuncRemoveCommitteeMember = "removeCommitteeMember"
+	FuncCreateProposal        = "createProposal"
+	FuncVote                  = "vote"
+	FuncFinalizeProposal      = "finalizeProposal"
+	FuncReclaimVotingTokens   = "reclaimVotingTokens"
+	FuncGetVotingConfig       = "getVotingConfig"
+	FuncGetProposal           = "getProposal"
+	FuncGetProposals          = "getProposals"
+
+	// state variables
+	VarVotingPower     = "p"
+	VarVotingColor     = "c"
+	VarQuorum          = "q"
+	VarThreshold       = "t"
+	VarVotingPeriod    = "v"
+	VarCommittee       = "m"
+	VarProposals       = "r"
+	VarProposalCounter = "n"
+	VarVotes           = "o"
+	VarVoteDeposits    = "d"
+	VarOpenProposals   = "a"
+	VarFinished        = "f"
+	VarFinishedHead    = "h"
+)
+
+// voting power modes
+const (
+	// voting power of the vote is the amount of tokens of the voting color transferred with it.
+	// The tokens are locked in the 'governance' until the voting is over, so they can't be used to vote twice.
+	// The proposer must have tokens of the voting color in its on-chain account
+	VotingPowerBalance = "balance"
+	// one vote per committee member. Committee members are maintained by the chain owner
+	VotingPowerCommittee = "committee"
+)
+
+// votes
+const (
+	VoteYes = "yes"
+	VoteNo  = "no"
+)
+
+// proposal statuses
+const (
+	ProposalPending  = "pending"
+	ProposalExecuted = "executed"
+	ProposalRejected = "rejected"
+	// the proposal passed, but the call returned an error
+	ProposalFailed = "failed"
+)
+
+const (
+	// DefaultQuorum is the default minimum percentage of the total voting power which must vote
+	DefaultQuorum = 50
+	// DefaultThreshold is the default percentage of 'yes' among all votes which must be exceeded
+	DefaultThreshold = 50
+	// DefaultVotingPeriod is the default voting period in seconds
+	DefaultVotingPeriod = 24 * 60 * 60
+	// MaxVotingPeriod is the maximum voting period in seconds
+	MaxVotingPeriod = 90 * 24 * 60 * 60
+	// MaxOpenProposals is the maximum number of pending proposals of one proposer
+	MaxOpenProposals = 5
+	// MaxFinishedProposals is the maximum number of finished proposals kept in the state.
+	// The earliest finished proposals are pruned first. They remain in the eventlog of the 'governance'
+	MaxFinishedProposals = 100
+	// DefaultMaxNumberOfProposals is the default number of proposals returned by getProposals
+	DefaultMaxNumberOfProposals = 50
+	// MaxNumberOfProposals is the limit of proposals returned by getProposals
+	MaxNumberOfProposals = 100
+)
+
+// VotingConfig is the configuration of voting on the chain
+type VotingConfig struct {
+	// VotingPowerBalance or VotingPowerCommittee. Empty if voting is not configured
+	VotingPower string
+	// color of tokens in VotingPowerBalance mode
+	VotingColor balance.Color
+	// percentage of the total voting power which must vote
+	Quorum int64
+	// percentage of 'yes' among all votes which must be exceeded
+	Threshold int64
+	// seconds
+	VotingPeriod int64
+}
+
+// serde
+func (c *VotingConfig) Write(w io.Writer) error {
+	if err := util.WriteString16(w, c.VotingPower); err != nil {
+		return err
+	}
+	if _, err := w.Write(c.VotingColor[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, c.Quorum); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, c.Threshold); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, c.VotingPeriod); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *VotingConfig) Read(r io.Reader) error {
+	var err error
+	if c.VotingPower, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err = util.ReadColor(r, &c.VotingColor); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &c.Quorum); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &c.Threshold); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &c.VotingPeriod); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Proposal is a call to the entry point of the 'root' or the 'governance' itself to be made
+// by the 'governance' contract if the proposal passes the vote
+type Proposal struct {
+	ID          uint32
+	Proposer    coretypes.AgentID
+	Description string
+	// contract and its entry point
+	Contract coretypes.Hname
+	Target   coretypes.Hname
+	// parameters of the call
+	Params dict.Dict
+	// voting configuration at the moment the proposal was created
+	Config VotingConfig
+	// timestamp (nanoseconds) of the end of the voting
+	Deadline  int64
+	VotesYes  int64
+	VotesNo   int64
+	NumVoters uint32
+	Status    string
+	// error returned by the call. Only for ProposalFailed
+	Error string
+}
+
+func (p *Proposal) String() string {
+	return fmt.Sprintf("#%d: %s::%s, proposer: %s, status: %s, yes: %d, no: %d",
+		p.ID, p.Contract, p.Target, p.Proposer, p.Status, p.VotesYes, p.VotesNo)
+}
+
+// serde
+func (p *Proposal) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, p.ID); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Proposer[:]); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, p.Description); err != nil {
+		return err
+	}
+	if err := p.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := p.Target.Write(w); err != nil {
+		return err
+	}
+	params := p.Params
+	if params == nil {
+		params = dict.New()
+	}
+	if err := params.Write(w); err != nil {
+		return err
+	}
+	if err := p.Config.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, p.Deadline); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, p.VotesYes); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, p.VotesNo); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, p.NumVoters); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, p.Status); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, p.Error); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p *Proposal) Read(r io.Reader) error {
+	var err error
+	if err = util.ReadUint32(r, &p.ID); err != nil {
+		return err
+	}
+	if err = coretypes.ReadAgentID(r, &p.Proposer); err != nil {
+		return err
+	}
+	if p.Description, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err = p.Contract.Read(r); err != nil {
+		return err
+	}
+	if err = p.Target.Read(r); err != nil {
+		return err
+	}
+	p.Params = dict.New()
+	if err = p.Params.Read(r); err != nil {
+		return err
+	}
+	if err = p.Config.Read(r); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &p.Deadline); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &p.VotesYes); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &p.VotesNo); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &p.NumVoters); err != nil {
+		return err
+	}
+	if p.Status, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if p.Error, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func EncodeProposal(p *Proposal) []byte {
+	return util.MustBytes(p)
+}
+
+func DecodeProposal(data []byte) (*Proposal, error) {
+	ret := new(Proposal)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
@@ -0,0 +1,292 @@
+package governance
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+)
+
+// GetVotingConfig returns the voting configuration of the chain.
+// VotingPower of the returned config is empty if voting is not configured
+func GetVotingConfig(state kv.KVStoreReader) *VotingConfig {
+	d := kvdecoder.New(state)
+	return &VotingConfig{
+		VotingPower:  d.MustGetString(VarVotingPower, ""),
+		VotingColor:  d.MustGetColor(VarVotingColor, balance.ColorIOTA),
+		Quorum:       d.MustGetInt64(VarQuorum, DefaultQuorum),
+		Threshold:    d.MustGetInt64(VarThreshold, DefaultThreshold),
+		VotingPeriod: d.MustGetInt64(VarVotingPeriod, DefaultVotingPeriod),
+	}
+}
+
+func setVotingConfigIntern(state kv.KVStore, cfg *VotingConfig) {
+	state.Set(VarVotingPower, codec.EncodeString(cfg.VotingPower))
+	state.Set(VarVotingColor, codec.EncodeColor(cfg.VotingColor))
+	state.Set(VarQuorum, codec.EncodeInt64(cfg.Quorum))
+	state.Set(VarThreshold, codec.EncodeInt64(cfg.Threshold))
+	state.Set(VarVotingPeriod, codec.EncodeInt64(cfg.VotingPeriod))
+}
+
+// GetCommittee returns the voting committee of the chain ordered by agentID
+func GetCommittee(state kv.KVStoreReader) []coretypes.AgentID {
+	ret := make([]coretypes.AgentID, 0)
+	collections.NewMapReadOnly(state, VarCommittee).MustIterateKeys(func(key []byte) bool {
+		a, err := coretypes.NewAgentIDFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, a)
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return string(ret[i][:]) < string(ret[j][:]) })
+	return ret
+}
+
+func isCommitteeMember(state kv.KVStoreReader, agentID coretypes.AgentID) bool {
+	return collections.NewMapReadOnly(state, VarCommittee).MustHasAt(agentID[:])
+}
+
+// GetProposals returns at most maxNum retained proposals with IDs starting from the given one, ordered by ID
+func GetProposals(state kv.KVStoreReader, from uint32, maxNum uint32) []*Proposal {
+	ret := make([]*Proposal, 0)
+	num := GetNumProposals(state)
+	for id := from; id < num && id-from < maxNum; id++ {
+		if p, ok := GetProposal(state, id); ok {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// GetNumProposals returns the number of proposals ever created on the chain, i.e. the ID of the next proposal
+func GetNumProposals(state kv.KVStoreReader) uint32 {
+	n, _, err := codec.DecodeInt64(state.MustGet(VarProposalCounter))
+	if err != nil {
+		panic(err)
+	}
+	return uint32(n)
+}
+
+// GetProposal returns the proposal by ID
+func GetProposal(state kv.KVStoreReader, id uint32) (*Proposal, bool) {
+	data := collections.NewMapReadOnly(state, VarProposals).MustGetAt(util.Uint32To4Bytes(id))
+	if data == nil {
+		return nil, false
+	}
+	ret, err := DecodeProposal(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+func storeProposal(state kv.KVStore, p *Proposal) {
+	collections.NewMap(state, VarProposals).MustSetAt(util.Uint32To4Bytes(p.ID), EncodeProposal(p))
+}
+
+// getOpenProposalsMap is a map of proposers to the number of their pending proposals
+func getOpenProposalsMap(state kv.KVStore) *collections.Map {
+	return collections.NewMap(state, VarOpenProposals)
+}
+
+func getNumOpenProposals(state kv.KVStore, proposer coretypes.AgentID) int64 {
+	n, _, err := codec.DecodeInt64(getOpenProposalsMap(state).MustGetAt(proposer[:]))
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func addOpenProposals(state kv.KVStore, proposer coretypes.AgentID, delta int64) {
+	n := getNumOpenProposals(state, proposer) + delta
+	if n <= 0 {
+		getOpenProposalsMap(state).MustDelAt(proposer[:])
+		return
+	}
+	getOpenProposalsMap(state).MustSetAt(proposer[:], codec.EncodeInt64(n))
+}
+
+// getVotesMap is a map of voters of the proposal to their votes
+func getVotesMap(state kv.KVStore, id uint32) *collections.Map {
+	return collections.NewMap(state, VarVotes+string(util.Uint32To4Bytes(id)))
+}
+
+// getVoteDepositsMap is a map of voters of the proposal to the amounts of tokens locked by their votes
+func getVoteDepositsMap(state kv.KVStore, id uint32) *collections.Map {
+	return collections.NewMap(state, VarVoteDeposits+string(util.Uint32To4Bytes(id)))
+}
+
+// isAuthorized checks if the caller can change the voting configuration:
+// the chain owner or the 'governance' itself executing the passed proposal
+func isAuthorized(ctx coretypes.Sandbox) bool {
+	return ctx.CheckAuthorizationByChainOwner(ctx.Caller()) ||
+		ctx.Caller() == coretypes.NewAgentIDFromContractID(ctx.ContractID())
+}
+
+// isProposalTarget checks if the proposal can call the entry point. Proposals to the 'governance' itself
+// can only change the voting configuration. Authorization of calls to the 'root' is checked by the 'root'
+func isProposalTarget(contract coretypes.Hname, target coretypes.Hname) bool {
+	if target == coretypes.EntryPointInit {
+		return false
+	}
+	switch contract {
+	case coretypes.Hn(coreutil.CoreContractRoot):
+		return true
+	case Interface.Hname():
+		switch target {
+		case coretypes.Hn(FuncSetVotingConfig), coretypes.Hn(FuncAddCommitteeMember), coretypes.Hn(FuncRemoveCommitteeMember):
+			return true
+		}
+	}
+	return false
+}
+
+// getVotingPower returns the voting power of the agent according to the voting configuration
+func getVotingPower(ctx coretypes.Sandbox, cfg *VotingConfig, agentID coretypes.AgentID) (int64, error) {
+	switch cfg.VotingPower {
+	case VotingPowerCommittee:
+		if isCommitteeMember(ctx.State(), agentID) {
+			return 1, nil
+		}
+		return 0, nil
+	case VotingPowerBalance:
+		params := dict.New()
+		params.Set(accounts.ParamAgentID, codec.EncodeAgentID(agentID))
+		return callAccountsBalance(ctx, accounts.FuncBalance, params, cfg.VotingColor)
+	}
+	return 0, fmt.Errorf("voting is not configured")
+}
+
+// getTotalVotingPower returns the total voting power on the chain according to the voting configuration
+func getTotalVotingPower(ctx coretypes.Sandbox, cfg *VotingConfig) (int64, error) {
+	switch cfg.VotingPower {
+	case VotingPowerCommittee:
+		return int64(collections.NewMap(ctx.State(), VarCommittee).MustLen()), nil
+	case VotingPowerBalance:
+		return callAccountsBalance(ctx, accounts.FuncTotalAssets, nil, cfg.VotingColor)
+	}
+	return 0, fmt.Errorf("voting is not configured")
+}
+
+func callAccountsBalance(ctx coretypes.Sandbox, funName string, params dict.Dict, col balance.Color) (int64, error) {
+	res, err := ctx.Call(accounts.Interface.Hname(), coretypes.Hn(funName), params, nil)
+	if err != nil {
+		return 0, err
+	}
+	bals, err := accounts.DecodeBalances(res)
+	if err != nil {
+		return 0, err
+	}
+	return bals[col], nil
+}
+
+// isDecided checks if the outcome of the proposal can't be changed by the remaining votes:
+// 'yes' votes alone reach the quorum and exceed the threshold of the total voting power
+func isDecided(p *Proposal, total int64) bool {
+	return p.VotesYes*100 >= p.Config.Quorum*total && p.VotesYes*100 > p.Config.Threshold*total
+}
+
+// isPassed checks if the finished voting reached the quorum and the 'yes' votes exceed the threshold
+func isPassed(p *Proposal, total int64) bool {
+	cast := p.VotesYes + p.VotesNo
+	if cast == 0 {
+		return false
+	}
+	return cast*100 >= p.Config.Quorum*total && p.VotesYes*100 > p.Config.Threshold*cast
+}
+
+// executeProposal makes the call of the proposal and stores the outcome in the proposal.
+// If the call fails, its state changes are rolled back and the proposal is marked as failed
+func executeProposal(ctx coretypes.Sandbox, p *Proposal) {
+	_, err := ctx.Call(p.Contract, p.Target, p.Params, nil)
+	if err != nil {
+		p.Status = ProposalFailed
+		p.Error = err.Error()
+	} else {
+		p.Status = ProposalExecuted
+	}
+	closeProposal(ctx, p)
+}
+
+// closeProposal stores the proposal which is not pending anymore and prunes the earliest
+// finished proposals beyond MaxFinishedProposals
+func closeProposal(ctx coretypes.Sandbox, p *Proposal) {
+	state := ctx.State()
+	storeProposal(state, p)
+	emitProposalEvent(ctx, p)
+	addOpenProposals(state, p.Proposer, -1)
+
+	// finished proposals are kept in the order they are closed: VarFinished maps sequence numbers to IDs
+	finished := collections.NewMap(state, VarFinished)
+	head, _, err := codec.DecodeInt64(state.MustGet(VarFinishedHead))
+	if err != nil {
+		panic(err)
+	}
+	finished.MustSetAt(util.Uint64To8Bytes(uint64(head)+uint64(finished.MustLen())), util.Uint32To4Bytes(p.ID))
+	for finished.MustLen() > MaxFinishedProposals {
+		key := util.Uint64To8Bytes(uint64(head))
+		pruneProposal(ctx, util.MustUint32From4Bytes(finished.MustGetAt(key)))
+		finished.MustDelAt(key)
+		head++
+	}
+	state.Set(VarFinishedHead, codec.EncodeInt64(head))
+}
+
+// pruneProposal removes the finished proposal with its votes from the state.
+// Tokens locked by the votes and not reclaimed yet are returned to the on-chain accounts of the voters
+func pruneProposal(ctx coretypes.Sandbox, id uint32) {
+	state := ctx.State()
+	p, ok := GetProposal(state, id)
+	if !ok {
+		return
+	}
+	votes := getVotesMap(state, id)
+	for _, voter := range mapKeys(votes) {
+		votes.MustDelAt(voter)
+	}
+	deposits := getVoteDepositsMap(state, id)
+	for _, voter := range mapKeys(deposits) {
+		amount, _, err := codec.DecodeInt64(deposits.MustGetAt(voter))
+		if err != nil {
+			panic(err)
+		}
+		agentID, err := coretypes.NewAgentIDFromBytes(voter)
+		if err != nil {
+			panic(err)
+		}
+		tokens := cbalances.NewFromMap(map[balance.Color]int64{p.Config.VotingColor: amount})
+		if err = accounts.Accrue(ctx, agentID, tokens); err != nil {
+			panic(err)
+		}
+		deposits.MustDelAt(voter)
+	}
+	collections.NewMap(state, VarProposals).MustDelAt(util.Uint32To4Bytes(id))
+}
+
+// mapKeys returns the keys of the map ordered, so they are processed deterministically
+func mapKeys(m *collections.Map) [][]byte {
+	ret := make([][]byte, 0)
+	m.MustIterateKeys(func(key []byte) bool {
+		ret = append(ret, key)
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return string(ret[i]) < string(ret[j]) })
+	return ret
+}
+
+func emitProposalEvent(ctx coretypes.Sandbox, p *Proposal) {
+	payload := dict.New()
+	payload.Set(ParamProposal, EncodeProposal(p))
+	ctx.EmitEvent(p.Status, [][]byte{util.Uint32To4Bytes(p.ID)}, payload)
+}
@@ -0,0 +1,103 @@
+package root
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+)
+
+// GetAllowedPrograms returns the allowlist of program hashes, sorted.
+// Empty allowlists of program hashes and VM types mean any program can be deployed
+func GetAllowedPrograms(state kv.KVStoreReader) []hashing.HashValue {
+	ret := make([]hashing.HashValue, 0)
+	collections.NewMapReadOnly(state, VarAllowedPrograms).MustIterateKeys(func(key []byte) bool {
+		h, err := hashing.HashValueFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, h)
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return string(ret[i][:]) < string(ret[j][:]) })
+	return ret
+}
+
+// GetAllowedVMTypes returns the allowlist of VM types of program blobs, sorted
+func GetAllowedVMTypes(state kv.KVStoreReader) []string {
+	ret := make([]string, 0)
+	collections.NewMapReadOnly(state, VarAllowedVMTypes).MustIterateKeys(func(key []byte) bool {
+		ret = append(ret, string(key))
+		return true
+	})
+	sort.Strings(ret)
+	return ret
+}
+
+// checkProgramAllowed checks if the program can be deployed according to the allowlists.
+// The program is allowed if its hash is in the allowlist of programs or the VM type of its blob
+// is in the allowlist of VM types
+func checkProgramAllowed(ctx coretypes.Sandbox, progHash hashing.HashValue) error {
+	programs := collections.NewMap(ctx.State(), VarAllowedPrograms)
+	vmTypes := collections.NewMap(ctx.State(), VarAllowedVMTypes)
+	if programs.MustLen() == 0 && vmTypes.MustLen() == 0 {
+		return nil
+	}
+	if programs.MustHasAt(progHash[:]) {
+		return nil
+	}
+	params := dict.New()
+	params.Set(blob.ParamHash, codec.EncodeHashValue(progHash))
+	params.Set(blob.ParamField, []byte(blob.VarFieldVMType))
+	res, err := ctx.Call(blob.Interface.Hname(), coretypes.Hn(blob.FuncGetBlobField), params, nil)
+	if err == nil && vmTypes.MustHasAt(res.MustGet(blob.ParamBytes)) {
+		return nil
+	}
+	return fmt.Errorf("program %s is not in the deployment allowlist", progHash.String())
+}
+
+// GetDeployQuota returns the max number of contracts the agent can deploy. 0 means unlimited
+func GetDeployQuota(state kv.KVStoreReader, agentID coretypes.AgentID) int64 {
+	v := collections.NewMapReadOnly(state, VarDeployQuotas).MustGetAt(agentID[:])
+	if v == nil {
+		v = state.MustGet(VarDefaultDeployQuota)
+	}
+	ret, _, err := codec.DecodeInt64(v)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// GetDeployCount returns the number of contracts deployed by the agent
+func GetDeployCount(state kv.KVStoreReader, agentID coretypes.AgentID) int64 {
+	ret, _, err := codec.DecodeInt64(collections.NewMapReadOnly(state, VarDeployCounts).MustGetAt(agentID[:]))
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func incDeployCount(state kv.KVStore, agentID coretypes.AgentID) {
+	count := GetDeployCount(state, agentID)
+	collections.NewMap(state, VarDeployCounts).MustSetAt(agentID[:], codec.EncodeInt64(count+1))
+}
+
+// updateBlobReference calls the 'blob' contract to add or remove the reference to the program blob
+// from the contract registry. Program hashes which are not blobs are ignored by the 'blob' contract
+func updateBlobReference(ctx coretypes.Sandbox, progHash hashing.HashValue, add bool) error {
+	fname := blob.FuncRemoveReference
+	if add {
+		fname = blob.FuncAddReference
+	}
+	params := dict.New()
+	params.Set(blob.ParamHash, codec.EncodeHashValue(progHash))
+	_, err := ctx.Call(blob.Interface.Hname(), coretypes.Hn(fname), params, nil)
+	return err
+}
@@ -8,6 +8,7 @@ package root
 
 import (
 	"fmt"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	assert2 "github.com/iotaledger/wasp/packages/coretypes/assert"
@@ -17,7 +18,10 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
+	"time"
 )
 
 // initialize handles constructor, the "init" request. This is the first call to the chain
@@ -25,7 +29,7 @@ import (
 // - stores chain ID and chain description in the state
 // - sets state ownership to the caller
 // - creates record in the registry for the 'root' itself
-// - deploys other core contracts: 'accounts', 'blob', 'eventlog' by creating records in the registry and calling constructors
+// - deploys other core contracts: 'accounts', 'blob', 'eventlog', 'blocklog', 'governance' by creating records in the registry and calling constructors
 // Input:
 // - ParamChainID coretypes.ChainID. ID of the chain. Cannot be changed
 // - ParamChainColor balance.Color
@@ -72,6 +76,16 @@ func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
 	err = storeAndInitContract(ctx, &rec, nil)
 	a.Require(err == nil, "root.init.fail: %v", err)
 
+	// deploy blocklog
+	rec = NewContractRecord(blocklog.Interface, ctx.Caller())
+	err = storeAndInitContract(ctx, &rec, nil)
+	a.Require(err == nil, "root.init.fail: %v", err)
+
+	// deploy governance
+	rec = NewContractRecord(governance.Interface, ctx.Caller())
+	err = storeAndInitContract(ctx, &rec, nil)
+	a.Require(err == nil, "root.init.fail: %v", err)
+
 	state.Set(VarStateInitialized, []byte{0xFF})
 	state.Set(VarChainID, codec.EncodeChainID(chainID))
 	state.Set(VarChainColor, codec.EncodeColor(chainColor))
@@ -85,13 +99,16 @@ func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("root.initialize.deployed: '%s', hname = %s", blob.Interface.Name, blob.Interface.Hname().String())
 	ctx.Log().Debugf("root.initialize.deployed: '%s', hname = %s", accounts.Interface.Name, accounts.Interface.Hname().String())
 	ctx.Log().Debugf("root.initialize.deployed: '%s', hname = %s", eventlog.Interface.Name, eventlog.Interface.Hname().String())
+	ctx.Log().Debugf("root.initialize.deployed: '%s', hname = %s", blocklog.Interface.Name, blocklog.Interface.Hname().String())
+	ctx.Log().Debugf("root.initialize.deployed: '%s', hname = %s", governance.Interface.Name, governance.Interface.Hname().String())
 	ctx.Log().Debugf("root.initialize.success")
 	return nil, nil
 }
 
 // deployContract deploys contract and calls its 'init' constructor.
 // If call to the constructor returns an error or an other error occurs,
-// removes smart contract form the registry as if it was never attempted to deploy
+// removes smart contract form the registry as if it was never attempted to deploy.
+// Deployers other than the chain owner are restricted by the allowlists of programs and by deployment quotas
 // Inputs:
 // - ParamName string, the unique name of the contract in the chain. Later used as hname
 // - ParamProgramHash HashValue is a hash of the blob which represents program binary in the 'blob' contract.
@@ -110,6 +127,13 @@ func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 	name := params.MustGetString(ParamName)
 	a.Require(name != "", "wrong name")
 
+	if !CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()) {
+		a.RequireNoError(checkProgramAllowed(ctx, progHash))
+		quota := GetDeployQuota(ctx.State(), ctx.Caller())
+		a.Require(quota == 0 || GetDeployCount(ctx.State(), ctx.Caller()) < quota,
+			"root.deployContract: deployment quota %d of %s is exhausted", quota, ctx.Caller())
+	}
+
 	// pass to init function all params not consumed so far
 	initParams := dict.New()
 	for key, value := range ctx.Params() {
@@ -129,12 +153,74 @@ func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 		Creator:     ctx.Caller(),
 	}, initParams)
 	a.Require(err == nil, "root.deployContract.fail: %v", err)
+	incDeployCount(ctx.State(), ctx.Caller())
+	a.RequireNoError(updateBlobReference(ctx, progHash, true))
 
 	ctx.Event(fmt.Sprintf("[deploy] name: %s hname: %s, progHash: %s, dscr: '%s'",
 		name, coretypes.Hn(name), progHash.String(), description))
 	return nil, nil
 }
 
+// upgradeContract replaces the program of the deployed contract. The state of the contract is preserved
+// because it is kept in the sub-partition of the contract's hname, which is not changed.
+// The new program is loaded through the processor cache when 'migrate' is called.
+// If the new program has 'migrate' entry point, it is called with parameters.
+// If the program fails to load or 'migrate' returns an error, the upgrade is rolled back.
+// Only the creator of the contract or the chain owner can upgrade it. Core contracts can't be upgraded.
+// The new program of the creator other than the chain owner must pass the allowlists of programs
+// Inputs:
+// - ParamHname coretypes.Hname of the contract to upgrade
+// - ParamProgramHash HashValue of the new program
+// - ParamDescription string new description of the contract (optional, the description is not changed if omitted)
+// - all other parameters are passed to the 'migrate' entry point
+func upgradeContract(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("root.upgradeContract.begin")
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert2.NewAssert(ctx.Log())
+
+	hname := params.MustGetHname(ParamHname)
+	progHash := params.MustGetHashValue(ParamProgramHash)
+	description := params.MustGetString(ParamDescription, "")
+	a.Require(!isCoreContract(hname), "root.upgradeContract: core contract %s can't be upgraded", hname)
+
+	rec, err := FindContract(ctx.State(), hname)
+	a.Require(err == nil, "root.upgradeContract.fail: %v", err)
+	a.Require(ctx.Caller() == rec.Creator || CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()),
+		"root.upgradeContract: not authorized")
+	if !CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()) {
+		a.RequireNoError(checkProgramAllowed(ctx, progHash))
+	}
+
+	// pass to migrate function all params not consumed so far
+	migrateParams := dict.New()
+	for key, value := range ctx.Params() {
+		if key != ParamHname && key != ParamProgramHash && key != ParamDescription {
+			migrateParams.Set(key, value)
+		}
+	}
+	contractRegistry := collections.NewMap(ctx.State(), VarContractRegistry)
+	oldRecData := EncodeContractRecord(rec)
+	oldProgHash := rec.ProgramHash
+	rec.ProgramHash = progHash
+	if description != "" {
+		rec.Description = description
+	}
+	contractRegistry.MustSetAt(hname.Bytes(), EncodeContractRecord(rec))
+
+	// the call loads the new program. It fails if the program can't be loaded
+	_, err = ctx.Call(hname, coretypes.EntryPointMigrate, migrateParams, nil)
+	if err != nil && err != coretypes.ErrEntryPointNotFound {
+		// loading the program or call to 'migrate' failed: restore the old record
+		contractRegistry.MustSetAt(hname.Bytes(), oldRecData)
+		return nil, fmt.Errorf("root.upgradeContract.fail: contract '%s'/%s: calling 'migrate': %v", rec.Name, hname, err)
+	}
+	a.RequireNoError(updateBlobReference(ctx, oldProgHash, false))
+	a.RequireNoError(updateBlobReference(ctx, progHash, true))
+	ctx.Event(fmt.Sprintf("[upgrade] name: %s hname: %s, progHash: %s -> %s",
+		rec.Name, hname, oldProgHash.String(), progHash.String()))
+	return nil, nil
+}
+
 // findContract view finds and returns encoded record of the contract
 // Input:
 // - ParamHname
@@ -156,6 +242,34 @@ func findContract(ctx coretypes.SandboxView) (dict.Dict, error) {
 	return ret, nil
 }
 
+// countProgramReferences view returns the number of contracts in the registry with the program.
+// It takes the parameters of the 'blob' contract, which calls it
+// Input:
+// - blob.ParamHash program hash
+// Output:
+// - blob.ParamReferences int64
+func countProgramReferences(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	progHash, err := params.GetHashValue(blob.ParamHash)
+	if err != nil {
+		return nil, err
+	}
+	var count int64
+	collections.NewMapReadOnly(ctx.State(), VarContractRegistry).MustIterate(func(_ []byte, value []byte) bool {
+		rec, err := DecodeContractRecord(value)
+		if err != nil {
+			panic(err)
+		}
+		if rec.ProgramHash == progHash {
+			count++
+		}
+		return true
+	})
+	ret := dict.New()
+	ret.Set(blob.ParamReferences, codec.EncodeInt64(count))
+	return ret, nil
+}
+
 // getChainInfo view returns general info about the chain: chain ID, chain owner ID,
 // description and the whole contract registry
 // Input: none
@@ -164,6 +278,7 @@ func findContract(ctx coretypes.SandboxView) (dict.Dict, error) {
 // - VarChainOwnerID - AgentID
 // - VarDescription - string
 // - VarContractRegistry: a map of contract registry
+// - VarChainOwners: an array of the M-of-N owner set, VarOwnerQuorum - M
 func getChainInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	info := MustGetChainInfo(ctx.State())
 	ret := dict.New()
@@ -175,6 +290,14 @@ func getChainInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	ret.Set(VarFeeColor, codec.EncodeColor(info.FeeColor))
 	ret.Set(VarDefaultOwnerFee, codec.EncodeInt64(info.DefaultOwnerFee))
 	ret.Set(VarDefaultValidatorFee, codec.EncodeInt64(info.DefaultValidatorFee))
+	ret.Set(VarGasBudget, codec.EncodeInt64(info.GasBudget))
+	ret.Set(VarFeeShortfallPolicy, codec.EncodeString(info.FeeShortfallPolicy))
+	ret.Set(VarFeeShortfallPenalty, codec.EncodeInt64(info.FeeShortfallPenalty))
+	ret.Set(VarOwnerQuorum, codec.EncodeInt64(info.OwnerQuorum))
+	owners := collections.NewArray(ret, VarChainOwners)
+	for _, o := range info.ChainOwners {
+		owners.MustPush(codec.EncodeAgentID(o))
+	}
 
 	src := collections.NewMapReadOnly(ctx.State(), VarContractRegistry)
 	dst := collections.NewMap(ret, VarContractRegistry)
@@ -217,6 +340,8 @@ func claimChainOwnership(ctx coretypes.Sandbox) (dict.Dict, error) {
 
 	state.Set(VarChainOwnerID, codec.EncodeAgentID(nextOwner))
 	state.Del(VarChainOwnerIDDelegated)
+	// the new chain owner governs the chain alone
+	setChainOwnersIntern(state, nil, 0)
 	ctx.Log().Debugf("root.chainChainOwner.success: chain owner changed: %s --> %s",
 		currentOwner.String(), nextOwner.String())
 	return nil, nil
@@ -225,9 +350,12 @@ func claimChainOwnership(ctx coretypes.Sandbox) (dict.Dict, error) {
 // getFeeInfo returns fee information for the contact.
 // Input:
 // - ParamHname coretypes.Hname contract id
+// - ParamEntryPoint coretypes.Hname entry point of the contract (optional)
 // Output:
 // - ParamFeeColor balance.Color color of tokens accepted for fees
+// - ParamOwnerFee int64 fee for the chain owner
 // - ParamValidatorFee int64 minimum fee for contract
+// - ParamFeeMode string FeeModeFlat or FeeModeBasisPoints
 // Note: return default chain values if contract doesn't exist
 func getFeeInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	params := kvdecoder.New(ctx.Params())
@@ -235,11 +363,16 @@ func getFeeInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	if err != nil {
 		return nil, err
 	}
-	feeColor, ownerFee, validatorFee := GetFeeInfo(ctx.State(), hname)
+	entryPoint, err := params.GetHname(ParamEntryPoint, 0)
+	if err != nil {
+		return nil, err
+	}
+	feeColor, ownerFee, validatorFee, basisPoints := GetFeeInfo(ctx.State(), hname, entryPoint)
 	ret := dict.New()
 	ret.Set(ParamFeeColor, codec.EncodeColor(feeColor))
 	ret.Set(ParamOwnerFee, codec.EncodeInt64(ownerFee))
 	ret.Set(ParamValidatorFee, codec.EncodeInt64(validatorFee))
+	ret.Set(ParamFeeMode, codec.EncodeString(FeeModeString(basisPoints)))
 	return ret, nil
 }
 
@@ -277,11 +410,13 @@ func setDefaultFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
-// setContractFee sets fee for the particular smart contract
+// setContractFee sets fee for the particular smart contract or for its entry point
 // Input:
 // - ParamHname coretypes.Hname smart contract ID
+// - ParamEntryPoint coretypes.Hname entry point of the contract. May be skipped, then fees of the contract are set
 // - ParamOwnerFee int64 non-negative value of the owner fee. May be skipped, then it is not set
 // - ParamValidatorFee int64 non-negative value of the contract fee. May be skipped, then it is not set
+// - ParamFeeMode string FeeModeFlat or FeeModeBasisPoints. May be skipped, then it is not changed
 func setContractFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a := assert2.NewAssert(ctx.Log())
 	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setContractFee: not authorized")
@@ -293,19 +428,74 @@ func setContractFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 	if err != nil {
 		return nil, err
 	}
+	entryPoint := params.MustGetHname(ParamEntryPoint, 0)
 
 	ownerFee := params.MustGetInt64(ParamOwnerFee, -1)
 	ownerFeeSet := ownerFee >= 0
 	validatorFee := params.MustGetInt64(ParamValidatorFee, -1)
 	validatorFeeSet := validatorFee >= 0
+	feeMode := params.MustGetString(ParamFeeMode, "")
+	feeModeSet := feeMode != ""
 
-	a.Require(ownerFeeSet || validatorFeeSet, "root.setContractFee: wrong parameters")
-	if ownerFeeSet {
-		rec.OwnerFee = ownerFee
+	a.Require(ownerFeeSet || validatorFeeSet || feeModeSet, "root.setContractFee: wrong parameters")
+	a.Require(!feeModeSet || feeMode == FeeModeFlat || feeMode == FeeModeBasisPoints,
+		"root.setContractFee: wrong fee mode '%s'", feeMode)
+
+	if entryPoint == 0 {
+		if ownerFeeSet {
+			rec.OwnerFee = ownerFee
+		}
+		if validatorFeeSet {
+			rec.ValidatorFee = validatorFee
+		}
+		if feeModeSet {
+			rec.FeeBasisPoints = feeMode == FeeModeBasisPoints
+		}
+		a.Require(checkFees(rec.OwnerFee, rec.ValidatorFee, rec.FeeBasisPoints), "root.setContractFee: wrong fees")
+	} else {
+		fee, ok := rec.EntryPointFees[entryPoint]
+		if !ok {
+			fee = &EntryPointFee{}
+		}
+		if ownerFeeSet {
+			fee.OwnerFee = ownerFee
+		}
+		if validatorFeeSet {
+			fee.ValidatorFee = validatorFee
+		}
+		if feeModeSet {
+			fee.BasisPoints = feeMode == FeeModeBasisPoints
+		}
+		a.Require(checkFees(fee.OwnerFee, fee.ValidatorFee, fee.BasisPoints), "root.setContractFee: wrong fees")
+		if rec.EntryPointFees == nil {
+			rec.EntryPointFees = make(map[coretypes.Hname]*EntryPointFee)
+		}
+		rec.EntryPointFees[entryPoint] = fee
 	}
-	if validatorFeeSet {
-		rec.ValidatorFee = validatorFee
+	collections.NewMap(ctx.State(), VarContractRegistry).MustSetAt(hname.Bytes(), EncodeContractRecord(rec))
+	return nil, nil
+}
+
+// removeEntryPointFee removes the fee of the entry point. Fees of the contract are in effect for it then
+// Input:
+// - ParamHname coretypes.Hname smart contract ID
+// - ParamEntryPoint coretypes.Hname entry point of the contract
+func removeEntryPointFee(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.removeEntryPointFee: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+
+	hname := params.MustGetHname(ParamHname)
+	rec, err := FindContract(ctx.State(), hname)
+	if err != nil {
+		return nil, err
 	}
+	entryPoint := params.MustGetHname(ParamEntryPoint)
+	_, ok := rec.EntryPointFees[entryPoint]
+	a.Require(ok, "root.removeEntryPointFee: fee of the entry point %s is not set", entryPoint)
+
+	delete(rec.EntryPointFees, entryPoint)
 	collections.NewMap(ctx.State(), VarContractRegistry).MustSetAt(hname.Bytes(), EncodeContractRecord(rec))
 	return nil, nil
 }
@@ -339,3 +529,336 @@ func revokeDeployPermission(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Event(fmt.Sprintf("[revoke deploy permission] from agentID: %s", deployer))
 	return nil, nil
 }
+
+// allowProgram adds the program hash or the VM type to the deployment allowlists.
+// Once any of the allowlists is not empty, deployers other than the chain owner can deploy only allowed programs
+// Input:
+//  - ParamProgramHash hashing.HashValue. Optional
+//  - ParamVMType string VM type of the program blob, for example 'wasmtimevm'. Optional
+func allowProgram(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return setProgramAllowed(ctx, true)
+}
+
+// disallowProgram removes the program hash or the VM type from the deployment allowlists.
+// Contracts already deployed are not affected
+// Input:
+//  - ParamProgramHash hashing.HashValue. Optional
+//  - ParamVMType string. Optional
+func disallowProgram(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return setProgramAllowed(ctx, false)
+}
+
+func setProgramAllowed(ctx coretypes.Sandbox, allow bool) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setProgramAllowed: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	var m *collections.Map
+	var key []byte
+	var what string
+	switch {
+	case ctx.Params().MustHas(ParamProgramHash):
+		progHash := params.MustGetHashValue(ParamProgramHash)
+		m, key, what = collections.NewMap(ctx.State(), VarAllowedPrograms), progHash[:], "program hash "+progHash.String()
+	case ctx.Params().MustHas(ParamVMType):
+		vmType := params.MustGetString(ParamVMType)
+		a.Require(vmType != "", "root.setProgramAllowed: empty VM type")
+		m, key, what = collections.NewMap(ctx.State(), VarAllowedVMTypes), []byte(vmType), "VM type "+vmType
+	default:
+		a.Require(false, "root.setProgramAllowed: program hash or VM type must be specified")
+	}
+	if allow {
+		m.MustSetAt(key, []byte{0xFF})
+		ctx.Event(fmt.Sprintf("[allow program] %s", what))
+	} else {
+		a.Require(m.MustHasAt(key), "root.setProgramAllowed: %s is not in the allowlist", what)
+		m.MustDelAt(key)
+		ctx.Event(fmt.Sprintf("[disallow program] %s", what))
+	}
+	return nil, nil
+}
+
+// setDeployQuota sets the max number of contracts the deployer can deploy. Quota 0 means unlimited.
+// If ParamDeployer is not specified, sets the default quota of all deployers.
+// If ParamDeployQuota is not specified, removes the quota of the deployer, so the default applies.
+// The quotas don't apply to the chain owner
+// Input:
+//  - ParamDeployer coretypes.AgentID. Optional
+//  - ParamDeployQuota int64 >= 0. Optional if ParamDeployer is specified
+func setDeployQuota(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setDeployQuota: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	if !ctx.Params().MustHas(ParamDeployer) {
+		quota := params.MustGetInt64(ParamDeployQuota)
+		a.Require(quota >= 0, "root.setDeployQuota: wrong quota %d", quota)
+		ctx.State().Set(VarDefaultDeployQuota, codec.EncodeInt64(quota))
+		ctx.Event(fmt.Sprintf("[set default deploy quota] %d", quota))
+		return nil, nil
+	}
+	deployer := params.MustGetAgentID(ParamDeployer)
+	quotas := collections.NewMap(ctx.State(), VarDeployQuotas)
+	if !ctx.Params().MustHas(ParamDeployQuota) {
+		quotas.MustDelAt(deployer[:])
+		ctx.Event(fmt.Sprintf("[remove deploy quota] of agentID: %s", deployer))
+		return nil, nil
+	}
+	quota := params.MustGetInt64(ParamDeployQuota)
+	a.Require(quota >= 0, "root.setDeployQuota: wrong quota %d", quota)
+	quotas.MustSetAt(deployer[:], codec.EncodeInt64(quota))
+	ctx.Event(fmt.Sprintf("[set deploy quota] %d of agentID: %s", quota, deployer))
+	return nil, nil
+}
+
+// getDeployPolicy returns the deployment allowlists and the default quota
+// Output:
+//  - ParamAllowedPrograms array of hashing.HashValue
+//  - ParamAllowedVMTypes array of string
+//  - ParamDeployQuota int64 default quota. 0 means unlimited
+func getDeployPolicy(ctx coretypes.SandboxView) (dict.Dict, error) {
+	ret := dict.New()
+	programs := collections.NewArray(ret, ParamAllowedPrograms)
+	for _, h := range GetAllowedPrograms(ctx.State()) {
+		programs.MustPush(codec.EncodeHashValue(h))
+	}
+	vmTypes := collections.NewArray(ret, ParamAllowedVMTypes)
+	for _, t := range GetAllowedVMTypes(ctx.State()) {
+		vmTypes.MustPush(codec.EncodeString(t))
+	}
+	stateDecoder := kvdecoder.New(ctx.State(), ctx.Log())
+	ret.Set(ParamDeployQuota, codec.EncodeInt64(stateDecoder.MustGetInt64(VarDefaultDeployQuota, 0)))
+	return ret, nil
+}
+
+// getDeployerInfo returns the deploy permission, the effective quota and the number of deployed contracts of the agent
+// Input:
+//  - ParamDeployer coretypes.AgentID
+// Output:
+//  - ParamDeployPermission []byte{0xFF} if granted with grantDeployPermission, absent otherwise
+//  - ParamDeployQuota int64. 0 means unlimited
+//  - ParamDeployCount int64
+func getDeployerInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	deployer, err := params.GetAgentID(ParamDeployer)
+	if err != nil {
+		return nil, err
+	}
+	state := ctx.State()
+	ret := dict.New()
+	if collections.NewMapReadOnly(state, VarDeployPermissions).MustHasAt(deployer[:]) {
+		ret.Set(ParamDeployPermission, []byte{0xFF})
+	}
+	ret.Set(ParamDeployQuota, codec.EncodeInt64(GetDeployQuota(state, deployer)))
+	ret.Set(ParamDeployCount, codec.EncodeInt64(GetDeployCount(state, deployer)))
+	return ret, nil
+}
+
+// setGasBudget sets the gas budget of one request on the chain
+// Input:
+//  - ParamGasBudget int64 gas budget, not less than coretypes.MinGasBudget.
+//    0 or absent means the default gas budget coretypes.DefaultGasBudget
+func setGasBudget(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setGasBudget: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	gasBudget := params.MustGetInt64(ParamGasBudget, 0)
+	a.Require(gasBudget == 0 || gasBudget >= coretypes.MinGasBudget, "root.setGasBudget: wrong parameters")
+
+	if gasBudget > 0 {
+		ctx.State().Set(VarGasBudget, codec.EncodeInt64(gasBudget))
+	} else {
+		ctx.State().Del(VarGasBudget)
+	}
+	ctx.Event(fmt.Sprintf("[set gas budget] %d", gasBudget))
+	return nil, nil
+}
+
+// setFeeShortfallPolicy sets the policy of handling requests which don't carry enough fees
+// Input:
+//  - ParamFeeShortfallPolicy string, one of FeeShortfallRefund, FeeShortfallChargeAvailable, FeeShortfallPenalty.
+//    Absent means the default FeeShortfallRefund
+//  - ParamFeeShortfallPenalty int64 the amount of fee tokens charged with FeeShortfallPenalty. Must be positive
+func setFeeShortfallPolicy(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setFeeShortfallPolicy: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	policy := params.MustGetString(ParamFeeShortfallPolicy, FeeShortfallRefund)
+	penalty := params.MustGetInt64(ParamFeeShortfallPenalty, 0)
+	switch policy {
+	case FeeShortfallRefund, FeeShortfallChargeAvailable:
+		a.Require(penalty == 0, "root.setFeeShortfallPolicy: penalty is only used with the '%s' policy", FeeShortfallPenalty)
+	case FeeShortfallPenalty:
+		a.Require(penalty > 0, "root.setFeeShortfallPolicy: penalty must be positive")
+	default:
+		a.Require(false, "root.setFeeShortfallPolicy: unknown policy '%s'", policy)
+	}
+
+	if policy == FeeShortfallRefund {
+		ctx.State().Del(VarFeeShortfallPolicy)
+	} else {
+		ctx.State().Set(VarFeeShortfallPolicy, codec.EncodeString(policy))
+	}
+	if penalty > 0 {
+		ctx.State().Set(VarFeeShortfallPenalty, codec.EncodeInt64(penalty))
+	} else {
+		ctx.State().Del(VarFeeShortfallPenalty)
+	}
+	ctx.Event(fmt.Sprintf("[set fee shortfall policy] %s, penalty: %d", policy, penalty))
+	return nil, nil
+}
+
+// rotateCommittee moves the chain to the new committee. The new committee is represented by the BLS address
+// of the distributed key, created by the DKG among nodes of the new committee.
+// The caller must run the DKG and set the next committee in the nodes before the rotation
+// (see apilib.RotateCommittee): the rotation is irreversible, and nodes take over the chain only if
+// the address was set as the address of the next committee in their chain records.
+// The anchor transaction of the block moves the chain token and all assets of the chain to the new address.
+// The chain ID is not changed. Only the chain owner can rotate the committee.
+// Nodes of the old committee keep moving outputs which arrive at the old address,
+// e.g. requests sent to the chain ID address, to the new one
+// Input:
+//  - ParamChainAddress address.Address address of the new committee
+func rotateCommittee(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.rotateCommittee: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	newAddress := params.MustGetAddress(ParamChainAddress)
+	a.Require(newAddress.Version() == address.VersionBLS, "root.rotateCommittee: the address of the committee must be a BLS address")
+	currentAddress, ok := GetChainAddress(ctx.State())
+	a.Require(ok, "root.rotateCommittee: chain address not found")
+	a.Require(newAddress != currentAddress, "root.rotateCommittee: the committee already has the address %s", newAddress.String())
+
+	ctx.State().Set(VarChainAddress, codec.EncodeAddress(newAddress))
+	ctx.Event(fmt.Sprintf("[rotate committee] %s --> %s", currentAddress.String(), newAddress.String()))
+	return nil, nil
+}
+
+// setChainOwners sets the M-of-N owner set of the chain. Once the owner set is set, owner-only entry points
+// of the 'root' can only be called through the proposeOwnerAction/approveOwnerAction flow.
+// All pending proposals are removed
+// Input:
+//  - ParamChainOwners []coretypes.AgentID encoded with EncodeChainOwners. Empty or absent removes the owner set
+//  - ParamOwnerQuorum int64 M, number of distinct approvals needed. 0 or absent if the owner set is empty
+func setChainOwners(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setChainOwners: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	owners, err := DecodeChainOwners(params.MustGetBytes(ParamChainOwners, nil))
+	a.RequireNoError(err)
+	quorum := params.MustGetInt64(ParamOwnerQuorum, 0)
+
+	if len(owners) == 0 {
+		a.Require(quorum == 0, "root.setChainOwners: wrong quorum")
+	} else {
+		a.Require(len(owners) <= MaxChainOwners, "root.setChainOwners: too many owners")
+		a.Require(quorum > 0 && quorum <= int64(len(owners)), "root.setChainOwners: wrong quorum")
+		seen := make(map[coretypes.AgentID]bool)
+		for _, o := range owners {
+			a.Require(!seen[o], "root.setChainOwners: duplicate owner %s", o.String())
+			seen[o] = true
+		}
+	}
+	setChainOwnersIntern(ctx.State(), owners, quorum)
+	ctx.Event(fmt.Sprintf("[set chain owners] %d of %d", quorum, len(owners)))
+	return nil, nil
+}
+
+// proposeOwnerAction proposes the call to the owner-only entry point of the core contract on behalf of the owner set.
+// The proposer approves it. The call is made immediately if the quorum is 1
+// Input:
+//  - ParamProposalContract coretypes.Hname the core contract. Default is the 'root'
+//  - ParamProposalTarget coretypes.Hname the entry point of the contract
+//  - ParamProposalTTL int64 number of seconds the proposal can be approved. Default is DefaultProposalTTL
+//  - any other parameters are passed to the call
+// Output:
+//  - ParamProposalID int64 ID of the proposal
+func proposeOwnerAction(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	state := ctx.State()
+	_, quorum := GetChainOwners(state)
+	a.Require(quorum > 0, "root.proposeOwnerAction: the chain has no owner set")
+	a.Require(isInOwnerSet(state, ctx.Caller()), "root.proposeOwnerAction: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	contract := params.MustGetHname(ParamProposalContract, Interface.Hname())
+	target := params.MustGetHname(ParamProposalTarget)
+	a.Require(isOwnerProposalTarget(contract, target), "root.proposeOwnerAction: wrong target entry point %s::%s",
+		contract.String(), target.String())
+	ttl := params.MustGetInt64(ParamProposalTTL, DefaultProposalTTL)
+	a.Require(ttl > 0 && ttl <= MaxProposalTTL, "root.proposeOwnerAction: wrong ttl %d", ttl)
+
+	callParams := ctx.Params().Clone()
+	callParams.Del(ParamProposalContract)
+	callParams.Del(ParamProposalTarget)
+	callParams.Del(ParamProposalTTL)
+
+	pruneOwnerProposals(state, ctx.GetTimestamp())
+
+	stateDecoder := kvdecoder.New(state, ctx.Log())
+	id := stateDecoder.MustGetInt64(VarOwnerProposalCounter, 0)
+	state.Set(VarOwnerProposalCounter, codec.EncodeInt64(id+1))
+	p := &OwnerProposal{
+		ID:        uint32(id),
+		Proposer:  ctx.Caller(),
+		Contract:  contract,
+		Target:    target,
+		Params:    callParams,
+		Deadline:  ctx.GetTimestamp() + ttl*int64(time.Second),
+		Approvals: []coretypes.AgentID{ctx.Caller()},
+	}
+	ctx.Event(fmt.Sprintf("[owner proposal] %s", p.String()))
+	if _, err := executeOwnerProposalIfApproved(ctx, p, quorum); err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamProposalID, codec.EncodeInt64(id))
+	return ret, nil
+}
+
+// approveOwnerAction approves the pending proposal. The call is made once the quorum of approvals is reached.
+// If the call fails, the approval is not stored
+// Input:
+//  - ParamProposalID int64 ID of the proposal
+// Output: the result of the call, if made
+func approveOwnerAction(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	state := ctx.State()
+	_, quorum := GetChainOwners(state)
+	a.Require(quorum > 0, "root.approveOwnerAction: the chain has no owner set")
+	a.Require(isInOwnerSet(state, ctx.Caller()), "root.approveOwnerAction: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id := params.MustGetInt64(ParamProposalID)
+	p, ok := getOwnerProposal(state, uint32(id))
+	a.Require(ok, "root.approveOwnerAction: proposal #%d not found", id)
+	a.Require(p.Deadline >= ctx.GetTimestamp(), "root.approveOwnerAction: proposal #%d expired", id)
+	a.Require(!p.IsApprovedBy(ctx.Caller()), "root.approveOwnerAction: proposal #%d already approved by %s", id, ctx.Caller().String())
+
+	p.Approvals = append(p.Approvals, ctx.Caller())
+	ctx.Event(fmt.Sprintf("[owner approval] #%d by %s", id, ctx.Caller().String()))
+	return executeOwnerProposalIfApproved(ctx, p, quorum)
+}
+
+// getOwnerProposals returns the M-of-N owner set and pending (not expired) proposals
+// Output:
+//  - ParamChainOwners []coretypes.AgentID encoded with EncodeChainOwners
+//  - ParamOwnerQuorum int64
+//  - ParamProposals: array of encoded OwnerProposal, ordered by ID
+func getOwnerProposals(ctx coretypes.SandboxView) (dict.Dict, error) {
+	owners, quorum := GetChainOwners(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamChainOwners, EncodeChainOwners(owners))
+	ret.Set(ParamOwnerQuorum, codec.EncodeInt64(quorum))
+	arr := collections.NewArray(ret, ParamProposals)
+	for _, p := range GetOwnerProposals(ctx.State()) {
+		if p.Deadline >= ctx.GetTimestamp() {
+			arr.MustPush(EncodeOwnerProposal(p))
+		}
+	}
+	return ret, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"io"
+	"sort"
 
 	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
 	"github.com/iotaledger/wasp/packages/hashing"
@@ -14,7 +15,7 @@ import (
 )
 
 const (
-	Name        = "root"
+	Name        = coreutil.CoreContractRoot
 	description = "Root Contract"
 )
 
@@ -30,15 +31,30 @@ var (
 func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.Func(FuncDeployContract, deployContract),
+		coreutil.Func(FuncUpgradeContract, upgradeContract),
 		coreutil.ViewFunc(FuncFindContract, findContract),
+		coreutil.ViewFunc(FuncCountProgramReferences, countProgramReferences),
 		coreutil.Func(FuncClaimChainOwnership, claimChainOwnership),
 		coreutil.Func(FuncDelegateChainOwnership, delegateChainOwnership),
 		coreutil.ViewFunc(FuncGetChainInfo, getChainInfo),
 		coreutil.ViewFunc(FuncGetFeeInfo, getFeeInfo),
 		coreutil.Func(FuncSetDefaultFee, setDefaultFee),
 		coreutil.Func(FuncSetContractFee, setContractFee),
+		coreutil.Func(FuncRemoveEntryPointFee, removeEntryPointFee),
 		coreutil.Func(FuncGrantDeploy, grantDeployPermission),
 		coreutil.Func(FuncRevokeDeploy, revokeDeployPermission),
+		coreutil.Func(FuncAllowProgram, allowProgram),
+		coreutil.Func(FuncDisallowProgram, disallowProgram),
+		coreutil.Func(FuncSetDeployQuota, setDeployQuota),
+		coreutil.ViewFunc(FuncGetDeployPolicy, getDeployPolicy),
+		coreutil.ViewFunc(FuncGetDeployerInfo, getDeployerInfo),
+		coreutil.Func(FuncSetGasBudget, setGasBudget),
+		coreutil.Func(FuncRotateCommittee, rotateCommittee),
+		coreutil.Func(FuncSetFeeShortfallPolicy, setFeeShortfallPolicy),
+		coreutil.Func(FuncSetChainOwners, setChainOwners),
+		coreutil.Func(FuncProposeOwnerAction, proposeOwnerAction),
+		coreutil.Func(FuncApproveOwnerAction, approveOwnerAction),
+		coreutil.ViewFunc(FuncGetOwnerProposals, getOwnerProposals),
 	})
 }
 
@@ -56,28 +72,59 @@ const (
 	VarContractRegistry      = "r"
 	VarDescription           = "d"
 	VarDeployPermissions     = "dep"
+	VarGasBudget             = "gb"
+	VarFeeShortfallPolicy    = "fs"
+	VarFeeShortfallPenalty   = "fp"
+	VarChainOwners           = "os"
+	VarOwnerQuorum           = "oq"
+	VarOwnerProposals        = "op"
+	VarOwnerProposalCounter  = "opn"
+	VarAllowedPrograms       = "ap"
+	VarAllowedVMTypes        = "av"
+	VarDefaultDeployQuota    = "dq"
+	VarDeployQuotas          = "dqs"
+	VarDeployCounts          = "dc"
 )
 
 // param variables
 const (
-	ParamChainID      = "$$chainid$$"
-	ParamChainColor   = "$$color$$"
-	ParamChainAddress = "$$address$$"
-	ParamChainOwner   = "$$owner$$"
-	ParamProgramHash  = "$$proghash$$"
-	ParamDescription  = "$$description$$"
-	ParamHname        = "$$hname$$"
-	ParamName         = "$$name$$"
-	ParamData         = "$$data$$"
-	ParamFeeColor     = "$$feecolor$$"
-	ParamOwnerFee     = "$$ownerfee$$"
-	ParamValidatorFee = "$$validatorfee$$"
-	ParamDeployer     = "$$deployer$$"
+	ParamChainID             = "$$chainid$$"
+	ParamChainColor          = "$$color$$"
+	ParamChainAddress        = "$$address$$"
+	ParamChainOwner          = "$$owner$$"
+	ParamProgramHash         = "$$proghash$$"
+	ParamDescription         = "$$description$$"
+	ParamHname               = "$$hname$$"
+	ParamName                = "$$name$$"
+	ParamData                = "$$data$$"
+	ParamFeeColor            = "$$feecolor$$"
+	ParamOwnerFee            = "$$ownerfee$$"
+	ParamValidatorFee        = "$$validatorfee$$"
+	ParamDeployer            = "$$deployer$$"
+	ParamGasBudget           = "$$gasbudget$$"
+	ParamFeeShortfallPolicy  = "$$feeshortfallpolicy$$"
+	ParamFeeShortfallPenalty = "$$feeshortfallpenalty$$"
+	ParamEntryPoint          = "$$entrypoint$$"
+	ParamFeeMode             = "$$feemode$$"
+	ParamChainOwners         = "$$owners$$"
+	ParamOwnerQuorum         = "$$quorum$$"
+	ParamProposalID          = "$$proposalid$$"
+	ParamProposalContract    = "$$proposalcontract$$"
+	ParamProposalTarget      = "$$proposaltarget$$"
+	ParamProposalTTL         = "$$proposalttl$$"
+	ParamProposals           = "$$proposals$$"
+	ParamVMType              = "$$vmtype$$"
+	ParamDeployQuota         = "$$deployquota$$"
+	ParamDeployCount         = "$$deploycount$$"
+	ParamDeployPermission    = "$$deploypermission$$"
+	ParamAllowedPrograms     = "$$allowedprograms$$"
+	ParamAllowedVMTypes      = "$$allowedvmtypes$$"
 )
 
 // function names
 const (
 	FuncDeployContract         = "deployContract"
+	FuncUpgradeContract        = "upgradeContract"
 	FuncFindContract           = "findContract"
 	FuncGetChainInfo           = "getChainInfo"
 	FuncDelegateChainOwnership = "delegateChainOwnership"
@@ -85,10 +132,66 @@ const (
 	FuncGetFeeInfo             = "getFeeInfo"
 	FuncSetDefaultFee          = "setDefaultFee"
 	FuncSetContractFee         = "setContractFee"
+	FuncRemoveEntryPointFee    = "removeEntryPointFee"
 	FuncGrantDeploy            = "grantDeployPermission"
 	FuncRevokeDeploy           = "revokeDeployPermission"
+	FuncSetGasBudget           = "setGasBudget"
+	FuncRotateCommittee        = "rotateCommittee"
+	FuncSetFeeShortfallPolicy  = "setFeeShortfallPolicy"
+	FuncSetChainOwners         = "setChainOwners"
+	FuncProposeOwnerAction     = "proposeOwnerAction"
+	FuncApproveOwnerAction     = "approveOwnerAction"
+	FuncGetOwnerProposals      = "getOwnerProposals"
+	FuncAllowProgram           = "allowProgram"
+	FuncDisallowProgram        = "disallowProgram"
+	FuncSetDeployQuota         = "setDeployQuota"
+	FuncGetDeployPolicy        = "getDeployPolicy"
+	FuncGetDeployerInfo        = "getDeployerInfo"
+	FuncCountProgramReferences = coreutil.CoreFuncRootCountProgramReferences
 )
 
+// fee shortfall policies: how the request which doesn't carry enough fees is handled.
+// In any case the request is not called
+const (
+	// the whole transfer is accrued to the sender on-chain. The default
+	FeeShortfallRefund = "refund"
+	// all fee tokens of the transfer are charged and split between the chain owner and the validator
+	// in proportion of their fees. The rest is accrued to the sender on-chain
+	FeeShortfallChargeAvailable = "chargeAvailable"
+	// the fixed penalty, but not more than the fee tokens of the transfer, is charged and split between
+	// the chain owner and the validator in proportion of their fees. The rest is accrued to the sender on-chain
+	FeeShortfallPenalty = "penalty"
+)
+
+// fee modes of the contract or of its entry point
+const (
+	// fees are fixed amounts of fee tokens. The default
+	FeeModeFlat = "flat"
+	// fees are in basis points (1/100 of percent) of the amount of fee tokens transferred with the request
+	FeeModeBasisPoints = "bp"
+)
+
+const (
+	// MaxChainOwners is the maximum size of the M-of-N owner set
+	MaxChainOwners = 32
+	// DefaultProposalTTL is the time in seconds the owner proposal can be approved, if not specified otherwise
+	DefaultProposalTTL = 24 * 60 * 60
+	// MaxProposalTTL is the maximum time in seconds the owner proposal can be approved
+	MaxProposalTTL = 30 * 24 * 60 * 60
+)
+
+// MaxBasisPoints is 100%. The sum of owner and validator fees in basis points can't be larger
+const MaxBasisPoints = 10000
+
+// EntryPointFee is the fee of the particular entry point of the contract.
+// It overrides the fees of the contract, including zero values
+type EntryPointFee struct {
+	OwnerFee     int64
+	ValidatorFee int64
+	// if true, fees are in basis points of the transferred fee tokens
+	BasisPoints bool
+}
+
 // ContractRecord is a structure which contains metadata of the deployed contract instance
 type ContractRecord struct {
 	// The ProgramHash uniquely defines the program of the smart contract
@@ -110,6 +213,11 @@ type ContractRecord struct {
 	// The agentID of the entity which deployed the instance. It can be interpreted as
 	// an priviledged user of the instance, however it is up to the smart contract.
 	Creator coretypes.AgentID
+	// If true, OwnerFee and ValidatorFee are in basis points of the fee tokens transferred with the request.
+	// Chain-global defaults are not in effect then
+	FeeBasisPoints bool
+	// Fees of the particular entry points. They override the fees of the contract
+	EntryPointFees map[coretypes.Hname]*EntryPointFee
 }
 
 // ChainInfo is an API structure which contains main properties of the chain in on place
@@ -122,6 +230,12 @@ type ChainInfo struct {
 	FeeColor            balance.Color
 	DefaultOwnerFee     int64
 	DefaultValidatorFee int64
+	GasBudget           int64
+	FeeShortfallPolicy  string
+	FeeShortfallPenalty int64
+	// M-of-N owner set. Empty if the chain is governed by the single chain owner
+	ChainOwners []coretypes.AgentID
+	OwnerQuorum int64
 }
 
 func (p *ContractRecord) Hname() coretypes.Hname {
@@ -148,6 +262,33 @@ func (p *ContractRecord) Write(w io.Writer) error {
 	if _, err := w.Write(p.Creator[:]); err != nil {
 		return err
 	}
+	if err := util.WriteBoolByte(w, p.FeeBasisPoints); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(p.EntryPointFees))); err != nil {
+		return err
+	}
+	// deterministic order
+	entryPoints := make([]coretypes.Hname, 0, len(p.EntryPointFees))
+	for ep := range p.EntryPointFees {
+		entryPoints = append(entryPoints, ep)
+	}
+	sort.Slice(entryPoints, func(i, j int) bool { return entryPoints[i] < entryPoints[j] })
+	for _, ep := range entryPoints {
+		fee := p.EntryPointFees[ep]
+		if err := ep.Write(w); err != nil {
+			return err
+		}
+		if err := util.WriteInt64(w, fee.OwnerFee); err != nil {
+			return err
+		}
+		if err := util.WriteInt64(w, fee.ValidatorFee); err != nil {
+			return err
+		}
+		if err := util.WriteBoolByte(w, fee.BasisPoints); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -171,6 +312,38 @@ func (p *ContractRecord) Read(r io.Reader) error {
 	if err := coretypes.ReadAgentID(r, &p.Creator); err != nil {
 		return err
 	}
+	if err := util.ReadBoolByte(r, &p.FeeBasisPoints); err != nil {
+		if err != io.EOF {
+			return err
+		}
+		// legacy record without fee mode and entry point fees
+		p.FeeBasisPoints = false
+		return nil
+	}
+	var numEntryPoints uint16
+	if err := util.ReadUint16(r, &numEntryPoints); err != nil {
+		return err
+	}
+	if numEntryPoints > 0 {
+		p.EntryPointFees = make(map[coretypes.Hname]*EntryPointFee)
+	}
+	for i := uint16(0); i < numEntryPoints; i++ {
+		var ep coretypes.Hname
+		if err := ep.Read(r); err != nil {
+			return err
+		}
+		fee := &EntryPointFee{}
+		if err := util.ReadInt64(r, &fee.OwnerFee); err != nil {
+			return err
+		}
+		if err := util.ReadInt64(r, &fee.ValidatorFee); err != nil {
+			return err
+		}
+		if err := util.ReadBoolByte(r, &fee.BasisPoints); err != nil {
+			return err
+		}
+		p.EntryPointFees[ep] = fee
+	}
 	return nil
 }
 
@@ -2,13 +2,21 @@ package root
 
 import (
 	"fmt"
+	"sort"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
 )
 
 // FindContract is an internal utility function which finds a contract in the KVStore
@@ -33,6 +41,33 @@ func FindContract(state kv.KVStoreReader, hname coretypes.Hname) (*ContractRecor
 	return ret, nil
 }
 
+// NewContractRecords returns records of the contracts which are present in the registry of 'updated'
+// but not in the registry of 'prev', sorted by hname. Both are partitions of the 'root' contract.
+// It is used outside the VM to find contracts deployed by the block, with 'updated' holding the block's mutations
+func NewContractRecords(prev, updated kv.KVStoreReader) ([]*ContractRecord, error) {
+	prevRegistry := collections.NewMapReadOnly(prev, VarContractRegistry)
+	ret := make([]*ContractRecord, 0)
+	var err error
+	collections.NewMapReadOnly(updated, VarContractRegistry).MustIterate(func(elemKey []byte, value []byte) bool {
+		if prevRegistry.MustHasAt(elemKey) {
+			return true
+		}
+		var rec *ContractRecord
+		if rec, err = DecodeContractRecord(value); err != nil {
+			return false
+		}
+		ret = append(ret, rec)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("root: %v", err)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return coretypes.Hn(ret[i].Name) < coretypes.Hn(ret[j].Name)
+	})
+	return ret, nil
+}
+
 // MustGetChainInfo return global variables of the chain
 func MustGetChainInfo(state kv.KVStoreReader) ChainInfo {
 	d := kvdecoder.New(state)
@@ -45,14 +80,29 @@ func MustGetChainInfo(state kv.KVStoreReader) ChainInfo {
 		FeeColor:            d.MustGetColor(VarFeeColor, balance.ColorIOTA),
 		DefaultOwnerFee:     d.MustGetInt64(VarDefaultOwnerFee, 0),
 		DefaultValidatorFee: d.MustGetInt64(VarDefaultValidatorFee, 0),
+		GasBudget:           d.MustGetInt64(VarGasBudget, coretypes.DefaultGasBudget),
+		FeeShortfallPolicy:  d.MustGetString(VarFeeShortfallPolicy, FeeShortfallRefund),
+		FeeShortfallPenalty: d.MustGetInt64(VarFeeShortfallPenalty, 0),
 	}
+	ret.ChainOwners, ret.OwnerQuorum = GetChainOwners(state)
 	return ret
 }
 
-// GetFeeInfo is an internal utility function which returns fee info for the contract
+// GetChainAddress returns the address which holds the chain token and all assets of the chain.
+// Returns false if the chain is not initialized yet
+func GetChainAddress(state kv.KVStoreReader) (address.Address, bool) {
+	ret, ok, err := codec.DecodeAddress(state.MustGet(VarChainAddress))
+	if err != nil || !ok {
+		return address.Address{}, false
+	}
+	return ret, true
+}
+
+// GetFeeInfo is an internal utility function which returns fee info for the entry point of the contract
 // It is called from within the 'root' contract as well as VMContext and viewcontext objects
 // It is not exposed to the sandbox
-func GetFeeInfo(state kv.KVStoreReader, hname coretypes.Hname) (balance.Color, int64, int64) {
+// If the last return value is true, fees are in basis points of the fee tokens transferred with the request
+func GetFeeInfo(state kv.KVStoreReader, hname coretypes.Hname, entryPoint coretypes.Hname) (balance.Color, int64, int64, bool) {
 	//returns nil of contract not found
 	rec, err := FindContract(state, hname)
 	if err != nil {
@@ -62,26 +112,56 @@ func GetFeeInfo(state kv.KVStoreReader, hname coretypes.Hname) (balance.Color, i
 			rec = nil
 		}
 	}
-	return GetFeeInfoByContractRecord(state, rec)
+	return GetFeeInfoByContractRecord(state, rec, entryPoint)
 }
 
-func GetFeeInfoByContractRecord(state kv.KVStoreReader, rec *ContractRecord) (balance.Color, int64, int64) {
-	var ownerFee, validatorFee int64
-	if rec != nil {
-		ownerFee = rec.OwnerFee
-		validatorFee = rec.ValidatorFee
-	}
+// GetFeeInfoByContractRecord returns fees of the entry point of the contract.
+// The fee of the entry point overrides fees of the contract. Zero flat fees of the contract
+// fall back to chain-global defaults
+func GetFeeInfoByContractRecord(state kv.KVStoreReader, rec *ContractRecord, entryPoint coretypes.Hname) (balance.Color, int64, int64, bool) {
 	feeColor, defaultOwnerFee, defaultValidatorFee, err := GetDefaultFeeInfo(state)
 	if err != nil {
 		panic(err)
 	}
+	if rec == nil {
+		return feeColor, defaultOwnerFee, defaultValidatorFee, false
+	}
+	if fee, ok := rec.EntryPointFees[entryPoint]; ok {
+		return feeColor, fee.OwnerFee, fee.ValidatorFee, fee.BasisPoints
+	}
+	if rec.FeeBasisPoints {
+		return feeColor, rec.OwnerFee, rec.ValidatorFee, true
+	}
+	ownerFee := rec.OwnerFee
+	validatorFee := rec.ValidatorFee
 	if ownerFee == 0 {
 		ownerFee = defaultOwnerFee
 	}
 	if validatorFee == 0 {
 		validatorFee = defaultValidatorFee
 	}
-	return feeColor, ownerFee, validatorFee
+	return feeColor, ownerFee, validatorFee, false
+}
+
+// FeeFromBasisPoints calculates the fee in basis points of the amount. Rounded down
+func FeeFromBasisPoints(amount int64, basisPoints int64) int64 {
+	return (amount/MaxBasisPoints)*basisPoints + (amount%MaxBasisPoints)*basisPoints/MaxBasisPoints
+}
+
+// FeeModeString returns the name of the fee mode
+func FeeModeString(basisPoints bool) string {
+	if basisPoints {
+		return FeeModeBasisPoints
+	}
+	return FeeModeFlat
+}
+
+// checkFees checks the fee values in the mode
+func checkFees(ownerFee, validatorFee int64, basisPoints bool) bool {
+	if ownerFee < 0 || validatorFee < 0 {
+		return false
+	}
+	return !basisPoints || ownerFee+validatorFee <= MaxBasisPoints
 }
 
 func GetDefaultFeeInfo(state kv.KVStoreReader) (balance.Color, int64, int64, error) {
@@ -126,7 +206,18 @@ func DecodeContractRegistry(contractRegistry *collections.ImmutableMap) (map[cor
 	return ret, err
 }
 
-func CheckAuthorizationByChainOwner(state kv.KVStore, agentID coretypes.AgentID) bool {
+// CheckAuthorizationByChainOwner checks if the agent is authorized to act as the owner of the chain.
+// It is the only place where the ownership of the chain is checked, other contracts use it through the sandbox
+func CheckAuthorizationByChainOwner(state kv.KVStoreReader, agentID coretypes.AgentID) bool {
+	if _, quorum := GetChainOwners(state); quorum > 0 {
+		// the chain is governed by the M-of-N owner set: only approved proposals are authorized.
+		// They are called by the 'root' itself
+		chainID, _, err := codec.DecodeChainID(state.MustGet(VarChainID))
+		if err != nil {
+			panic(err)
+		}
+		return agentID == coretypes.NewAgentIDFromContractID(coretypes.NewContractID(chainID, Interface.Hname()))
+	}
 	currentOwner, _, err := codec.DecodeAgentID(state.MustGet(VarChainOwnerID))
 	if err != nil {
 		panic(err)
@@ -151,10 +242,27 @@ func storeAndInitContract(ctx coretypes.Sandbox, rec *ContractRecord, initParams
 	return err
 }
 
+// isCoreContract checks if the contract is one of the core contracts deployed with the chain
+func isCoreContract(hname coretypes.Hname) bool {
+	_, ok := getCoreContractInterface(hname)
+	return ok
+}
+
+func getCoreContractInterface(hname coretypes.Hname) (*coreutil.ContractInterface, bool) {
+	for _, i := range []*coreutil.ContractInterface{
+		Interface, accounts.Interface, blob.Interface, eventlog.Interface, blocklog.Interface, governance.Interface,
+	} {
+		if i.Hname() == hname {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
 // isAuthorizedToDeploy checks if caller is authorized to deploy smart contract
 func isAuthorizedToDeploy(ctx coretypes.Sandbox) bool {
 	caller := ctx.Caller()
-	if caller == ctx.ChainOwnerID() {
+	if CheckAuthorizationByChainOwner(ctx.State(), caller) {
 		// chain owner is always authorized
 		return true
 	}
@@ -165,3 +273,32 @@ func isAuthorizedToDeploy(ctx coretypes.Sandbox) bool {
 
 	return collections.NewMap(ctx.State(), VarDeployPermissions).MustHasAt(caller[:])
 }
+
+// executeOwnerProposalIfApproved makes the call of the proposal if it has enough approvals.
+// Otherwise the proposal is stored
+func executeOwnerProposalIfApproved(ctx coretypes.Sandbox, p *OwnerProposal, quorum int64) (dict.Dict, error) {
+	if int64(len(p.Approvals)) < quorum {
+		storeOwnerProposal(ctx.State(), p)
+		return nil, nil
+	}
+	deleteOwnerProposal(ctx.State(), p.ID)
+	ctx.Event(fmt.Sprintf("[owner proposal executed] #%d: %s::%s", p.ID, p.Contract.String(), p.Target.String()))
+	return ctx.Call(p.Contract, p.Target, p.Params, nil)
+}
+
+// setChainOwnersIntern stores the owner set and removes all pending proposals
+func setChainOwnersIntern(state kv.KVStore, owners []coretypes.AgentID, quorum int64) {
+	arr := collections.NewArray(state, VarChainOwners)
+	arr.MustErase()
+	for _, o := range owners {
+		arr.MustPush(codec.EncodeAgentID(o))
+	}
+	if quorum > 0 {
+		state.Set(VarOwnerQuorum, codec.EncodeInt64(quorum))
+	} else {
+		state.Del(VarOwnerQuorum)
+	}
+	for _, p := range GetOwnerProposals(state) {
+		deleteOwnerProposal(state, p.ID)
+	}
+}
@@ -0,0 +1,244 @@
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// OwnerProposal is a pending call to the owner-only entry point of one of the core contracts
+// proposed by one of the chain owners of the M-of-N owner set.
+// The call is made by the 'root' contract itself once M distinct owners approve it before the deadline
+type OwnerProposal struct {
+	ID       uint32
+	Proposer coretypes.AgentID
+	// core contract and its entry point
+	Contract coretypes.Hname
+	Target   coretypes.Hname
+	// parameters of the call
+	Params dict.Dict
+	// timestamp (nanoseconds) after which the proposal can't be approved
+	Deadline  int64
+	Approvals []coretypes.AgentID
+}
+
+// IsApprovedBy checks if the agent approved the proposal
+func (p *OwnerProposal) IsApprovedBy(agentID coretypes.AgentID) bool {
+	for _, a := range p.Approvals {
+		if a == agentID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *OwnerProposal) String() string {
+	return fmt.Sprintf("#%d: %s::%s, proposer: %s, deadline: %d, approvals: %d",
+		p.ID, p.Contract, p.Target, p.Proposer, p.Deadline, len(p.Approvals))
+}
+
+// serde
+func (p *OwnerProposal) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, p.ID); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.Proposer[:]); err != nil {
+		return err
+	}
+	if err := p.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := p.Target.Write(w); err != nil {
+		return err
+	}
+	params := p.Params
+	if params == nil {
+		params = dict.New()
+	}
+	if err := params.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, p.Deadline); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(p.Approvals))); err != nil {
+		return err
+	}
+	for i := range p.Approvals {
+		if _, err := w.Write(p.Approvals[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *OwnerProposal) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &p.ID); err != nil {
+		return err
+	}
+	if err := coretypes.ReadAgentID(r, &p.Proposer); err != nil {
+		return err
+	}
+	if err := p.Contract.Read(r); err != nil {
+		return err
+	}
+	if err := p.Target.Read(r); err != nil {
+		return err
+	}
+	p.Params = dict.New()
+	if err := p.Params.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &p.Deadline); err != nil {
+		return err
+	}
+	var numApprovals uint16
+	if err := util.ReadUint16(r, &numApprovals); err != nil {
+		return err
+	}
+	p.Approvals = make([]coretypes.AgentID, numApprovals)
+	for i := range p.Approvals {
+		if err := coretypes.ReadAgentID(r, &p.Approvals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func EncodeOwnerProposal(p *OwnerProposal) []byte {
+	return util.MustBytes(p)
+}
+
+func DecodeOwnerProposal(data []byte) (*OwnerProposal, error) {
+	ret := new(OwnerProposal)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// EncodeChainOwners encodes the owner set into the value of ParamChainOwners
+func EncodeChainOwners(owners []coretypes.AgentID) []byte {
+	var buf bytes.Buffer
+	for i := range owners {
+		buf.Write(owners[i][:])
+	}
+	return buf.Bytes()
+}
+
+// DecodeChainOwners decodes the value of ParamChainOwners
+func DecodeChainOwners(data []byte) ([]coretypes.AgentID, error) {
+	if len(data)%coretypes.AgentIDLength != 0 {
+		return nil, fmt.Errorf("DecodeChainOwners: wrong data length")
+	}
+	ret := make([]coretypes.AgentID, len(data)/coretypes.AgentIDLength)
+	r := bytes.NewReader(data)
+	for i := range ret {
+		if err := coretypes.ReadAgentID(r, &ret[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// GetChainOwners returns the M-of-N owner set of the chain and M.
+// Empty set means the chain is governed by the single chain owner
+func GetChainOwners(state kv.KVStoreReader) ([]coretypes.AgentID, int64) {
+	arr := collections.NewArrayReadOnly(state, VarChainOwners)
+	ret := make([]coretypes.AgentID, arr.MustLen())
+	for i := range ret {
+		a, _, err := codec.DecodeAgentID(arr.MustGetAt(uint16(i)))
+		if err != nil {
+			panic(err)
+		}
+		ret[i] = a
+	}
+	quorum, _, err := codec.DecodeInt64(state.MustGet(VarOwnerQuorum))
+	if err != nil {
+		panic(err)
+	}
+	return ret, quorum
+}
+
+func isInOwnerSet(state kv.KVStoreReader, agentID coretypes.AgentID) bool {
+	owners, _ := GetChainOwners(state)
+	for _, o := range owners {
+		if o == agentID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOwnerProposals returns all stored proposals ordered by ID, including expired ones
+func GetOwnerProposals(state kv.KVStoreReader) []*OwnerProposal {
+	ret := make([]*OwnerProposal, 0)
+	collections.NewMapReadOnly(state, VarOwnerProposals).MustIterate(func(_ []byte, value []byte) bool {
+		p, err := DecodeOwnerProposal(value)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, p)
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+func getOwnerProposal(state kv.KVStoreReader, id uint32) (*OwnerProposal, bool) {
+	data := collections.NewMapReadOnly(state, VarOwnerProposals).MustGetAt(util.Uint32To4Bytes(id))
+	if data == nil {
+		return nil, false
+	}
+	ret, err := DecodeOwnerProposal(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+func storeOwnerProposal(state kv.KVStore, p *OwnerProposal) {
+	collections.NewMap(state, VarOwnerProposals).MustSetAt(util.Uint32To4Bytes(p.ID), EncodeOwnerProposal(p))
+}
+
+func deleteOwnerProposal(state kv.KVStore, id uint32) {
+	collections.NewMap(state, VarOwnerProposals).MustDelAt(util.Uint32To4Bytes(id))
+}
+
+// pruneOwnerProposals removes proposals expired at the moment ts
+func pruneOwnerProposals(state kv.KVStore, ts int64) {
+	for _, p := range GetOwnerProposals(state) {
+		if p.Deadline < ts {
+			deleteOwnerProposal(state, p.ID)
+		}
+	}
+}
+
+// isOwnerProposalTarget checks if the entry point of the core contract can be called by the proposal
+func isOwnerProposalTarget(contract coretypes.Hname, entryPoint coretypes.Hname) bool {
+	if entryPoint == coretypes.EntryPointInit {
+		return false
+	}
+	i, ok := getCoreContractInterface(contract)
+	if !ok {
+		return false
+	}
+	ep, ok := i.GetEntryPoint(entryPoint)
+	if !ok || ep.IsView() {
+		return false
+	}
+	if contract != Interface.Hname() {
+		return true
+	}
+	switch entryPoint {
+	case coretypes.Hn(FuncProposeOwnerAction), coretypes.Hn(FuncApproveOwnerAction), coretypes.Hn(FuncClaimChainOwnership):
+		return false
+	}
+	return true
+}
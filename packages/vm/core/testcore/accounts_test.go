@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +96,186 @@ func TestAccountsDepositWithdrawToChainFail(t *testing.T) {
 	chain.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 42+2)
 	env.AssertAddressBalance(newOwner.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-42-2)
 }
+
+func TestAccountsWithdrawToAddressPartial(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	color, err := env.MintTokens(user, 10)
+	require.NoError(t, err)
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfers(map[balance.Color]int64{balance.ColorIOTA: 42, color: 10})
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+	chain.AssertAccountBalance(userAgentID, color, 10)
+
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{color: 4}))
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+2)
+	chain.AssertAccountBalance(userAgentID, color, 6)
+	env.AssertAddressBalance(user.Address(), color, 4)
+
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 40, color: 6}))
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+3-40)
+	chain.AssertAccountBalance(userAgentID, color, 0)
+	env.AssertAddressBalance(user.Address(), color, 10)
+	chain.CheckAccountLedger()
+}
+
+func TestAccountsWithdrawToAddressNotEnoughFunds(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 100}))
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+2)
+
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: -1}))
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+3)
+	chain.CheckAccountLedger()
+}
+
+func TestAccountsTransfer(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	target := env.NewSignatureSchemeWithFunds()
+	targetAgentID := coretypes.NewAgentIDFromAddress(target.Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+
+	err = chain.TransferOnChain(user, targetAgentID, map[balance.Color]int64{balance.ColorIOTA: 30})
+	require.NoError(t, err)
+	// the request token is credited to the sender
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1+1-30)
+	chain.AssertAccountBalance(targetAgentID, balance.ColorIOTA, 30)
+	env.AssertAddressBalance(target.Address(), balance.ColorIOTA, testutil.RequestFundsAmount)
+	chain.CheckAccountLedger()
+
+	events := chain.GetEvents(eventlog.ParamContractHname, accounts.Interface.Hname(), eventlog.ParamTopic, targetAgentID[:])
+	require.Len(t, events, 1)
+	require.EqualValues(t, accounts.FuncTransfer, events[0].Name)
+	require.EqualValues(t, userAgentID[:], events[0].Topics[0])
+	bals, err := accounts.DecodeBalances(events[0].Payload)
+	require.NoError(t, err)
+	require.EqualValues(t, map[balance.Color]int64{balance.ColorIOTA: 30}, bals)
+}
+
+func TestAccountsTransferNotEnoughFunds(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	target := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+
+	err := chain.TransferOnChain(user, target, map[balance.Color]int64{balance.ColorIOTA: 30})
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1)
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 0)
+	chain.CheckAccountLedger()
+}
+
+func TestAccountsTransferWrongAmount(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	target := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+
+	err := chain.TransferOnChain(user, target, map[balance.Color]int64{balance.ColorIOTA: 0})
+	require.Error(t, err)
+	err = chain.TransferOnChain(user, target, map[balance.Color]int64{balance.ColorIOTA: -1})
+	require.Error(t, err)
+	err = chain.TransferOnChain(user, target, nil)
+	require.Error(t, err)
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 0)
+	chain.CheckAccountLedger()
+}
+
+func TestAccountsHistory(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	targetAgentID := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	err = chain.TransferOnChain(user, targetAgentID, map[balance.Color]int64{balance.ColorIOTA: 30})
+	require.NoError(t, err)
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToAddress)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 0)
+
+	// each request credits the request token to the sender
+	entries, n := chain.GetAccountHistory(userAgentID, 0, 100)
+	require.EqualValues(t, 6, n)
+	require.Len(t, entries, 6)
+	for _, e := range entries {
+		t.Logf("%s", e)
+	}
+	expected := []struct {
+		credit bool
+		reason string
+		amount int64
+	}{
+		{false, accounts.ReasonWithdrawal, 42 + 1 + 1 - 30 + 1},
+		{true, accounts.ReasonDeposit, 1},
+		{false, accounts.ReasonTransfer, 30},
+		{true, accounts.ReasonDeposit, 1},
+		{true, accounts.ReasonDeposit, 42},
+		{true, accounts.ReasonDeposit, 1},
+	}
+	for i, exp := range expected {
+		require.EqualValues(t, exp.credit, entries[i].Credit)
+		require.EqualValues(t, exp.reason, entries[i].Reason)
+		require.EqualValues(t, map[balance.Color]int64{balance.ColorIOTA: exp.amount}, entries[i].Balances)
+	}
+	require.EqualValues(t, targetAgentID, entries[2].Counterparty)
+	require.EqualValues(t, entries[2].RequestID, entries[3].RequestID)
+
+	entries, n = chain.GetAccountHistory(targetAgentID, 0, 100)
+	require.EqualValues(t, 1, n)
+	require.True(t, entries[0].Credit)
+	require.EqualValues(t, accounts.ReasonTransfer, entries[0].Reason)
+	require.EqualValues(t, userAgentID, entries[0].Counterparty)
+
+	// pagination
+	entries, n = chain.GetAccountHistory(userAgentID, 2, 2)
+	require.EqualValues(t, 6, n)
+	require.Len(t, entries, 2)
+	require.EqualValues(t, accounts.ReasonTransfer, entries[0].Reason)
+	require.EqualValues(t, 1, entries[1].Balances[balance.ColorIOTA])
+}
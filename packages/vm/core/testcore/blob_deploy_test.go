@@ -117,13 +117,13 @@ func TestDeployGrant(t *testing.T) {
 	require.NoError(t, err)
 
 	_, contacts := chain.GetInfo()
-	require.EqualValues(t, 5, len(contacts))
+	require.EqualValues(t, 7, len(contacts))
 
 	err = chain.DeployWasmContract(user1, "testInccounter2", wasmFile)
 	require.NoError(t, err)
 
 	_, contacts = chain.GetInfo()
-	require.EqualValues(t, 6, len(contacts))
+	require.EqualValues(t, 8, len(contacts))
 }
 
 func TestRevokeDeploy(t *testing.T) {
@@ -142,7 +142,7 @@ func TestRevokeDeploy(t *testing.T) {
 	require.NoError(t, err)
 
 	_, contacts := chain.GetInfo()
-	require.EqualValues(t, 5, len(contacts))
+	require.EqualValues(t, 7, len(contacts))
 
 	req = solo.NewCallParams(root.Interface.Name, root.FuncRevokeDeploy,
 		root.ParamDeployer, user1AgentID,
@@ -154,7 +154,7 @@ func TestRevokeDeploy(t *testing.T) {
 	require.Error(t, err)
 
 	_, contacts = chain.GetInfo()
-	require.EqualValues(t, 5, len(contacts))
+	require.EqualValues(t, 7, len(contacts))
 }
 
 func TestDeployGrantFail(t *testing.T) {
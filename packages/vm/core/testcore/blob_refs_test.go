@@ -0,0 +1,139 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/stretchr/testify/require"
+)
+
+// testVMType is the VM type of blobs which are loaded as the 'inccounter' program, whatever the binary
+const testVMType = "inccountervm"
+
+func init() {
+	err := processors.RegisterVMType(testVMType, func(_ []byte) (coretypes.Processor, error) {
+		return inccounter.Interface, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func getBlobRecord(t *testing.T, chain *solo.Chain, h hashing.HashValue) *blob.BlobRecord {
+	res, err := chain.CallView(blob.Interface.Name, blob.FuncListBlobs)
+	require.NoError(t, err)
+	blobs, err := blob.DecodeDirectory(res)
+	require.NoError(t, err)
+	return blobs[h]
+}
+
+func deleteBlob(chain *solo.Chain, h hashing.HashValue) error {
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncDeleteBlob, blob.ParamHash, h)
+	_, err := chain.PostRequestSync(req, nil)
+	return err
+}
+
+func TestBlobReferences(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	h1, err := chain.UploadBlob(nil, blob.VarFieldVMType, testVMType, blob.VarFieldProgramBinary, "program 1")
+	require.NoError(t, err)
+	h2, err := chain.UploadBlob(nil, blob.VarFieldVMType, testVMType, blob.VarFieldProgramBinary, "program 2")
+	require.NoError(t, err)
+
+	rec := getBlobRecord(t, chain, h1)
+	require.NotNil(t, rec)
+	require.EqualValues(t, len(testVMType)+len("program 1"), rec.Size)
+	require.EqualValues(t, 0, rec.References)
+	require.EqualValues(t, chain.OriginatorAgentID, rec.Uploader)
+	require.True(t, rec.Timestamp > 0 && rec.Timestamp < env.LogicalTime().UnixNano())
+
+	require.NoError(t, chain.DeployContract(nil, "inc1", h1))
+	require.NoError(t, chain.DeployContract(nil, "inc2", h1))
+	require.EqualValues(t, 2, getBlobRecord(t, chain, h1).References)
+	err = deleteBlob(chain, h1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "referenced")
+
+	require.NoError(t, chain.UpgradeContract(nil, "inc1", h2))
+	require.NoError(t, chain.UpgradeContract(nil, "inc2", h2))
+	require.EqualValues(t, 0, getBlobRecord(t, chain, h1).References)
+	require.EqualValues(t, 2, getBlobRecord(t, chain, h2).References)
+
+	require.NoError(t, deleteBlob(chain, h1))
+	require.Nil(t, getBlobRecord(t, chain, h1))
+	_, ok := chain.GetBlobInfo(h1)
+	require.False(t, ok)
+	require.Error(t, deleteBlob(chain, h1))
+	require.Error(t, deleteBlob(chain, h2))
+
+	// the references are updated by the 'root' only
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncRemoveReference, blob.ParamHash, h2)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	// the blob is stored again
+	h, err := chain.UploadBlob(nil, blob.VarFieldVMType, testVMType, blob.VarFieldProgramBinary, "program 1")
+	require.NoError(t, err)
+	require.EqualValues(t, h1, h)
+}
+
+func TestBlobDeleteAuthorization(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploader := env.NewSignatureSchemeWithFunds()
+	other := env.NewSignatureSchemeWithFunds()
+
+	h1, err := chain.UploadBlob(uploader, "data", "blob 1")
+	require.NoError(t, err)
+	h2, err := chain.UploadBlob(uploader, "data", "blob 2")
+	require.NoError(t, err)
+	require.EqualValues(t, coretypes.NewAgentIDFromAddress(uploader.Address()), getBlobRecord(t, chain, h1).Uploader)
+
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncDeleteBlob, blob.ParamHash, h1)
+	_, err = chain.PostRequestSync(req, other)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, uploader)
+	require.NoError(t, err)
+	// the chain owner
+	require.NoError(t, deleteBlob(chain, h2))
+
+	res, err := chain.CallView(blob.Interface.Name, blob.FuncListBlobs)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, len(res))
+
+	// the native program is not a blob
+	require.NoError(t, chain.DeployContract(nil, "inc", inccounter.Interface.ProgramHash))
+	require.Nil(t, getBlobRecord(t, chain, inccounter.Interface.ProgramHash))
+}
+
+func TestBlobDeleteLegacyReferences(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	h, err := chain.UploadBlob(nil, blob.VarFieldVMType, testVMType, blob.VarFieldProgramBinary, "program 1")
+	require.NoError(t, err)
+	require.NoError(t, chain.DeployContract(nil, "inc1", h))
+	require.EqualValues(t, 1, getBlobRecord(t, chain, h).References)
+
+	// blobs stored before the references were counted have no record ("m" map of the 'blob' state)
+	blobState := subrealm.New(chain.State.Variables(), kv.Key(blob.Interface.Hname().Bytes()))
+	collections.NewMap(blobState, "m").MustDelAt(h[:])
+	require.EqualValues(t, 0, getBlobRecord(t, chain, h).References)
+
+	err = deleteBlob(chain, h)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "referenced")
+	_, ok := chain.GetBlobInfo(h)
+	require.True(t, ok)
+}
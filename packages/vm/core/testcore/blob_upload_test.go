@@ -0,0 +1,227 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func appendUploadChunk(chain *solo.Chain, uploader signaturescheme.SignatureScheme, h hashing.HashValue, field string, offset int, data []byte) error {
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncAppendChunk,
+		blob.ParamHash, h,
+		blob.ParamField, []byte(field),
+		blob.ParamOffset, offset,
+		blob.ParamBytes, data,
+	)
+	_, err := chain.PostRequestSync(req, uploader)
+	return err
+}
+
+func postUploadRequest(chain *solo.Chain, uploader signaturescheme.SignatureScheme, funName string, h hashing.HashValue) error {
+	req := solo.NewCallParams(blob.Interface.Name, funName, blob.ParamHash, h)
+	_, err := chain.PostRequestSync(req, uploader)
+	return err
+}
+
+func getUpload(t *testing.T, chain *solo.Chain, uploader signaturescheme.SignatureScheme, h hashing.HashValue) *blob.Upload {
+	res, err := chain.CallView(blob.Interface.Name, blob.FuncGetUpload,
+		blob.ParamHash, h,
+		blob.ParamUploader, coretypes.NewAgentIDFromAddress(uploader.Address()),
+	)
+	require.NoError(t, err)
+	if !res.MustHas(blob.ParamUpload) {
+		return nil
+	}
+	u, err := blob.DecodeUpload(res.MustGet(blob.ParamUpload))
+	require.NoError(t, err)
+	return u
+}
+
+func getBlobField(t *testing.T, chain *solo.Chain, h hashing.HashValue, field string) []byte {
+	res, err := chain.CallView(blob.Interface.Name, blob.FuncGetBlobField,
+		blob.ParamHash, h,
+		blob.ParamField, field,
+	)
+	require.NoError(t, err)
+	return res.MustGet(blob.ParamBytes)
+}
+
+func TestBlobUploadChunked(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	binary := make([]byte, 1000)
+	for i := range binary {
+		binary[i] = byte(i)
+	}
+	h, err := chain.UploadBlobChunked(300, nil,
+		blob.VarFieldVMType, "dummyvm",
+		blob.VarFieldProgramBinary, binary,
+	)
+	require.NoError(t, err)
+
+	require.EqualValues(t, binary, getBlobField(t, chain, h, blob.VarFieldProgramBinary))
+	require.Nil(t, getUpload(t, chain, chain.OriginatorSigScheme, h))
+
+	// same as uploaded in one request
+	h1, err := chain.UploadBlob(nil,
+		blob.VarFieldVMType, "dummyvm",
+		blob.VarFieldProgramBinary, binary,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, h, h1)
+}
+
+func TestBlobUploadResume(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploader := env.NewSignatureSchemeWithFunds()
+	data := []byte("0123456789")
+	h := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": data}))
+
+	// no upload
+	require.Error(t, appendUploadChunk(chain, uploader, h, "data", 0, data[:4]))
+
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+	require.NoError(t, appendUploadChunk(chain, uploader, h, "data", 0, data[:4]))
+	// the chunk is repeated
+	require.Error(t, appendUploadChunk(chain, uploader, h, "data", 0, data[:4]))
+	// too big chunk
+	require.Error(t, appendUploadChunk(chain, uploader, h, "data", 4, make([]byte, blob.MaxChunkSize+1)))
+	// the upload belongs to the uploader
+	require.Error(t, appendUploadChunk(chain, nil, h, "data", 4, data[4:]))
+
+	// resumed from the offset
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+	u := getUpload(t, chain, uploader, h)
+	require.NotNil(t, u)
+	require.EqualValues(t, 4, u.FieldSize([]byte("data")))
+	require.NoError(t, appendUploadChunk(chain, uploader, h, "data", 4, data[4:]))
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncFinalizeUpload, h))
+
+	require.EqualValues(t, data, getBlobField(t, chain, h, "data"))
+
+	// already exists
+	require.Error(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+}
+
+func TestBlobUploadWrongHash(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	data := []byte("0123456789")
+	h := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": data}))
+
+	require.NoError(t, postUploadRequest(chain, nil, blob.FuncBeginUpload, h))
+	require.NoError(t, appendUploadChunk(chain, nil, h, "data", 0, []byte("9876543210")))
+	err := postUploadRequest(chain, nil, blob.FuncFinalizeUpload, h)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hash mismatch")
+
+	_, ok := chain.GetBlobInfo(h)
+	require.False(t, ok)
+	require.NotNil(t, getUpload(t, chain, chain.OriginatorSigScheme, h))
+}
+
+func TestBlobUploadExpired(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploader := env.NewSignatureSchemeWithFunds()
+	data := []byte("0123456789")
+	h := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": data}))
+
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+	require.NoError(t, appendUploadChunk(chain, uploader, h, "data", 0, data[:4]))
+
+	env.AdvanceClockBy(blob.UploadTTL*time.Second + time.Second)
+	require.Error(t, appendUploadChunk(chain, uploader, h, "data", 4, data[4:]))
+	require.Error(t, postUploadRequest(chain, uploader, blob.FuncFinalizeUpload, h))
+
+	// the abandoned upload is removed when any upload begins
+	h1 := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"other": data}))
+	require.NoError(t, postUploadRequest(chain, nil, blob.FuncBeginUpload, h1))
+	require.Nil(t, getUpload(t, chain, uploader, h))
+}
+
+func TestBlobUploadEmptyField(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	h, err := chain.UploadBlobChunked(4, nil,
+		blob.VarFieldVMType, "dummyvm",
+		blob.VarFieldProgramDescription, "",
+		blob.VarFieldProgramBinary, []byte("0123456789"),
+	)
+	require.NoError(t, err)
+	require.Nil(t, getUpload(t, chain, chain.OriginatorSigScheme, h))
+
+	info, ok := chain.GetBlobInfo(h)
+	require.True(t, ok)
+	require.EqualValues(t, 0, info[blob.VarFieldProgramDescription])
+	require.EqualValues(t, 10, info[blob.VarFieldProgramBinary])
+
+	// the empty chunk only registers the field
+	data := []byte("0123456789")
+	h1 := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": data}))
+	require.NoError(t, postUploadRequest(chain, nil, blob.FuncBeginUpload, h1))
+	require.NoError(t, appendUploadChunk(chain, nil, h1, "data", 0, data[:4]))
+	require.Error(t, appendUploadChunk(chain, nil, h1, "data", 4, []byte{}))
+}
+
+func TestBlobUploadAbort(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploader := env.NewSignatureSchemeWithFunds()
+	data := []byte("0123456789")
+	h := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": data}))
+
+	require.Error(t, postUploadRequest(chain, uploader, blob.FuncAbortUpload, h))
+
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+	require.NoError(t, appendUploadChunk(chain, uploader, h, "data", 0, data[:4]))
+	// the upload belongs to the uploader
+	require.Error(t, postUploadRequest(chain, nil, blob.FuncAbortUpload, h))
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncAbortUpload, h))
+	require.Nil(t, getUpload(t, chain, uploader, h))
+	require.Error(t, postUploadRequest(chain, uploader, blob.FuncFinalizeUpload, h))
+
+	// starts from scratch
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, h))
+	require.EqualValues(t, 0, getUpload(t, chain, uploader, h).FieldSize([]byte("data")))
+	require.NoError(t, appendUploadChunk(chain, uploader, h, "data", 0, data))
+	require.NoError(t, postUploadRequest(chain, uploader, blob.FuncFinalizeUpload, h))
+	require.EqualValues(t, data, getBlobField(t, chain, h, "data"))
+}
+
+func TestBlobUploadPruneLater(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploader := env.NewSignatureSchemeWithFunds()
+	hashes := make([]hashing.HashValue, 3)
+	for i := range hashes {
+		hashes[i] = blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"data": []byte{byte(i)}}))
+		require.NoError(t, postUploadRequest(chain, uploader, blob.FuncBeginUpload, hashes[i]))
+		env.AdvanceClockBy(blob.UploadTTL * time.Second / 2)
+	}
+	// the last upload is extended
+	require.NoError(t, appendUploadChunk(chain, uploader, hashes[2], "data", 0, []byte{2}))
+
+	env.AdvanceClockBy(3 * blob.UploadTTL * time.Second / 4)
+	h := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"other": []byte{0}}))
+	require.NoError(t, postUploadRequest(chain, nil, blob.FuncBeginUpload, h))
+	require.Nil(t, getUpload(t, chain, uploader, hashes[0]))
+	require.Nil(t, getUpload(t, chain, uploader, hashes[1]))
+	require.NotNil(t, getUpload(t, chain, uploader, hashes[2]))
+
+	env.AdvanceClockBy(10 * blob.UploadTTL * time.Second)
+	require.NoError(t, postUploadRequest(chain, nil, blob.FuncBeginUpload, hashes[0]))
+	require.Nil(t, getUpload(t, chain, uploader, hashes[2]))
+	require.Nil(t, getUpload(t, chain, chain.OriginatorSigScheme, h))
+}
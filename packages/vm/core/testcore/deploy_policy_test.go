@@ -0,0 +1,214 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeployer(t *testing.T) (*solo.Solo, *solo.Chain, signaturescheme.SignatureScheme) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	deployer := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncGrantDeploy,
+		root.ParamDeployer, coretypes.NewAgentIDFromAddress(deployer.Address()),
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	return env, chain, deployer
+}
+
+func checkDeployerInfo(t *testing.T, chain *solo.Chain, deployer signaturescheme.SignatureScheme, quota, count int64) {
+	res, err := chain.CallView(root.Interface.Name, root.FuncGetDeployerInfo,
+		root.ParamDeployer, coretypes.NewAgentIDFromAddress(deployer.Address()),
+	)
+	require.NoError(t, err)
+	require.True(t, res.MustHas(root.ParamDeployPermission))
+	q, _, err := codec.DecodeInt64(res.MustGet(root.ParamDeployQuota))
+	require.NoError(t, err)
+	require.EqualValues(t, quota, q)
+	c, _, err := codec.DecodeInt64(res.MustGet(root.ParamDeployCount))
+	require.NoError(t, err)
+	require.EqualValues(t, count, c)
+}
+
+func TestDeployAllowedPrograms(t *testing.T) {
+	_, chain, deployer := setupDeployer(t)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncAllowProgram,
+		root.ParamProgramHash, inccounter.Interface.ProgramHash,
+	)
+	_, err := chain.PostRequestSync(req, deployer)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	res, err := chain.CallView(root.Interface.Name, root.FuncGetDeployPolicy)
+	require.NoError(t, err)
+	programs := collections.NewArrayReadOnly(res, root.ParamAllowedPrograms)
+	require.EqualValues(t, 1, programs.MustLen())
+	h, _, err := codec.DecodeHashValue(programs.MustGetAt(0))
+	require.NoError(t, err)
+	require.EqualValues(t, inccounter.Interface.ProgramHash, h)
+
+	err = chain.DeployContract(deployer, "inc", inccounter.Interface.ProgramHash)
+	require.NoError(t, err)
+	err = chain.DeployContract(deployer, "sb", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+	// the chain owner is not restricted
+	err = chain.DeployContract(nil, "sb", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	// the upgrade is restricted too
+	err = chain.UpgradeContract(deployer, "inc", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncDisallowProgram,
+		root.ParamProgramHash, inccounter.Interface.ProgramHash,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	// not in the allowlist anymore
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	// empty allowlists: no restriction
+	err = chain.DeployContract(deployer, "sb2", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	checkDeployerInfo(t, chain, deployer, 0, 2)
+}
+
+func TestDeployAllowedVMTypes(t *testing.T) {
+	_, chain, deployer := setupDeployer(t)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncAllowProgram, root.ParamVMType, "dummyvm")
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	res, err := chain.CallView(root.Interface.Name, root.FuncGetDeployPolicy)
+	require.NoError(t, err)
+	vmTypes := collections.NewArrayReadOnly(res, root.ParamAllowedVMTypes)
+	require.EqualValues(t, 1, vmTypes.MustLen())
+	require.EqualValues(t, "dummyvm", string(vmTypes.MustGetAt(0)))
+
+	// no blob with the allowed VM type
+	err = chain.DeployContract(deployer, "sb", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "allowlist")
+
+	// the blob passes the allowlist, but the VM can't load the program
+	progHash, err := chain.UploadBlob(nil,
+		blob.VarFieldVMType, "dummyvm",
+		blob.VarFieldProgramBinary, []byte("dummy binary"),
+	)
+	require.NoError(t, err)
+	err = chain.DeployContract(deployer, "dummy", progHash)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "allowlist")
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncAllowProgram)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+}
+
+func TestDeployQuota(t *testing.T) {
+	env, chain, deployer := setupDeployer(t)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDeployQuota, root.ParamDeployQuota, 1)
+	_, err := chain.PostRequestSync(req, deployer)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkDeployerInfo(t, chain, deployer, 1, 0)
+
+	err = chain.DeployContract(deployer, "sb1", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	err = chain.DeployContract(deployer, "sb2", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+	checkDeployerInfo(t, chain, deployer, 1, 1)
+
+	// the quota of the deployer overrides the default
+	deployerAgentID := coretypes.NewAgentIDFromAddress(deployer.Address())
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDeployQuota,
+		root.ParamDeployer, deployerAgentID,
+		root.ParamDeployQuota, 2,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	err = chain.DeployContract(deployer, "sb2", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	checkDeployerInfo(t, chain, deployer, 2, 2)
+
+	// back to the default
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDeployQuota, root.ParamDeployer, deployerAgentID)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkDeployerInfo(t, chain, deployer, 1, 2)
+
+	// the chain owner has no quota
+	err = chain.DeployContract(nil, "sb3", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "sb4", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDeployQuota, root.ParamDeployQuota, -1)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	other := env.NewSignatureSchemeWithFunds()
+	res, err := chain.CallView(root.Interface.Name, root.FuncGetDeployerInfo,
+		root.ParamDeployer, coretypes.NewAgentIDFromAddress(other.Address()),
+	)
+	require.NoError(t, err)
+	require.False(t, res.MustHas(root.ParamDeployPermission))
+}
+
+func TestDeployByChainOwners(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncAllowProgram,
+		root.ParamProgramHash, inccounter.Interface.ProgramHash,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	owners := []signaturescheme.SignatureScheme{env.NewSignatureSchemeWithFunds(), env.NewSignatureSchemeWithFunds()}
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainOwners,
+		root.ParamChainOwners, root.EncodeChainOwners([]coretypes.AgentID{
+			coretypes.NewAgentIDFromAddress(owners[0].Address()),
+			coretypes.NewAgentIDFromAddress(owners[1].Address()),
+		}),
+		root.ParamOwnerQuorum, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// the former chain owner is not authorized anymore
+	err = chain.DeployContract(nil, "sb", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+
+	// the approved proposal is not restricted by the allowlist
+	req = solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+		root.ParamProposalTarget, coretypes.Hn(root.FuncDeployContract),
+		root.ParamProgramHash, sbtestsc.Interface.ProgramHash,
+		root.ParamName, "sb",
+	)
+	_, err = chain.PostRequestSync(req, owners[0])
+	require.NoError(t, err)
+	_, contracts := chain.GetInfo()
+	_, ok := contracts[coretypes.Hn("sb")]
+	require.True(t, ok)
+}
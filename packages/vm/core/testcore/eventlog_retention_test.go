@@ -0,0 +1,212 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadDummyBlobs(t *testing.T, chain *solo.Chain, n int) {
+	for i := 0; i < n; i++ {
+		_, err := chain.UploadBlob(nil, "data", fmt.Sprintf("dummy blob %d", i), "time", chain.Env.LogicalTime().UnixNano())
+		require.NoError(t, err)
+	}
+}
+
+func getEarliestTs(t *testing.T, chain *solo.Chain, name string) int64 {
+	res, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetNumRecords, eventlog.ParamContractHname, coretypes.Hn(name))
+	require.NoError(t, err)
+	ts, _, err := codec.DecodeInt64(res.MustGet(eventlog.ParamEarliestTs))
+	require.NoError(t, err)
+	return ts
+}
+
+func TestEventLogRetentionMaxRecords(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploadDummyBlobs(t, chain, 3)
+	total := chain.GetEventLogNumRecords(blob.Interface.Name)
+	require.True(t, total > 3)
+
+	req := solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, blob.Interface.Hname(),
+		eventlog.ParamMaxRecords, 3,
+	)
+	_, err := chain.PostRequestSync(req, env.NewSignatureSchemeWithFunds())
+	require.Error(t, err)
+	require.EqualValues(t, total, chain.GetEventLogNumRecords(blob.Interface.Name))
+
+	// the log of the contract is pruned immediately
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, chain.GetEventLogNumRecords(blob.Interface.Name))
+	recs, err := chain.GetEventLogRecords(blob.Interface.Name)
+	require.NoError(t, err)
+	require.Len(t, recs, 3)
+	require.EqualValues(t, recs[2].Timestamp, getEarliestTs(t, chain, blob.Interface.Name))
+
+	uploadDummyBlobs(t, chain, 2)
+	require.EqualValues(t, 3, chain.GetEventLogNumRecords(blob.Interface.Name))
+
+	res, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRetention, eventlog.ParamContractHname, blob.Interface.Hname())
+	require.NoError(t, err)
+	require.True(t, res.MustHas(eventlog.ParamOwnPolicy))
+	maxRecords, _, err := codec.DecodeInt64(res.MustGet(eventlog.ParamMaxRecords))
+	require.NoError(t, err)
+	require.EqualValues(t, 3, maxRecords)
+
+	// other contracts are not affected
+	require.EqualValues(t, 1, chain.GetEventLogNumRecords(root.Interface.Name))
+}
+
+func TestEventLogRetentionMaxAge(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	// the default policy
+	req := solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention, eventlog.ParamMaxAge, 60)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	uploadDummyBlobs(t, chain, 2)
+	num := chain.GetEventLogNumRecords(blob.Interface.Name)
+	require.True(t, num > 0)
+	earliest := getEarliestTs(t, chain, blob.Interface.Name)
+
+	env.AdvanceClockBy(61 * time.Second)
+	// records are pruned once per block, also when the contract is silent
+	require.EqualValues(t, num, chain.GetEventLogNumRecords(blob.Interface.Name))
+	_, err = chain.PostRequestSync(solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, chain.GetEventLogNumRecords(blob.Interface.Name))
+	uploadDummyBlobs(t, chain, 1)
+	require.True(t, chain.GetEventLogNumRecords(blob.Interface.Name) > 0)
+	require.True(t, getEarliestTs(t, chain, blob.Interface.Name) > earliest+60*int64(time.Second))
+
+	// the contract overrides the default
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, blob.Interface.Hname(),
+		eventlog.ParamMaxRecords, 100,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	num = chain.GetEventLogNumRecords(blob.Interface.Name)
+	env.AdvanceClockBy(61 * time.Second)
+	uploadDummyBlobs(t, chain, 1)
+	require.True(t, chain.GetEventLogNumRecords(blob.Interface.Name) > num)
+
+	// back to the default
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, blob.Interface.Hname(),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	res, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRetention, eventlog.ParamContractHname, blob.Interface.Hname())
+	require.NoError(t, err)
+	require.False(t, res.MustHas(eventlog.ParamOwnPolicy))
+	maxAge, _, err := codec.DecodeInt64(res.MustGet(eventlog.ParamMaxAge))
+	require.NoError(t, err)
+	require.EqualValues(t, 60, maxAge)
+
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention, eventlog.ParamMaxAge, -1)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+}
+
+func TestEventLogRetentionDefault(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploadDummyBlobs(t, chain, 3)
+	require.True(t, chain.GetEventLogNumRecords(blob.Interface.Name) > 2)
+
+	// all logs are pruned when the default policy is changed
+	req := solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention, eventlog.ParamMaxRecords, 2)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chain.GetEventLogNumRecords(blob.Interface.Name))
+}
+
+func TestEventLogRetentionTypedEvents(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	targets := make([]coretypes.AgentID, 3)
+	for i := range targets {
+		targets[i] = coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+		err = chain.TransferOnChain(user, targets[i], map[balance.Color]int64{balance.ColorIOTA: 1})
+		require.NoError(t, err)
+	}
+	require.Len(t, chain.GetEvents(eventlog.ParamContractHname, accounts.Interface.Hname()), 3)
+
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, accounts.Interface.Hname(),
+		eventlog.ParamMaxRecords, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	events := chain.GetEvents(eventlog.ParamContractHname, accounts.Interface.Hname())
+	require.Len(t, events, 1)
+	require.EqualValues(t, targets[2][:], events[0].Topics[1])
+	require.Len(t, chain.GetEvents(eventlog.ParamEventName, accounts.FuncTransfer), 1)
+	require.Len(t, chain.GetEvents(eventlog.ParamTopic, targets[0][:]), 0)
+	require.Len(t, chain.GetEvents(), 1)
+
+	// the age limit prunes the events of the silent contract
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, accounts.Interface.Hname(),
+		eventlog.ParamMaxAge, 60,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.Len(t, chain.GetEvents(), 1)
+	env.AdvanceClockBy(61 * time.Second)
+	uploadDummyBlobs(t, chain, 1)
+	require.Len(t, chain.GetEvents(), 0)
+}
+
+func TestEventLogRetentionLegacyLogs(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	uploadDummyBlobs(t, chain, 3)
+	num := chain.GetEventLogNumRecords(blob.Interface.Name)
+	require.True(t, num > 2)
+
+	// logs written before the contracts with logs were tracked
+	state := subrealm.New(chain.State.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	contracts := collections.NewMap(state, eventlog.VarLoggedContracts)
+	keys := make([][]byte, 0)
+	contracts.MustIterateKeys(func(key []byte) bool {
+		keys = append(keys, key)
+		return true
+	})
+	for _, key := range keys {
+		contracts.MustDelAt(key)
+	}
+	state.Del(eventlog.VarLoggedContractsIndexed)
+
+	req := solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention, eventlog.ParamMaxRecords, 2)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, chain.GetEventLogNumRecords(blob.Interface.Name))
+}
@@ -1,7 +1,12 @@
 package testcore
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
@@ -85,3 +90,100 @@ func TestChainLogDeploy(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, recs, 0)
 }
+
+func TestEventLogRecordsByRequest(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, "field", "data")
+	tx, _, err := chain.PostRequestSyncTx(req, nil)
+	require.NoError(t, err)
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	blockIndex := chain.State.BlockIndex()
+
+	recs := chain.GetEventLogRecordsByRequest(reqID)
+	require.Len(t, recs, 2)
+	// the latest first
+	require.EqualValues(t, blob.Interface.Hname(), recs[1].Contract)
+	require.Contains(t, string(recs[1].Data), "[blob]")
+	require.EqualValues(t, blob.Interface.Hname(), recs[0].Contract)
+	require.Contains(t, string(recs[0].Data), "[req]")
+	for _, rec := range recs {
+		require.EqualValues(t, reqID, rec.RequestID)
+		require.EqualValues(t, blockIndex, rec.BlockIndex)
+	}
+	require.EqualValues(t, recs, chain.GetEventLogRecordsByBlock(blockIndex))
+
+	// the failed request
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 10)
+	tx, _, err = chain.PostRequestSyncTx(req, env.NewSignatureSchemeWithFunds())
+	require.Error(t, err)
+	recs = chain.GetEventLogRecordsByRequest(coretypes.NewRequestID(tx.ID(), 0))
+	require.Len(t, recs, 1)
+	require.EqualValues(t, root.Interface.Hname(), recs[0].Contract)
+	require.Contains(t, string(recs[0].Data), err.Error())
+
+	require.Len(t, chain.GetEventLogRecordsByBlock(blockIndex+100), 0)
+}
+
+func TestEventLogRecordsPruned(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, "field", "data")
+	tx, _, err := chain.PostRequestSyncTx(req, nil)
+	require.NoError(t, err)
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	require.Len(t, chain.GetEventLogRecordsByRequest(reqID), 2)
+
+	req = solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention,
+		eventlog.ParamContractHname, blob.Interface.Hname(),
+		eventlog.ParamMaxRecords, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	recs := chain.GetEventLogRecordsByRequest(reqID)
+	require.Len(t, recs, 1)
+	require.Contains(t, string(recs[0].Data), "[req]")
+
+	// the index entry of the pruned record is pruned too
+	state := subrealm.New(chain.State.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	locations := collections.NewMapReadOnly(state, eventlog.VarRecordLocations).MustLen()
+	_, err = chain.PostRequestSync(solo.NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, "field", "data2"), nil)
+	require.NoError(t, err)
+	require.Len(t, chain.GetEventLogRecordsByRequest(reqID), 0)
+	// new [blob] and [req] records of blob replace the pruned ones
+	require.EqualValues(t, locations, collections.NewMapReadOnly(state, eventlog.VarRecordLocations).MustLen())
+}
+
+func TestEventLogRecordsAndEventsByRequest(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	target := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	par := accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 10})
+	par.Set(accounts.ParamAgentID, codec.EncodeAgentID(target))
+	tx, _, err := chain.PostRequestSyncTx(solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncTransfer, par), user)
+	require.NoError(t, err)
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+
+	// records and typed events share the index by request
+	recs := chain.GetEventLogRecordsByRequest(reqID)
+	require.Len(t, recs, 1)
+	require.Contains(t, string(recs[0].Data), "[req]")
+	events := chain.GetEvents(eventlog.ParamRequestID, reqID)
+	require.Len(t, events, 1)
+	require.EqualValues(t, accounts.FuncTransfer, events[0].Name)
+
+	// 0 means the maximum
+	res, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRecordsByBlock,
+		eventlog.ParamBlockIndex, int64(chain.State.BlockIndex()),
+		eventlog.ParamMaxLastRecords, 0,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, collections.NewArrayReadOnly(res, eventlog.ParamRecords).MustLen())
+}
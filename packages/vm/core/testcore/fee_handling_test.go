@@ -102,3 +102,30 @@ func TestFeeIsEnough2(t *testing.T) {
 	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1)
 	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-3)
 }
+
+func TestValidatorFeeTarget(t *testing.T) {
+	env := solo.New(t, false, false)
+	validator := env.NewSignatureSchemeWithFunds()
+	validatorAgentID := coretypes.NewAgentIDFromAddress(validator.Address())
+	chain := env.NewChain(nil, "chain1", validatorAgentID)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, blob.Interface.Hname(),
+		root.ParamValidatorFee, 2,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	checkFees(chain, blob.Interface.Name, 0, 2)
+
+	user := env.NewSignatureSchemeWithFunds()
+	_, err = chain.UploadBlob(user,
+		blob.VarFieldVMType, "dummyType",
+		blob.VarFieldProgramBinary, "dummyBinary",
+	)
+	require.NoError(t, err)
+
+	// the validator fee goes to the fee target of the chain, not to the chain owner
+	chain.AssertAccountBalance(validatorAgentID, balance.ColorIOTA, 2)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, 2)
+}
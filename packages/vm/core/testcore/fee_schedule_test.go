@@ -0,0 +1,189 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func checkEntryPointFees(chain *solo.Chain, contract, funName string, expectedOf, expectedVf int64, expectedMode string) {
+	col, ownerFee, validatorFee, mode := chain.GetEntryPointFeeInfo(contract, funName)
+	require.EqualValues(chain.Env.T, balance.ColorIOTA, col)
+	require.EqualValues(chain.Env.T, expectedOf, ownerFee)
+	require.EqualValues(chain.Env.T, expectedVf, validatorFee)
+	require.EqualValues(chain.Env.T, expectedMode, mode)
+}
+
+func TestSetEntryPointFee(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 3)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+		root.ParamOwnerFee, 10,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	checkFees(chain, accounts.Interface.Name, 3, 0)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 10, 0, root.FeeModeFlat)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncWithdrawToAddress, 3, 0, root.FeeModeFlat)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 3)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 15)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	// the first request is refunded, the second is charged 10
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 3+1+15-10+1)
+
+	// zero fee of the entry point overrides the default
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+		root.ParamOwnerFee, 0,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 0, 0, root.FeeModeFlat)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncRemoveEntryPointFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 3, 0, root.FeeModeFlat)
+
+	// nothing to remove
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	chain.CheckAccountLedger()
+}
+
+func TestSetContractFeeBasisPoints(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	chain.ValidatorFeeTarget = coretypes.NewRandomAgentID()
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamOwnerFee, 100,
+		root.ParamValidatorFee, 50,
+		root.ParamFeeMode, root.FeeModeBasisPoints,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 100, 50, root.FeeModeBasisPoints)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	ownerBalance := chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA)
+
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 1000)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	// 1% to the owner, 0.5% to the validator
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1000-10-5+1)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, ownerBalance+10)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 5)
+
+	// rounded down
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 199)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, ownerBalance+10+1)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 5)
+
+	// flat fee of the entry point
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+		root.ParamOwnerFee, 7,
+		root.ParamFeeMode, root.FeeModeFlat,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 7, 0, root.FeeModeFlat)
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncWithdrawToAddress, 100, 50, root.FeeModeBasisPoints)
+	chain.CheckAccountLedger()
+}
+
+func TestSetContractFeeWrongParams(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+		root.ParamOwnerFee, 10,
+	)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamFeeMode, "dummy",
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamOwnerFee, 6000,
+		root.ParamValidatorFee, 5000,
+		root.ParamFeeMode, root.FeeModeBasisPoints,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncRemoveEntryPointFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamEntryPoint, coretypes.Hn(accounts.FuncDeposit),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	checkEntryPointFees(chain, accounts.Interface.Name, accounts.FuncDeposit, 0, 0, root.FeeModeFlat)
+}
+
+func TestContractRecordLegacyFormat(t *testing.T) {
+	rec := &root.ContractRecord{
+		ProgramHash:  root.Interface.ProgramHash,
+		Description:  "legacy contract",
+		Name:         "legacy",
+		OwnerFee:     5,
+		ValidatorFee: 1,
+		Creator:      coretypes.NewRandomAgentID(),
+	}
+	data := root.EncodeContractRecord(rec)
+	// records stored before fee schedules end with the creator
+	legacy := data[:len(data)-3]
+	back, err := root.DecodeContractRecord(legacy)
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+	require.False(t, back.FeeBasisPoints)
+	require.Empty(t, back.EntryPointFees)
+
+	back, err = root.DecodeContractRecord(data)
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFeeShortfall sets fees of the 'accounts' contract: 6 to the chain owner and 4 to the validator.
+// Validator fees go to the separate account
+func setupFeeShortfall(t *testing.T, policy string, penalty int64) (*solo.Solo, *solo.Chain) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	chain.ValidatorFeeTarget = coretypes.NewRandomAgentID()
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, accounts.Interface.Hname(),
+		root.ParamOwnerFee, 6,
+		root.ParamValidatorFee, 4,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	if policy != root.FeeShortfallRefund {
+		req = solo.NewCallParams(root.Interface.Name, root.FuncSetFeeShortfallPolicy,
+			root.ParamFeeShortfallPolicy, policy,
+			root.ParamFeeShortfallPenalty, penalty,
+		)
+		_, err = chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, policy, info.FeeShortfallPolicy)
+	require.EqualValues(t, penalty, info.FeeShortfallPenalty)
+	return env, chain
+}
+
+func postWithFeeShortfall(t *testing.T, env *solo.Solo, chain *solo.Chain, policy string) (coretypes.AgentID, int64) {
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	ownerBalance := chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA)
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 5)
+	tx, _, err := chain.PostRequestSyncTx(req, user)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not enough fees")
+
+	receipt, ok := chain.GetRequestReceipt(coretypes.NewRequestID(tx.ID(), 0))
+	require.True(t, ok)
+	require.False(t, receipt.Succeeded())
+	require.EqualValues(t, policy, receipt.FeeShortfall)
+
+	recs, err := chain.GetEventLogRecordsString(accounts.Interface.Name)
+	require.NoError(t, err)
+	require.Contains(t, recs, "Fee shortfall policy '"+policy+"'")
+
+	require.EqualValues(t, ownerBalance+receipt.Fee*6/10, chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA))
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, receipt.Fee-receipt.Fee*6/10)
+	chain.CheckAccountLedger()
+	return userAgentID, receipt.Fee
+}
+
+func TestFeeShortfallRefund(t *testing.T) {
+	env, chain := setupFeeShortfall(t, root.FeeShortfallRefund, 0)
+	userAgentID, fee := postWithFeeShortfall(t, env, chain, root.FeeShortfallRefund)
+	require.EqualValues(t, 0, fee)
+	// the transfer and the request token
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 5+1)
+}
+
+func TestFeeShortfallChargeAvailable(t *testing.T) {
+	env, chain := setupFeeShortfall(t, root.FeeShortfallChargeAvailable, 0)
+	userAgentID, fee := postWithFeeShortfall(t, env, chain, root.FeeShortfallChargeAvailable)
+	require.EqualValues(t, 5, fee)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 2)
+}
+
+func TestFeeShortfallPenalty(t *testing.T) {
+	env, chain := setupFeeShortfall(t, root.FeeShortfallPenalty, 2)
+	userAgentID, fee := postWithFeeShortfall(t, env, chain, root.FeeShortfallPenalty)
+	require.EqualValues(t, 2, fee)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 5-2+1)
+}
+
+func TestFeeShortfallPenaltyOffLedger(t *testing.T) {
+	env, chain := setupFeeShortfall(t, root.FeeShortfallPenalty, 2)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 5)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 5-2+1)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 1)
+
+	// fees are charged from the on-chain account of the sender
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit)
+	_, err = chain.PostOffLedgerRequestSync(req, user, 1)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 5-2+1-2)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 2)
+
+	_, err = chain.PostOffLedgerRequestSync(req, user, 2)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 0)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 3)
+
+	// nothing left to charge
+	_, err = chain.PostOffLedgerRequestSync(req, user, 3)
+	require.Error(t, err)
+	chain.AssertAccountBalance(chain.ValidatorFeeTarget, balance.ColorIOTA, 3)
+	chain.CheckAccountLedger()
+}
+
+func TestFeeShortfallPolicyWrongParams(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetFeeShortfallPolicy,
+		root.ParamFeeShortfallPolicy, root.FeeShortfallChargeAvailable,
+	)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetFeeShortfallPolicy,
+		root.ParamFeeShortfallPolicy, "dummy",
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetFeeShortfallPolicy,
+		root.ParamFeeShortfallPolicy, root.FeeShortfallPenalty,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetFeeShortfallPolicy,
+		root.ParamFeeShortfallPolicy, root.FeeShortfallRefund,
+		root.ParamFeeShortfallPenalty, 5,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, root.FeeShortfallRefund, info.FeeShortfallPolicy)
+	require.EqualValues(t, 0, info.FeeShortfallPenalty)
+}
@@ -0,0 +1,476 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/governance"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+// setupVotingCommittee configures voting by the committee of 3 members
+func setupVotingCommittee(t *testing.T) (*solo.Solo, *solo.Chain, []signaturescheme.SignatureScheme) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerCommittee,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	members := make([]signaturescheme.SignatureScheme, 3)
+	for i := range members {
+		members[i] = env.NewSignatureSchemeWithFunds()
+		req = solo.NewCallParams(governance.Interface.Name, governance.FuncAddCommitteeMember,
+			governance.ParamAgentID, coretypes.NewAgentIDFromAddress(members[i].Address()),
+		)
+		_, err = chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	return env, chain, members
+}
+
+func createProposal(t *testing.T, chain *solo.Chain, proposer signaturescheme.SignatureScheme, target string, params ...interface{}) int64 {
+	par := []interface{}{governance.ParamTarget, coretypes.Hn(target)}
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal, append(par, params...)...)
+	res, err := chain.PostRequestSync(req, proposer)
+	require.NoError(t, err)
+	id, ok, err := codec.DecodeInt64(res.MustGet(governance.ParamProposalID))
+	require.NoError(t, err)
+	require.True(t, ok)
+	return id
+}
+
+func voteOnProposal(chain *solo.Chain, voter signaturescheme.SignatureScheme, id int64, vote string) error {
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncVote,
+		governance.ParamProposalID, id,
+		governance.ParamVote, vote,
+	)
+	_, err := chain.PostRequestSync(req, voter)
+	return err
+}
+
+func finalizeProposal(chain *solo.Chain, id int64) error {
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncFinalizeProposal, governance.ParamProposalID, id)
+	_, err := chain.PostRequestSync(req, nil)
+	return err
+}
+
+func TestGovernanceCommittee(t *testing.T) {
+	_, chain, members := setupVotingCommittee(t)
+	governanceAgentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(chain.ChainID, governance.Interface.Hname()))
+
+	res, err := chain.CallView(governance.Interface.Name, governance.FuncGetVotingConfig)
+	require.NoError(t, err)
+	require.EqualValues(t, governance.VotingPowerCommittee, string(res.MustGet(governance.ParamVotingPower)))
+
+	// the chain is handed over to the governance
+	req := solo.NewCallParams(root.Interface.Name, root.FuncDelegateChainOwnership, root.ParamChainOwner, governanceAgentID)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	id := createProposal(t, chain, members[0], root.FuncClaimChainOwnership)
+	require.EqualValues(t, 0, id)
+	require.NoError(t, voteOnProposal(chain, members[0], id, governance.VoteYes))
+	require.EqualValues(t, governance.ProposalPending, chain.GetGovernanceProposal(id).Status)
+	// already voted
+	require.Error(t, voteOnProposal(chain, members[0], id, governance.VoteYes))
+	// not a member
+	require.Error(t, voteOnProposal(chain, nil, id, governance.VoteYes))
+
+	// 2 of 3 decide the outcome: the proposal is executed without waiting for the deadline
+	require.NoError(t, voteOnProposal(chain, members[1], id, governance.VoteYes))
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalExecuted, p.Status)
+	require.EqualValues(t, 2, p.VotesYes)
+	require.EqualValues(t, 2, p.NumVoters)
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, governanceAgentID, info.ChainOwnerID)
+	require.Error(t, voteOnProposal(chain, members[2], id, governance.VoteNo))
+
+	// the former owner is not authorized anymore
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	id = createProposal(t, chain, members[2], root.FuncSetGasBudget,
+		governance.ParamDescription, "raise the gas budget",
+		root.ParamGasBudget, 100000,
+	)
+	require.EqualValues(t, 1, id)
+	require.NoError(t, voteOnProposal(chain, members[2], id, governance.VoteYes))
+	require.NoError(t, voteOnProposal(chain, members[0], id, governance.VoteYes))
+	p = chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalExecuted, p.Status)
+	require.EqualValues(t, "raise the gas budget", p.Description)
+	info, _ = chain.GetInfo()
+	require.EqualValues(t, 100000, info.GasBudget)
+}
+
+func TestGovernanceProposalFailed(t *testing.T) {
+	_, chain, members := setupVotingCommittee(t)
+
+	// the governance is not the chain owner: the call is not authorized by the 'root'
+	id := createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	require.NoError(t, voteOnProposal(chain, members[0], id, governance.VoteYes))
+	require.NoError(t, voteOnProposal(chain, members[1], id, governance.VoteYes))
+
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalFailed, p.Status)
+	require.NotEmpty(t, p.Error)
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+}
+
+func TestGovernanceProposalRejected(t *testing.T) {
+	env, chain, members := setupVotingCommittee(t)
+
+	id := createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	require.NoError(t, voteOnProposal(chain, members[0], id, governance.VoteYes))
+	require.NoError(t, voteOnProposal(chain, members[1], id, governance.VoteNo))
+	// voting is not over
+	require.Error(t, finalizeProposal(chain, id))
+
+	env.AdvanceClockBy(governance.DefaultVotingPeriod*time.Second + time.Second)
+	require.Error(t, voteOnProposal(chain, members[2], id, governance.VoteYes))
+	require.NoError(t, finalizeProposal(chain, id))
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalRejected, p.Status)
+	require.EqualValues(t, 1, p.VotesYes)
+	require.EqualValues(t, 1, p.VotesNo)
+
+	// already finalized
+	require.Error(t, finalizeProposal(chain, id))
+}
+
+func voteWithTokens(chain *solo.Chain, voter signaturescheme.SignatureScheme, id int64, vote string, color balance.Color, amount int64) error {
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncVote,
+		governance.ParamProposalID, id,
+		governance.ParamVote, vote,
+	).WithTransfer(color, amount)
+	_, err := chain.PostRequestSync(req, voter)
+	return err
+}
+
+func reclaimVotingTokens(chain *solo.Chain, voter signaturescheme.SignatureScheme, id int64) error {
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncReclaimVotingTokens, governance.ParamProposalID, id)
+	_, err := chain.PostRequestSync(req, voter)
+	return err
+}
+
+func TestGovernanceBalance(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	governanceAgentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(chain.ChainID, governance.Interface.Hname()))
+
+	user1 := env.NewSignatureSchemeWithFunds()
+	user1AgentID := coretypes.NewAgentIDFromAddress(user1.Address())
+	user2 := env.NewSignatureSchemeWithFunds()
+	user2AgentID := coretypes.NewAgentIDFromAddress(user2.Address())
+	color, err := env.MintTokens(user1, 100)
+	require.NoError(t, err)
+
+	// 30 tokens in the on-chain account of user1, 50 tokens to user2
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(color, 30)
+	_, err = chain.PostRequestSync(req, user1)
+	require.NoError(t, err)
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit,
+		accounts.ParamAgentID, user2AgentID,
+	).WithTransfer(color, 50)
+	_, err = chain.PostRequestSync(req, user1)
+	require.NoError(t, err)
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToAddress)
+	_, err = chain.PostRequestSync(req, user2)
+	require.NoError(t, err)
+	env.AssertAddressBalance(user2.Address(), color, 50)
+
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerBalance,
+		governance.ParamVotingColor, color,
+		governance.ParamVotingPeriod, 60,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// no tokens of the voting color
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal,
+		governance.ParamTarget, coretypes.Hn(root.FuncSetGasBudget),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	id := createProposal(t, chain, user1, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	// the vote without tokens has no voting power
+	require.Error(t, voteOnProposal(chain, user2, id, governance.VoteNo))
+	require.NoError(t, voteWithTokens(chain, user1, id, governance.VoteYes, color, 20))
+	require.NoError(t, voteWithTokens(chain, user2, id, governance.VoteNo, color, 50))
+
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalPending, p.Status)
+	require.EqualValues(t, 20, p.VotesYes)
+	require.EqualValues(t, 70-20, p.VotesNo)
+	chain.AssertAccountBalance(governanceAgentID, color, 70)
+
+	// the tokens are locked until the voting is over
+	require.Error(t, reclaimVotingTokens(chain, user1, id))
+
+	env.AdvanceClockBy(61 * time.Second)
+	require.NoError(t, finalizeProposal(chain, id))
+	require.EqualValues(t, governance.ProposalRejected, chain.GetGovernanceProposal(id).Status)
+
+	require.NoError(t, reclaimVotingTokens(chain, user1, id))
+	require.NoError(t, reclaimVotingTokens(chain, user2, id))
+	require.Error(t, reclaimVotingTokens(chain, user2, id))
+	chain.AssertAccountBalance(governanceAgentID, color, 0)
+	chain.AssertAccountBalance(user1AgentID, color, 30+20)
+	chain.AssertAccountBalance(user2AgentID, color, 50)
+	chain.CheckAccountLedger()
+}
+
+func TestGovernanceBalanceDoubleVote(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user1 := env.NewSignatureSchemeWithFunds()
+	user1AgentID := coretypes.NewAgentIDFromAddress(user1.Address())
+	user2 := env.NewSignatureSchemeWithFunds()
+	user2AgentID := coretypes.NewAgentIDFromAddress(user2.Address())
+	color, err := env.MintTokens(user1, 100)
+	require.NoError(t, err)
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(color, 10)
+	_, err = chain.PostRequestSync(req, user1)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerBalance,
+		governance.ParamVotingColor, color,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	id := createProposal(t, chain, user1, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	require.NoError(t, voteWithTokens(chain, user1, id, governance.VoteNo, color, 40))
+	require.Error(t, voteWithTokens(chain, user1, id, governance.VoteNo, color, 40))
+
+	// the voted tokens are not in the account of user1 anymore, so they can't be passed to user2 to vote again
+	err = chain.TransferOnChain(user1, user2AgentID, map[balance.Color]int64{color: 40})
+	require.Error(t, err)
+	chain.AssertAccountBalance(user1AgentID, color, 10)
+	require.Error(t, voteOnProposal(chain, user2, id, governance.VoteNo))
+
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, 0, p.VotesYes)
+	require.EqualValues(t, 40, p.VotesNo)
+	require.EqualValues(t, 1, p.NumVoters)
+}
+
+func TestGovernanceProposalToGovernance(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	member1 := env.NewSignatureSchemeWithFunds()
+	member2 := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerCommittee,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncAddCommitteeMember,
+		governance.ParamAgentID, coretypes.NewAgentIDFromAddress(member1.Address()),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// only the entry points which change the voting configuration can be proposed
+	for _, target := range []string{governance.FuncVote, governance.FuncGetProposals, coretypes.FuncInit} {
+		req = solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal,
+			governance.ParamContract, governance.Interface.Hname(),
+			governance.ParamTarget, coretypes.Hn(target),
+		)
+		_, err = chain.PostRequestSync(req, member1)
+		require.Error(t, err)
+	}
+
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal,
+		governance.ParamContract, governance.Interface.Hname(),
+		governance.ParamTarget, coretypes.Hn(governance.FuncAddCommitteeMember),
+		governance.ParamAgentID, coretypes.NewAgentIDFromAddress(member2.Address()),
+	)
+	res, err := chain.PostRequestSync(req, member1)
+	require.NoError(t, err)
+	id, _, err := codec.DecodeInt64(res.MustGet(governance.ParamProposalID))
+	require.NoError(t, err)
+	require.NoError(t, voteOnProposal(chain, member1, id, governance.VoteYes))
+
+	p := chain.GetGovernanceProposal(id)
+	require.EqualValues(t, governance.ProposalExecuted, p.Status)
+	require.EqualValues(t, governance.Interface.Hname(), p.Contract)
+	res, err = chain.CallView(governance.Interface.Name, governance.FuncGetVotingConfig)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, collections.NewArrayReadOnly(res, governance.ParamCommittee).MustLen())
+}
+
+func TestGovernanceWrongParams(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+
+	// voting is not configured
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal,
+		governance.ParamTarget, coretypes.Hn(root.FuncSetGasBudget),
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	// not the chain owner
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerCommittee,
+	)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncAddCommitteeMember,
+		governance.ParamAgentID, coretypes.NewAgentIDFromAddress(user.Address()),
+	)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	for _, par := range [][]interface{}{
+		{governance.ParamVotingPower, "dummy"},
+		{governance.ParamVotingPower, governance.VotingPowerCommittee, governance.ParamQuorum, 101},
+		{governance.ParamVotingPower, governance.VotingPowerCommittee, governance.ParamThreshold, 100},
+		{governance.ParamVotingPower, governance.VotingPowerCommittee, governance.ParamVotingPeriod, 0},
+	} {
+		req = solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig, par...)
+		_, err = chain.PostRequestSync(req, nil)
+		require.Error(t, err)
+	}
+	res, err := chain.CallView(governance.Interface.Name, governance.FuncGetVotingConfig)
+	require.NoError(t, err)
+	require.EqualValues(t, "", string(res.MustGet(governance.ParamVotingPower)))
+
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncRemoveCommitteeMember,
+		governance.ParamAgentID, coretypes.NewAgentIDFromAddress(user.Address()),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+}
+
+func TestGovernanceOpenProposalsLimit(t *testing.T) {
+	_, chain, members := setupVotingCommittee(t)
+
+	ids := make([]int64, governance.MaxOpenProposals)
+	for i := range ids {
+		ids[i] = createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000+i)
+	}
+	req := solo.NewCallParams(governance.Interface.Name, governance.FuncCreateProposal,
+		governance.ParamTarget, coretypes.Hn(root.FuncSetGasBudget),
+	)
+	_, err := chain.PostRequestSync(req, members[0])
+	require.Error(t, err)
+	// the limit is per proposer
+	createProposal(t, chain, members[1], root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+
+	// the finished proposal is not counted
+	require.NoError(t, voteOnProposal(chain, members[0], ids[0], governance.VoteYes))
+	require.NoError(t, voteOnProposal(chain, members[1], ids[0], governance.VoteYes))
+	require.NotEqualValues(t, governance.ProposalPending, chain.GetGovernanceProposal(ids[0]).Status)
+	createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+}
+
+func TestGovernanceVoteRefund(t *testing.T) {
+	_, chain, members := setupVotingCommittee(t)
+	governanceAgentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(chain.ChainID, governance.Interface.Hname()))
+
+	memberAgentID := coretypes.NewAgentIDFromAddress(members[0].Address())
+
+	id := createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	before := chain.GetAccountBalance(memberAgentID).Balance(balance.ColorIOTA)
+	// tokens don't give voting power in the committee mode: they are returned to the voter
+	require.NoError(t, voteWithTokens(chain, members[0], id, governance.VoteYes, balance.ColorIOTA, 42))
+	require.EqualValues(t, 1, chain.GetGovernanceProposal(id).VotesYes)
+	// plus the request token
+	chain.AssertAccountBalance(memberAgentID, balance.ColorIOTA, before+42+1)
+	chain.AssertAccountBalance(governanceAgentID, balance.ColorIOTA, 0)
+	chain.CheckAccountLedger()
+}
+
+func TestGovernanceGetProposals(t *testing.T) {
+	_, chain, members := setupVotingCommittee(t)
+	for i := 0; i < 3; i++ {
+		createProposal(t, chain, members[0], root.FuncSetGasBudget, root.ParamGasBudget, 100000+i)
+	}
+
+	getProposals := func(params ...interface{}) []*governance.Proposal {
+		res, err := chain.CallView(governance.Interface.Name, governance.FuncGetProposals, params...)
+		require.NoError(t, err)
+		numProposals, _, err := codec.DecodeInt64(res.MustGet(governance.ParamNumProposals))
+		require.NoError(t, err)
+		require.EqualValues(t, 3, numProposals)
+		arr := collections.NewArrayReadOnly(res, governance.ParamProposals)
+		ret := make([]*governance.Proposal, arr.MustLen())
+		for i := range ret {
+			ret[i], err = governance.DecodeProposal(arr.MustGetAt(uint16(i)))
+			require.NoError(t, err)
+		}
+		return ret
+	}
+	require.Len(t, getProposals(), 3)
+	ps := getProposals(governance.ParamFrom, 1, governance.ParamMaxRecords, 1)
+	require.Len(t, ps, 1)
+	require.EqualValues(t, 1, ps[0].ID)
+	require.Len(t, getProposals(governance.ParamFrom, 3), 0)
+
+	_, err := chain.CallView(governance.Interface.Name, governance.FuncGetProposals, governance.ParamFrom, -1)
+	require.Error(t, err)
+}
+
+func TestGovernancePruneProposals(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	color, err := env.MintTokens(user, governance.MaxFinishedProposals+2)
+	require.NoError(t, err)
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(color, 1)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	// any 'yes' vote decides the outcome
+	req = solo.NewCallParams(governance.Interface.Name, governance.FuncSetVotingConfig,
+		governance.ParamVotingPower, governance.VotingPowerBalance,
+		governance.ParamVotingColor, color,
+		governance.ParamQuorum, 0,
+		governance.ParamThreshold, 0,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	for i := 0; i <= governance.MaxFinishedProposals; i++ {
+		id := createProposal(t, chain, user, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+		require.NoError(t, voteWithTokens(chain, user, id, governance.VoteYes, color, 1))
+	}
+
+	// the earliest finished proposal is pruned and the tokens locked by its vote are returned
+	_, err = chain.CallView(governance.Interface.Name, governance.FuncGetProposal, governance.ParamProposalID, 0)
+	require.Error(t, err)
+	require.NotEqualValues(t, governance.ProposalPending, chain.GetGovernanceProposal(1).Status)
+	chain.AssertAccountBalance(userAgentID, color, 1+1)
+
+	require.Error(t, reclaimVotingTokens(chain, user, 0))
+	require.NoError(t, reclaimVotingTokens(chain, user, 1))
+	chain.AssertAccountBalance(userAgentID, color, 1+2)
+	chain.CheckAccountLedger()
+}
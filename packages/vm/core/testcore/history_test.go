@@ -0,0 +1,44 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallViewAtBlockIndex(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	blockIndexBefore := chain.State.BlockIndex()
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 1000)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	blockIndexAfter := chain.State.BlockIndex()
+	require.EqualValues(t, blockIndexBefore+1, blockIndexAfter)
+
+	res, err := chain.CallViewAtBlockIndex(blockIndexBefore, root.Interface.Name, root.FuncGetChainInfo)
+	require.NoError(t, err)
+	fee, _, err := codec.DecodeInt64(res.MustGet(root.VarDefaultOwnerFee))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, fee)
+
+	res, err = chain.CallViewAtBlockIndex(blockIndexAfter, root.Interface.Name, root.FuncGetChainInfo)
+	require.NoError(t, err)
+	fee, _, err = codec.DecodeInt64(res.MustGet(root.VarDefaultOwnerFee))
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, fee)
+
+	// the origin state contains no contracts
+	_, err = chain.CallViewAtBlockIndex(0, root.Interface.Name, root.FuncGetChainInfo)
+	require.Error(t, err)
+
+	_, err = chain.CallViewAtBlockIndex(blockIndexAfter+1, root.Interface.Name, root.FuncGetChainInfo)
+	require.Error(t, err)
+}
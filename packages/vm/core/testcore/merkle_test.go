@@ -0,0 +1,56 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/merkle"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	stateRoot := chain.GetStateRoot()
+	require.NotEqual(t, hashing.NilHash, stateRoot)
+	require.EqualValues(t, chain.State.StateRoot(), stateRoot)
+
+	value, proof := chain.GetMerkleProof(root.Interface.Name, root.VarChainID)
+	require.EqualValues(t, codec.EncodeChainID(chain.ChainID), value)
+	require.NoError(t, merkle.VerifyInclusion(stateRoot, proof.Key, value, proof))
+
+	// proof survives serialization
+	proofBack, err := merkle.ProofFromBytes(proof.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, merkle.VerifyInclusion(stateRoot, proof.Key, value, proofBack))
+	require.Error(t, merkle.VerifyInclusion(stateRoot, proof.Key, []byte("wrong value"), proofBack))
+
+	value, proof = chain.GetMerkleProof(root.Interface.Name, "nonexistent")
+	require.Nil(t, value)
+	require.NoError(t, merkle.VerifyNonInclusion(stateRoot, proof.Key, proof))
+}
+
+func TestMerkleRootChanges(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	stateRoot := chain.GetStateRoot()
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 1000)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	newStateRoot := chain.GetStateRoot()
+	require.NotEqual(t, stateRoot, newStateRoot)
+
+	value, proof := chain.GetMerkleProof(root.Interface.Name, root.VarDefaultOwnerFee)
+	require.EqualValues(t, codec.EncodeInt64(1000), value)
+	require.NoError(t, merkle.VerifyInclusion(newStateRoot, proof.Key, value, proof))
+	require.Error(t, merkle.VerifyInclusion(stateRoot, proof.Key, value, proof))
+}
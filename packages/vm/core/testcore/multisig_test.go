@@ -0,0 +1,258 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+// setupChainOwners sets the 2-of-3 owner set of the chain
+func setupChainOwners(t *testing.T) (*solo.Solo, *solo.Chain, []signaturescheme.SignatureScheme) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	owners := make([]signaturescheme.SignatureScheme, 3)
+	ownerIDs := make([]coretypes.AgentID, 3)
+	for i := range owners {
+		owners[i] = env.NewSignatureSchemeWithFunds()
+		ownerIDs[i] = coretypes.NewAgentIDFromAddress(owners[i].Address())
+	}
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetChainOwners,
+		root.ParamChainOwners, root.EncodeChainOwners(ownerIDs),
+		root.ParamOwnerQuorum, 2,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, ownerIDs, info.ChainOwners)
+	require.EqualValues(t, 2, info.OwnerQuorum)
+	return env, chain, owners
+}
+
+func proposeGasBudget(t *testing.T, chain *solo.Chain, proposer signaturescheme.SignatureScheme, gasBudget int64, ttl int64) int64 {
+	req := solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+		root.ParamProposalTarget, coretypes.Hn(root.FuncSetGasBudget),
+		root.ParamProposalTTL, ttl,
+		root.ParamGasBudget, gasBudget,
+	)
+	res, err := chain.PostRequestSync(req, proposer)
+	require.NoError(t, err)
+	id, ok, err := codec.DecodeInt64(res.MustGet(root.ParamProposalID))
+	require.NoError(t, err)
+	require.True(t, ok)
+	return id
+}
+
+func approveOwnerAction(chain *solo.Chain, owner signaturescheme.SignatureScheme, id int64) error {
+	req := solo.NewCallParams(root.Interface.Name, root.FuncApproveOwnerAction, root.ParamProposalID, id)
+	_, err := chain.PostRequestSync(req, owner)
+	return err
+}
+
+func TestChainOwnersProposeApprove(t *testing.T) {
+	_, chain, owners := setupChainOwners(t)
+
+	// owner-only entry points can't be called directly anymore
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, owners[0])
+	require.Error(t, err)
+
+	id := proposeGasBudget(t, chain, owners[0], 100000, root.DefaultProposalTTL)
+	require.EqualValues(t, 0, id)
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+
+	proposals := chain.GetOwnerProposals()
+	require.Len(t, proposals, 1)
+	require.EqualValues(t, coretypes.Hn(root.FuncSetGasBudget), proposals[0].Target)
+	require.EqualValues(t, coretypes.NewAgentIDFromAddress(owners[0].Address()), proposals[0].Proposer)
+	require.Len(t, proposals[0].Approvals, 1)
+
+	// already approved by the proposer
+	require.Error(t, approveOwnerAction(chain, owners[0], id))
+	// not an owner
+	require.Error(t, approveOwnerAction(chain, nil, id))
+	require.Len(t, chain.GetOwnerProposals(), 1)
+
+	require.NoError(t, approveOwnerAction(chain, owners[2], id))
+	info, _ = chain.GetInfo()
+	require.EqualValues(t, 100000, info.GasBudget)
+	require.Len(t, chain.GetOwnerProposals(), 0)
+
+	// executed proposal can't be approved
+	require.Error(t, approveOwnerAction(chain, owners[1], id))
+}
+
+func TestChainOwnersProposalExpired(t *testing.T) {
+	env, chain, owners := setupChainOwners(t)
+
+	id := proposeGasBudget(t, chain, owners[1], 100000, 60)
+	env.AdvanceClockBy(61 * time.Second)
+	require.Error(t, approveOwnerAction(chain, owners[0], id))
+	// the view is called at the timestamp of the latest block
+	require.Len(t, chain.GetOwnerProposals(), 0)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+
+	// the next proposal has the new ID
+	id = proposeGasBudget(t, chain, owners[1], 200000, 60)
+	require.EqualValues(t, 1, id)
+	require.NoError(t, approveOwnerAction(chain, owners[0], id))
+	info, _ = chain.GetInfo()
+	require.EqualValues(t, 200000, info.GasBudget)
+}
+
+func TestChainOwnersWrongProposal(t *testing.T) {
+	_, chain, owners := setupChainOwners(t)
+
+	for _, target := range []string{root.FuncApproveOwnerAction, root.FuncGetChainInfo, root.FuncClaimChainOwnership, "dummy"} {
+		req := solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+			root.ParamProposalTarget, coretypes.Hn(target),
+		)
+		_, err := chain.PostRequestSync(req, owners[0])
+		require.Error(t, err)
+	}
+	req := solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+		root.ParamProposalTarget, coretypes.Hn(root.FuncSetGasBudget),
+		root.ParamGasBudget, 100000,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	// the call fails when approved: the proposal stays pending
+	id := proposeGasBudget(t, chain, owners[0], 1, root.DefaultProposalTTL)
+	require.Error(t, approveOwnerAction(chain, owners[1], id))
+	proposals := chain.GetOwnerProposals()
+	require.Len(t, proposals, 1)
+	require.Len(t, proposals[0].Approvals, 1)
+}
+
+func TestChainOwnersRemove(t *testing.T) {
+	_, chain, owners := setupChainOwners(t)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+		root.ParamProposalTarget, coretypes.Hn(root.FuncSetChainOwners),
+	)
+	res, err := chain.PostRequestSync(req, owners[2])
+	require.NoError(t, err)
+	id, _, err := codec.DecodeInt64(res.MustGet(root.ParamProposalID))
+	require.NoError(t, err)
+	require.NoError(t, approveOwnerAction(chain, owners[1], id))
+
+	info, _ := chain.GetInfo()
+	require.Len(t, info.ChainOwners, 0)
+	require.EqualValues(t, 0, info.OwnerQuorum)
+
+	// the single chain owner governs the chain again
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, 100000)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
+func TestSetChainOwnersWrongParams(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	owner := coretypes.NewRandomAgentID()
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetChainOwners,
+		root.ParamChainOwners, root.EncodeChainOwners([]coretypes.AgentID{owner}),
+		root.ParamOwnerQuorum, 1,
+	)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	for _, quorum := range []int64{0, 3} {
+		req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainOwners,
+			root.ParamChainOwners, root.EncodeChainOwners([]coretypes.AgentID{owner, coretypes.NewRandomAgentID()}),
+			root.ParamOwnerQuorum, quorum,
+		)
+		_, err = chain.PostRequestSync(req, nil)
+		require.Error(t, err)
+	}
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainOwners,
+		root.ParamChainOwners, root.EncodeChainOwners([]coretypes.AgentID{owner, owner}),
+		root.ParamOwnerQuorum, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.Len(t, info.ChainOwners, 0)
+}
+
+func TestChainOwnersProposalOtherContract(t *testing.T) {
+	_, chain, owners := setupChainOwners(t)
+
+	// owner-only entry points of other core contracts can't be called directly either
+	req := solo.NewCallParams(eventlog.Interface.Name, eventlog.FuncSetRetention, eventlog.ParamMaxRecords, 100)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, owners[0])
+	require.Error(t, err)
+
+	// views and 'init' can't be proposed
+	for _, target := range []string{eventlog.FuncGetRetention, coretypes.FuncInit} {
+		req = solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+			root.ParamProposalContract, eventlog.Interface.Hname(),
+			root.ParamProposalTarget, coretypes.Hn(target),
+		)
+		_, err = chain.PostRequestSync(req, owners[0])
+		require.Error(t, err)
+	}
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncProposeOwnerAction,
+		root.ParamProposalContract, eventlog.Interface.Hname(),
+		root.ParamProposalTarget, coretypes.Hn(eventlog.FuncSetRetention),
+		eventlog.ParamMaxRecords, 100,
+	)
+	res, err := chain.PostRequestSync(req, owners[0])
+	require.NoError(t, err)
+	id, _, err := codec.DecodeInt64(res.MustGet(root.ParamProposalID))
+	require.NoError(t, err)
+	proposals := chain.GetOwnerProposals()
+	require.Len(t, proposals, 1)
+	require.EqualValues(t, eventlog.Interface.Hname(), proposals[0].Contract)
+
+	require.NoError(t, approveOwnerAction(chain, owners[1], id))
+	res, err = chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRetention)
+	require.NoError(t, err)
+	maxRecords, _, err := codec.DecodeInt64(res.MustGet(eventlog.ParamMaxRecords))
+	require.NoError(t, err)
+	require.EqualValues(t, 100, maxRecords)
+}
+
+func TestOwnerProposalEncoding(t *testing.T) {
+	p := &root.OwnerProposal{
+		ID:        1,
+		Proposer:  coretypes.NewRandomAgentID(),
+		Contract:  root.Interface.Hname(),
+		Target:    coretypes.Hn(root.FuncSetGasBudget),
+		Params:    dict.New(),
+		Deadline:  100,
+		Approvals: []coretypes.AgentID{coretypes.NewRandomAgentID()},
+	}
+	data := root.EncodeOwnerProposal(p)
+	back, err := root.DecodeOwnerProposal(data)
+	require.NoError(t, err)
+	require.EqualValues(t, p, back)
+
+	_, err = root.DecodeOwnerProposal(data[:len(data)-coretypes.HnameLength])
+	require.Error(t, err)
+}
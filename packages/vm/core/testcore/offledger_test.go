@@ -0,0 +1,113 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffLedgerByOwner(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	require.EqualValues(t, 0, chain.GetNonce(chain.OriginatorAgentID))
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 1000)
+	_, err := chain.PostOffLedgerRequestSync(req, nil, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, chain.GetNonce(chain.OriginatorAgentID))
+	_, ownerFee, _ := chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 1000, ownerFee)
+	// no request token is accrued for off-ledger requests
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, 1)
+
+	// replay is rejected
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 2000)
+	_, err = chain.PostOffLedgerRequestSync(req, nil, 1)
+	require.Error(t, err)
+	_, ownerFee, _ = chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 1000, ownerFee)
+
+	_, err = chain.PostOffLedgerRequestSync(req, nil, 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, chain.GetNonce(chain.OriginatorAgentID))
+	_, ownerFee, _ = chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 2000, ownerFee)
+}
+
+func TestOffLedgerFees(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 1000)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1001)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 100)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, 2)
+
+	// fees are charged from the sender's on-chain account
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit)
+	_, err = chain.PostOffLedgerRequestSync(req, user, 1)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 901)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, 102)
+
+	// sender without funds on the chain can't pay fees
+	poorUser := env.NewSignatureSchemeWithFunds()
+	_, err = chain.PostOffLedgerRequestSync(req, poorUser, 1)
+	require.Error(t, err)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, 102)
+	// the nonce is consumed even if the request was not called
+	require.EqualValues(t, 1, chain.GetNonce(coretypes.NewAgentIDFromAddress(poorUser.Address())))
+	chain.CheckAccountLedger()
+}
+
+func TestOffLedgerOutOfOrder(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	// requests signed with nonces 1..3 are processed in the different order
+	for i, nonce := range []uint64{2, 3, 1} {
+		req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 1000+i)
+		_, err := chain.PostOffLedgerRequestSync(req, nil, nonce)
+		require.NoError(t, err)
+		_, ownerFee, _ := chain.GetFeeInfo(accounts.Interface.Name)
+		require.EqualValues(t, 1000+i, ownerFee)
+	}
+	require.EqualValues(t, 3, chain.GetNonce(chain.OriginatorAgentID))
+
+	// each of them can't be replayed
+	for _, nonce := range []uint64{1, 2, 3} {
+		req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 5000)
+		_, err := chain.PostOffLedgerRequestSync(req, nil, nonce)
+		require.Error(t, err)
+	}
+	_, ownerFee, _ := chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 1002, ownerFee)
+
+	// nonce older than the window is rejected even if it was never used
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 5000)
+	_, err := chain.PostOffLedgerRequestSync(req, nil, 100)
+	require.NoError(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 6000)
+	_, err = chain.PostOffLedgerRequestSync(req, nil, 100-accounts.NonceWindowSize-1)
+	require.Error(t, err)
+	_, err = chain.PostOffLedgerRequestSync(req, nil, 100-accounts.NonceWindowSize)
+	require.NoError(t, err)
+	_, ownerFee, _ = chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 6000, ownerFee)
+}
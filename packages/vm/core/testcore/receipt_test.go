@@ -0,0 +1,83 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiptOk(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(blob.Interface.Name, blob.FuncStoreBlob, "field", "data")
+	tx, res, err := chain.PostRequestSyncTx(req, nil)
+	require.NoError(t, err)
+
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+	receipt, ok := chain.GetRequestReceipt(reqID)
+	require.True(t, ok)
+	require.True(t, receipt.Succeeded())
+	require.EqualValues(t, reqID, receipt.RequestID)
+	require.EqualValues(t, chain.State.BlockIndex(), receipt.BlockIndex)
+	require.EqualValues(t, 0, receipt.RequestIndex)
+	require.EqualValues(t, res.MustGet(blob.ParamHash), receipt.Result.MustGet(blob.ParamHash))
+	require.EqualValues(t, 0, receipt.Fee)
+	require.Len(t, receipt.Events, 1)
+	require.EqualValues(t, blob.Interface.Hname(), receipt.Events[0].Contract)
+	require.Contains(t, receipt.Events[0].Message, "[blob]")
+}
+
+func TestReceiptFailed(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 10)
+	tx, _, err := chain.PostRequestSyncTx(req, user)
+	require.Error(t, err)
+
+	receipt, ok := chain.GetRequestReceipt(coretypes.NewRequestID(tx.ID(), 0))
+	require.True(t, ok)
+	require.False(t, receipt.Succeeded())
+	require.EqualValues(t, err.Error(), receipt.Error)
+	require.EqualValues(t, 0, len(receipt.Result))
+	require.Len(t, receipt.Events, 0)
+}
+
+func TestReceiptFees(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 10)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 100)
+	tx, _, err := chain.PostRequestSyncTx(req, user)
+	require.NoError(t, err)
+
+	receipt, ok := chain.GetRequestReceipt(coretypes.NewRequestID(tx.ID(), 0))
+	require.True(t, ok)
+	require.True(t, receipt.Succeeded())
+	require.EqualValues(t, balance.ColorIOTA, receipt.FeeColor)
+	require.EqualValues(t, 10, receipt.Fee)
+}
+
+func TestReceiptNotFound(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	_, ok := chain.GetRequestReceipt(coretypes.RequestID{})
+	require.False(t, ok)
+}
@@ -47,7 +47,7 @@ func TestGetInfo(t *testing.T) {
 	require.EqualValues(t, chain.ChainColor, info.ChainColor)
 	require.EqualValues(t, chain.ChainAddress, info.ChainAddress)
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
-	require.EqualValues(t, 4, len(contracts))
+	require.EqualValues(t, 6, len(contracts))
 
 	_, ok := contracts[root.Interface.Hname()]
 	require.True(t, ok)
@@ -74,7 +74,7 @@ func TestDeployExample(t *testing.T) {
 
 	require.EqualValues(t, chain.ChainID, info.ChainID)
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
-	require.EqualValues(t, 5, len(contracts))
+	require.EqualValues(t, 7, len(contracts))
 
 	_, ok := contracts[root.Interface.Hname()]
 	require.True(t, ok)
@@ -113,7 +113,7 @@ func TestDeployDouble(t *testing.T) {
 
 	require.EqualValues(t, chain.ChainID, info.ChainID)
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
-	require.EqualValues(t, 5, len(contracts))
+	require.EqualValues(t, 7, len(contracts))
 
 	_, ok := contracts[root.Interface.Hname()]
 	require.True(t, ok)
@@ -168,3 +168,30 @@ func TestChangeOwnerUnauthorized(t *testing.T) {
 	info, _ := chain.GetInfo()
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
 }
+
+func TestSetGasBudgetUnauthorized(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, coretypes.MinGasBudget)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+}
+
+func TestSetGasBudgetTooSmall(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, coretypes.MinGasBudget-1)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateCommittee(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 100)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	oldAddress := chain.ChainAddress
+	totalIotas := env.GetAddressBalance(oldAddress, balance.ColorIOTA)
+
+	newCommittee := signaturescheme.RandBLS()
+	err = chain.RotateCommittee(nil, newCommittee)
+	require.NoError(t, err)
+
+	// chain token and all assets moved to the new address, chain ID is the same
+	require.EqualValues(t, newCommittee.Address(), chain.ChainAddress)
+	env.AssertAddressBalance(oldAddress, chain.ChainColor, 0)
+	env.AssertAddressBalance(oldAddress, balance.ColorIOTA, 0)
+	env.AssertAddressBalance(chain.ChainAddress, chain.ChainColor, 1)
+	env.AssertAddressBalance(chain.ChainAddress, balance.ColorIOTA, totalIotas+1)
+
+	prop, err := chain.StateTx.Properties()
+	require.NoError(t, err)
+	require.EqualValues(t, chain.ChainID, *prop.MustChainID())
+	require.EqualValues(t, chain.ChainAddress, *prop.MustChainAddress())
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, chain.ChainID, info.ChainID)
+	require.EqualValues(t, chain.ChainAddress, info.ChainAddress)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 101)
+
+	// the new committee runs the chain
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetDefaultFee, root.ParamOwnerFee, 10)
+	_, err = chain.PostOffLedgerRequestSync(req, nil, 1)
+	require.NoError(t, err)
+	_, ownerFee, _ := chain.GetFeeInfo(accounts.Interface.Name)
+	require.EqualValues(t, 10, ownerFee)
+	env.AssertAddressBalance(chain.ChainAddress, chain.ChainColor, 1)
+}
+
+func TestRotateCommitteeNotAuthorized(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	oldAddress := chain.ChainAddress
+	req := solo.NewCallParams(root.Interface.Name, root.FuncRotateCommittee, root.ParamChainAddress, signaturescheme.RandBLS().Address())
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, oldAddress, info.ChainAddress)
+	env.AssertAddressBalance(oldAddress, chain.ChainColor, 1)
+}
+
+func TestRotateCommitteeNotBLS(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	oldAddress := chain.ChainAddress
+	// nobody can sign for the ED25519 address on behalf of the committee
+	err := chain.RotateCommittee(nil, env.NewSignatureScheme())
+	require.Error(t, err)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, oldAddress, info.ChainAddress)
+	env.AssertAddressBalance(oldAddress, chain.ChainColor, 1)
+}
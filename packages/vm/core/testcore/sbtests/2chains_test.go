@@ -82,3 +82,43 @@ func test2Chains(t *testing.T, w bool) {
 	chain2.AssertAccountBalance(accountsAgentID1, balance.ColorIOTA, 1) // !!!! TODO
 	chain2.AssertAccountBalance(accountsAgentID2, balance.ColorIOTA, 0)
 }
+
+func Test2ChainsPartialWithdraw(t *testing.T) { run2(t, test2ChainsPartialWithdraw, true) }
+func test2ChainsPartialWithdraw(t *testing.T, w bool) {
+	env := solo.New(t, false, false)
+	chain1 := env.NewChain(nil, "ch1")
+	chain2 := env.NewChain(nil, "ch2")
+
+	_, _ = setupTestSandboxSC(t, chain1, nil, w)
+	contractID2, _ := setupTestSandboxSC(t, chain2, nil, w)
+	contractAgentID2 := coretypes.NewAgentIDFromContractID(contractID2)
+
+	userWallet := env.NewSignatureSchemeWithFunds()
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit,
+		accounts.ParamAgentID, contractAgentID2,
+	).WithTransfer(
+		balance.ColorIOTA, 42,
+	)
+	_, err := chain1.PostRequestSync(req, userWallet)
+	require.NoError(t, err)
+	chain1.AssertAccountBalance(contractAgentID2, balance.ColorIOTA, 42)
+
+	req = solo.NewCallParams(sbtestsc.Name, sbtestsc.FuncWithdrawToChain,
+		sbtestsc.ParamChainID, chain1.ChainID,
+		sbtestsc.ParamAmount, 10,
+	).WithTransfer(
+		balance.ColorIOTA, 3,
+	)
+	_, err = chain2.PostRequestSync(req, userWallet)
+	require.NoError(t, err)
+
+	chain1.WaitForEmptyBacklog()
+	chain2.WaitForEmptyBacklog()
+
+	// one of 2 iotas posted with the request is the request token
+	chain1.AssertAccountBalance(contractAgentID2, balance.ColorIOTA, 42+1-10)
+	chain2.AssertAccountBalance(contractAgentID2, balance.ColorIOTA, 10)
+	chain1.CheckAccountLedger()
+	chain2.CheckAccountLedger()
+}
@@ -0,0 +1,112 @@
+package sbtests
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowance(t *testing.T) { run2(t, testAllowance, true) }
+func testAllowance(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+	cAID := coretypes.NewAgentIDFromContractID(cID)
+
+	owner := env.NewSignatureSchemeWithFunds()
+	ownerAgentID := coretypes.NewAgentIDFromAddress(owner.Address())
+	caller := env.NewSignatureSchemeWithFunds()
+	target := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, owner)
+	require.NoError(t, err)
+
+	par := accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 20})
+	par.Set(accounts.ParamSpender, codec.EncodeAgentID(cAID))
+	_, err = chain.PostRequestSync(solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncApprove, par), owner)
+	require.NoError(t, err)
+	require.EqualValues(t, 20, chain.GetAllowance(ownerAgentID, cAID).Balance(balance.ColorIOTA))
+	chain.AssertAccountBalance(ownerAgentID, balance.ColorIOTA, 42+2)
+
+	transferFrom := func(amount int64) error {
+		par := accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: amount})
+		par.Set(accounts.ParamOwner, codec.EncodeAgentID(ownerAgentID))
+		par.Set(accounts.ParamAgentID, codec.EncodeAgentID(target))
+		_, err := chain.PostRequestSync(solo.NewCallParamsFromDic(SandboxSCName, sbtestsc.FuncTransferFrom, par), caller)
+		return err
+	}
+
+	require.NoError(t, transferFrom(15))
+	chain.AssertAccountBalance(ownerAgentID, balance.ColorIOTA, 42+2-15)
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 15)
+	require.EqualValues(t, 5, chain.GetAllowance(ownerAgentID, cAID).Balance(balance.ColorIOTA))
+
+	// allowance is too low, nothing changes
+	require.Error(t, transferFrom(10))
+	chain.AssertAccountBalance(ownerAgentID, balance.ColorIOTA, 42+2-15)
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 15)
+	require.EqualValues(t, 5, chain.GetAllowance(ownerAgentID, cAID).Balance(balance.ColorIOTA))
+
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncRevoke, accounts.ParamSpender, cAID)
+	_, err = chain.PostRequestSync(req, owner)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, chain.GetAllowance(ownerAgentID, cAID).Len())
+
+	require.Error(t, transferFrom(1))
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 15)
+	chain.CheckAccountLedger()
+}
+
+func TestAllowanceNotEnoughFunds(t *testing.T) { run2(t, testAllowanceNotEnoughFunds, true) }
+func testAllowanceNotEnoughFunds(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+	cAID := coretypes.NewAgentIDFromContractID(cID)
+
+	owner := env.NewSignatureSchemeWithFunds()
+	ownerAgentID := coretypes.NewAgentIDFromAddress(owner.Address())
+
+	par := accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 100})
+	par.Set(accounts.ParamSpender, codec.EncodeAgentID(cAID))
+	_, err := chain.PostRequestSync(solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncApprove, par), owner)
+	require.NoError(t, err)
+
+	// the allowance is not spent if the transfer fails
+	par = accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 50})
+	par.Set(accounts.ParamOwner, codec.EncodeAgentID(ownerAgentID))
+	_, err = chain.PostRequestSync(solo.NewCallParamsFromDic(SandboxSCName, sbtestsc.FuncTransferFrom, par), nil)
+	require.Error(t, err)
+	require.EqualValues(t, 100, chain.GetAllowance(ownerAgentID, cAID).Balance(balance.ColorIOTA))
+	chain.AssertAccountBalance(cAID, balance.ColorIOTA, 0)
+	chain.CheckAccountLedger()
+}
+
+func TestTransferFromNotContract(t *testing.T) { run2(t, testTransferFromNotContract, true) }
+func testTransferFromNotContract(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+
+	owner := env.NewSignatureSchemeWithFunds()
+	ownerAgentID := coretypes.NewAgentIDFromAddress(owner.Address())
+	spender := env.NewSignatureSchemeWithFunds()
+	spenderAgentID := coretypes.NewAgentIDFromAddress(spender.Address())
+
+	par := accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 1})
+	par.Set(accounts.ParamSpender, codec.EncodeAgentID(spenderAgentID))
+	_, err := chain.PostRequestSync(solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncApprove, par), owner)
+	require.NoError(t, err)
+
+	par = accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 1})
+	par.Set(accounts.ParamOwner, codec.EncodeAgentID(ownerAgentID))
+	_, err = chain.PostRequestSync(solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncTransferFrom, par), spender)
+	require.Error(t, err)
+	chain.AssertAccountBalance(ownerAgentID, balance.ColorIOTA, 1)
+	chain.AssertAccountBalance(spenderAgentID, balance.ColorIOTA, 1)
+	require.EqualValues(t, 1, chain.GetAllowance(ownerAgentID, spenderAgentID).Balance(balance.ColorIOTA))
+}
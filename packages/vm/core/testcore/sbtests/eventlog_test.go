@@ -1,6 +1,7 @@
 package sbtests
 
 import (
+	"github.com/bytecodealliance/wasmtime-go"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
@@ -239,3 +240,131 @@ func testChainLogMultiple(t *testing.T, w bool) {
 	require.EqualValues(t, 1, strings.Count(strTest, "[Event]"))
 	require.EqualValues(t, 1, strings.Count(strTest, "33333"))
 }
+
+func TestEventlogTypedEvents(t *testing.T) { run2(t, testEventlogTypedEvents, true) }
+func testEventlogTypedEvents(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	var reqIDs []coretypes.RequestID
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogTypedEvent,
+			sbtestsc.VarCounter, i,
+		)
+		tx, _, err := chain.PostRequestSyncTx(req, user)
+		require.NoError(t, err)
+		reqIDs = append(reqIDs, coretypes.NewRequestID(tx.ID(), 0))
+	}
+	lastBlock := chain.State.BlockIndex()
+
+	events := chain.GetEvents(eventlog.ParamContractHname, coretypes.Hn(SandboxSCName))
+	require.Len(t, events, 5)
+	require.EqualValues(t, "counter", events[0].Name)
+	require.EqualValues(t, codec.EncodeInt64(5), events[0].Topics[0])
+	require.EqualValues(t, codec.EncodeInt64(5), events[0].Payload.MustGet(sbtestsc.VarCounter))
+	require.EqualValues(t, reqIDs[4], events[0].RequestID)
+	require.EqualValues(t, lastBlock, events[0].BlockIndex)
+
+	events = chain.GetEvents(eventlog.ParamEventName, "counter")
+	require.Len(t, events, 5)
+	events = chain.GetEvents(eventlog.ParamEventName, "nonexistent")
+	require.Len(t, events, 0)
+
+	events = chain.GetEvents(eventlog.ParamTopic, codec.EncodeInt64(3))
+	require.Len(t, events, 1)
+	require.EqualValues(t, reqIDs[2], events[0].RequestID)
+	events = chain.GetEvents(eventlog.ParamTopic, userAgentID[:])
+	require.Len(t, events, 5)
+
+	events = chain.GetEvents(eventlog.ParamRequestID, reqIDs[1])
+	require.Len(t, events, 1)
+	require.EqualValues(t, codec.EncodeInt64(2), events[0].Topics[0])
+
+	events = chain.GetEvents(eventlog.ParamFromBlock, lastBlock-1, eventlog.ParamToBlock, lastBlock)
+	require.Len(t, events, 2)
+	events = chain.GetEvents(eventlog.ParamFromBlock, lastBlock-1)
+	require.Len(t, events, 2)
+
+	// wrong block ranges are rejected
+	for _, par := range [][]interface{}{
+		{eventlog.ParamToBlock, -1},
+		{eventlog.ParamFromBlock, -1},
+		{eventlog.ParamFromBlock, lastBlock, eventlog.ParamToBlock, lastBlock - 1},
+		{eventlog.ParamToBlock, lastBlock + 1},
+		{eventlog.ParamFromBlock, lastBlock + 1},
+	} {
+		_, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents, par...)
+		require.Error(t, err)
+	}
+
+	events = chain.GetEvents(eventlog.ParamMaxLastRecords, 3)
+	require.Len(t, events, 3)
+	// 0 means the maximum number of events
+	events = chain.GetEvents(eventlog.ParamMaxLastRecords, 0)
+	require.Len(t, events, 5)
+
+	receipt, ok := chain.GetRequestReceipt(reqIDs[0])
+	require.True(t, ok)
+	require.Len(t, receipt.Events, 1)
+	require.True(t, strings.HasPrefix(receipt.Events[0].Message, "[counter]"))
+}
+
+// wasmEvents is a minimal Wasm contract which emits the plain event and the typed event 'transfer'
+// with the topic 'abc' through the 'event' key of the root object. The func 'emitInvalid' sets the key
+// with the wrong type. It is built from the text format, so the test does not need the Rust toolchain
+const wasmEvents = `(module
+  (import "wasplib" "hostGetObjectId" (func $getObjectId (param i32 i32 i32) (result i32)))
+  (import "wasplib" "hostSetBytes" (func $setBytes (param i32 i32 i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "emit")
+  (data (i32.const 16) "emitInvalid")
+  (data (i32.const 32) "plain event")
+  (data (i32.const 48) "\08transfer\01\03abc\00")
+  (func (export "on_load")
+    (local $exports i32)
+    ;; exports array of the root object: KeyExports, OBJTYPE_STRING | OBJTYPE_ARRAY
+    (local.set $exports (call $getObjectId (i32.const 1) (i32.const -18) (i32.const 0x2c)))
+    (call $setBytes (local.get $exports) (i32.const 0) (i32.const 12) (i32.const 0) (i32.const 4))
+    (call $setBytes (local.get $exports) (i32.const 1) (i32.const 12) (i32.const 16) (i32.const 11)))
+  (func (export "on_call_entrypoint") (param i32)
+    (if (i32.eq (local.get 0) (i32.const 1))
+      (then
+        ;; KeyEvent, OBJTYPE_INT64
+        (call $setBytes (i32.const 1) (i32.const -17) (i32.const 9) (i32.const 32) (i32.const 8)))
+      (else
+        ;; KeyEvent, OBJTYPE_STRING
+        (call $setBytes (i32.const 1) (i32.const -17) (i32.const 12) (i32.const 32) (i32.const 11))
+        ;; KeyEvent, OBJTYPE_BYTES
+        (call $setBytes (i32.const 1) (i32.const -17) (i32.const 3) (i32.const 48) (i32.const 15))))))`
+
+func TestEventlogTypedEventsWasm(t *testing.T) {
+	_, chain := setupChain(t, nil)
+
+	wasm, err := wasmtime.Wat2Wasm(wasmEvents)
+	require.NoError(t, err)
+	progHash, err := chain.UploadWasm(nil, wasm)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "events", progHash)
+	require.NoError(t, err)
+
+	_, err = chain.PostRequestSync(solo.NewCallParams("events", "emit"), nil)
+	require.NoError(t, err)
+	recs, err := chain.GetEventLogRecords("events")
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	// the latest first
+	require.EqualValues(t, "plain event", string(recs[1].Data))
+
+	events := chain.GetEvents(eventlog.ParamContractHname, coretypes.Hn("events"))
+	require.Len(t, events, 1)
+	require.EqualValues(t, "transfer", events[0].Name)
+	require.EqualValues(t, [][]byte{[]byte("abc")}, events[0].Topics)
+	require.Len(t, chain.GetEvents(eventlog.ParamTopic, []byte("abc")), 1)
+
+	_, err = chain.PostRequestSync(solo.NewCallParams("events", "emitInvalid"), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid type of event")
+	require.Len(t, chain.GetEvents(eventlog.ParamContractHname, coretypes.Hn("events")), 1)
+}
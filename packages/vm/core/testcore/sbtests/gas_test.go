@@ -0,0 +1,119 @@
+package sbtests
+
+import (
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfiniteLoop(t *testing.T) { run2(t, testInfiniteLoop, true) }
+func testInfiniteLoop(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncInfiniteLoop)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), coretypes.ErrGasBudgetExceeded.Error())
+
+	// all state changes of the request are rolled back
+	ret, err := chain.CallView(SandboxSCName, sbtestsc.FuncGetCounter)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, counter)
+
+	// the chain keeps working
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncIncCounter)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
+func TestSetGasBudget(t *testing.T) { run2(t, testSetGasBudget, true) }
+func testSetGasBudget(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.DefaultGasBudget, info.GasBudget)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, coretypes.MinGasBudget)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	info, _ = chain.GetInfo()
+	require.EqualValues(t, coretypes.MinGasBudget, info.GasBudget)
+
+	// budget is too small for the call
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncRunRecursion, sbtestsc.ParamIntParamValue, 100)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), coretypes.ErrGasBudgetExceeded.Error())
+
+	// back to default
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncRunRecursion, sbtestsc.ParamIntParamValue, 100)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
+// wasmInfiniteLoop is a minimal Wasm contract with a func and a view which never return.
+// It is built from the text format, so the test does not need the Rust toolchain
+const wasmInfiniteLoop = `(module
+  (import "wasplib" "hostGetObjectId" (func $getObjectId (param i32 i32 i32) (result i32)))
+  (import "wasplib" "hostSetBytes" (func $setBytes (param i32 i32 i32 i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "infiniteLoop")
+  (data (i32.const 16) "infiniteLoopView")
+  (func (export "on_load")
+    (local $exports i32)
+    ;; exports array of the root object: KeyExports, OBJTYPE_STRING | OBJTYPE_ARRAY
+    (local.set $exports (call $getObjectId (i32.const 1) (i32.const -18) (i32.const 0x2c)))
+    (call $setBytes (local.get $exports) (i32.const 0) (i32.const 12) (i32.const 0) (i32.const 12))
+    (call $setBytes (local.get $exports) (i32.const 0x8001) (i32.const 12) (i32.const 16) (i32.const 16)))
+  (func (export "on_call_entrypoint") (param i32)
+    (loop $forever (br $forever))))`
+
+func TestInfiniteLoopWasm(t *testing.T) {
+	_, chain := setupChain(t, nil)
+
+	wasm, err := wasmtime.Wat2Wasm(wasmInfiniteLoop)
+	require.NoError(t, err)
+	progHash, err := chain.UploadWasm(nil, wasm)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "loop", progHash)
+	require.NoError(t, err)
+
+	// the Wasm code is stopped by the gas budget
+	req := solo.NewCallParams("loop", "infiniteLoop")
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), coretypes.ErrGasBudgetExceeded.Error())
+
+	// views are bounded by the default gas budget
+	_, err = chain.CallView("loop", "infiniteLoopView")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), coretypes.ErrGasBudgetExceeded.Error())
+
+	// the chain keeps working
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetGasBudget, root.ParamGasBudget, coretypes.MinGasBudget)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	info, _ := chain.GetInfo()
+	require.EqualValues(t, coretypes.MinGasBudget, info.GasBudget)
+
+	// a smaller budget stops the Wasm code earlier, but the same way
+	req = solo.NewCallParams("loop", "infiniteLoop")
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), coretypes.ErrGasBudgetExceeded.Error())
+}
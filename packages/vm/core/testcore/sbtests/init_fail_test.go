@@ -29,11 +29,11 @@ func TestFailRepeat(t *testing.T) {
 		sbtestsc.ParamFail, 1)
 	require.Error(t, err)
 	_, rec := chain.GetInfo()
-	require.EqualValues(t, 4, len(rec))
+	require.EqualValues(t, 6, len(rec))
 
 	// repeat must succeed
 	err = chain.DeployContract(nil, sbtestsc.Name, sbtestsc.Interface.ProgramHash)
 	require.NoError(t, err)
 	_, rec = chain.GetInfo()
-	require.EqualValues(t, 5, len(rec))
+	require.EqualValues(t, 7, len(rec))
 }
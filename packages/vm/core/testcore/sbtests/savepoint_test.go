@@ -0,0 +1,68 @@
+package sbtests
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+const sandboxSCName2 = "test_sandbox2"
+
+func getCounter(t *testing.T, chain *solo.Chain, scName string) int64 {
+	ret, err := chain.CallView(scName, sbtestsc.FuncGetCounter)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	return counter
+}
+
+func TestCallAndCatch(t *testing.T) { run2(t, testCallAndCatch, true) }
+func testCallAndCatch(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+	err := chain.DeployContract(nil, sandboxSCName2, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	cID2 := coretypes.NewContractID(chain.ChainID, coretypes.Hn(sandboxSCName2))
+
+	req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallAndCatch,
+		sbtestsc.ParamHnameContract, cID2.Hname(),
+	).WithTransfer(balance.ColorIOTA, 42)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// effects of the caller are kept
+	require.EqualValues(t, 1, getCounter(t, chain, SandboxSCName))
+	chain.AssertAccountBalance(coretypes.NewAgentIDFromContractID(cID), balance.ColorIOTA, 42)
+
+	// effects of the failed call, including the transfer, are rolled back
+	require.EqualValues(t, 0, getCounter(t, chain, sandboxSCName2))
+	chain.AssertAccountBalance(coretypes.NewAgentIDFromContractID(cID2), balance.ColorIOTA, 0)
+	chain.CheckAccountLedger()
+}
+
+func TestCallAndCatchNotCaught(t *testing.T) { run2(t, testCallAndCatchNotCaught, true) }
+func testCallAndCatchNotCaught(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+	err := chain.DeployContract(nil, sandboxSCName2, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	cID2 := coretypes.NewContractID(chain.ChainID, coretypes.Hn(sandboxSCName2))
+
+	// the error of the nested call is returned to the request: everything is rolled back
+	req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallOnChain,
+		sbtestsc.ParamIntParamValue, 1,
+		sbtestsc.ParamHnameContract, cID2.Hname(),
+		sbtestsc.ParamHnameEP, coretypes.Hn(sbtestsc.FuncIncCounterAndPanic),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), sbtestsc.MsgIncCounterPanic)
+
+	require.EqualValues(t, 0, getCounter(t, chain, SandboxSCName))
+	require.EqualValues(t, 0, getCounter(t, chain, sandboxSCName2))
+}
@@ -34,6 +34,22 @@ func testEventLogEventData(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// testEventLogTypedEvent emits typed event 'counter' with the counter and the sender as topics
+func testEventLogTypedEvent(ctx coretypes.Sandbox) (dict.Dict, error) {
+	inc, ok, err := codec.DecodeInt64(ctx.Params().MustGet(VarCounter))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		inc = 1
+	}
+	sender := ctx.Caller()
+	payload := dict.New()
+	payload.Set(VarCounter, codec.EncodeInt64(inc))
+	ctx.EmitEvent("counter", [][]byte{codec.EncodeInt64(inc), sender[:]}, payload)
+	return nil, nil
+}
+
 func testChainOwnerIDView(ctx coretypes.SandboxView) (dict.Dict, error) {
 	cOwnerID := ctx.ChainOwnerID()
 	ret := dict.New()
@@ -11,13 +11,19 @@ import (
 )
 
 // calls withdrawToChain to the chain ID
+// withdraws ParamAmount iotas if specified, otherwise all funds
 func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Infof(FuncWithdrawToChain)
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 	targetChain := params.MustGetChainID(ParamChainID)
+	var withdrawParams dict.Dict
+	if amount := params.MustGetInt64(ParamAmount, 0); amount > 0 {
+		withdrawParams = accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: amount})
+	}
 	succ := ctx.PostRequest(coretypes.PostRequestParams{
 		TargetContractID: accounts.Interface.ContractID(targetChain),
 		EntryPoint:       coretypes.Hn(accounts.FuncWithdrawToChain),
+		Params:           withdrawParams,
 		Transfer: cbalances.NewFromMap(map[balance.Color]int64{
 			balance.ColorIOTA: 2,
 		}),
@@ -28,3 +34,10 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Infof("%s: success", FuncWithdrawToChain)
 	return nil, nil
 }
+
+// calls accounts.transferFrom with the same params, i.e. takes funds from the account of the owner
+// within the allowance of this contract
+func transferFrom(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Infof(FuncTransferFrom)
+	return ctx.Call(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncTransferFrom), ctx.Params(), nil)
+}
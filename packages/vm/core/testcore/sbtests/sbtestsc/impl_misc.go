@@ -129,3 +129,51 @@ func getInt(ctx coretypes.SandboxView) (dict.Dict, error) {
 	ret.Set(kv.Key(paramName), codec.EncodeInt64(paramValue))
 	return ret, nil
 }
+
+// infiniteLoop increments the counter forever. It only stops when the gas budget is exhausted
+func infiniteLoop(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := kvdecoder.New(ctx.State(), ctx.Log())
+	for {
+		counter := state.MustGetInt64(VarCounter, 0)
+		ctx.State().Set(VarCounter, codec.EncodeInt64(counter+1))
+	}
+}
+
+// migrate is called by the 'root' when the contract is upgraded to this program.
+// ParamIntParamValue, if present, is stored as the new value of the counter.
+// ParamFail, if present, makes the upgrade fail
+func migrate(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert2.NewAssert(ctx.Log())
+	a.Require(ctx.Params().MustGet(ParamFail) == nil, "failing on purpose")
+
+	state := kvdecoder.New(ctx.State(), ctx.Log())
+	counter := params.MustGetInt64(ParamIntParamValue, state.MustGetInt64(VarCounter, 0))
+	ctx.State().Set(VarCounter, codec.EncodeInt64(counter))
+	return nil, nil
+}
+
+func incCounterAndPanic(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := kvdecoder.New(ctx.State(), ctx.Log())
+	counter := state.MustGetInt64(VarCounter, 0)
+	ctx.State().Set(VarCounter, codec.EncodeInt64(counter+1))
+	ctx.Log().Panicf(MsgIncCounterPanic)
+	return nil, nil
+}
+
+// callAndCatch increments the counter and calls 'incCounterAndPanic' of ParamHnameContract
+// with the incoming transfer. The error of the call is ignored
+func callAndCatch(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	hnameContract := params.MustGetHname(ParamHnameContract)
+
+	state := kvdecoder.New(ctx.State(), ctx.Log())
+	counter := state.MustGetInt64(VarCounter, 0)
+	ctx.State().Set(VarCounter, codec.EncodeInt64(counter+1))
+
+	_, err := ctx.Call(hnameContract, coretypes.Hn(FuncIncCounterAndPanic), nil, ctx.IncomingTransfer())
+	if err != nil {
+		ctx.Log().Infof("callAndCatch: error caught: %v", err)
+	}
+	return nil, nil
+}
@@ -3,6 +3,7 @@ package sbtestsc
 
 import (
 	"github.com/iotaledger/wasp/contracts/native"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
 	"github.com/iotaledger/wasp/packages/hashing"
 )
@@ -31,6 +32,7 @@ func init() {
 		coreutil.Func(FuncEventLogGenericData, testEventLogGenericData),
 		coreutil.Func(FuncEventLogEventData, testEventLogEventData),
 		coreutil.Func(FuncEventLogDeploy, testEventLogDeploy),
+		coreutil.Func(FuncEventLogTypedEvent, testEventLogTypedEvent),
 		coreutil.ViewFunc(FuncSandboxCall, testSandboxCall),
 
 		coreutil.Func(FuncPanicFullEP, testPanicFullEP),
@@ -43,6 +45,7 @@ func init() {
 		coreutil.Func(FuncSendToAddress, sendToAddress),
 
 		coreutil.Func(FuncWithdrawToChain, withdrawToChain),
+		coreutil.Func(FuncTransferFrom, transferFrom),
 		coreutil.Func(FuncCallOnChain, callOnChain),
 		coreutil.Func(FuncSetInt, setInt),
 		coreutil.ViewFunc(FuncGetInt, getInt),
@@ -50,6 +53,10 @@ func init() {
 		coreutil.Func(FuncIncCounter, incCounter),
 		coreutil.ViewFunc(FuncGetCounter, getCounter),
 		coreutil.Func(FuncRunRecursion, runRecursion),
+		coreutil.Func(FuncInfiniteLoop, infiniteLoop),
+		coreutil.Func(FuncIncCounterAndPanic, incCounterAndPanic),
+		coreutil.Func(FuncCallAndCatch, callAndCatch),
+		coreutil.Func(coretypes.FuncMigrate, migrate),
 
 		coreutil.Func(FuncPassTypesFull, passTypesFull),
 		coreutil.ViewFunc(FuncPassTypesView, passTypesView),
@@ -66,6 +73,7 @@ const (
 	FuncEventLogGenericData = "testEventLogGenericData"
 	FuncEventLogEventData   = "testEventLogEventData"
 	FuncEventLogDeploy      = "testEventLogDeploy"
+	FuncEventLogTypedEvent  = "testEventLogTypedEvent"
 
 	//Function sandbox test
 	FuncChainOwnerIDView = "testChainOwnerIDView"
@@ -85,6 +93,7 @@ const (
 	FuncCallPanicViewEPFromView = "testCallPanicViewEPFromView"
 
 	FuncWithdrawToChain = "withdrawToChain"
+	FuncTransferFrom    = "transferFrom"
 
 	FuncDoNothing     = "doNothing"
 	FuncSendToAddress = "sendToAddress"
@@ -97,6 +106,10 @@ const (
 	FuncGetCounter   = "getCounter"
 	FuncIncCounter   = "incCounter"
 	FuncRunRecursion = "runRecursion"
+	FuncInfiniteLoop = "infiniteLoop"
+
+	FuncIncCounterAndPanic = "incCounterAndPanic"
+	FuncCallAndCatch       = "callAndCatch"
 
 	FuncPassTypesFull = "passTypesFull"
 	FuncPassTypesView = "passTypesView"
@@ -121,10 +134,12 @@ const (
 	ParamIntParamValue   = "intParamValue"
 	ParamHnameContract   = "hnameContract"
 	ParamHnameEP         = "hnameEP"
+	ParamAmount          = "amount"
 
 	// error fragments for testing
 	MsgFullPanic         = "========== panic FULL ENTRY POINT ========="
 	MsgViewPanic         = "========== panic VIEW ========="
 	MsgDoNothing         = "========== doing nothing"
 	MsgPanicUnauthorized = "============== panic due to unauthorized call"
+	MsgIncCounterPanic   = "========== panic after incrementing the counter"
 )
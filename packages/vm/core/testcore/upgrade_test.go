@@ -0,0 +1,181 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+const upgradeName = "counter"
+
+func setupUpgrade(t *testing.T) (*solo.Solo, *solo.Chain) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.DeployContract(nil, upgradeName, inccounter.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	req := solo.NewCallParams(upgradeName, inccounter.FuncIncCounter)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	return env, chain
+}
+
+func checkUpgradeCounter(t *testing.T, chain *solo.Chain, expected int64) {
+	ret, err := chain.CallView(upgradeName, sbtestsc.FuncGetCounter)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, expected, counter)
+}
+
+func TestUpgradeContract(t *testing.T) {
+	_, chain := setupUpgrade(t)
+
+	err := chain.UpgradeContract(nil, upgradeName, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	rec, err := chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, sbtestsc.Interface.ProgramHash, rec.ProgramHash)
+
+	// state is preserved
+	checkUpgradeCounter(t, chain, 2)
+
+	// entry points of the new program
+	req := solo.NewCallParams(upgradeName, sbtestsc.FuncIncCounter)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	checkUpgradeCounter(t, chain, 3)
+
+	recs, err := chain.GetEventLogRecordsString(root.Interface.Name)
+	require.NoError(t, err)
+	require.Contains(t, recs, "[upgrade] name: "+upgradeName)
+}
+
+func TestUpgradeContractMigrate(t *testing.T) {
+	_, chain := setupUpgrade(t)
+
+	err := chain.UpgradeContract(nil, upgradeName, sbtestsc.Interface.ProgramHash, sbtestsc.ParamIntParamValue, 42)
+	require.NoError(t, err)
+	checkUpgradeCounter(t, chain, 42)
+}
+
+func TestUpgradeContractMigrateFail(t *testing.T) {
+	_, chain := setupUpgrade(t)
+
+	err := chain.UpgradeContract(nil, upgradeName, sbtestsc.Interface.ProgramHash,
+		sbtestsc.ParamFail, 1, sbtestsc.ParamIntParamValue, 42)
+	require.Error(t, err)
+
+	rec, err := chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, inccounter.Interface.ProgramHash, rec.ProgramHash)
+	checkUpgradeCounter(t, chain, 2)
+}
+
+func TestUpgradeContractByCreator(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	creator := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncGrantDeploy,
+		root.ParamDeployer, coretypes.NewAgentIDFromAddress(creator.Address()))
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	err = chain.DeployContract(creator, upgradeName, inccounter.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	err = chain.UpgradeContract(creator, upgradeName, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	// neither creator nor chain owner
+	other := env.NewSignatureSchemeWithFunds()
+	err = chain.UpgradeContract(other, upgradeName, inccounter.Interface.ProgramHash)
+	require.Error(t, err)
+
+	rec, err := chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, sbtestsc.Interface.ProgramHash, rec.ProgramHash)
+}
+
+func TestUpgradeCoreContract(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.UpgradeContract(nil, accounts.Interface.Name, sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+}
+
+func TestUpgradeContractNotFound(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.UpgradeContract(nil, upgradeName, sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+}
+
+func TestUpgradeContractDescription(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.DeployContract(nil, upgradeName, inccounter.Interface.ProgramHash, root.ParamDescription, "old")
+	require.NoError(t, err)
+
+	err = chain.UpgradeContract(nil, upgradeName, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	rec, err := chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, "old", rec.Description)
+
+	err = chain.UpgradeContract(nil, upgradeName, inccounter.Interface.ProgramHash, root.ParamDescription, "new")
+	require.NoError(t, err)
+	rec, err = chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, "new", rec.Description)
+	require.EqualValues(t, inccounter.Interface.ProgramHash, rec.ProgramHash)
+}
+
+func TestUpgradeContractLoadFail(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.DeployContract(nil, upgradeName, inccounter.Interface.ProgramHash, root.ParamDescription, "old")
+	require.NoError(t, err)
+
+	// no blob and no builtin program with the hash
+	err = chain.UpgradeContract(nil, upgradeName, hashing.HashStrings("no such program"), root.ParamDescription, "new")
+	require.Error(t, err)
+
+	rec, err := chain.FindContract(upgradeName)
+	require.NoError(t, err)
+	require.EqualValues(t, inccounter.Interface.ProgramHash, rec.ProgramHash)
+	require.EqualValues(t, "old", rec.Description)
+}
+
+func TestCallMigrateDirectly(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.DeployContract(nil, upgradeName, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	req := solo.NewCallParams(upgradeName, coretypes.FuncMigrate, sbtestsc.ParamIntParamValue, 42)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	checkUpgradeCounter(t, chain, 0)
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package testcore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVestingLinearWithCliff(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	distributor := env.NewSignatureSchemeWithFunds()
+	beneficiary := env.NewSignatureSchemeWithFunds()
+	beneficiaryAgentID := coretypes.NewAgentIDFromAddress(beneficiary.Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+		accounts.ParamAgentID, beneficiaryAgentID,
+		accounts.ParamVestingCliff, 100,
+		accounts.ParamVestingDuration, 1000,
+	).WithTransfer(balance.ColorIOTA, 100)
+	_, err := chain.PostRequestSync(req, distributor)
+	require.NoError(t, err)
+	chain.CheckAccountLedger()
+
+	chain.AssertAccountBalance(beneficiaryAgentID, balance.ColorIOTA, 100)
+	require.EqualValues(t, 100, chain.GetLockedBalance(beneficiaryAgentID).Balance(balance.ColorIOTA))
+	require.EqualValues(t, 0, chain.GetUnlockedBalance(beneficiaryAgentID).Balance(balance.ColorIOTA))
+	schedules := chain.GetVestingSchedules(beneficiaryAgentID)
+	require.Len(t, schedules, 1)
+	require.EqualValues(t, 100, schedules[0].Amount)
+	require.EqualValues(t, 100, schedules[0].Cliff)
+	require.EqualValues(t, 1000, schedules[0].Duration)
+
+	// before the cliff nothing is released
+	env.AdvanceClockBy(50 * time.Second)
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 10}))
+	_, err = chain.PostRequestSync(req, beneficiary)
+	require.Error(t, err)
+	// the request token is not locked
+	chain.AssertAccountBalance(beneficiaryAgentID, balance.ColorIOTA, 100+1)
+
+	// withdrawal of everything takes only the unlocked part
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToAddress)
+	_, err = chain.PostRequestSync(req, beneficiary)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(beneficiaryAgentID, balance.ColorIOTA, 100)
+	env.AssertAddressBalance(beneficiary.Address(), balance.ColorIOTA, testutil.RequestFundsAmount)
+
+	// half of the vesting period passed
+	env.AdvanceClockBy(450 * time.Second)
+	require.EqualValues(t, 50, chain.GetLockedBalance(beneficiaryAgentID).Balance(balance.ColorIOTA))
+	require.EqualValues(t, 50, chain.GetUnlockedBalance(beneficiaryAgentID).Balance(balance.ColorIOTA))
+
+	target := coretypes.NewAgentIDFromAddress(env.NewSignatureSchemeWithFunds().Address())
+	err = chain.TransferOnChain(beneficiary, target, map[balance.Color]int64{balance.ColorIOTA: 60})
+	require.Error(t, err)
+	err = chain.TransferOnChain(beneficiary, target, map[balance.Color]int64{balance.ColorIOTA: 40})
+	require.NoError(t, err)
+	chain.AssertAccountBalance(target, balance.ColorIOTA, 40)
+	chain.AssertAccountBalance(beneficiaryAgentID, balance.ColorIOTA, 100+1+1-40)
+
+	// the vesting period is over
+	env.AdvanceClockBy(500 * time.Second)
+	require.EqualValues(t, 0, chain.GetLockedBalance(beneficiaryAgentID).Balance(balance.ColorIOTA))
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToAddress)
+	_, err = chain.PostRequestSync(req, beneficiary)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(beneficiaryAgentID, balance.ColorIOTA, 0)
+	env.AssertAddressBalance(beneficiary.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-40+100)
+	chain.CheckAccountLedger()
+}
+
+func TestVestingFixedTimestamp(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+
+	unlockAt := env.LogicalTime().Add(time.Hour).Unix()
+	// locks own tokens
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+		accounts.ParamVestingStart, unlockAt,
+	).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+	require.EqualValues(t, 42, chain.GetLockedBalance(userAgentID).Balance(balance.ColorIOTA))
+
+	req = solo.NewCallParamsFromDic(accounts.Interface.Name, accounts.FuncWithdrawToAddress,
+		accounts.EncodeBalances(map[balance.Color]int64{balance.ColorIOTA: 42}))
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	env.AdvanceClockBy(59 * time.Minute)
+	require.EqualValues(t, 42, chain.GetLockedBalance(userAgentID).Balance(balance.ColorIOTA))
+	env.AdvanceClockBy(time.Minute)
+	require.EqualValues(t, 0, chain.GetLockedBalance(userAgentID).Balance(balance.ColorIOTA))
+
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1+1+1)
+	chain.CheckAccountLedger()
+}
+
+func TestVestingWrongParams(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+		accounts.ParamVestingDuration, -1,
+	).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	// nothing to lock
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	require.EqualValues(t, 0, len(chain.GetVestingSchedules(userAgentID)))
+	chain.CheckAccountLedger()
+}
+
+func TestVestingFlooding(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	griefer := env.NewSignatureSchemeWithFunds()
+	victim := env.NewSignatureSchemeWithFunds()
+	victimAgentID := coretypes.NewAgentIDFromAddress(victim.Address())
+
+	// dust can't be locked
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+		accounts.ParamAgentID, victimAgentID,
+		accounts.ParamVestingStart, 1<<40,
+	).WithTransfer(balance.ColorIOTA, accounts.MinVestingAmount-1)
+	_, err := chain.PostRequestSync(req, env.NewSignatureSchemeWithFunds())
+	require.Error(t, err)
+
+	for i := 0; i < accounts.MaxVestingSchedules; i++ {
+		req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+			accounts.ParamAgentID, victimAgentID,
+			accounts.ParamVestingStart, 1<<40+i,
+		).WithTransfer(balance.ColorIOTA, accounts.MinVestingAmount)
+		_, err = chain.PostRequestSync(req, griefer)
+		require.NoError(t, err)
+	}
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDepositLocked,
+		accounts.ParamAgentID, victimAgentID,
+		accounts.ParamVestingStart, 1<<41,
+	).WithTransfer(balance.ColorIOTA, accounts.MinVestingAmount)
+	_, err = chain.PostRequestSync(req, griefer)
+	require.Error(t, err)
+
+	require.EqualValues(t, accounts.MaxVestingSchedules, len(chain.GetVestingSchedules(victimAgentID)))
+	chain.CheckAccountLedger()
+}
@@ -2,7 +2,6 @@ package runvm
 
 import (
 	"fmt"
-	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/vm/statetxbuilder"
 	"github.com/iotaledger/wasp/packages/vm/vmcontext"
@@ -20,14 +19,13 @@ func RunComputationsAsync(ctx *vm.VMTask) error {
 		return fmt.Errorf("RunComputationsAsync: must be at least 1 request")
 	}
 
-	txb, err := statetxbuilder.New(address.Address(ctx.ChainID), ctx.Color, ctx.Balances)
+	txb, err := statetxbuilder.New(ctx.ChainAddress, ctx.Color, ctx.Balances)
 	if err != nil {
 		ctx.Log.Debugf("statetxbuilder.New: %v", err)
 		return err
 	}
 
 	// TODO 1 graceful shutdown of the running VM task (with daemon)
-	// TODO 2 timeout for VM. Gas limit
 
 	go runTask(ctx, txb)
 	return nil
@@ -55,11 +53,11 @@ func runTask(task *vm.VMTask, txb *statetxbuilder.Builder) {
 	// loop over the batch of requests and run each request on the VM.
 	// the result accumulates in the VMContext and in the list of stateUpdates
 	timestamp := task.Timestamp
-	for _, reqRef := range task.Requests {
+	for i, reqRef := range task.Requests {
 		if reqRef.RequestSection().SolidArgs() == nil {
 			task.Log.Panicf("inconsistency: request args have not been solidified")
 		}
-		vmctx.RunTheRequest(reqRef, timestamp)
+		vmctx.RunTheRequest(reqRef, uint16(i), timestamp)
 		lastStateUpdate, lastResult, lastErr = vmctx.GetResult()
 
 		stateUpdates = append(stateUpdates, lastStateUpdate)
@@ -88,6 +86,7 @@ func runTask(task *vm.VMTask, txb *statetxbuilder.Builder) {
 	task.ResultTransaction, err = vmctx.FinalizeTransactionEssence(
 		task.VirtualState.BlockIndex()+1,
 		stateHash,
+		vsClone.StateRoot(),
 		vsClone.Timestamp(),
 	)
 	if err != nil {
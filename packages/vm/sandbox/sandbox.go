@@ -36,6 +36,10 @@ func (s *sandbox) ChainOwnerID() coretypes.AgentID {
 	return s.vmctx.ChainOwnerID()
 }
 
+func (s *sandbox) CheckAuthorizationByChainOwner(agentID coretypes.AgentID) bool {
+	return s.vmctx.CheckAuthorizationByChainOwner(agentID)
+}
+
 func (s *sandbox) ContractCreator() coretypes.AgentID {
 	return s.vmctx.ContractCreator()
 }
@@ -63,11 +67,13 @@ func (s *sandbox) Caller() coretypes.AgentID {
 // DeployContract deploys contract by the binary hash
 // and calls "init" endpoint (constructor) with provided parameters
 func (s *sandbox) DeployContract(programHash hashing.HashValue, name string, description string, initParams dict.Dict) error {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
 	return s.vmctx.DeployContract(programHash, name, description, initParams)
 }
 
 // Call calls an entry point of contact, passes parameters and funds
 func (s *sandbox) Call(contractHname coretypes.Hname, entryPoint coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) (dict.Dict, error) {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
 	return s.vmctx.Call(contractHname, entryPoint, params, transfer)
 }
 
@@ -85,10 +91,12 @@ func (s *sandbox) GetEntropy() hashing.HashValue {
 }
 
 func (s *sandbox) TransferToAddress(targetAddr address.Address, transfer coretypes.ColoredBalances) bool {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
 	return s.vmctx.TransferToAddress(targetAddr, transfer)
 }
 
 func (s *sandbox) PostRequest(par coretypes.PostRequestParams) bool {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
 	return s.vmctx.PostRequest(par)
 }
 
@@ -97,11 +105,27 @@ func (s *sandbox) Log() coretypes.LogInterface {
 }
 
 func (s *sandbox) Event(msg string) {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
 	s.Log().Infof("eventlog::%s -> '%s'", s.vmctx.CurrentContractHname(), msg)
 	s.vmctx.StoreToEventLog(s.vmctx.CurrentContractHname(), []byte(msg))
 	s.vmctx.EventPublisher().Publish(msg)
 }
 
+func (s *sandbox) EmitEvent(name string, topics [][]byte, payload dict.Dict) {
+	s.vmctx.BurnGas(coretypes.GasSandboxCall)
+	msg := s.vmctx.StoreEventToEventLog(s.vmctx.CurrentContractHname(), name, topics, payload)
+	s.Log().Infof("eventlog::%s -> '%s'", s.vmctx.CurrentContractHname(), msg)
+	s.vmctx.EventPublisher().Publish(msg)
+}
+
+func (s *sandbox) BurnGas(gas int64) {
+	s.vmctx.BurnGas(gas)
+}
+
+func (s *sandbox) GasRemaining() int64 {
+	return s.vmctx.GasRemaining()
+}
+
 func (s *sandbox) IncomingTransfer() coretypes.ColoredBalances {
 	return s.vmctx.GetIncoming()
 }
@@ -15,6 +15,7 @@ import (
 type Builder struct {
 	vtxb            *vtxBuilder
 	chainAddress    address.Address
+	numErased       int64 // number of request tokens erased to iotas in the output to the chain address
 	stateSection    *sctransaction.StateSection
 	requestSections []*sctransaction.RequestSection
 }
@@ -41,6 +42,7 @@ func (txb *Builder) Clone() *Builder {
 	ret := &Builder{
 		vtxb:            txb.vtxb.clone(),
 		chainAddress:    txb.chainAddress,
+		numErased:       txb.numErased,
 		stateSection:    txb.stateSection.Clone(),
 		requestSections: make([]*sctransaction.RequestSection, len(txb.requestSections)),
 	}
@@ -55,6 +57,42 @@ func (txb *Builder) SetStateParams(stateIndex uint32, stateHash hashing.HashValu
 	return nil
 }
 
+// SetChainID stores chain ID in the state section. It identifies the chain when
+// the chain token is not held by the chain ID address, i.e. after the committee was rotated
+func (txb *Builder) SetChainID(chainID coretypes.ChainID) {
+	txb.stateSection.WithChainID(chainID)
+}
+
+// ChainAddress is the address which holds the chain token and all assets of the chain
+func (txb *Builder) ChainAddress() address.Address {
+	return txb.chainAddress
+}
+
+// MoveChainTo moves the chain token and all assets of the chain to the new address.
+// It hands over the chain to the committee which controls the new address.
+// Outputs of requests sent to the chain itself are not moved
+func (txb *Builder) MoveChainTo(addr address.Address) error {
+	if addr == txb.chainAddress {
+		return nil
+	}
+	if err := txb.vtxb.moveOutput(txb.chainAddress, addr, txb.stateSection.Color(), 1); err != nil {
+		return err
+	}
+	if txb.numErased > 0 {
+		if err := txb.vtxb.moveOutput(txb.chainAddress, addr, balance.ColorIOTA, txb.numErased); err != nil {
+			return err
+		}
+	}
+	txb.vtxb.reminderAddr = addr
+	txb.chainAddress = addr
+	return nil
+}
+
+// SetStateRoot sets the root of the Merkle tree of the state
+func (txb *Builder) SetStateRoot(stateRoot hashing.HashValue) {
+	txb.stateSection.WithStateRoot(stateRoot)
+}
+
 // AddRequestSectionWithTransfer adds request block with the request
 // token and adds respective outputs for the colored transfers
 func (txb *Builder) AddRequestSection(req *sctransaction.RequestSection) error {
@@ -87,7 +125,11 @@ func (txb *Builder) Balance(col balance.Color) int64 {
 }
 
 func (txb *Builder) Erase1TokenToChain(col balance.Color) bool {
-	return txb.vtxb.EraseColor(txb.chainAddress, col, 1) == nil
+	if txb.vtxb.EraseColor(txb.chainAddress, col, 1) != nil {
+		return false
+	}
+	txb.numErased++
+	return true
 }
 
 func (txb *Builder) Build() (*sctransaction.Transaction, error) {
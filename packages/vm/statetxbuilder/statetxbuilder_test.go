@@ -1,12 +1,15 @@
 package statetxbuilder
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	_ "github.com/iotaledger/wasp/packages/sctransaction/properties"
+	"github.com/iotaledger/wasp/packages/txutil"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -79,3 +82,53 @@ func TestClone(t *testing.T) {
 
 	require.EqualValues(t, tx.ID(), tx1.ID())
 }
+
+func TestMoveChainTo(t *testing.T) {
+	chSig := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	chAddr := chSig.Address()
+	newAddr := signaturescheme.ED25519(ed25519.GenerateKeyPair()).Address()
+	col1, _, err := balance.ColorFromBytes(hashing.RandomHash(nil).Bytes())
+	require.NoError(t, err)
+	txid1, _, err := transaction.IDFromBytes(hashing.RandomHash(nil).Bytes())
+	require.NoError(t, err)
+	txid2, _, err := transaction.IDFromBytes(hashing.RandomHash(nil).Bytes())
+	require.NoError(t, err)
+
+	inps := map[transaction.ID][]*balance.Balance{
+		txid1: {
+			balance.New(col1, 1),
+			balance.New(balance.ColorIOTA, 3),
+		},
+		txid2: {
+			balance.New(balance.Color(txid2), 1),
+			balance.New(balance.ColorIOTA, 5),
+		},
+	}
+	b, err := New(chAddr, col1, inps)
+	require.NoError(t, err)
+	require.True(t, b.Erase1TokenToChain(balance.Color(txid2)))
+
+	err = b.MoveChainTo(newAddr)
+	require.NoError(t, err)
+	require.EqualValues(t, newAddr, b.ChainAddress())
+	b.SetChainID(coretypes.ChainID(chAddr))
+
+	tx, err := b.Build()
+	require.NoError(t, err)
+	tx.Sign(chSig)
+
+	var numOutputs int
+	tx.Outputs().ForEach(func(addr address.Address, bals []*balance.Balance) bool {
+		numOutputs++
+		require.EqualValues(t, newAddr, addr)
+		require.EqualValues(t, 1, txutil.BalanceOfColor(bals, col1))
+		require.EqualValues(t, 9, txutil.BalanceOfColor(bals, balance.ColorIOTA))
+		return true
+	})
+	require.EqualValues(t, 1, numOutputs)
+
+	prop, err := tx.Properties()
+	require.NoError(t, err)
+	require.EqualValues(t, coretypes.ChainID(chAddr), *prop.MustChainID())
+	require.EqualValues(t, newAddr, *prop.MustChainAddress())
+}
@@ -220,6 +220,23 @@ func (vtxb *vtxBuilder) addToOutputs(targetAddr address.Address, col balance.Col
 	cmap[col] = b + amount
 }
 
+// moveOutput moves tokens already assigned to the output to one address to the output to another address
+func (vtxb *vtxBuilder) moveOutput(fromAddr, toAddr address.Address, col balance.Color, amount int64) error {
+	cmap, ok := vtxb.outputBalances[fromAddr]
+	if !ok || cmap[col] < amount {
+		return errorNotEnoughBalance
+	}
+	cmap[col] -= amount
+	if cmap[col] == 0 {
+		delete(cmap, col)
+	}
+	if len(cmap) == 0 {
+		delete(vtxb.outputBalances, fromAddr)
+	}
+	vtxb.addToOutputs(toAddr, col, amount)
+	return nil
+}
+
 // MoveTokens move token without changing color
 func (vtxb *vtxBuilder) MoveTokens(targetAddr address.Address, col balance.Color, amount int64, filterTxid ...valuetransaction.ID) error {
 	if vtxb.GetInputBalance(col, filterTxid...) < amount {
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"bytes"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/hive.go/logger"
@@ -25,6 +26,9 @@ type VMTask struct {
 	// inputs (immutable)
 	ChainID coretypes.ChainID
 	Color   balance.Color
+	// address of the committee which holds the chain token and Balances.
+	// It is equal to ChainID unless the committee was rotated
+	ChainAddress address.Address
 	// deterministic source of entropy
 	Entropy            hashing.HashValue
 	Balances           map[valuetransaction.ID][]*balance.Balance
@@ -36,6 +36,17 @@ func NewFromDB(chainID coretypes.ChainID, proc *processors.ProcessorCache) (*vie
 	return New(chainID, state_.Variables(), state_.Timestamp(), proc, nil), nil
 }
 
+// NewFromDBAtBlockIndex creates view context on the state of the chain as it was after the block with the index.
+// The historical state is reconstructed from blocks stored in the DB.
+// Returns false if the block index is beyond the solid state of the chain
+func NewFromDBAtBlockIndex(chainID coretypes.ChainID, blockIndex uint32, proc *processors.ProcessorCache) (*viewcontext, bool, error) {
+	state_, ok, err := state.LoadStateAtBlockIndex(&chainID, blockIndex)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return New(chainID, state_.Variables(), state_.Timestamp(), proc, nil), true, nil
+}
+
 func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, proc *processors.ProcessorCache, logSet *logger.Logger) *viewcontext {
 	if logSet == nil {
 		logSet = logDefault
@@ -31,7 +31,8 @@ func (vmctx *VMContext) TransferToAddress(targetAddr address.Address, transfer c
 		vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
 		defer vmctx.popCallContext()
 
-		if !accounts.DebitFromAccount(vmctx.State(), agentID, transfer) {
+		h := vmctx.historyContext(accounts.ReasonWithdrawal, coretypes.NewAgentIDFromAddress(targetAddr))
+		if !accounts.DebitFromAccountWithHistory(vmctx.State(), agentID, transfer, h) {
 			return false
 		}
 	}
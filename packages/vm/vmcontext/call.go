@@ -12,7 +12,7 @@ import (
 
 var (
 	ErrContractNotFound   = errors.New("contract not found")
-	ErrEntryPointNotFound = errors.New("entry point not found")
+	ErrEntryPointNotFound = coretypes.ErrEntryPointNotFound
 	ErrProcessorNotFound  = errors.New("VM not found. Internal error")
 	ErrNotEnoughFees      = errors.New("not enough fees")
 	ErrWrongRequestToken  = errors.New("wrong request token")
@@ -48,20 +48,21 @@ func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode
 		}
 		defer vmctx.popCallContext()
 
-		return ep.CallView(NewSandboxView(vmctx))
+		return vmctx.callWithRollback(func() (dict.Dict, error) {
+			return ep.CallView(NewSandboxView(vmctx))
+		})
 	}
 	if err := vmctx.pushCallContextWithTransfer(targetContract, params, transfer); err != nil {
 		return nil, err
 	}
 	defer vmctx.popCallContext()
 
-	// prevent calling 'init' not from root contract or not while initializing root
-	if epCode == coretypes.EntryPointInit && targetContract != root.Interface.Hname() {
-		if !vmctx.callerIsRoot() {
-			return nil, fmt.Errorf("attempt to callByProgramHash init not from the root contract")
-		}
+	if err := vmctx.checkCallerOfEntryPoint(targetContract, epCode); err != nil {
+		return nil, err
 	}
-	return ep.Call(NewSandbox(vmctx))
+	return vmctx.callWithRollback(func() (dict.Dict, error) {
+		return ep.Call(NewSandbox(vmctx))
+	})
 }
 
 func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (dict.Dict, error) {
@@ -83,15 +84,24 @@ func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname,
 	}
 	defer vmctx.popCallContext()
 
-	// prevent calling 'init' not from root contract or not while initializing root
-	if epCode == coretypes.EntryPointInit && targetContract != root.Interface.Hname() {
-		if !vmctx.callerIsRoot() {
-			return nil, fmt.Errorf("attempt to callByProgramHash init not from the root contract")
-		}
+	if err := vmctx.checkCallerOfEntryPoint(targetContract, epCode); err != nil {
+		return nil, err
 	}
 	return ep.Call(NewSandbox(vmctx))
 }
 
+// checkCallerOfEntryPoint prevents calling 'init' and 'migrate' not from the root contract.
+// 'init' of the root itself is called while initializing the chain
+func (vmctx *VMContext) checkCallerOfEntryPoint(targetContract coretypes.Hname, epCode coretypes.Hname) error {
+	switch {
+	case epCode == coretypes.EntryPointInit && targetContract != root.Interface.Hname() && !vmctx.callerIsRoot():
+		return fmt.Errorf("attempt to callByProgramHash init not from the root contract")
+	case epCode == coretypes.EntryPointMigrate && !vmctx.callerIsRoot():
+		return fmt.Errorf("attempt to callByProgramHash migrate not from the root contract")
+	}
+	return nil
+}
+
 func (vmctx *VMContext) callerIsRoot() bool {
 	caller := vmctx.Caller()
 	if caller.IsAddress() {
@@ -101,7 +111,7 @@ func (vmctx *VMContext) callerIsRoot() bool {
 }
 
 func (vmctx *VMContext) requesterIsChainOwner() bool {
-	return vmctx.chainOwnerID == vmctx.reqRef.SenderAgentID()
+	return vmctx.CheckAuthorizationByChainOwner(vmctx.reqRef.SenderAgentID())
 }
 
 func (vmctx *VMContext) Params() dict.Dict {
@@ -4,14 +4,21 @@ import (
 	"fmt"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 )
 
 func (vmctx *VMContext) pushCallContextWithTransfer(contract coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) error {
+	var sp *savepoint
+	if len(vmctx.callStack) > 0 {
+		// nested call. The call from the request is rolled back by RunTheRequest
+		sp = vmctx.newSavepoint()
+	}
 	if transfer != nil {
 		agentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(vmctx.ChainID(), contract))
 		if len(vmctx.callStack) == 0 {
-			vmctx.creditToAccount(agentID, transfer)
+			vmctx.creditToAccount(agentID, transfer, accounts.ReasonDeposit)
 		} else {
 			fromAgentID := coretypes.NewAgentIDFromContractID(coretypes.NewContractID(vmctx.ChainID(), vmctx.CurrentContractHname()))
 			if !vmctx.moveBetweenAccounts(fromAgentID, agentID, transfer) {
@@ -20,6 +27,7 @@ func (vmctx *VMContext) pushCallContextWithTransfer(contract coretypes.Hname, pa
 		}
 	}
 	vmctx.pushCallContext(contract, params, transfer)
+	vmctx.getCallContext().savepoint = sp
 	return nil
 }
 
@@ -57,6 +65,48 @@ func (vmctx *VMContext) popCallContext() {
 	vmctx.callStack = vmctx.callStack[:len(vmctx.callStack)-1]
 }
 
+func (vmctx *VMContext) newSavepoint() *savepoint {
+	return &savepoint{
+		numMutations: vmctx.stateUpdate.Mutations().Len(),
+		numEvents:    len(vmctx.requestEvents),
+		txBuilder:    vmctx.txBuilder.Clone(),
+	}
+}
+
+// rollbackCallContext reverts all effects of the current call, including the transfer,
+// to the savepoint taken when the call context was pushed
+func (vmctx *VMContext) rollbackCallContext() {
+	sp := vmctx.getCallContext().savepoint
+	if sp == nil {
+		return
+	}
+	vmctx.stateUpdate.Mutations().Truncate(sp.numMutations)
+	vmctx.requestEvents = vmctx.requestEvents[:sp.numEvents]
+	vmctx.txBuilder = sp.txBuilder
+}
+
+// callWithRollback calls the entry point in the current call context.
+// If the call returns an error or panics, its effects are rolled back and the error is returned to the caller.
+// Panics of gas budget and DB access are not recovered: they abort the whole request
+func (vmctx *VMContext) callWithRollback(f func() (dict.Dict, error)) (ret dict.Dict, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r == coretypes.ErrGasBudgetExceeded {
+				panic(r)
+			}
+			if _, ok := r.(buffered.DBError); ok {
+				panic(r)
+			}
+			ret = nil
+			err = fmt.Errorf("recovered from panic in the call to %s: %v", vmctx.CurrentContractHname(), r)
+		}
+		if err != nil {
+			vmctx.rollbackCallContext()
+		}
+	}()
+	return f()
+}
+
 func (vmctx *VMContext) getCallContext() *callContext {
 	if len(vmctx.callStack) == 0 {
 		panic("getCallContext: stack is empty")
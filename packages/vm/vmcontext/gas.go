@@ -0,0 +1,42 @@
+package vmcontext
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// BurnGas charges gas to the budget of the current request.
+// It panics with coretypes.ErrGasBudgetExceeded when the budget is exhausted.
+// The panic is caught in RunTheRequest, which rolls back all effects of the request
+func (vmctx *VMContext) BurnGas(gas int64) {
+	if !vmctx.gasMetering || vmctx.gasBudget == 0 {
+		return
+	}
+	vmctx.gasBurned += gas
+	if vmctx.gasBurned > vmctx.gasBudget {
+		panic(coretypes.ErrGasBudgetExceeded)
+	}
+}
+
+// GasRemaining returns gas remaining in the budget of the current request.
+// If gas is not metered, it returns the default budget, so the Wasm code is bounded anyway
+func (vmctx *VMContext) GasRemaining() int64 {
+	if !vmctx.gasMetering || vmctx.gasBudget == 0 {
+		return coretypes.DefaultGasBudget
+	}
+	return vmctx.gasBudget - vmctx.gasBurned
+}
+
+// GasBurned returns gas burned by the last request
+func (vmctx *VMContext) GasBurned() int64 {
+	return vmctx.gasBurned
+}
+
+// startGasMetering resets gas counter for the call to the target entry point of the request
+func (vmctx *VMContext) startGasMetering() {
+	vmctx.gasBurned = 0
+	vmctx.gasMetering = true
+}
+
+func (vmctx *VMContext) stopGasMetering() {
+	vmctx.gasMetering = false
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 )
 
 func (vmctx *VMContext) ChainID() coretypes.ChainID {
@@ -65,13 +66,14 @@ func (vmctx *VMContext) PostRequest(par coretypes.PostRequestParams) bool {
 		"transfer", cbalances.Str(par.Transfer),
 	)
 	myAgentID := vmctx.MyAgentID()
+	targetAgentID := coretypes.NewAgentIDFromContractID(par.TargetContractID)
 	if !vmctx.debitFromAccount(myAgentID, cbalances.NewFromMap(map[balance.Color]int64{
 		balance.ColorIOTA: 1,
-	})) {
+	}), accounts.ReasonWithdrawal, targetAgentID) {
 		vmctx.log.Debugf("-- PostRequestSync: not enough funds for request token")
 		return false
 	}
-	if !vmctx.debitFromAccount(myAgentID, par.Transfer) {
+	if !vmctx.debitFromAccount(myAgentID, par.Transfer, accounts.ReasonWithdrawal, targetAgentID) {
 		vmctx.log.Debugf("-- PostRequestSync: not enough funds")
 		return false
 	}
@@ -112,5 +114,8 @@ func (vmctx *VMContext) RequestID() coretypes.RequestID {
 }
 
 func (vmctx *VMContext) NumFreeMinted() int64 {
+	if vmctx.reqRef.IsOffLedger() {
+		return 0
+	}
 	return vmctx.reqRef.Tx.MustProperties().NumFreeMintedTokens()
 }
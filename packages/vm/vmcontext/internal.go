@@ -1,12 +1,17 @@
 package vmcontext
 
 import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/processors"
@@ -15,23 +20,50 @@ import (
 // creditToAccount deposits transfer from request to chain account of of the called contract
 // It adds new tokens to the chain ledger
 // It is used when new tokens arrive with a request
-func (vmctx *VMContext) creditToAccount(agentID coretypes.AgentID, transfer coretypes.ColoredBalances) {
+func (vmctx *VMContext) creditToAccount(agentID coretypes.AgentID, transfer coretypes.ColoredBalances, reason string) {
 	if len(vmctx.callStack) > 0 {
 		vmctx.log.Panicf("creditToAccount must be called only from request")
 	}
 	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
 	defer vmctx.popCallContext()
 
-	accounts.CreditToAccount(vmctx.State(), agentID, transfer)
+	accounts.CreditToAccountWithHistory(vmctx.State(), agentID, transfer, vmctx.historyContext(reason, vmctx.reqRef.SenderAgentID()))
 }
 
 // debitFromAccount subtracts tokens from account if it is enough of it.
-// should be called only when posting request
-func (vmctx *VMContext) debitFromAccount(agentID coretypes.AgentID, transfer coretypes.ColoredBalances) bool {
+// should be called only when posting request or charging fees
+func (vmctx *VMContext) debitFromAccount(agentID coretypes.AgentID, transfer coretypes.ColoredBalances, reason string, counterparty coretypes.AgentID) bool {
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	defer vmctx.popCallContext()
+
+	return accounts.DebitFromAccountWithHistory(vmctx.State(), agentID, transfer, vmctx.historyContext(reason, counterparty))
+}
+
+// getUnlockedBalance returns the balance of the color in the on-chain account which can be debited,
+// i.e. is not locked by vesting schedules
+func (vmctx *VMContext) getUnlockedBalance(agentID coretypes.AgentID, col balance.Color) int64 {
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	defer vmctx.popCallContext()
+
+	return accounts.GetUnlockedBalances(vmctx.State(), agentID, time.Unix(0, vmctx.timestamp).Unix())[col]
+}
+
+// historyContext is the context of the change of the account in the current request
+func (vmctx *VMContext) historyContext(reason string, counterparty coretypes.AgentID) *accounts.HistoryContext {
+	return &accounts.HistoryContext{
+		RequestID:    *vmctx.reqRef.RequestID(),
+		Timestamp:    vmctx.timestamp,
+		Reason:       reason,
+		Counterparty: counterparty,
+	}
+}
+
+// checkAndUpdateNonce protects from replay of off-ledger requests
+func (vmctx *VMContext) checkAndUpdateNonce(agentID coretypes.AgentID, nonce uint64) bool {
 	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
 	defer vmctx.popCallContext()
 
-	return accounts.DebitFromAccount(vmctx.State(), agentID, transfer)
+	return accounts.CheckAndUpdateNonce(vmctx.State(), agentID, nonce)
 }
 
 func (vmctx *VMContext) moveBetweenAccounts(fromAgentID, toAgentID coretypes.AgentID, transfer coretypes.ColoredBalances) bool {
@@ -42,7 +74,8 @@ func (vmctx *VMContext) moveBetweenAccounts(fromAgentID, toAgentID coretypes.Age
 	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
 	defer vmctx.popCallContext()
 
-	return accounts.MoveBetweenAccounts(vmctx.State(), fromAgentID, toAgentID, transfer)
+	return accounts.MoveBetweenAccountsWithHistory(vmctx.State(), fromAgentID, toAgentID, transfer,
+		vmctx.historyContext(accounts.ReasonTransfer, fromAgentID))
 }
 
 func (vmctx *VMContext) findContractByHname(contractHname coretypes.Hname) (*root.ContractRecord, bool) {
@@ -56,6 +89,13 @@ func (vmctx *VMContext) findContractByHname(contractHname coretypes.Hname) (*roo
 	return ret, true
 }
 
+func (vmctx *VMContext) CheckAuthorizationByChainOwner(agentID coretypes.AgentID) bool {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.CheckAuthorizationByChainOwner(vmctx.State(), agentID)
+}
+
 func (vmctx *VMContext) mustGetChainInfo() root.ChainInfo {
 	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
@@ -63,11 +103,18 @@ func (vmctx *VMContext) mustGetChainInfo() root.ChainInfo {
 	return root.MustGetChainInfo(vmctx.State())
 }
 
-func (vmctx *VMContext) getFeeInfo() (balance.Color, int64, int64) {
+func (vmctx *VMContext) getChainAddress() (address.Address, bool) {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.GetChainAddress(vmctx.State())
+}
+
+func (vmctx *VMContext) getFeeInfo() (balance.Color, int64, int64, bool) {
 	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
 
-	return root.GetFeeInfoByContractRecord(vmctx.State(), vmctx.contractRecord)
+	return root.GetFeeInfoByContractRecord(vmctx.State(), vmctx.contractRecord, vmctx.reqRef.RequestSection().EntryPointCode())
 }
 
 func (vmctx *VMContext) getBinary(programHash hashing.HashValue) (string, []byte, error) {
@@ -102,11 +149,12 @@ func (vmctx *VMContext) moveBalance(target coretypes.AgentID, col balance.Color,
 	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
 
-	return accounts.MoveBetweenAccounts(
+	return accounts.MoveBetweenAccountsWithHistory(
 		vmctx.State(),
 		vmctx.MyAgentID(),
 		target,
 		cbalances.NewFromMap(map[balance.Color]int64{col: amount}),
+		vmctx.historyContext(accounts.ReasonTransfer, vmctx.MyAgentID()),
 	)
 }
 
@@ -115,5 +163,44 @@ func (vmctx *VMContext) StoreToEventLog(contract coretypes.Hname, data []byte) {
 	defer vmctx.popCallContext()
 
 	vmctx.log.Debugf("StoreToEventLog/%s: data: '%s'", contract.String(), string(data))
-	eventlog.AppendToLog(vmctx.State(), vmctx.timestamp, contract, data)
+	eventlog.AppendToLog(vmctx.State(), vmctx.timestamp, contract, data, vmctx.RequestID(), vmctx.blockIndex)
+	vmctx.requestEvents = append(vmctx.requestEvents, &blocklog.Event{Contract: contract, Message: string(data)})
+}
+
+// StoreEventToEventLog stores the typed event of the contract. Returns string representation of the event
+func (vmctx *VMContext) StoreEventToEventLog(contract coretypes.Hname, name string, topics [][]byte, payload dict.Dict) string {
+	if payload == nil {
+		payload = dict.New()
+	}
+	e := &eventlog.Event{
+		Contract:   contract,
+		Name:       name,
+		Topics:     topics,
+		Payload:    payload,
+		RequestID:  vmctx.RequestID(),
+		BlockIndex: vmctx.blockIndex,
+		Timestamp:  vmctx.timestamp,
+	}
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	eventlog.AppendEvent(vmctx.State(), e)
+	msg := e.String()
+	vmctx.requestEvents = append(vmctx.requestEvents, &blocklog.Event{Contract: contract, Message: msg})
+	return msg
+}
+
+// pruneEventLog prunes the logs of all contracts according to the retention policies
+func (vmctx *VMContext) pruneEventLog() {
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	eventlog.PruneLogs(vmctx.State(), vmctx.timestamp)
+}
+
+func (vmctx *VMContext) saveRequestReceipt(receipt *blocklog.RequestReceipt) {
+	vmctx.pushCallContext(blocklog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	blocklog.SaveRequestReceipt(vmctx.State(), receipt)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/processors"
 	"github.com/iotaledger/wasp/packages/vm/statetxbuilder"
@@ -22,6 +23,7 @@ type VMContext struct {
 	// same for the block
 	chainID      coretypes.ChainID
 	chainOwnerID coretypes.AgentID
+	blockIndex   uint32
 	processors   *processors.ProcessorCache
 	balances     map[valuetransaction.ID][]*balance.Balance
 	txBuilder    *statetxbuilder.Builder // mutated
@@ -32,7 +34,18 @@ type VMContext struct {
 	feeColor           balance.Color
 	ownerFee           int64
 	validatorFee       int64
+	// policy and penalty for requests which don't carry enough fees
+	feeShortfallPolicy  string
+	feeShortfallPenalty int64
+	// gas related
+	gasBudget   int64 // gas budget of one request. 0 means no limit
+	gasBurned   int64 // gas burned by the current request
+	gasMetering bool  // gas is metered only during the call to the target entry point
 	// request context
+	requestIndex       uint16 // index of the request in the block
+	feeCharged         int64  // total fee charged for the request
+	feeShortfall       string // fee shortfall policy applied to the request, if any
+	requestEvents      []*blocklog.Event
 	remainingAfterFees coretypes.ColoredBalances
 	entropy            hashing.HashValue // mutates with each request
 	reqRef             vm.RequestRefWithFreeTokens
@@ -51,19 +64,29 @@ type callContext struct {
 	contract         coretypes.Hname           // called contract
 	params           dict.Dict                 // params passed
 	transfer         coretypes.ColoredBalances // transfer passed
+	savepoint        *savepoint                // effects of the call are rolled back to it if the call fails
+}
+
+// savepoint is the state of the VMContext before the nested call, including the transfer
+type savepoint struct {
+	numMutations int
+	numEvents    int
+	txBuilder    *statetxbuilder.Builder
 }
 
 // NewVMContext a constructor
 func NewVMContext(task *vm.VMTask, txb *statetxbuilder.Builder) (*VMContext, error) {
 	ret := &VMContext{
-		processors:   task.Processors,
-		chainID:      task.ChainID,
-		balances:     task.Balances,
-		txBuilder:    txb,
-		virtualState: task.VirtualState.Clone(),
-		log:          task.Log,
-		entropy:      task.Entropy,
-		callStack:    make([]*callContext, 0),
+		processors:         task.Processors,
+		chainID:            task.ChainID,
+		blockIndex:         task.VirtualState.BlockIndex() + 1,
+		balances:           task.Balances,
+		txBuilder:          txb,
+		virtualState:       task.VirtualState.Clone(),
+		log:                task.Log,
+		entropy:            task.Entropy,
+		validatorFeeTarget: task.ValidatorFeeTarget,
+		callStack:          make([]*callContext, 0),
 	}
 	return ret, nil
 }
@@ -10,21 +10,42 @@ import (
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
 // runTheRequest:
 // - handles request token
 // - processes reward logic
-func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timestamp int64) {
-	vmctx.initRequestContext(reqRef, timestamp)
-	vmctx.mustHandleRequestToken()
-
-	if !vmctx.isInitChainRequest() {
+// - off-ledger request is checked for replay, fees are charged from the sender's on-chain account
+// - stores the receipt of the request
+func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, requestIndex uint16, timestamp int64) {
+	vmctx.initRequestContext(reqRef, requestIndex, timestamp)
+	if reqRef.IsOffLedger() {
 		vmctx.mustGetBaseValues()
-		vmctx.mustHandleFees()
+		if !vmctx.handleOffLedgerRequest() {
+			// replayed request or not enough funds for fees in the sender's account: the request is not called
+			vmctx.lastResult = nil
+			vmctx.finalizeRequestCall()
+			return
+		}
+	} else {
+		vmctx.mustHandleRequestToken()
+
+		feesOk := true
+		if !vmctx.isInitChainRequest() {
+			vmctx.mustGetBaseValues()
+			feesOk = vmctx.mustHandleFees()
+		}
+		vmctx.mustHandleFreeTokens()
+		if !feesOk {
+			// not enough fees: the request is not called
+			vmctx.lastResult = nil
+			vmctx.finalizeRequestCall()
+			return
+		}
 	}
-	vmctx.mustHandleFreeTokens()
 	defer vmctx.finalizeRequestCall()
 
 	if vmctx.contractRecord == nil {
@@ -44,6 +65,10 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 			if r := recover(); r != nil {
 				vmctx.lastResult = nil
 				vmctx.lastError = fmt.Errorf("recovered from panic in VM: %v", r)
+				if r == coretypes.ErrGasBudgetExceeded {
+					// the request burned all the gas. All its effects are rolled back
+					vmctx.lastError = fmt.Errorf("%w: burned %d, budget %d", coretypes.ErrGasBudgetExceeded, vmctx.gasBurned, vmctx.gasBudget)
+				}
 				if dberr, ok := r.(buffered.DBError); ok {
 					// There was an error accessing the DB
 					// The world stops
@@ -58,6 +83,7 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 		// treating panic and error returned from request the same way
 		vmctx.txBuilder = snapshotTxBuilder
 		vmctx.stateUpdate = snapshotStateUpdate
+		vmctx.requestEvents = nil
 
 		vmctx.mustHandleFallback()
 	}
@@ -77,51 +103,137 @@ func (vmctx *VMContext) mustHandleRequestToken() {
 	// always accrue 1 uncolored iota to the sender on-chain. This makes completely fee-less requests possible
 	vmctx.creditToAccount(vmctx.reqRef.SenderAgentID(), cbalances.NewFromMap(map[balance.Color]int64{
 		balance.ColorIOTA: 1,
-	}))
+	}), accounts.ReasonDeposit)
 	vmctx.remainingAfterFees = vmctx.reqRef.RequestSection().Transfer()
 	vmctx.log.Debugf("mustHandleFees: 1 request token accrued to the sender: %s\n", vmctx.reqRef.SenderAgentID())
 }
 
-// mustHandleFees:
-// - handles request token
-// - handles node fee, including fallback if not enough
-func (vmctx *VMContext) mustHandleFees() {
+// mustHandleFees charges fees from the transfer of the request.
+// If the transfer doesn't carry enough fees, the fee shortfall policy of the chain is applied,
+// the rest of the transfer is accrued to the sender and false is returned: the request must not be called
+func (vmctx *VMContext) mustHandleFees() bool {
 	transfer := vmctx.reqRef.RequestSection().Transfer()
 	totalFee := vmctx.ownerFee + vmctx.validatorFee
 	if totalFee == 0 || vmctx.requesterIsChainOwner() {
 		// no fees enabled or the caller is the chain owner
 		vmctx.log.Debugf("mustHandleFees: no fees charged\n")
 		vmctx.remainingAfterFees = transfer
-		return
+		return true
 	}
 	// handle fees
-	if transfer.Balance(vmctx.feeColor) < totalFee {
-		// TODO more sophisticated policy, for example taking fees to chain owner, the rest returned to sender
-		// fallback: not enough fees. Accrue everything to the sender
+	if available := transfer.Balance(vmctx.feeColor); available < totalFee {
+		charge := vmctx.feeShortfallCharge(available)
+		vmctx.accrueFeeShortfall(charge)
+		// the rest of the transfer is accrued to the sender
 		sender := vmctx.reqRef.SenderAgentID()
-		vmctx.creditToAccount(sender, transfer)
-		vmctx.lastError = fmt.Errorf("mustHandleFees: not enough fees for request %s. Transfer accrued to %s",
-			vmctx.reqRef.RequestID().Short(), sender.String())
+		remaining := map[balance.Color]int64{
+			vmctx.feeColor: -charge,
+		}
+		transfer.AddToMap(remaining)
+		vmctx.creditToAccount(sender, cbalances.NewFromMap(remaining), accounts.ReasonDeposit)
+		vmctx.lastError = fmt.Errorf("mustHandleFees: not enough fees for request %s: %d < %d. Fee shortfall policy '%s': %d charged, the rest accrued to %s",
+			vmctx.reqRef.RequestID().Short(), available, totalFee, vmctx.feeShortfallPolicy, charge, sender.String())
 		vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
-		return
+		return false
+	}
+	vmctx.accrueFees()
+	// subtract fees from the transfer
+	remaining := map[balance.Color]int64{
+		vmctx.feeColor: -totalFee,
 	}
-	// enough fees. Split between owner and validator
+	transfer.AddToMap(remaining)
+	vmctx.remainingAfterFees = cbalances.NewFromMap(remaining)
+	return true
+}
+
+// accrueFees splits fees between owner and validator
+func (vmctx *VMContext) accrueFees() {
+	vmctx.feeCharged = vmctx.ownerFee + vmctx.validatorFee
 	if vmctx.ownerFee > 0 {
 		vmctx.creditToAccount(vmctx.ChainOwnerID(), cbalances.NewFromMap(map[balance.Color]int64{
 			vmctx.feeColor: vmctx.ownerFee,
-		}))
+		}), accounts.ReasonFee)
 	}
 	if vmctx.validatorFee > 0 {
 		vmctx.creditToAccount(vmctx.validatorFeeTarget, cbalances.NewFromMap(map[balance.Color]int64{
 			vmctx.feeColor: vmctx.validatorFee,
-		}))
+		}), accounts.ReasonFee)
 	}
-	// subtract fees from the transfer
-	remaining := map[balance.Color]int64{
-		vmctx.feeColor: -totalFee,
+}
+
+// feeShortfallCharge returns the amount of fee tokens charged according to the fee shortfall policy
+// when only 'available' fee tokens are there
+func (vmctx *VMContext) feeShortfallCharge(available int64) int64 {
+	switch vmctx.feeShortfallPolicy {
+	case root.FeeShortfallChargeAvailable:
+		return available
+	case root.FeeShortfallPenalty:
+		if vmctx.feeShortfallPenalty < available {
+			return vmctx.feeShortfallPenalty
+		}
+		return available
 	}
-	transfer.AddToMap(remaining)
-	vmctx.remainingAfterFees = cbalances.NewFromMap(remaining)
+	return 0
+}
+
+// accrueFeeShortfall splits the fee tokens charged from the request with not enough fees
+// between owner and validator in proportion of their fees
+func (vmctx *VMContext) accrueFeeShortfall(charge int64) {
+	vmctx.feeShortfall = vmctx.feeShortfallPolicy
+	vmctx.feeCharged = charge
+	if charge == 0 {
+		return
+	}
+	ownerPart := charge * vmctx.ownerFee / (vmctx.ownerFee + vmctx.validatorFee)
+	if ownerPart > 0 {
+		vmctx.creditToAccount(vmctx.ChainOwnerID(), cbalances.NewFromMap(map[balance.Color]int64{
+			vmctx.feeColor: ownerPart,
+		}), accounts.ReasonFee)
+	}
+	if charge-ownerPart > 0 {
+		vmctx.creditToAccount(vmctx.validatorFeeTarget, cbalances.NewFromMap(map[balance.Color]int64{
+			vmctx.feeColor: charge - ownerPart,
+		}), accounts.ReasonFee)
+	}
+}
+
+// handleOffLedgerRequest:
+// - rejects the request if its nonce was already used by the sender or is too old (see accounts.CheckAndUpdateNonce)
+// - charges fees from the on-chain account of the sender. Off-ledger request has no transfer
+// Returns false if the request must not be called
+func (vmctx *VMContext) handleOffLedgerRequest() bool {
+	sender := vmctx.reqRef.SenderAgentID()
+	nonce := vmctx.reqRef.OffLedger.Nonce()
+	if !vmctx.checkAndUpdateNonce(sender, nonce) {
+		vmctx.lastError = fmt.Errorf("handleOffLedgerRequest: request %s of %s is rejected: nonce %d was already used or is too old",
+			vmctx.reqRef.RequestID().Short(), sender.String(), nonce)
+		return false
+	}
+	totalFee := vmctx.ownerFee + vmctx.validatorFee
+	if totalFee == 0 || vmctx.requesterIsChainOwner() {
+		vmctx.log.Debugf("handleOffLedgerRequest: no fees charged\n")
+		return true
+	}
+	if available := vmctx.getUnlockedBalance(sender, vmctx.feeColor); available < totalFee {
+		charge := vmctx.feeShortfallCharge(available)
+		if charge > 0 && !vmctx.debitFromAccount(sender, cbalances.NewFromMap(map[balance.Color]int64{
+			vmctx.feeColor: charge,
+		}), accounts.ReasonFee, vmctx.ChainOwnerID()) {
+			vmctx.log.Panicf("handleOffLedgerRequest: inconsistency: can't debit %d from %s", charge, sender.String())
+		}
+		vmctx.accrueFeeShortfall(charge)
+		vmctx.lastError = fmt.Errorf("handleOffLedgerRequest: not enough fees for request %s in the account of %s: %d < %d. Fee shortfall policy '%s': %d charged",
+			vmctx.reqRef.RequestID().Short(), sender.String(), available, totalFee, vmctx.feeShortfallPolicy, charge)
+		return false
+	}
+	fees := cbalances.NewFromMap(map[balance.Color]int64{
+		vmctx.feeColor: totalFee,
+	})
+	if !vmctx.debitFromAccount(sender, fees, accounts.ReasonFee, vmctx.ChainOwnerID()) {
+		vmctx.log.Panicf("handleOffLedgerRequest: inconsistency: can't debit fees from %s", sender.String())
+	}
+	vmctx.accrueFees()
+	return true
 }
 
 // mustHandleFreeTokens free tokens accrued to the chain owner
@@ -129,7 +241,7 @@ func (vmctx *VMContext) mustHandleFreeTokens() {
 	if vmctx.reqRef.FreeTokens == nil || vmctx.reqRef.FreeTokens.Len() == 0 {
 		return
 	}
-	vmctx.creditToAccount(vmctx.ChainOwnerID(), vmctx.reqRef.FreeTokens)
+	vmctx.creditToAccount(vmctx.ChainOwnerID(), vmctx.reqRef.FreeTokens, accounts.ReasonDeposit)
 }
 
 // mustHandleFallback all remaining tokens are:
@@ -143,7 +255,7 @@ func (vmctx *VMContext) mustHandleFallback() {
 			vmctx.log.Panicf("mustHandleFallback: transferring tokens to address %s", sender.MustAddress().String())
 		}
 	} else {
-		vmctx.creditToAccount(sender, vmctx.remainingAfterFees)
+		vmctx.creditToAccount(sender, vmctx.remainingAfterFees, accounts.ReasonDeposit)
 	}
 }
 
@@ -152,13 +264,21 @@ func (vmctx *VMContext) mustCallFromRequest() {
 	req := vmctx.reqRef.RequestSection()
 	vmctx.log.Debugf("mustCallFromRequest: %s -- %s\n", vmctx.reqRef.RequestID().String(), req.String())
 
+	vmctx.startGasMetering()
+	defer vmctx.stopGasMetering()
+
 	// calling only non vew entry points. Calling the view will trigger error and fallback
 	vmctx.lastResult, vmctx.lastError = vmctx.callNonViewByProgramHash(
 		vmctx.reqHname, req.EntryPointCode(), req.SolidArgs(), vmctx.remainingAfterFees, vmctx.contractRecord.ProgramHash)
 }
 
 func (vmctx *VMContext) finalizeRequestCall() {
+	vmctx.saveRequestReceipt(vmctx.newRequestReceipt())
 	vmctx.mustRequestToEventLog(vmctx.lastError)
+	if vmctx.requestIndex == 0 && !vmctx.isInitChainRequest() {
+		// once per block
+		vmctx.pruneEventLog()
+	}
 	vmctx.virtualState.ApplyStateUpdate(vmctx.stateUpdate)
 
 	vmctx.log.Debugw("runTheRequest OUT",
@@ -167,6 +287,25 @@ func (vmctx *VMContext) finalizeRequestCall() {
 	)
 }
 
+// newRequestReceipt collects the outcome of the current request
+func (vmctx *VMContext) newRequestReceipt() *blocklog.RequestReceipt {
+	ret := &blocklog.RequestReceipt{
+		RequestID:    *vmctx.reqRef.RequestID(),
+		BlockIndex:   vmctx.blockIndex,
+		RequestIndex: vmctx.requestIndex,
+		Result:       vmctx.lastResult,
+		FeeColor:     vmctx.feeColor,
+		Fee:          vmctx.feeCharged,
+		FeeShortfall: vmctx.feeShortfall,
+		Events:       vmctx.requestEvents,
+	}
+	if vmctx.lastError != nil {
+		ret.Error = vmctx.lastError.Error()
+		ret.Result = nil
+	}
+	return ret
+}
+
 func (vmctx *VMContext) mustRequestToEventLog(err error) {
 	if err != nil {
 		vmctx.log.Error(err)
@@ -187,20 +326,40 @@ func (vmctx *VMContext) mustGetBaseValues() {
 		vmctx.log.Panicf("initRequestContext: major inconsistency of chainID")
 	}
 	vmctx.chainOwnerID = info.ChainOwnerID
-	vmctx.feeColor, vmctx.ownerFee, vmctx.validatorFee = vmctx.getFeeInfo()
+	var basisPoints bool
+	vmctx.feeColor, vmctx.ownerFee, vmctx.validatorFee, basisPoints = vmctx.getFeeInfo()
+	if basisPoints {
+		// fees are the share of fee tokens transferred with the request. Off-ledger request has no transfer
+		var amount int64
+		if transfer := vmctx.reqRef.RequestSection().Transfer(); transfer != nil {
+			amount = transfer.Balance(vmctx.feeColor)
+		}
+		vmctx.ownerFee = root.FeeFromBasisPoints(amount, vmctx.ownerFee)
+		vmctx.validatorFee = root.FeeFromBasisPoints(amount, vmctx.validatorFee)
+	}
+	vmctx.gasBudget = info.GasBudget
+	vmctx.feeShortfallPolicy = info.FeeShortfallPolicy
+	vmctx.feeShortfallPenalty = info.FeeShortfallPenalty
 }
 
 // initRequestContext initializes VMContext for request and returns  if contract exists
-func (vmctx *VMContext) initRequestContext(reqRef vm.RequestRefWithFreeTokens, timestamp int64) {
+func (vmctx *VMContext) initRequestContext(reqRef vm.RequestRefWithFreeTokens, requestIndex uint16, timestamp int64) {
 	reqHname := reqRef.RequestSection().Target().Hname()
 	vmctx.reqRef = reqRef
 	vmctx.reqHname = reqHname
+	vmctx.requestIndex = requestIndex
+	vmctx.feeCharged = 0
+	vmctx.feeShortfall = ""
+	vmctx.requestEvents = nil
 
 	vmctx.timestamp = timestamp
 	vmctx.stateUpdate = state.NewStateUpdate(reqRef.RequestID()).WithTimestamp(timestamp)
 	vmctx.callStack = vmctx.callStack[:0]
 	vmctx.entropy = hashing.HashData(vmctx.entropy[:])
 	vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
+	vmctx.gasBudget = 0 // no limit until read from the chain
+	vmctx.gasBurned = 0
+	vmctx.gasMetering = false
 
 	vmctx.contractRecord, _ = vmctx.findContractByHname(vmctx.reqHname)
 }
@@ -210,12 +369,20 @@ func (vmctx *VMContext) isInitChainRequest() bool {
 	return s.Target().Hname() == root.Interface.Hname() && s.EntryPointCode() == coretypes.EntryPointInit
 }
 
-func (vmctx *VMContext) FinalizeTransactionEssence(blockIndex uint32, stateHash hashing.HashValue, timestamp int64) (*sctransaction.Transaction, error) {
+func (vmctx *VMContext) FinalizeTransactionEssence(blockIndex uint32, stateHash, stateRoot hashing.HashValue, timestamp int64) (*sctransaction.Transaction, error) {
+	// if the committee was rotated, the chain token and all assets go to the address of the new committee
+	if chainAddress, ok := vmctx.getChainAddress(); ok {
+		if err := vmctx.txBuilder.MoveChainTo(chainAddress); err != nil {
+			return nil, err
+		}
+	}
+	vmctx.txBuilder.SetChainID(vmctx.chainID)
 	// add state block
 	err := vmctx.txBuilder.SetStateParams(blockIndex, stateHash, timestamp)
 	if err != nil {
 		return nil, err
 	}
+	vmctx.txBuilder.SetStateRoot(stateRoot)
 	tx, err := vmctx.txBuilder.Build()
 	if err != nil {
 		return nil, err
@@ -12,6 +12,7 @@ type stateWrapper struct {
 	contractSubPartitionPrefix kv.Key
 	virtualState               state.VirtualState
 	stateUpdate                state.StateUpdate
+	burnGas                    func(gas int64) // nil means gas is not metered
 }
 
 func newStateWrapper(contractHname coretypes.Hname, virtualState state.VirtualState, stateUpdate state.StateUpdate) stateWrapper {
@@ -23,19 +24,28 @@ func newStateWrapper(contractHname coretypes.Hname, virtualState state.VirtualSt
 	}
 }
 
+func (s *stateWrapper) burn(gas int64) {
+	if s.burnGas != nil {
+		s.burnGas(gas)
+	}
+}
+
 func (s *stateWrapper) addContractSubPartition(key kv.Key) kv.Key {
 	return s.contractSubPartitionPrefix + key
 }
 
 func (vmctx *VMContext) stateWrapper() stateWrapper {
-	return newStateWrapper(
+	ret := newStateWrapper(
 		vmctx.CurrentContractHname(),
 		vmctx.virtualState,
 		vmctx.stateUpdate,
 	)
+	ret.burnGas = vmctx.BurnGas
+	return ret
 }
 
 func (s stateWrapper) Has(name kv.Key) (bool, error) {
+	s.burn(coretypes.GasStateRead)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -47,6 +57,7 @@ func (s stateWrapper) Has(name kv.Key) (bool, error) {
 func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error {
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
+		s.burn(coretypes.GasStateRead)
 		return f(key[len(s.contractSubPartitionPrefix):], value)
 	})
 	if done {
@@ -57,6 +68,7 @@ func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error
 		if ok {
 			return true
 		}
+		s.burn(coretypes.GasStateRead)
 		return f(key[len(s.contractSubPartitionPrefix):], value)
 	})
 }
@@ -64,6 +76,7 @@ func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error
 func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error {
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
+		s.burn(coretypes.GasStateRead)
 		return f(key[len(s.contractSubPartitionPrefix):])
 	})
 	if done {
@@ -74,11 +87,13 @@ func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error
 		if ok {
 			return true
 		}
+		s.burn(coretypes.GasStateRead)
 		return f(key[len(s.contractSubPartitionPrefix):])
 	})
 }
 
 func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
+	s.burn(coretypes.GasStateRead)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -88,11 +103,13 @@ func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
 }
 
 func (s stateWrapper) Del(name kv.Key) {
+	s.burn(coretypes.GasStateWrite)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationDel(name))
 }
 
 func (s stateWrapper) Set(name kv.Key, value []byte) {
+	s.burn(coretypes.GasStateWrite + int64(len(value))*coretypes.GasPerByteWritten)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationSet(name, value))
 }
@@ -9,12 +9,51 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 )
 
+// GasBurner charges gas for the execution of the Wasm code and for its calls to the host
+type GasBurner interface {
+	BurnGas(gas int64)
+	GasRemaining() int64
+}
+
 type WasmHost struct {
 	KvStoreHost
 	vm          WasmVM
 	codeToFunc  map[uint32]string
 	funcToCode  map[string]uint32
 	funcToIndex map[string]int32
+	gasBurner   GasBurner
+}
+
+// SetGasBurner sets the gas burner of the calls to the Wasm code. It returns the previous one.
+// nil means gas is not charged and the Wasm code is bounded by the default gas budget
+func (host *WasmHost) SetGasBurner(gasBurner GasBurner) GasBurner {
+	ret := host.gasBurner
+	host.gasBurner = gasBurner
+	return ret
+}
+
+func (host *WasmHost) burnGas(size int32) {
+	if size < 0 {
+		size = 0
+	}
+	host.burnWasmGas(coretypes.GasWasmHostCall + int64(size)*coretypes.GasPerWasmByte)
+}
+
+func (host *WasmHost) burnWasmGas(gas int64) {
+	if host.gasBurner != nil {
+		host.gasBurner.BurnGas(gas)
+	}
+}
+
+// gasRemaining returns gas available for the execution of the Wasm code
+func (host *WasmHost) gasRemaining() int64 {
+	if host.gasBurner == nil {
+		return coretypes.DefaultGasBudget
+	}
+	if ret := host.gasBurner.GasRemaining(); ret > 0 {
+		return ret
+	}
+	return 0
 }
 
 func (host *WasmHost) InitVM(vm WasmVM, useBase58Keys bool) error {
@@ -5,7 +5,9 @@ package wasmhost
 
 import (
 	"errors"
+
 	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/iotaledger/wasp/packages/coretypes"
 )
 
 type WasmTimeVM struct {
@@ -15,47 +17,58 @@ type WasmTimeVM struct {
 	memory   *wasmtime.Memory
 	module   *wasmtime.Module
 	store    *wasmtime.Store
+	// total fuel added to the store
+	fuelAdded uint64
+	// fuel consumed by the store which is already charged as gas
+	fuelCharged uint64
 }
 
 func NewWasmTimeVM() *WasmTimeVM {
 	vm := &WasmTimeVM{}
-	vm.store = wasmtime.NewStore(wasmtime.NewEngine())
-	vm.linker = wasmtime.NewLinker(vm.store)
+	config := wasmtime.NewConfig()
+	// Wasm code is metered deterministically by fuel, consumed by each executed instruction
+	config.SetConsumeFuel(true)
+	vm.store = wasmtime.NewStore(wasmtime.NewEngineWithConfig(config))
+	vm.linker = wasmtime.NewLinker(vm.store.Engine)
 	return vm
 }
 
 func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 	vm.WasmVmBase.LinkHost(impl, host)
-	err := vm.linker.DefineFunc("wasplib", "hostGetBytes",
+	err := vm.linker.DefineFunc(vm.store, "wasplib", "hostGetBytes",
 		func(objId int32, keyId int32, typeId int32, stringRef int32, size int32) int32 {
+			defer vm.syncFuel()()
 			return vm.HostGetBytes(objId, keyId, typeId, stringRef, size)
 		})
 	if err != nil {
 		return err
 	}
-	err = vm.linker.DefineFunc("wasplib", "hostGetKeyId",
+	err = vm.linker.DefineFunc(vm.store, "wasplib", "hostGetKeyId",
 		func(keyRef int32, size int32) int32 {
+			defer vm.syncFuel()()
 			return vm.HostGetKeyId(keyRef, size)
 		})
 	if err != nil {
 		return err
 	}
-	err = vm.linker.DefineFunc("wasplib", "hostGetObjectId",
+	err = vm.linker.DefineFunc(vm.store, "wasplib", "hostGetObjectId",
 		func(objId int32, keyId int32, typeId int32) int32 {
+			defer vm.syncFuel()()
 			return vm.HostGetObjectId(objId, keyId, typeId)
 		})
 	if err != nil {
 		return err
 	}
-	err = vm.linker.DefineFunc("wasplib", "hostSetBytes",
+	err = vm.linker.DefineFunc(vm.store, "wasplib", "hostSetBytes",
 		func(objId int32, keyId int32, typeId int32, stringRef int32, size int32) {
+			defer vm.syncFuel()()
 			vm.HostSetBytes(objId, keyId, typeId, stringRef, size)
 		})
 	if err != nil {
 		return err
 	}
 	// go implementation uses this one to write panic message
-	err = vm.linker.DefineFunc("wasi_unstable", "fd_write",
+	err = vm.linker.DefineFunc(vm.store, "wasi_unstable", "fd_write",
 		func(fd int32, iovs int32, size int32, written int32) int32 {
 			return vm.HostFdWrite(fd, iovs, size, written)
 		})
@@ -71,11 +84,15 @@ func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
 	if err != nil {
 		return err
 	}
-	vm.instance, err = vm.linker.Instantiate(vm.module)
+	// the start function of the module runs Wasm code too
+	err = vm.call(func() (err error) {
+		vm.instance, err = vm.linker.Instantiate(vm.store, vm.module)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	memory := vm.instance.GetExport("memory")
+	memory := vm.instance.GetExport(vm.store, "memory")
 	if memory == nil {
 		return errors.New("no memory export")
 	}
@@ -87,25 +104,89 @@ func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
 }
 
 func (vm *WasmTimeVM) RunFunction(functionName string) error {
-	export := vm.instance.GetExport(functionName)
+	export := vm.instance.GetExport(vm.store, functionName)
 	if export == nil {
 		return errors.New("unknown export function: '" + functionName + "'")
 	}
-	_, err := export.Func().Call()
-	return err
+	return vm.call(func() error {
+		_, err := export.Func().Call(vm.store)
+		return err
+	})
 }
 
 func (vm *WasmTimeVM) RunScFunction(index int32) error {
-	export := vm.instance.GetExport("on_call_entrypoint")
+	export := vm.instance.GetExport(vm.store, "on_call_entrypoint")
 	if export == nil {
 		return errors.New("unknown export function: 'on_call_entrypoint'")
 	}
 	frame := vm.PreCall()
-	_, err := export.Func().Call(index)
+	err := vm.call(func() error {
+		_, err := export.Func().Call(vm.store, index)
+		return err
+	})
 	vm.PostCall(frame)
 	return err
 }
 
+// call runs the Wasm code with the fuel of the gas remaining in the budget
+// and charges the fuel consumed as gas
+func (vm *WasmTimeVM) call(run func() error) error {
+	vm.refuel()
+	err := run()
+	outOfFuel := vm.burnFuel() == 0
+	if err != nil && outOfFuel {
+		// the trap was caused by running out of fuel
+		vm.host.burnWasmGas(vm.host.gasRemaining() + 1)
+	}
+	return err
+}
+
+// syncFuel charges the fuel consumed by the Wasm code before the call to the host.
+// The returned function refuels the store after the call, because the host may burn gas too
+func (vm *WasmTimeVM) syncFuel() func() {
+	vm.burnFuel()
+	return vm.refuel
+}
+
+// refuel sets the fuel of the store to the gas remaining in the budget
+func (vm *WasmTimeVM) refuel() {
+	remaining := vm.fuelRemaining()
+	target := uint64(vm.host.gasRemaining() * coretypes.WasmFuelPerGas)
+	var err error
+	switch {
+	case target > remaining:
+		err = vm.store.AddFuel(target - remaining)
+		vm.fuelAdded += target - remaining
+	case target < remaining:
+		_, err = vm.store.ConsumeFuel(remaining - target)
+	}
+	if err != nil {
+		panic(err)
+	}
+	// fuel taken away from the store here is not charged as gas
+	vm.fuelCharged, _ = vm.store.FuelConsumed()
+}
+
+// burnFuel charges the fuel consumed since the last refuel as gas, rounded up.
+// Returns the fuel remaining in the store
+func (vm *WasmTimeVM) burnFuel() uint64 {
+	consumed, _ := vm.store.FuelConsumed()
+	if consumed > vm.fuelCharged {
+		gas := int64(consumed - vm.fuelCharged)
+		vm.fuelCharged = consumed
+		vm.host.burnWasmGas((gas + coretypes.WasmFuelPerGas - 1) / coretypes.WasmFuelPerGas)
+	}
+	return vm.fuelRemaining()
+}
+
+func (vm *WasmTimeVM) fuelRemaining() uint64 {
+	consumed, _ := vm.store.FuelConsumed()
+	if consumed >= vm.fuelAdded {
+		return 0
+	}
+	return vm.fuelAdded - consumed
+}
+
 func (vm *WasmTimeVM) UnsafeMemory() []byte {
-	return vm.memory.UnsafeData()
+	return vm.memory.UnsafeData(vm.store)
 }
@@ -49,6 +49,7 @@ func (vm *WasmVmBase) HostGetBytes(objId int32, keyId int32, typeId int32, strin
 
 	// negative size means only check for existence
 	if size < 0 {
+		host.burnGas(0)
 		if host.Exists(objId, keyId, typeId) {
 			return 0
 		}
@@ -57,6 +58,7 @@ func (vm *WasmVmBase) HostGetBytes(objId int32, keyId int32, typeId int32, strin
 	}
 
 	bytes := host.GetBytes(objId, keyId, typeId)
+	host.burnGas(int32(len(bytes)))
 	if bytes == nil {
 		return -1
 	}
@@ -66,6 +68,7 @@ func (vm *WasmVmBase) HostGetBytes(objId int32, keyId int32, typeId int32, strin
 func (vm *WasmVmBase) HostGetKeyId(keyRef int32, size int32) int32 {
 	host := vm.host
 	host.TraceAll("HostGetKeyId(r%d,s%d)", keyRef, size)
+	host.burnGas(size)
 	// non-negative size means original key was a string
 	if size >= 0 {
 		bytes := vm.vmGetBytes(keyRef, size)
@@ -80,12 +83,14 @@ func (vm *WasmVmBase) HostGetKeyId(keyRef int32, size int32) int32 {
 func (vm *WasmVmBase) HostGetObjectId(objId int32, keyId int32, typeId int32) int32 {
 	host := vm.host
 	host.TraceAll("HostGetObjectId(o%d,k%d,t%d)", objId, keyId, typeId)
+	host.burnGas(0)
 	return host.GetObjectId(objId, keyId, typeId)
 }
 
 func (vm *WasmVmBase) HostSetBytes(objId int32, keyId int32, typeId int32, stringRef int32, size int32) {
 	host := vm.host
 	host.TraceAll("HostSetBytes(o%d,k%d,t%d,r%d,s%d)", objId, keyId, typeId, stringRef, size)
+	host.burnGas(size)
 	bytes := vm.vmGetBytes(stringRef, size)
 	host.SetBytes(objId, keyId, typeId, bytes)
 }
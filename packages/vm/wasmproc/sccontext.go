@@ -14,6 +14,8 @@ import (
 	"github.com/iotaledger/wasp/packages/vm/wasmhost"
 )
 
+// typeIds are the declared types of the keys of the root object.
+// KeyEvent also accepts OBJTYPE_BYTES, the encoded typed event
 var typeIds = map[int32]int32{
 	wasmhost.KeyBalances:        wasmhost.OBJTYPE_MAP,
 	wasmhost.KeyCall:            wasmhost.OBJTYPE_BYTES,
@@ -122,7 +124,15 @@ func (o *ScContext) SetBytes(keyId int32, typeId int32, bytes []byte) {
 	case wasmhost.KeyDeploy:
 		o.processDeploy(bytes)
 	case wasmhost.KeyEvent:
-		o.vm.ctx.Event(string(bytes))
+		// the declared type is the string of the plain event, the bytes are the encoded typed event
+		switch typeId {
+		case wasmhost.OBJTYPE_STRING:
+			o.vm.ctx.Event(string(bytes))
+		case wasmhost.OBJTYPE_BYTES:
+			o.processEvent(bytes)
+		default:
+			o.Panic("invalid type of event: %d", typeId)
+		}
 	case wasmhost.KeyLog:
 		o.vm.log().Infof(string(bytes))
 	case wasmhost.KeyTrace:
@@ -179,6 +189,23 @@ func (o *ScContext) processDeploy(bytes []byte) {
 	}
 }
 
+// processEvent emits the typed event: name, topics and the payload map
+func (o *ScContext) processEvent(bytes []byte) {
+	decode := NewBytesDecoder(bytes)
+	name := string(decode.Bytes())
+	numTopics := decode.Int64()
+	if numTopics < 0 {
+		o.Panic("invalid number of topics: %d", numTopics)
+	}
+	topics := make([][]byte, numTopics)
+	for i := range topics {
+		topics[i] = decode.Bytes()
+	}
+	payload := o.getParams(int32(decode.Int64()))
+	o.Trace("EVENT '%s'", name)
+	o.vm.ctx.EmitEvent(name, topics, payload)
+}
+
 func (o *ScContext) processPost(bytes []byte) {
 	decode := NewBytesDecoder(bytes)
 	contract, err := coretypes.NewContractIDFromBytes(decode.Bytes())
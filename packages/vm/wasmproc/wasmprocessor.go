@@ -66,6 +66,13 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 	host.ctxView = ctxView
 	host.nesting++
 
+	// views are not charged, but they are bounded by the default gas budget
+	var gasBurner wasmhost.GasBurner = &viewGasBurner{}
+	if ctx != nil {
+		gasBurner = ctx
+	}
+	saveGasBurner := host.SetGasBurner(gasBurner)
+
 	defer func() {
 		host.nesting--
 		if host.nesting == 0 {
@@ -75,6 +82,7 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 		}
 		host.ctx = saveCtx
 		host.ctxView = saveCtxView
+		host.SetGasBurner(saveGasBurner)
 	}()
 
 	testMode, _ := host.params().Has("testMode")
@@ -97,6 +105,22 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 	return results, nil
 }
 
+// viewGasBurner bounds the execution of the view by the default gas budget
+type viewGasBurner struct {
+	burned int64
+}
+
+func (g *viewGasBurner) BurnGas(gas int64) {
+	g.burned += gas
+	if g.burned > coretypes.DefaultGasBudget {
+		panic(coretypes.ErrGasBudgetExceeded)
+	}
+}
+
+func (g *viewGasBurner) GasRemaining() int64 {
+	return coretypes.DefaultGasBudget - g.burned
+}
+
 func (host *wasmProcessor) Call(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return host.call(ctx, nil)
 }
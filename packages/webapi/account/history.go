@@ -0,0 +1,98 @@
+package account
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func AddEndpoints(server echoswagger.ApiRouter) {
+	server.GET(routes.AccountHistory(":chainID", ":agentID"), handleAccountHistory).
+		SetSummary("Get the history of credits and debits of the on-chain account, the latest first").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath("", "agentID", "AgentID (base58)").
+		AddParamQuery(uint32(0), "offset", "Number of the latest entries to skip", false).
+		AddParamQuery(uint32(accounts.DefaultMaxHistoryRecords), "limit", fmt.Sprintf("Max number of entries to return, up to %d", accounts.MaxHistoryRecords), false).
+		AddResponse(http.StatusOK, "Account history", model.AccountHistory{}, nil)
+}
+
+func handleAccountHistory(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
+	}
+	agentID, err := coretypes.NewAgentIDFromBase58(c.Param("agentID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid agent ID %+v: %s", c.Param("agentID"), err.Error()))
+	}
+	offset, err := parseUint32QueryParam(c, "offset", 0)
+	if err != nil {
+		return err
+	}
+	limit, err := parseUint32QueryParam(c, "limit", accounts.DefaultMaxHistoryRecords)
+	if err != nil {
+		return err
+	}
+	if limit > accounts.MaxHistoryRecords {
+		limit = accounts.MaxHistoryRecords
+	}
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %+v", chainID.String()))
+	}
+
+	vctx, err := viewcontext.NewFromDB(*ch.ID(), ch.Processors())
+	if err != nil {
+		return fmt.Errorf("Failed to create context: %v", err)
+	}
+	ret, err := vctx.CallView(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncGetAccountHistory), codec.MakeDict(map[string]interface{}{
+		accounts.ParamAgentID:    agentID,
+		accounts.ParamOffset:     int64(offset),
+		accounts.ParamMaxRecords: int64(limit),
+	}))
+	if err != nil {
+		return err
+	}
+	numEntries, _, err := codec.DecodeInt64(ret.MustGet(accounts.ParamNumRecords))
+	if err != nil {
+		return err
+	}
+	entries := collections.NewArrayReadOnly(ret, accounts.ParamHistory)
+	res := &model.AccountHistory{
+		AgentID:    agentID.String(),
+		NumEntries: uint32(numEntries),
+		Entries:    make([]*model.AccountHistoryEntry, entries.MustLen()),
+	}
+	for i := range res.Entries {
+		e, err := accounts.DecodeHistoryEntry(entries.MustGetAt(uint16(i)))
+		if err != nil {
+			return err
+		}
+		res.Entries[i] = model.NewAccountHistoryEntry(e)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+func parseUint32QueryParam(c echo.Context, name string, def uint32) (uint32, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return def, nil
+	}
+	ret, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, httperrors.BadRequest(fmt.Sprintf("Invalid %s: %+v", name, s))
+	}
+	return uint32(ret), nil
+}
@@ -7,9 +7,12 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
 	"github.com/iotaledger/wasp/plugins/chains"
+	registry_plugin "github.com/iotaledger/wasp/plugins/registry"
 	"github.com/labstack/echo/v4"
 	"github.com/pangpanglabs/echoswagger/v2"
 )
@@ -22,6 +25,11 @@ func addChainEndpoints(adm echoswagger.ApiGroup) {
 	adm.POST(routes.DeactivateChain(":chainID"), handleDeactivateChain).
 		AddParamPath("", "chainID", "ChainID (base58)").
 		SetSummary("Deactivate a chain")
+
+	adm.POST(routes.SetNextCommittee(":chainID"), handleSetNextCommittee).
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(model.NextCommittee{CommitteeNodes: []string{"wasp3:4000", "wasp4:4000"}, Address: model.NewAddress(&address.Address{})}, "NextCommittee", "Nodes of the new committee", true).
+		SetSummary("Set the committee which takes over the chain after the committee rotation")
 }
 
 func handleActivateChain(c echo.Context) error {
@@ -62,3 +70,49 @@ func handleDeactivateChain(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+// handleSetNextCommittee stores in the chain record the nodes of the committee which takes over the chain
+// and the address of the distributed key generated by the DKG among them.
+// The node takes over the chain only if the chain owner rotates the committee to that address
+func handleSetNextCommittee(c echo.Context) error {
+	scAddress, err := address.FromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain id: %s", c.Param("chainID")))
+	}
+	var req model.NextCommittee
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	if util.ContainsDuplicates(req.CommitteeNodes) {
+		return httperrors.BadRequest("Duplicate committee nodes")
+	}
+	if req.Address == "" {
+		return httperrors.BadRequest("Address of the next committee is required")
+	}
+	nextAddress := req.Address.Address()
+	dkshare, err := registry_plugin.DefaultRegistry().LoadDKShare(&nextAddress)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("The node has no share of the distributed key of %s", nextAddress.String()))
+	}
+	if int(dkshare.N) != len(req.CommitteeNodes) {
+		return httperrors.BadRequest(fmt.Sprintf("The distributed key of %s is shared by %d nodes, not %d",
+			nextAddress.String(), dkshare.N, len(req.CommitteeNodes)))
+	}
+	chainID := (coretypes.ChainID)(scAddress)
+	bd, err := registry.GetChainRecord(&chainID)
+	if err != nil {
+		return err
+	}
+	if bd == nil {
+		return httperrors.NotFound(fmt.Sprintf("ChainRecord not found: %s", chainID))
+	}
+	_, err = registry.UpdateChainRecord(&chainID, func(bd *registry.ChainRecord) bool {
+		bd.NextCommitteeNodes = req.CommitteeNodes
+		bd.NextStateAddress = nextAddress
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
+}
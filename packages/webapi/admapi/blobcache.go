@@ -0,0 +1,25 @@
+package admapi
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addBlobCacheEndpoints(adm echoswagger.ApiGroup) {
+	adm.GET(routes.BlobCacheStats(), handleBlobCacheStats).
+		SetSummary("Get the size of the blob cache and the statistics of cleanups").
+		AddResponse(http.StatusOK, "Blob cache statistics", model.BlobCacheStats{}, nil)
+}
+
+func handleBlobCacheStats(c echo.Context) error {
+	stats, err := registry.DefaultRegistry().GetBlobCacheStats()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewBlobCacheStats(stats))
+}
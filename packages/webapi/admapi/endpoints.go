@@ -24,6 +24,7 @@ func AddEndpoints(adm echoswagger.ApiGroup, adminWhitelist []net.IP) {
 	addChainRecordEndpoints(adm)
 	addChainEndpoints(adm)
 	addDKSharesEndpoints(adm)
+	addBlobCacheEndpoints(adm)
 }
 
 // allow only if the remote address is private or in whitelist
@@ -0,0 +1,168 @@
+package chainevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"golang.org/x/net/websocket"
+)
+
+// keepaliveInterval is the period of the comments sent to the Server-Sent Events clients
+// to keep idle connections open
+const keepaliveInterval = 30 * time.Second
+
+func AddEndpoints(server echoswagger.ApiRouter) {
+	server.GET(routes.ChainEvents(":chainID"), handleChainEvents).
+		SetSummary("Stream the events of the chain").
+		SetDescription("If the request is a WebSocket handshake, each event is sent as a JSON message through the WebSocket. "+
+			"Otherwise, the events are streamed as Server-Sent Events, with the type of the event as the SSE event name. "+
+			"If the client can't keep up, events are dropped and the client receives the 'dropped' event with their number").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamQuery("", "type", "Comma-separated list of event types (request, block, contract, deploy). If omitted, all types are streamed", false).
+		AddParamQuery("", "contract", "Comma-separated list of contract hnames (hex). Applies to contract and deploy events only", false).
+		AddParamQuery("", "success", "If true, only successful requests are streamed, if false only failed ones. Applies to request events only", false).
+		AddResponse(http.StatusOK, "Stream of chain events", model.ChainEvent{}, nil).
+		AddResponse(http.StatusNotFound, "Chain not found", httperrors.NotFound(""), nil).
+		AddResponse(http.StatusServiceUnavailable, "Too many clients stream the events of the chain", httperrors.ServiceUnavailable(""), nil)
+
+	startForwarder()
+}
+
+func handleChainEvents(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	if chains.GetChain(chainID) == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+	f, err := parseFilter(c.QueryParam("type"), c.QueryParam("contract"), c.QueryParam("success"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+
+	sub, ok := subscribe(chainID, f)
+	if !ok {
+		return httperrors.ServiceUnavailable(fmt.Sprintf("Too many clients stream the events of the chain %s", chainID))
+	}
+	defer unsubscribe(chainID, sub)
+
+	if c.IsWebSocket() {
+		websocket.Handler(func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			// the client is not expected to send anything: reading only detects the closed connection
+			closed := make(chan struct{})
+			go func() {
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				close(closed)
+			}()
+			ticker := time.NewTicker(keepaliveInterval)
+			defer ticker.Stop()
+			for {
+				var ev *model.ChainEvent
+				select {
+				case <-closed:
+					return
+				case <-ticker.C:
+				case ev = <-sub.ch:
+				}
+				for _, e := range sub.eventsToSend(chainID, ev) {
+					if err := websocket.JSON.Send(ws, e); err != nil {
+						return
+					}
+				}
+			}
+		}).ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+	for {
+		var ev *model.ChainEvent
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+		case ev = <-sub.ch:
+		}
+		events := sub.eventsToSend(chainID, ev)
+		if len(events) == 0 {
+			// keepalive: the comment line is ignored by the clients
+			if _, err := fmt.Fprint(w, ":\n\n"); err != nil {
+				return nil
+			}
+		}
+		for _, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
+
+func parseFilter(types string, contracts string, success string) (*filter, error) {
+	ret := &filter{
+		types:     make(map[string]bool),
+		contracts: make(map[coretypes.Hname]bool),
+	}
+	for _, t := range splitList(types) {
+		switch t {
+		case model.ChainEventRequest, model.ChainEventBlock, model.ChainEventContract, model.ChainEventDeploy:
+			ret.types[t] = true
+		default:
+			return nil, fmt.Errorf("Invalid event type: %s", t)
+		}
+	}
+	for _, s := range splitList(contracts) {
+		hname, err := coretypes.HnameFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid contract hname: %s", s)
+		}
+		ret.contracts[hname] = true
+	}
+	if success != "" {
+		b, err := strconv.ParseBool(success)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid success flag: %s", success)
+		}
+		ret.success = &b
+	}
+	return ret, nil
+}
+
+func splitList(s string) []string {
+	ret := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
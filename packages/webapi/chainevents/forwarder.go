@@ -0,0 +1,259 @@
+package chainevents
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+const (
+	// subscriberBufferSize is the number of events buffered for each subscriber.
+	// Events are dropped for subscribers which can't keep up, the subscriber is notified about it
+	subscriberBufferSize = 100
+	// maxSubscribersPerChain is the maximum number of clients streaming the events of one chain
+	maxSubscribersPerChain = 100
+)
+
+// filter selects the events streamed to the subscriber. Empty set or nil success flag means no filtering
+type filter struct {
+	types     map[string]bool
+	contracts map[coretypes.Hname]bool
+	success   *bool
+}
+
+type subscriber struct {
+	filter *filter
+	ch     chan *model.ChainEvent
+	// number of events dropped since the last notification. Accessed atomically
+	dropped uint32
+}
+
+var (
+	subscribers      = make(map[coretypes.ChainID]map[*subscriber]struct{})
+	subscribersMutex sync.RWMutex
+	forwarderOnce    sync.Once
+)
+
+func (f *filter) accepts(ev *model.ChainEvent, hname coretypes.Hname) bool {
+	if len(f.types) > 0 && !f.types[ev.Type] {
+		return false
+	}
+	if f.success != nil && ev.Request != nil && ev.Request.Success != *f.success {
+		return false
+	}
+	if len(f.contracts) == 0 || (ev.Contract == nil && ev.Deploy == nil) {
+		return true
+	}
+	return f.contracts[hname]
+}
+
+// subscribe returns false if the chain already has maxSubscribersPerChain subscribers
+func subscribe(chainID coretypes.ChainID, f *filter) (*subscriber, bool) {
+	sub := &subscriber{
+		filter: f,
+		ch:     make(chan *model.ChainEvent, subscriberBufferSize),
+	}
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	if len(subscribers[chainID]) >= maxSubscribersPerChain {
+		return nil, false
+	}
+	if _, ok := subscribers[chainID]; !ok {
+		subscribers[chainID] = make(map[*subscriber]struct{})
+	}
+	subscribers[chainID][sub] = struct{}{}
+	return sub, true
+}
+
+func unsubscribe(chainID coretypes.ChainID, sub *subscriber) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	delete(subscribers[chainID], sub)
+	if len(subscribers[chainID]) == 0 {
+		delete(subscribers, chainID)
+	}
+}
+
+func startForwarder() {
+	forwarderOnce.Do(func() {
+		publisher.Event.Attach(events.NewClosure(forward))
+	})
+}
+
+func forward(msgType string, parts []string) {
+	if len(parts) < 1 {
+		return
+	}
+	chainID, err := coretypes.NewChainIDFromBase58(parts[0])
+	if err != nil {
+		return
+	}
+	subscribersMutex.RLock()
+	defer subscribersMutex.RUnlock()
+
+	subs, ok := subscribers[chainID]
+	if !ok {
+		return
+	}
+	ev, hname, ok := chainEventFromMessage(chainID, msgType, parts)
+	if !ok {
+		return
+	}
+	for sub := range subs {
+		if !sub.filter.accepts(ev, hname) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			atomic.AddUint32(&sub.dropped, 1)
+		}
+	}
+}
+
+// eventsToSend returns the events to be sent to the subscriber after ev is received from the channel,
+// ev is nil if nothing was received. The notification about the events dropped since the previous one precedes ev
+func (sub *subscriber) eventsToSend(chainID coretypes.ChainID, ev *model.ChainEvent) []*model.ChainEvent {
+	ret := make([]*model.ChainEvent, 0, 2)
+	if n := atomic.SwapUint32(&sub.dropped, 0); n > 0 {
+		ret = append(ret, &model.ChainEvent{
+			Type:    model.ChainEventDropped,
+			ChainID: model.NewChainID(&chainID),
+			Dropped: &model.DroppedEventInfo{Count: n},
+		})
+	}
+	if ev != nil {
+		ret = append(ret, ev)
+	}
+	return ret
+}
+
+// chainEventFromMessage converts the message of the publisher into the chain event.
+// Returns false if the message is not streamed to the clients
+func chainEventFromMessage(chainID coretypes.ChainID, msgType string, parts []string) (*model.ChainEvent, coretypes.Hname, bool) {
+	ret := &model.ChainEvent{ChainID: model.NewChainID(&chainID)}
+	switch msgType {
+	case "state":
+		// chainID, blockIndex, blockSize, txid, stateHash, timestamp
+		if len(parts) != 6 {
+			return nil, 0, false
+		}
+		blockIndex, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, 0, false
+		}
+		blockSize, err := strconv.ParseUint(parts[2], 10, 16)
+		if err != nil {
+			return nil, 0, false
+		}
+		txid, err := valuetransaction.IDFromBase58(parts[3])
+		if err != nil {
+			return nil, 0, false
+		}
+		stateHash, err := hashing.HashValueFromBase58(parts[4])
+		if err != nil {
+			return nil, 0, false
+		}
+		timestamp, err := strconv.ParseInt(parts[5], 10, 64)
+		if err != nil {
+			return nil, 0, false
+		}
+		ret.Type = model.ChainEventBlock
+		ret.Block = &model.BlockEventInfo{
+			BlockIndex: uint32(blockIndex),
+			BlockSize:  uint16(blockSize),
+			AnchorTxID: model.NewValueTxID(&txid),
+			StateHash:  model.NewHashValue(stateHash),
+			Timestamp:  timestamp,
+		}
+		return ret, 0, true
+
+	case "request_out":
+		// chainID, txid, reqIndex, blockIndex, indexInBlock, blockSize, success
+		if len(parts) != 7 {
+			return nil, 0, false
+		}
+		txid, err := valuetransaction.IDFromBase58(parts[1])
+		if err != nil {
+			return nil, 0, false
+		}
+		reqIndex, err := strconv.ParseUint(parts[2], 10, 16)
+		if err != nil {
+			return nil, 0, false
+		}
+		blockIndex, err := strconv.ParseUint(parts[3], 10, 32)
+		if err != nil {
+			return nil, 0, false
+		}
+		indexInBlock, err := strconv.ParseUint(parts[4], 10, 16)
+		if err != nil {
+			return nil, 0, false
+		}
+		success, err := strconv.ParseBool(parts[6])
+		if err != nil {
+			return nil, 0, false
+		}
+		reqID := coretypes.NewRequestID(txid, uint16(reqIndex))
+		ret.Type = model.ChainEventRequest
+		ret.Request = &model.RequestEventInfo{
+			RequestID:    reqID.Base58(),
+			BlockIndex:   uint32(blockIndex),
+			IndexInBlock: uint16(indexInBlock),
+			Success:      success,
+		}
+		return ret, 0, true
+
+	case "deploy":
+		// chainID, blockIndex, hname, programHash, name
+		if len(parts) < 5 {
+			return nil, 0, false
+		}
+		blockIndex, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, 0, false
+		}
+		hname, err := coretypes.HnameFromString(parts[2])
+		if err != nil {
+			return nil, 0, false
+		}
+		progHash, err := hashing.HashValueFromBase58(parts[3])
+		if err != nil {
+			return nil, 0, false
+		}
+		ret.Type = model.ChainEventDeploy
+		ret.Deploy = &model.DeployEventInfo{
+			BlockIndex:  uint32(blockIndex),
+			Hname:       hname.String(),
+			Name:        strings.Join(parts[4:], " "),
+			ProgramHash: model.NewHashValue(progHash),
+		}
+		return ret, hname, true
+
+	case "vmmsg":
+		// chainID, hname, msg
+		if len(parts) < 3 {
+			return nil, 0, false
+		}
+		hname, err := coretypes.HnameFromString(parts[1])
+		if err != nil {
+			return nil, 0, false
+		}
+		ret.Type = model.ChainEventContract
+		ret.Contract = &model.ContractEventInfo{
+			Hname:   hname.String(),
+			Message: strings.Join(parts[2:], " "),
+		}
+		return ret, hname, true
+	}
+	return nil, 0, false
+}
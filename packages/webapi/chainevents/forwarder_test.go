@@ -0,0 +1,235 @@
+package chainevents
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/stretchr/testify/require"
+)
+
+func requestOutMsg(chainID coretypes.ChainID, txid valuetransaction.ID, success bool) []string {
+	return []string{chainID.String(), txid.String(), "1", "5", "0", "2", strconv.FormatBool(success)}
+}
+
+func deployMsg(chainID coretypes.ChainID, name string, progHash hashing.HashValue) []string {
+	return []string{chainID.String(), "5", coretypes.Hn(name).String(), progHash.String(), name}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter("", "", "")
+	require.NoError(t, err)
+	require.Empty(t, f.types)
+	require.Empty(t, f.contracts)
+	require.Nil(t, f.success)
+
+	f, err = parseFilter(" request, deploy ,", coretypes.Hn("test").String(), "false")
+	require.NoError(t, err)
+	require.EqualValues(t, map[string]bool{model.ChainEventRequest: true, model.ChainEventDeploy: true}, f.types)
+	require.EqualValues(t, map[coretypes.Hname]bool{coretypes.Hn("test"): true}, f.contracts)
+	require.NotNil(t, f.success)
+	require.False(t, *f.success)
+
+	_, err = parseFilter("request,unknown", "", "")
+	require.Error(t, err)
+	_, err = parseFilter("", "not-a-hname", "")
+	require.Error(t, err)
+	_, err = parseFilter("", "", "maybe")
+	require.Error(t, err)
+}
+
+func TestChainEventFromMessage(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	txid := valuetransaction.RandomID()
+	stateHash := hashing.RandomHash(nil)
+
+	t.Run("block", func(t *testing.T) {
+		ev, _, ok := chainEventFromMessage(chainID, "state",
+			[]string{chainID.String(), "5", "2", txid.String(), stateHash.String(), "1000"})
+		require.True(t, ok)
+		require.EqualValues(t, model.ChainEventBlock, ev.Type)
+		require.EqualValues(t, model.NewChainID(&chainID), ev.ChainID)
+		require.EqualValues(t, 5, ev.Block.BlockIndex)
+		require.EqualValues(t, 2, ev.Block.BlockSize)
+		require.EqualValues(t, model.NewValueTxID(&txid), ev.Block.AnchorTxID)
+		require.EqualValues(t, model.NewHashValue(stateHash), ev.Block.StateHash)
+		require.EqualValues(t, 1000, ev.Block.Timestamp)
+
+		_, _, ok = chainEventFromMessage(chainID, "state", []string{chainID.String(), "5"})
+		require.False(t, ok)
+	})
+	t.Run("request", func(t *testing.T) {
+		for _, success := range []bool{true, false} {
+			ev, _, ok := chainEventFromMessage(chainID, "request_out", requestOutMsg(chainID, txid, success))
+			require.True(t, ok)
+			require.EqualValues(t, model.ChainEventRequest, ev.Type)
+			reqID := coretypes.NewRequestID(txid, 1)
+			require.EqualValues(t, reqID.Base58(), ev.Request.RequestID)
+			require.EqualValues(t, 5, ev.Request.BlockIndex)
+			require.EqualValues(t, 0, ev.Request.IndexInBlock)
+			require.EqualValues(t, success, ev.Request.Success)
+		}
+		// the message without the success flag is not streamed
+		_, _, ok := chainEventFromMessage(chainID, "request_out", requestOutMsg(chainID, txid, true)[:6])
+		require.False(t, ok)
+	})
+	t.Run("deploy", func(t *testing.T) {
+		ev, hname, ok := chainEventFromMessage(chainID, "deploy", deployMsg(chainID, "my contract", stateHash))
+		require.True(t, ok)
+		require.EqualValues(t, coretypes.Hn("my contract"), hname)
+		require.EqualValues(t, model.ChainEventDeploy, ev.Type)
+		require.Nil(t, ev.Contract)
+		require.EqualValues(t, 5, ev.Deploy.BlockIndex)
+		require.EqualValues(t, hname.String(), ev.Deploy.Hname)
+		require.EqualValues(t, "my contract", ev.Deploy.Name)
+		require.EqualValues(t, model.NewHashValue(stateHash), ev.Deploy.ProgramHash)
+
+		_, _, ok = chainEventFromMessage(chainID, "deploy", []string{chainID.String(), "5", "xyz", stateHash.String(), "a"})
+		require.False(t, ok)
+	})
+	t.Run("contract", func(t *testing.T) {
+		hname := coretypes.Hn("test")
+		ev, h, ok := chainEventFromMessage(chainID, "vmmsg", []string{chainID.String(), hname.String(), "[deploy]", "hello"})
+		require.True(t, ok)
+		require.EqualValues(t, hname, h)
+		// events of the contracts are not interpreted
+		require.EqualValues(t, model.ChainEventContract, ev.Type)
+		require.EqualValues(t, "[deploy] hello", ev.Contract.Message)
+	})
+	t.Run("other", func(t *testing.T) {
+		_, _, ok := chainEventFromMessage(chainID, "request_in", []string{chainID.String(), txid.String(), "0"})
+		require.False(t, ok)
+	})
+}
+
+func TestFilterAccepts(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	txid := valuetransaction.RandomID()
+	reqOk, _, _ := chainEventFromMessage(chainID, "request_out", requestOutMsg(chainID, txid, true))
+	reqFailed, _, _ := chainEventFromMessage(chainID, "request_out", requestOutMsg(chainID, txid, false))
+	deploy, deployHname, _ := chainEventFromMessage(chainID, "deploy", deployMsg(chainID, "test", hashing.RandomHash(nil)))
+	other := coretypes.Hn("other")
+
+	f, err := parseFilter("", "", "")
+	require.NoError(t, err)
+	require.True(t, f.accepts(reqOk, 0))
+	require.True(t, f.accepts(reqFailed, 0))
+	require.True(t, f.accepts(deploy, deployHname))
+
+	f, err = parseFilter("request", "", "true")
+	require.NoError(t, err)
+	require.True(t, f.accepts(reqOk, 0))
+	require.False(t, f.accepts(reqFailed, 0))
+	require.False(t, f.accepts(deploy, deployHname))
+
+	f, err = parseFilter("", other.String(), "false")
+	require.NoError(t, err)
+	require.False(t, f.accepts(reqOk, 0))
+	require.True(t, f.accepts(reqFailed, 0))
+	require.False(t, f.accepts(deploy, deployHname))
+	require.True(t, f.accepts(deploy, other))
+}
+
+func TestForward(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	txid := valuetransaction.RandomID()
+
+	fAll, err := parseFilter("", "", "")
+	require.NoError(t, err)
+	fFailed, err := parseFilter("request", "", "false")
+	require.NoError(t, err)
+	subAll, ok := subscribe(chainID, fAll)
+	require.True(t, ok)
+	subFailed, ok := subscribe(chainID, fFailed)
+	require.True(t, ok)
+
+	forward("request_out", requestOutMsg(chainID, txid, true))
+	forward("request_out", requestOutMsg(chainID, txid, false))
+	forward("deploy", deployMsg(chainID, "test", hashing.RandomHash(nil)))
+	// messages of other chains and invalid messages are ignored
+	otherChainID := coretypes.NewRandomChainID()
+	forward("request_out", requestOutMsg(otherChainID, txid, false))
+	forward("request_out", []string{"not-a-chain-id"})
+	forward("request_out", nil)
+
+	require.Len(t, subAll.ch, 3)
+	require.True(t, (<-subAll.ch).Request.Success)
+	require.False(t, (<-subAll.ch).Request.Success)
+	require.EqualValues(t, model.ChainEventDeploy, (<-subAll.ch).Type)
+
+	require.Len(t, subFailed.ch, 1)
+	require.False(t, (<-subFailed.ch).Request.Success)
+
+	unsubscribe(chainID, subFailed)
+	forward("request_out", requestOutMsg(chainID, txid, false))
+	require.Len(t, subAll.ch, 1)
+	require.Len(t, subFailed.ch, 0)
+
+	unsubscribe(chainID, subAll)
+	subscribersMutex.RLock()
+	_, ok = subscribers[chainID]
+	subscribersMutex.RUnlock()
+	require.False(t, ok)
+}
+
+func TestForwardSlowSubscriber(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	f, err := parseFilter("", "", "")
+	require.NoError(t, err)
+	sub, ok := subscribe(chainID, f)
+	require.True(t, ok)
+	defer unsubscribe(chainID, sub)
+
+	done := make(chan struct{})
+	go func() {
+		// events are dropped instead of blocking the publisher
+		for i := 0; i < subscriberBufferSize+10; i++ {
+			forward("request_out", requestOutMsg(chainID, valuetransaction.RandomID(), true))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forward blocked on the slow subscriber")
+	}
+	require.Len(t, sub.ch, subscriberBufferSize)
+
+	// the client is notified about the dropped events before the next event
+	ev := <-sub.ch
+	events := sub.eventsToSend(chainID, ev)
+	require.Len(t, events, 2)
+	require.EqualValues(t, model.ChainEventDropped, events[0].Type)
+	require.EqualValues(t, 10, events[0].Dropped.Count)
+	require.Equal(t, ev, events[1])
+	require.Len(t, sub.eventsToSend(chainID, nil), 0)
+}
+
+func TestSubscribeLimit(t *testing.T) {
+	chainID := coretypes.NewRandomChainID()
+	f, err := parseFilter("", "", "")
+	require.NoError(t, err)
+	subs := make([]*subscriber, maxSubscribersPerChain)
+	for i := range subs {
+		var ok bool
+		subs[i], ok = subscribe(chainID, f)
+		require.True(t, ok)
+	}
+	_, ok := subscribe(chainID, f)
+	require.False(t, ok)
+	// the limit is per chain
+	sub, ok := subscribe(coretypes.NewRandomChainID(), f)
+	require.True(t, ok)
+	unsubscribe(chainID, sub)
+
+	unsubscribe(chainID, subs[0])
+	subs[0], ok = subscribe(chainID, f)
+	require.True(t, ok)
+	for _, sub := range subs {
+		unsubscribe(chainID, sub)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"net"
 
 	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/webapi/account"
 	"github.com/iotaledger/wasp/packages/webapi/admapi"
 	"github.com/iotaledger/wasp/packages/webapi/blob"
+	"github.com/iotaledger/wasp/packages/webapi/chainevents"
+	"github.com/iotaledger/wasp/packages/webapi/eventlog"
 	"github.com/iotaledger/wasp/packages/webapi/info"
 	"github.com/iotaledger/wasp/packages/webapi/request"
 	"github.com/iotaledger/wasp/packages/webapi/state"
@@ -21,7 +24,10 @@ func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	server.SetResponseContentType("application/json")
 
 	pub := server.Group("public", "").SetDescription("Public endpoints")
+	account.AddEndpoints(pub)
 	blob.AddEndpoints(pub)
+	chainevents.AddEndpoints(pub)
+	eventlog.AddEndpoints(pub)
 	info.AddEndpoints(pub)
 	request.AddEndpoints(pub)
 	state.AddEndpoints(pub)
@@ -0,0 +1,95 @@
+package eventlog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	coreeventlog "github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func AddEndpoints(server echoswagger.ApiRouter) {
+	server.GET(routes.EventLogRecordsByRequest(":chainID", ":reqID"), handleRecordsByRequest).
+		SetSummary("Get the eventlog records emitted by the request, the latest first").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath("", "reqID", "Request ID (base58)").
+		AddParamQuery(uint32(coreeventlog.DefaultMaxNumberOfRecords), "limit", fmt.Sprintf("Max number of records to return, at most %d", coreeventlog.MaxNumberOfRecords), false).
+		AddResponse(http.StatusOK, "Eventlog records", []*model.EventLogRecord{}, nil)
+
+	server.GET(routes.EventLogRecordsByBlock(":chainID", ":blockIndex"), handleRecordsByBlock).
+		SetSummary("Get the eventlog records emitted in the block, the latest first").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath(uint32(0), "blockIndex", "Index of the block").
+		AddParamQuery(uint32(coreeventlog.DefaultMaxNumberOfRecords), "limit", fmt.Sprintf("Max number of records to return, at most %d", coreeventlog.MaxNumberOfRecords), false).
+		AddResponse(http.StatusOK, "Eventlog records", []*model.EventLogRecord{}, nil)
+}
+
+func handleRecordsByRequest(c echo.Context) error {
+	reqID, err := coretypes.NewRequestIDFromBase58(c.Param("reqID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid request id %+v: %s", c.Param("reqID"), err.Error()))
+	}
+	return handleRecords(c, coreeventlog.FuncGetRecordsByRequest, map[string]interface{}{
+		coreeventlog.ParamRequestID: reqID,
+	})
+}
+
+func handleRecordsByBlock(c echo.Context) error {
+	blockIndex, err := strconv.ParseUint(c.Param("blockIndex"), 10, 32)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid block index %+v", c.Param("blockIndex")))
+	}
+	return handleRecords(c, coreeventlog.FuncGetRecordsByBlock, map[string]interface{}{
+		coreeventlog.ParamBlockIndex: int64(blockIndex),
+	})
+}
+
+func handleRecords(c echo.Context, fname string, params map[string]interface{}) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
+	}
+	limit := uint64(coreeventlog.DefaultMaxNumberOfRecords)
+	if s := c.QueryParam("limit"); s != "" {
+		if limit, err = strconv.ParseUint(s, 10, 32); err != nil || limit == 0 {
+			return httperrors.BadRequest(fmt.Sprintf("Invalid limit: %+v", s))
+		}
+	}
+	if limit > coreeventlog.MaxNumberOfRecords {
+		limit = coreeventlog.MaxNumberOfRecords
+	}
+	params[coreeventlog.ParamMaxLastRecords] = int64(limit)
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %+v", chainID.String()))
+	}
+
+	vctx, err := viewcontext.NewFromDB(*ch.ID(), ch.Processors())
+	if err != nil {
+		return fmt.Errorf("Failed to create context: %v", err)
+	}
+	ret, err := vctx.CallView(coreeventlog.Interface.Hname(), coretypes.Hn(fname), codec.MakeDict(params))
+	if err != nil {
+		return err
+	}
+	recs := collections.NewArrayReadOnly(ret, coreeventlog.ParamRecords)
+	res := make([]*model.EventLogRecord, recs.MustLen())
+	for i := range res {
+		rec, err := coreeventlog.DecodeRecord(recs.MustGetAt(uint16(i)))
+		if err != nil {
+			return err
+		}
+		res[i] = model.NewEventLogRecord(rec)
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+)
+
+type AccountHistory struct {
+	AgentID    string                 `swagger:"desc(Agent ID of the account)"`
+	NumEntries uint32                 `swagger:"desc(Total number of entries in the history of the account)"`
+	Entries    []*AccountHistoryEntry `swagger:"desc(Entries of the history, the latest first)"`
+}
+
+type AccountHistoryEntry struct {
+	Credit       bool            `swagger:"desc(True if tokens were credited to the account, false if debited)"`
+	Balances     map[Color]int64 `swagger:"desc(Amounts of tokens by color (base58))"`
+	Counterparty string          `swagger:"desc(Agent ID on the other side of the credit or debit)"`
+	Reason       string          `swagger:"desc(Reason of the change: deposit, fee, transfer or withdrawal)"`
+	RequestID    string          `swagger:"desc(ID of the request which changed the account (base58))"`
+	Timestamp    int64           `swagger:"desc(Timestamp of the change (unix nanoseconds))"`
+}
+
+func NewAccountHistoryEntry(e *accounts.HistoryEntry) *AccountHistoryEntry {
+	ret := &AccountHistoryEntry{
+		Credit:       e.Credit,
+		Balances:     make(map[Color]int64),
+		Counterparty: e.Counterparty.String(),
+		Reason:       e.Reason,
+		RequestID:    e.RequestID.Base58(),
+		Timestamp:    e.Timestamp,
+	}
+	for col, bal := range e.Balances {
+		col := col
+		ret.Balances[NewColor(&col)] = bal
+	}
+	return ret
+}
@@ -1,6 +1,11 @@
 package model
 
-import "github.com/iotaledger/wasp/packages/hashing"
+import (
+	"time"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+)
 
 type BlobData struct {
 	Data Bytes `swagger:"desc(Blob content (base64))"`
@@ -18,3 +23,23 @@ type BlobInfo struct {
 func NewBlobInfo(exists bool, hash hashing.HashValue) *BlobInfo {
 	return &BlobInfo{Exists: exists, Hash: NewHashValue(hash)}
 }
+
+type BlobCacheStats struct {
+	NumBlobs    int       `swagger:"desc(Number of blobs in the cache)"`
+	TotalSize   int64     `swagger:"desc(Total size of blobs in the cache in bytes)"`
+	Quota       int64     `swagger:"desc(Maximum total size of blobs in bytes used by the last cleanup. 0 means unlimited)"`
+	Expired     int64     `swagger:"desc(Number of blobs removed because of the expired TTL since the start of the node)"`
+	Evicted     int64     `swagger:"desc(Number of least recently used blobs removed because of the quota since the start of the node)"`
+	LastCleanup time.Time `swagger:"desc(Time of the last cleanup)"`
+}
+
+func NewBlobCacheStats(s *registry.BlobCacheStats) *BlobCacheStats {
+	return &BlobCacheStats{
+		NumBlobs:    s.NumBlobs,
+		TotalSize:   s.TotalSize,
+		Quota:       s.Quota,
+		Expired:     s.Expired,
+		Evicted:     s.Evicted,
+		LastCleanup: s.LastCleanup,
+	}
+}
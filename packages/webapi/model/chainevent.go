@@ -0,0 +1,54 @@
+package model
+
+// types of the chain events
+const (
+	ChainEventRequest  = "request"
+	ChainEventBlock    = "block"
+	ChainEventContract = "contract"
+	ChainEventDeploy   = "deploy"
+	// ChainEventDropped notifies the client that events were dropped because it couldn't keep up
+	ChainEventDropped = "dropped"
+)
+
+// ChainEvent is the message streamed by the chain events endpoints.
+// Depending on the Type, exactly one of Request, Block, Contract, Deploy or Dropped is present
+type ChainEvent struct {
+	Type     string             `swagger:"desc(Type of the event: request, block, contract, deploy or dropped)"`
+	ChainID  ChainID            `swagger:"desc(ChainID (base58-encoded))"`
+	Request  *RequestEventInfo  `json:",omitempty" swagger:"desc(Processed request. Present if Type is 'request')"`
+	Block    *BlockEventInfo    `json:",omitempty" swagger:"desc(New block. Present if Type is 'block')"`
+	Contract *ContractEventInfo `json:",omitempty" swagger:"desc(Event emitted by the contract. Present if Type is 'contract')"`
+	Deploy   *DeployEventInfo   `json:",omitempty" swagger:"desc(Contract deployed on the chain. Present if Type is 'deploy')"`
+	Dropped  *DroppedEventInfo  `json:",omitempty" swagger:"desc(Events dropped for the client. Present if Type is 'dropped')"`
+}
+
+type RequestEventInfo struct {
+	RequestID    string `swagger:"desc(ID of the request (base58))"`
+	BlockIndex   uint32 `swagger:"desc(Index of the block which contains the request)"`
+	IndexInBlock uint16 `swagger:"desc(Index of the request in the block)"`
+	Success      bool   `swagger:"desc(True if the request succeeded according to its receipt)"`
+}
+
+type BlockEventInfo struct {
+	BlockIndex uint32    `swagger:"desc(Index of the block)"`
+	BlockSize  uint16    `swagger:"desc(Number of requests in the block)"`
+	AnchorTxID ValueTxID `swagger:"desc(ID of the anchor transaction (base58))"`
+	StateHash  HashValue `swagger:"desc(Hash of the state (base58))"`
+	Timestamp  int64     `swagger:"desc(Timestamp of the block (unix nanoseconds))"`
+}
+
+type ContractEventInfo struct {
+	Hname   string `swagger:"desc(Hname of the contract which emitted the event (hex))"`
+	Message string `swagger:"desc(Message of the event)"`
+}
+
+type DeployEventInfo struct {
+	BlockIndex  uint32    `swagger:"desc(Index of the block which deployed the contract)"`
+	Hname       string    `swagger:"desc(Hname of the deployed contract (hex))"`
+	Name        string    `swagger:"desc(Name of the deployed contract)"`
+	ProgramHash HashValue `swagger:"desc(Hash of the program of the contract (base58))"`
+}
+
+type DroppedEventInfo struct {
+	Count uint32 `swagger:"desc(Number of events dropped since the previous notification)"`
+}
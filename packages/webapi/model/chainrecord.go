@@ -5,26 +5,49 @@ import (
 )
 
 type ChainRecord struct {
-	ChainID        ChainID  `swagger:"desc(ChainID (base58-encoded))"`
-	Color          Color    `swagger:"desc(Chain color (base58-encoded))"`
-	CommitteeNodes []string `swagger:"desc(List of committee nodes (network IDs))"`
-	Active         bool     `swagger:"desc(Whether or not the chain is active)"`
+	ChainID            ChainID  `swagger:"desc(ChainID (base58-encoded))"`
+	Color              Color    `swagger:"desc(Chain color (base58-encoded))"`
+	CommitteeNodes     []string `swagger:"desc(List of committee nodes (network IDs))"`
+	Active             bool     `swagger:"desc(Whether or not the chain is active)"`
+	StateAddress       Address  `swagger:"desc(Address of the committee which holds the chain token (base58-encoded). Defaults to the chain ID)"`
+	NextCommitteeNodes []string `swagger:"desc(List of committee nodes (network IDs) which take over the chain after the committee rotation)"`
+	NextStateAddress   Address  `swagger:"desc(Address of the distributed key of the next committee (base58-encoded). Empty if the next committee is not set)"`
 }
 
 func NewChainRecord(bd *registry.ChainRecord) *ChainRecord {
-	return &ChainRecord{
-		ChainID:        NewChainID(&bd.ChainID),
-		Color:          NewColor(&bd.Color),
-		CommitteeNodes: bd.CommitteeNodes[:],
-		Active:         bd.Active,
+	addr := bd.Address()
+	ret := &ChainRecord{
+		ChainID:            NewChainID(&bd.ChainID),
+		Color:              NewColor(&bd.Color),
+		CommitteeNodes:     bd.CommitteeNodes[:],
+		Active:             bd.Active,
+		StateAddress:       NewAddress(&addr),
+		NextCommitteeNodes: bd.NextCommitteeNodes[:],
 	}
+	if len(bd.NextCommitteeNodes) > 0 {
+		ret.NextStateAddress = NewAddress(&bd.NextStateAddress)
+	}
+	return ret
 }
 
 func (bd *ChainRecord) ChainRecord() *registry.ChainRecord {
-	return &registry.ChainRecord{
-		ChainID:        bd.ChainID.ChainID(),
-		Color:          bd.Color.Color(),
-		CommitteeNodes: bd.CommitteeNodes[:],
-		Active:         bd.Active,
+	ret := &registry.ChainRecord{
+		ChainID:            bd.ChainID.ChainID(),
+		Color:              bd.Color.Color(),
+		CommitteeNodes:     bd.CommitteeNodes[:],
+		Active:             bd.Active,
+		NextCommitteeNodes: bd.NextCommitteeNodes[:],
+	}
+	if bd.StateAddress != "" {
+		ret.StateAddress = bd.StateAddress.Address()
+	}
+	if bd.NextStateAddress != "" {
+		ret.NextStateAddress = bd.NextStateAddress.Address()
 	}
+	return ret
+}
+
+type NextCommittee struct {
+	CommitteeNodes []string `swagger:"desc(List of nodes (network IDs) of the new committee, in the order of indices of the distributed key)"`
+	Address        Address  `swagger:"desc(Address of the distributed key generated by the DKG among the nodes of the new committee (base58-encoded))"`
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+)
+
+type EventLogRecord struct {
+	Contract   string `swagger:"desc(Hname of the contract which emitted the record)"`
+	RequestID  string `swagger:"desc(ID of the request which emitted the record (base58))"`
+	BlockIndex uint32 `swagger:"desc(Index of the block which contains the request)"`
+	Timestamp  int64  `swagger:"desc(Timestamp of the record (unix nanoseconds))"`
+	Data       string `swagger:"desc(Data of the record)"`
+}
+
+func NewEventLogRecord(rec *eventlog.Record) *EventLogRecord {
+	return &EventLogRecord{
+		Contract:   rec.Contract.String(),
+		RequestID:  rec.RequestID.Base58(),
+		BlockIndex: rec.BlockIndex,
+		Timestamp:  rec.Timestamp,
+		Data:       string(rec.Data),
+	}
+}
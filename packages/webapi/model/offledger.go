@@ -0,0 +1,9 @@
+package model
+
+type OffLedgerRequestBody struct {
+	Request Bytes `swagger:"desc(Signed off-ledger request (base64))"`
+}
+
+type OffLedgerRequestResponse struct {
+	RequestID string `swagger:"desc(ID of the off-ledger request (base58))"`
+}
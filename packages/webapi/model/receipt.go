@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
+)
+
+type RequestReceipt struct {
+	RequestID    string               `swagger:"desc(ID of the request (base58))"`
+	BlockIndex   uint32               `swagger:"desc(Index of the block which contains the request)"`
+	RequestIndex uint16               `swagger:"desc(Index of the request in the block)"`
+	Succeeded    bool                 `swagger:"desc(True if the call of the request succeeded)"`
+	Error        string               `swagger:"desc(Error returned by the call. Empty if the request succeeded)"`
+	Result       dict.Dict            `swagger:"desc(Dictionary returned by the call)"`
+	FeeColor     Color                `swagger:"desc(Color of the fee (base58))"`
+	Fee          int64                `swagger:"desc(Total fee charged for the request)"`
+	FeeShortfall string               `swagger:"desc(Fee shortfall policy applied if the request did not carry enough fees. Empty if the fees were enough)"`
+	Events       []*ContractEventInfo `swagger:"desc(Events emitted by the contracts while processing the request)"`
+}
+
+func NewRequestReceipt(r *blocklog.RequestReceipt) *RequestReceipt {
+	ret := &RequestReceipt{
+		RequestID:    r.RequestID.Base58(),
+		BlockIndex:   r.BlockIndex,
+		RequestIndex: r.RequestIndex,
+		Succeeded:    r.Succeeded(),
+		Error:        r.Error,
+		Result:       r.Result,
+		FeeColor:     NewColor(&r.FeeColor),
+		Fee:          r.Fee,
+		FeeShortfall: r.FeeShortfall,
+		Events:       make([]*ContractEventInfo, len(r.Events)),
+	}
+	for i, e := range r.Events {
+		ret.Events[i] = &ContractEventInfo{
+			Hname:   e.Contract.String(),
+			Message: e.Message,
+		}
+	}
+	return ret
+}
@@ -0,0 +1,84 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addOffLedgerEndpoints(server echoswagger.ApiRouter) {
+	server.POST(routes.PostOffLedgerRequest(":chainID"), handlePostOffLedgerRequest).
+		SetSummary("Post the signed off-ledger request to the chain. The request is gossiped to the committee").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(model.OffLedgerRequestBody{}, "Request", "Off-ledger request", true).
+		AddResponse(http.StatusAccepted, "Request ID", model.OffLedgerRequestResponse{}, nil)
+}
+
+func handlePostOffLedgerRequest(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
+	}
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %+v", chainID.String()))
+	}
+	var body model.OffLedgerRequestBody
+	if err := c.Bind(&body); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	req, err := sctransaction.OffLedgerRequestFromBytes(body.Request.Bytes())
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid off-ledger request: %v", err))
+	}
+	if !req.VerifySignature() {
+		return httperrors.BadRequest("Invalid signature of the off-ledger request")
+	}
+	if req.Target().ChainID() != chainID {
+		return httperrors.BadRequest(fmt.Sprintf("Off-ledger request is not targeted to the chain %s", chainID.String()))
+	}
+	reqID := req.ID()
+	if ch.GetRequestProcessingStatus(&reqID) == chain.RequestProcessingStatusCompleted {
+		return httperrors.Conflict(fmt.Sprintf("Request already processed: %s", reqID.Base58()))
+	}
+	// replays are rejected by the VM anyway. Checking the nonce here saves the committee from the spam.
+	// Only the greatest nonce is known here: reuse of the nonce in the window below it is detected by the VM
+	lastNonce, err := getNonce(ch, coretypes.NewAgentIDFromAddress(req.SenderAddress()))
+	if err != nil {
+		return err
+	}
+	if req.Nonce() == lastNonce || (req.Nonce() < lastNonce && lastNonce-req.Nonce() > accounts.NonceWindowSize) {
+		return httperrors.Conflict(fmt.Sprintf("Nonce %d was already used by %s or is too old", req.Nonce(), req.SenderAddress().String()))
+	}
+	ch.ReceiveMessage(&chain.OffLedgerRequestMsg{Request: req})
+
+	return c.JSON(http.StatusAccepted, model.OffLedgerRequestResponse{RequestID: reqID.Base58()})
+}
+
+func getNonce(ch chain.Chain, agentID coretypes.AgentID) (uint64, error) {
+	vctx, err := viewcontext.NewFromDB(*ch.ID(), ch.Processors())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create context: %v", err)
+	}
+	params := dict.New()
+	params.Set(accounts.ParamAgentID, codec.EncodeAgentID(agentID))
+	ret, err := vctx.CallView(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncGetNonce), params)
+	if err != nil {
+		return 0, err
+	}
+	nonce, _, err := codec.DecodeInt64(ret.MustGet(accounts.ParamNonce))
+	return uint64(nonce), err
+}
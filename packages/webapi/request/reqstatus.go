@@ -8,6 +8,9 @@ import (
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/vm/core/blocklog"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
@@ -28,6 +31,15 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamPath("", "reqID", "Request ID (base58)").
 		AddParamBody(model.WaitRequestProcessedParams{}, "Params", "Optional parameters", false)
+
+	server.GET(routes.RequestReceipt(":chainID", ":reqID"), handleRequestReceipt).
+		SetSummary("Get the receipt of the request processed by the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath("", "reqID", "Request ID (base58)").
+		AddResponse(http.StatusOK, "Request receipt", model.RequestReceipt{}, nil).
+		AddResponse(http.StatusNotFound, "The request has not been processed by the chain", httperrors.NotFound(""), nil)
+
+	addOffLedgerEndpoints(server)
 }
 
 func handleRequestStatus(c echo.Context) error {
@@ -89,6 +101,32 @@ func handleWaitRequestProcessed(c echo.Context) error {
 	}
 }
 
+func handleRequestReceipt(c echo.Context) error {
+	ch, reqID, err := parseParams(c)
+	if err != nil {
+		return err
+	}
+	vctx, err := viewcontext.NewFromDB(*ch.ID(), ch.Processors())
+	if err != nil {
+		return fmt.Errorf("Failed to create context: %v", err)
+	}
+	ret, err := vctx.CallView(blocklog.Interface.Hname(), coretypes.Hn(blocklog.FuncGetRequestReceipt), codec.MakeDict(map[string]interface{}{
+		blocklog.ParamRequestID: reqID,
+	}))
+	if err != nil {
+		return err
+	}
+	data := ret.MustGet(blocklog.ParamReceipt)
+	if data == nil {
+		return httperrors.NotFound(fmt.Sprintf("Receipt not found: request %s has not been processed by the chain", reqID.Base58()))
+	}
+	receipt, err := blocklog.DecodeRequestReceipt(data)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewRequestReceipt(receipt))
+}
+
 func parseParams(c echo.Context) (chain.Chain, *coretypes.RequestID, error) {
 	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
 	if err != nil {
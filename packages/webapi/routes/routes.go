@@ -16,6 +16,22 @@ func WaitRequestProcessed(chainID string, reqID string) string {
 	return "/chain/" + chainID + "/request/" + reqID + "/wait"
 }
 
+func RequestReceipt(chainID string, reqID string) string {
+	return "/chain/" + chainID + "/request/" + reqID + "/receipt"
+}
+
+func PostOffLedgerRequest(chainID string) string {
+	return "/chain/" + chainID + "/request/offledger"
+}
+
+func AccountHistory(chainID string, agentID string) string {
+	return "/chain/" + chainID + "/account/" + agentID + "/history"
+}
+
+func ChainEvents(chainID string) string {
+	return "/chain/" + chainID + "/events"
+}
+
 func StateQuery(chainID string) string {
 	return "/chain/" + chainID + "/state/query"
 }
@@ -40,6 +56,10 @@ func DeactivateChain(chainID string) string {
 	return "/adm/chain/" + chainID + "/deactivate"
 }
 
+func SetNextCommittee(chainID string) string {
+	return "/adm/chain/" + chainID + "/nextcommittee"
+}
+
 func ListChainRecords() string {
 	return "/adm/chainrecords"
 }
@@ -67,3 +87,15 @@ func DumpState(contractID string) string {
 func Shutdown() string {
 	return "/adm/shutdown"
 }
+
+func BlobCacheStats() string {
+	return "/adm/blobcache"
+}
+
+func EventLogRecordsByRequest(chainID string, reqID string) string {
+	return "/chain/" + chainID + "/request/" + reqID + "/eventlog"
+}
+
+func EventLogRecordsByBlock(chainID string, blockIndex string) string {
+	return "/chain/" + chainID + "/block/" + blockIndex + "/eventlog"
+}
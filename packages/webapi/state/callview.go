@@ -2,12 +2,15 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
@@ -25,6 +28,7 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 		SetSummary("Call a view function on a contract").
 		AddParamPath("", "contractID", "ContractID (base58-encoded)").
 		AddParamPath("getInfo", "fname", "Function name").
+		AddParamQuery(uint32(0), "blockIndex", fmt.Sprintf("Index of the block. If given, the view is called on the historical state of the chain, which is reconstructed by replaying at most %d blocks from the closest cached snapshot", state.HistoryMaxReplayBlocks), false).
 		AddParamBody(dictExample, "params", "Parameters", false).
 		AddResponse(http.StatusOK, "Result", dictExample, nil)
 }
@@ -50,9 +54,30 @@ func handleCallView(c echo.Context) error {
 		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", contractID.ChainID()))
 	}
 
-	vctx, err := viewcontext.NewFromDB(*chain.ID(), chain.Processors())
-	if err != nil {
-		return fmt.Errorf(fmt.Sprintf("Failed to create context: %v", err))
+	var vctx interface {
+		CallView(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error)
+	}
+	if blockIndexStr := c.QueryParam("blockIndex"); blockIndexStr != "" {
+		blockIndex, err := strconv.ParseUint(blockIndexStr, 10, 32)
+		if err != nil {
+			return httperrors.BadRequest(fmt.Sprintf("Invalid block index: %+v", blockIndexStr))
+		}
+		var ok bool
+		vctx, ok, err = viewcontext.NewFromDBAtBlockIndex(*chain.ID(), uint32(blockIndex), chain.Processors())
+		if errors.Is(err, state.ErrReplayTooLong) {
+			return httperrors.BadRequest(fmt.Sprintf("Block index #%d is too far from the cached states: %v", blockIndex, err))
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to create context at block #%d: %v", blockIndex, err)
+		}
+		if !ok {
+			return httperrors.BadRequest(fmt.Sprintf("Block index #%d is beyond the state of the chain", blockIndex))
+		}
+	} else {
+		vctx, err = viewcontext.NewFromDB(*chain.ID(), chain.Processors())
+		if err != nil {
+			return fmt.Errorf(fmt.Sprintf("Failed to create context: %v", err))
+		}
 	}
 
 	ret, err := vctx.CallView(contractID.Hname(), coretypes.Hn(fname), params)
@@ -3,26 +3,35 @@ package chains
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/coretypes"
 
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/migration"
 	registry_pkg "github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/plugins/nodeconn"
 	"github.com/iotaledger/wasp/plugins/peering"
 	"github.com/iotaledger/wasp/plugins/registry"
 )
 
-const PluginName = "Chains"
+const (
+	PluginName = "Chains"
+	// migrationOutputsPeriod is how often outputs of the addresses of previous committees are requested.
+	// Outputs are also received with every update of the address
+	migrationOutputsPeriod = 1 * time.Minute
+)
 
 var (
 	log *logger.Logger
 
 	chains      = make(map[coretypes.ChainID]chain.Chain)
+	migrators   = make(map[address.Address]*migration.Migrator)
 	chainsMutex = &sync.RWMutex{}
 )
 
@@ -54,8 +63,16 @@ func run(_ *node.Plugin) {
 					log.Errorf("cannot activate committee %s: %v", chr.ChainID, err)
 				}
 			}
+			// the node keeps migrating outputs of its previous committees, even if it isn't in the current one
+			for _, m := range chr.Migrations {
+				if err := startMigration(chr, m); err != nil {
+					log.Errorf("cannot start migration of %s: %v", m.From.String(), err)
+				}
+			}
 		}
 
+		go keepRequestingMigrationOutputs(shutdownSignal)
+
 		<-shutdownSignal
 
 		func() {
@@ -66,6 +83,9 @@ func run(_ *node.Plugin) {
 			for _, com := range chains {
 				com.Dismiss()
 			}
+			for _, m := range migrators {
+				m.Close()
+			}
 			log.Infof("shutdown signal received: dismissing committees.. Done")
 		}()
 	})
@@ -95,7 +115,11 @@ func ActivateChain(chr *registry_pkg.ChainRecord) error {
 	// create new chain object
 	defaultRegistry := registry.DefaultRegistry()
 	c := chain.New(chr, log, peering.DefaultNetworkProvider(), defaultRegistry, defaultRegistry, func() {
-		nodeconn.Subscribe((address.Address)(chr.ChainID), chr.Color)
+		nodeconn.Subscribe(chr.Address(), chr.Color)
+		// requests are sent to the chain ID address, also after the committee was rotated
+		nodeconn.Subscribe(address.Address(chr.ChainID), chr.Color)
+	}, func(addr address.Address) {
+		rotateChain(chr.ChainID, addr)
 	})
 	if c != nil {
 		chains[chr.ChainID] = c
@@ -129,8 +153,166 @@ func GetChain(chainID coretypes.ChainID) chain.Chain {
 	ret, ok := chains[chainID]
 	if ok && ret.IsDismissed() {
 		delete(chains, chainID)
-		nodeconn.Unsubscribe((address.Address)(chainID))
+		unsubscribeUnused(ret.Address(), address.Address(chainID))
 		return nil
 	}
 	return ret
 }
+
+// GetMigrator returns the migrator of outputs of the address of the previous committee or nil if it doesn't exist
+func GetMigrator(addr address.Address) *migration.Migrator {
+	chainsMutex.RLock()
+	defer chainsMutex.RUnlock()
+
+	return migrators[addr]
+}
+
+// unsubscribeUnused unsubscribes from addresses which are not used by other chains or migrations.
+// Must be called under the lock
+func unsubscribeUnused(addrs ...address.Address) {
+	for _, addr := range addrs {
+		if _, ok := migrators[addr]; ok {
+			continue
+		}
+		used := false
+		for chainID, c := range chains {
+			if c.Address() == addr || address.Address(chainID) == addr {
+				used = true
+				break
+			}
+		}
+		if !used {
+			nodeconn.Unsubscribe(addr)
+		}
+	}
+}
+
+// GetChainByAddress returns active chain object which is run by the committee of the address
+// or nil if it doesn't exist. The address is equal to the chain ID unless the committee was rotated
+func GetChainByAddress(addr address.Address) chain.Chain {
+	chainsMutex.RLock()
+	defer chainsMutex.RUnlock()
+
+	for _, c := range chains {
+		if c.Address() == addr && !c.IsDismissed() {
+			return c
+		}
+	}
+	return nil
+}
+
+// rotateChain hands over the chain to the committee of the new address, after the chain token has been moved there.
+// The old chain object is dismissed. The chain is activated again with the new committee only if the node
+// has a share of the distributed key generated for the next committee and the chain was moved to its address,
+// otherwise the chain record is deactivated.
+// If the node was in the old committee, it keeps migrating outputs of the old address to the new one
+func rotateChain(chainID coretypes.ChainID, addr address.Address) {
+	dksRegistry := registry.DefaultRegistry()
+	_, err := dksRegistry.LoadDKShare(&addr)
+	hasShare := err == nil
+	var mig *registry_pkg.Migration
+	chr, err := registry_pkg.UpdateChainRecord(&chainID, func(bd *registry_pkg.ChainRecord) bool {
+		prev := bd.Address()
+		if _, err := dksRegistry.LoadDKShare(&prev); err == nil {
+			mig = &registry_pkg.Migration{
+				From:           prev,
+				To:             addr,
+				CommitteeNodes: bd.CommitteeNodes,
+			}
+			bd.Migrations = append(bd.Migrations, mig)
+		}
+		if hasShare && addr == bd.NextStateAddress && len(bd.NextCommitteeNodes) > 0 {
+			bd.CommitteeNodes = bd.NextCommitteeNodes
+		} else {
+			if hasShare {
+				log.Warnf("rotateChain %s: the chain was moved to %s, which is not the address of the next committee %s",
+					chainID.String(), addr.String(), bd.NextStateAddress.String())
+			}
+			bd.Active = false
+		}
+		bd.StateAddress = addr
+		bd.NextCommitteeNodes = nil
+		bd.NextStateAddress = address.Address{}
+		return true
+	})
+	if err != nil {
+		log.Errorf("rotateChain %s: %v", chainID.String(), err)
+		return
+	}
+
+	chainsMutex.Lock()
+	if c, ok := chains[chainID]; ok {
+		c.Dismiss()
+		delete(chains, chainID)
+		unsubscribeUnused(c.Address(), address.Address(chainID))
+	}
+	chainsMutex.Unlock()
+
+	if mig != nil {
+		if err := startMigration(chr, mig); err != nil {
+			log.Errorf("rotateChain %s: cannot start migration of %s: %v", chainID.String(), mig.From.String(), err)
+		}
+	}
+	if !chr.Active {
+		log.Infof("chain %s has been handed over to the committee of %s. The node is not in the new committee", chainID.String(), addr.String())
+		return
+	}
+	log.Infof("chain %s has been handed over to the committee of %s. Activating with the new committee", chainID.String(), addr.String())
+	if err := ActivateChain(chr); err != nil {
+		log.Errorf("rotateChain %s: %v", chainID.String(), err)
+	}
+}
+
+// startMigration starts moving outputs of the address of the previous committee of the node to the next committee
+func startMigration(chr *registry_pkg.ChainRecord, mig *registry_pkg.Migration) error {
+	chainsMutex.Lock()
+	defer chainsMutex.Unlock()
+
+	if _, ok := migrators[mig.From]; ok {
+		return nil
+	}
+	netProvider := peering.DefaultNetworkProvider()
+	ownIndex := uint16(0)
+	for i, netID := range mig.CommitteeNodes {
+		if netID == netProvider.Self().NetID() {
+			ownIndex = uint16(i)
+		}
+	}
+	from := mig.From
+	m, err := migration.New(chr.ChainID, mig.From, mig.To, mig.CommitteeNodes, netProvider, registry.DefaultRegistry(),
+		func(tx *valuetransaction.Transaction) {
+			if err := nodeconn.PostTransactionToNode(tx, &from, ownIndex); err != nil {
+				log.Errorf("posting migration transaction %s: %v", tx.ID().String(), err)
+			}
+		}, log)
+	if err != nil {
+		return err
+	}
+	migrators[mig.From] = m
+	nodeconn.Subscribe(mig.From, chr.Color)
+	if err := nodeconn.RequestOutputsFromNode(&from); err != nil {
+		log.Debugf("requesting outputs of %s: %v", from.String(), err)
+	}
+	log.Infof("migrating outputs of %s to %s", mig.From.String(), mig.To.String())
+	return nil
+}
+
+// keepRequestingMigrationOutputs requests outputs of the addresses of previous committees,
+// in case updates of the address were missed
+func keepRequestingMigrationOutputs(shutdownSignal <-chan struct{}) {
+	for {
+		select {
+		case <-shutdownSignal:
+			return
+		case <-time.After(migrationOutputsPeriod):
+			chainsMutex.RLock()
+			for addr := range migrators {
+				addr := addr
+				if err := nodeconn.RequestOutputsFromNode(&addr); err != nil {
+					log.Debugf("requesting outputs of %s: %v", addr.String(), err)
+				}
+			}
+			chainsMutex.RUnlock()
+		}
+	}
+}
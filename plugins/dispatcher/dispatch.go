@@ -35,35 +35,42 @@ func dispatchState(tx *sctransaction.Transaction) {
 
 func dispatchBalances(addr address.Address, bals map[valuetransaction.ID][]*balance.Balance) {
 	// pass to the committee by address
-	if cmt := chains.GetChain((coretypes.ChainID)(addr)); cmt != nil {
+	if cmt := chains.GetChainByAddress(addr); cmt != nil {
 		cmt.ReceiveMessage(chain.BalancesMsg{Balances: bals})
 	}
+	// outputs of the address of the previous committee are moved to the next one
+	if m := chains.GetMigrator(addr); m != nil {
+		m.ReceiveBalances(bals)
+	}
 }
 
 func dispatchAddressUpdate(addr address.Address, balances map[valuetransaction.ID][]*balance.Balance, tx *sctransaction.Transaction) {
 	log.Debugw("dispatchAddressUpdate", "addr", addr.String())
 
-	cmt := chains.GetChain((coretypes.ChainID)(addr))
+	// update balances before state and requests
+	dispatchBalances(addr, balances)
+
+	txProp := tx.MustProperties() // was parsed before
+	cmt := chains.GetChainByAddress(addr)
+	if cmt != nil {
+		log.Debugf("received tx with balances: %s", tx.ID().String())
+
+		if txProp.IsState() && *txProp.MustChainID() == *cmt.ID() {
+			// it is a state update to addr. Send it
+			cmt.ReceiveMessage(&chain.StateTransactionMsg{
+				Transaction: tx,
+			})
+			log.Debugf("state tx msg posted: %s", tx.ID().String())
+		}
+	} else {
+		// requests are sent to the chain ID address, which is not the address of the committee after rotation
+		cmt = chains.GetChain((coretypes.ChainID)(addr))
+	}
 	if cmt == nil {
 		log.Debugw("committee not found", "addr", addr.String())
 		// wrong addressee
 		return
 	}
-	log.Debugf("received tx with balances: %s", tx.ID().String())
-
-	// update balances before state and requests
-	cmt.ReceiveMessage(chain.BalancesMsg{
-		Balances: balances,
-	})
-
-	txProp := tx.MustProperties() // was parsed before
-	if txProp.IsState() && *txProp.MustChainID() == (coretypes.ChainID)(addr) {
-		// it is a state update to addr. Send it
-		cmt.ReceiveMessage(&chain.StateTransactionMsg{
-			Transaction: tx,
-		})
-		log.Debugf("state tx msg posted: %s", tx.ID().String())
-	}
 
 	// send all requests to addr
 	// if there are any free tokens, they will be attached to the first message.
@@ -73,7 +80,7 @@ func dispatchAddressUpdate(addr address.Address, balances map[valuetransaction.I
 		freeTokens = nil
 	}
 	for i, reqBlk := range tx.Requests() {
-		if reqBlk.Target().ChainID() == (coretypes.ChainID)(addr) {
+		if reqBlk.Target().ChainID() == *cmt.ID() {
 			cmt.ReceiveMessage(&chain.RequestMsg{
 				Transaction: tx,
 				Index:       (uint16)(i),
@@ -86,7 +93,7 @@ func dispatchAddressUpdate(addr address.Address, balances map[valuetransaction.I
 
 func dispatchTxInclusionLevel(level byte, txid *valuetransaction.ID, addrs []address.Address) {
 	for _, addr := range addrs {
-		cmt := chains.GetChain((coretypes.ChainID)(addr))
+		cmt := chains.GetChainByAddress(addr)
 		if cmt == nil {
 			continue
 		}
@@ -97,7 +97,8 @@ func processNodeMsg(msg interface{}) {
 		tx, err := sctransaction.ParseValueTransaction(msgt.Tx)
 		if err != nil {
 			log.Debugw("!!!! after parsing", "txid", msgt.Tx.ID().String(), "err", err)
-			// not a SC transaction. Ignore
+			// not a SC transaction, e.g. a plain transfer or a migration of outputs. Only balances are updated
+			dispatchBalances(msgt.Address, msgt.Balances)
 			return
 		}
 		dispatchAddressUpdate(msgt.Address, msgt.Balances, tx)
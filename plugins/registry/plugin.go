@@ -4,8 +4,10 @@
 package registry
 
 import (
+	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
 	hive_node "github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/parameters"
 	registry_pkg "github.com/iotaledger/wasp/packages/registry"
 	tcrypto_pkg "github.com/iotaledger/wasp/packages/tcrypto"
 )
@@ -13,6 +15,8 @@ import (
 const pluginName = "Registry"
 
 var (
+	log *logger.Logger
+
 	defaultRegistry *registry_pkg.Impl // A singleton.
 )
 
@@ -24,10 +28,14 @@ func DefaultRegistry() *registry_pkg.Impl {
 // Init is an entry point for the plugin.
 func Init(suite tcrypto_pkg.Suite) *hive_node.Plugin {
 	configure := func(_ *hive_node.Plugin) {
-		defaultRegistry = registry_pkg.NewRegistry(suite, logger.NewLogger(pluginName))
+		log = logger.NewLogger(pluginName)
+		defaultRegistry = registry_pkg.NewRegistry(suite, log)
 	}
 	run := func(_ *hive_node.Plugin) {
-		// Nothing to run here.
+		err := daemon.BackgroundWorker(pluginName+"[BlobCache]", defaultRegistry.RunBlobCacheCleanup, parameters.PriorityBlobCacheCleanup)
+		if err != nil {
+			log.Errorf("failed to start as daemon: %s", err)
+		}
 	}
 	return hive_node.NewPlugin(pluginName, hive_node.Enabled, configure, run)
 }
@@ -3,15 +3,21 @@ package chain
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	clientutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
 )
 
 func listAccountsCmd(args []string) {
@@ -59,3 +65,75 @@ func balanceCmd(args []string) {
 	}
 	log.PrintTable(header, rows)
 }
+
+func transferCmd(args []string) {
+	if len(args) < 3 || len(args)%2 != 1 {
+		log.Usage("%s chain transfer <agentid> <color> <amount> [<color> <amount> ...]\n", os.Args[0])
+	}
+
+	target, err := coretypes.NewAgentIDFromString(args[0])
+	log.Check(err)
+
+	amounts := make(map[balance.Color]int64)
+	for i := 1; i < len(args); i += 2 {
+		color, err := util.ColorFromString(args[i])
+		log.Check(err)
+		amount, err := strconv.Atoi(args[i+1])
+		log.Check(err)
+		amounts[color] += int64(amount)
+	}
+
+	clientutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return Client().TransferOnChain(target, amounts)
+	})
+}
+
+func accountHistoryCmd(args []string) {
+	if len(args) < 1 || len(args) > 3 {
+		log.Usage("%s chain account-history <agentid> [<offset> [<limit>]]\n", os.Args[0])
+	}
+
+	agentID, err := coretypes.NewAgentIDFromString(args[0])
+	log.Check(err)
+
+	params := dict.New()
+	params.Set(accounts.ParamAgentID, codec.EncodeAgentID(agentID))
+	if len(args) > 1 {
+		offset, err := strconv.Atoi(args[1])
+		log.Check(err)
+		params.Set(accounts.ParamOffset, codec.EncodeInt64(int64(offset)))
+	}
+	if len(args) > 2 {
+		limit, err := strconv.Atoi(args[2])
+		log.Check(err)
+		params.Set(accounts.ParamMaxRecords, codec.EncodeInt64(int64(limit)))
+	}
+
+	ret, err := SCClient(accounts.Interface.Hname()).CallView(accounts.FuncGetAccountHistory, params)
+	log.Check(err)
+
+	n, _, err := codec.DecodeInt64(ret.MustGet(accounts.ParamNumRecords))
+	log.Check(err)
+	entries := collections.NewArrayReadOnly(ret, accounts.ParamHistory)
+	log.Printf("Total %d history entries of account %s\n", n, agentID)
+
+	header := []string{"timestamp", "op", "reason", "balances", "counterparty", "request"}
+	rows := make([][]string, entries.MustLen())
+	for i := range rows {
+		e, err := accounts.DecodeHistoryEntry(entries.MustGetAt(uint16(i)))
+		log.Check(err)
+		op := "debit"
+		if e.Credit {
+			op = "credit"
+		}
+		rows[i] = []string{
+			time.Unix(0, e.Timestamp).UTC().Format(time.RFC3339),
+			op,
+			e.Reason,
+			cbalances.NewFromMap(e.Balances).String(),
+			e.Counterparty.String(),
+			e.RequestID.Base58(),
+		}
+	}
+	log.PrintTable(header, rows)
+}
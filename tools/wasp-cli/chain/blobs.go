@@ -3,7 +3,11 @@ package chain
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/dict"
@@ -22,6 +26,14 @@ func storeBlobCmd(args []string) {
 }
 
 func uploadBlob(fieldValues dict.Dict, forceWait bool) (hash hashing.HashValue) {
+	if blobSize(fieldValues) > blob.MaxChunkSize {
+		// too big for one request: chunked upload, resumed if interrupted
+		var err error
+		hash, err = Client().UploadBlobChunked(fieldValues, blob.MaxChunkSize, 1*time.Minute)
+		log.Check(err)
+		log.Printf("uploaded blob to chain in chunks -- hash: %s", hash)
+		return
+	}
 	util.WithSCTransaction(func() (tx *sctransaction.Transaction, err error) {
 		hash, tx, err = Client().UploadBlob(fieldValues, config.CommitteeApi(chainCommittee()), uploadQuorum)
 		if err == nil {
@@ -32,6 +44,14 @@ func uploadBlob(fieldValues dict.Dict, forceWait bool) (hash hashing.HashValue)
 	return
 }
 
+func blobSize(fieldValues dict.Dict) int {
+	ret := 0
+	for k, v := range fieldValues {
+		ret += len(k) + len(v)
+	}
+	return ret
+}
+
 func showBlobCmd(args []string) {
 	if len(args) != 1 {
 		log.Fatal("Usage: %s chain show-blob <hash>", os.Args[0])
@@ -58,19 +78,40 @@ func listBlobsCmd(args []string) {
 	ret, err := SCClient(blob.Interface.Hname()).CallView(blob.FuncListBlobs, nil)
 	log.Check(err)
 
-	blobs, err := blob.DecodeSizesMap(ret)
+	blobs, err := blob.DecodeDirectory(ret)
 	log.Check(err)
 
 	log.Printf("Total %d blob(s) in chain %s\n", len(ret), GetCurrentChainID())
 
-	header := []string{"hash", "size"}
+	header := []string{"hash", "size", "refs", "uploader", "uploaded"}
 	rows := make([][]string, len(ret))
 	i := 0
-	for k, size := range blobs {
-		hash, _, err := codec.DecodeHashValue([]byte(k))
-		log.Check(err)
-		rows[i] = []string{hash.String(), fmt.Sprintf("%d", size)}
+	for hash, rec := range blobs {
+		rows[i] = []string{
+			hash.String(),
+			fmt.Sprintf("%d", rec.Size),
+			fmt.Sprintf("%d", rec.References),
+			rec.Uploader.String(),
+			time.Unix(0, rec.Timestamp).UTC().Format(time.RFC3339),
+		}
 		i++
 	}
 	log.PrintTable(header, rows)
 }
+
+func deleteBlobCmd(args []string) {
+	if len(args) != 1 {
+		log.Fatal("Usage: %s chain delete-blob <hash>", os.Args[0])
+	}
+	params := dict.New()
+	params.Set(blob.ParamHash, util.ValueFromString("base58", args[0]))
+	util.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return Client().PostRequest(
+			blob.Interface.Hname(),
+			coretypes.Hn(blob.FuncDeleteBlob),
+			chainclient.PostRequestParams{
+				Args: requestargs.New().AddEncodeSimpleMany(params),
+			},
+		)
+	})
+}
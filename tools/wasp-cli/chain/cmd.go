@@ -15,25 +15,39 @@ func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
 	initDeployFlags(fs)
 	initUploadFlags(fs)
 	initAliasFlags(fs)
+	initEventsFlags(fs)
+	initLogFlags(fs)
+	initFeeFlags(fs)
 	flags.AddFlagSet(fs)
 }
 
 var subcmds = map[string]func([]string){
-	"list":            listCmd,
-	"deploy":          deployCmd,
-	"info":            infoCmd,
-	"list-contracts":  listContractsCmd,
-	"deploy-contract": deployContractCmd,
-	"list-accounts":   listAccountsCmd,
-	"balance":         balanceCmd,
-	"list-blobs":      listBlobsCmd,
-	"store-blob":      storeBlobCmd,
-	"show-blob":       showBlobCmd,
-	"log":             logCmd,
-	"post-request":    postRequestCmd,
-	"call-view":       callViewCmd,
-	"activate":        activateCmd,
-	"deactivate":      deactivateCmd,
+	"list":             listCmd,
+	"deploy":           deployCmd,
+	"info":             infoCmd,
+	"list-contracts":   listContractsCmd,
+	"deploy-contract":  deployContractCmd,
+	"upgrade-contract": upgradeContractCmd,
+	"rotate-committee": rotateCommitteeCmd,
+	"set-fee":          setFeeCmd,
+	"fee-info":         feeInfoCmd,
+	"owner-proposals":  ownerProposalsCmd,
+	"owner-propose":    proposeOwnerActionCmd,
+	"owner-approve":    approveOwnerActionCmd,
+	"list-accounts":    listAccountsCmd,
+	"balance":          balanceCmd,
+	"transfer":         transferCmd,
+	"account-history":  accountHistoryCmd,
+	"list-blobs":       listBlobsCmd,
+	"store-blob":       storeBlobCmd,
+	"show-blob":        showBlobCmd,
+	"delete-blob":      deleteBlobCmd,
+	"log":              logCmd,
+	"events":           eventsCmd,
+	"post-request":     postRequestCmd,
+	"call-view":        callViewCmd,
+	"activate":         activateCmd,
+	"deactivate":       deactivateCmd,
 }
 
 func chainCmd(args []string) {
@@ -0,0 +1,75 @@
+package chain
+
+import (
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
+)
+
+var (
+	eventsContract  string
+	eventsName      string
+	eventsTopic     string
+	eventsRequestID string
+	eventsFromBlock uint32
+	eventsToBlock   uint32
+)
+
+func initEventsFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&eventsContract, "contract", "", "", "events: filter by contract name")
+	flags.StringVarP(&eventsName, "event", "", "", "events: filter by event name")
+	flags.StringVarP(&eventsTopic, "topic", "", "", "events: filter by topic value (0x-prefixed for hex)")
+	flags.StringVarP(&eventsRequestID, "request", "", "", "events, log: filter by request ID (base58)")
+	flags.Uint32VarP(&eventsFromBlock, "from-block", "", 0, "events: index of the first block")
+	flags.Uint32VarP(&eventsToBlock, "to-block", "", 0, "events: index of the last block (default: latest)")
+}
+
+func eventsCmd(args []string) {
+	if len(args) != 0 {
+		log.Fatal("Usage: %s chain events [--contract=<name>] [--event=<name>] [--topic=<value>] [--request=<id>] [--from-block=<index>] [--to-block=<index>]", os.Args[0])
+	}
+	params := map[string]interface{}{
+		eventlog.ParamFromBlock: int64(eventsFromBlock),
+		eventlog.ParamToBlock:   int64(eventsToBlock),
+	}
+	if eventsContract != "" {
+		params[eventlog.ParamContractHname] = codec.EncodeHname(coretypes.Hn(eventsContract))
+	}
+	if eventsName != "" {
+		params[eventlog.ParamEventName] = eventsName
+	}
+	if eventsTopic != "" {
+		params[eventlog.ParamTopic] = parseTopic(eventsTopic)
+	}
+	if eventsRequestID != "" {
+		reqID, err := coretypes.NewRequestIDFromBase58(eventsRequestID)
+		log.Check(err)
+		params[eventlog.ParamRequestID] = reqID
+	}
+	r, err := SCClient(eventlog.Interface.Hname()).CallView(eventlog.FuncGetEvents, codec.MakeDict(params))
+	log.Check(err)
+
+	events := collections.NewArrayReadOnly(r, eventlog.ParamEvents)
+	for i := uint16(0); i < events.MustLen(); i++ {
+		e, err := eventlog.DecodeEvent(events.MustGetAt(i))
+		log.Check(err)
+		log.Printf("%s block #%d req %s %s\n", time.Unix(0, e.Timestamp), e.BlockIndex, e.RequestID.Short(), e.String())
+	}
+}
+
+func parseTopic(s string) []byte {
+	if !strings.HasPrefix(s, "0x") {
+		return []byte(s)
+	}
+	ret, err := hex.DecodeString(s[2:])
+	log.Check(err)
+	return ret
+}
@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/iotaledger/wasp/tools/wasp-cli/util"
+	"github.com/spf13/pflag"
+)
+
+var (
+	feeEntryPoint string
+	feeMode       string
+)
+
+func initFeeFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&feeEntryPoint, "entry-point", "", "", "set-fee: set the fee of the entry point of the contract")
+	flags.StringVarP(&feeMode, "fee-mode", "", "", fmt.Sprintf("set-fee: %s (number of tokens) or %s (basis points of the transferred fee tokens)",
+		root.FeeModeFlat, root.FeeModeBasisPoints))
+}
+
+func setFeeCmd(args []string) {
+	if len(args) != 3 {
+		log.Fatal("Usage: %s chain set-fee <contract> <owner-fee> <validator-fee> [--entry-point=<name>] [--fee-mode=%s|%s]",
+			os.Args[0], root.FeeModeFlat, root.FeeModeBasisPoints)
+	}
+	ownerFee, err := strconv.ParseInt(args[1], 10, 64)
+	log.Check(err)
+	validatorFee, err := strconv.ParseInt(args[2], 10, 64)
+	log.Check(err)
+
+	reqArgs := requestargs.New().
+		AddEncodeSimple(root.ParamHname, codec.EncodeHname(coretypes.Hn(args[0]))).
+		AddEncodeSimple(root.ParamOwnerFee, codec.EncodeInt64(ownerFee)).
+		AddEncodeSimple(root.ParamValidatorFee, codec.EncodeInt64(validatorFee))
+	if feeEntryPoint != "" {
+		reqArgs.AddEncodeSimple(root.ParamEntryPoint, codec.EncodeHname(coretypes.Hn(feeEntryPoint)))
+	}
+	if feeMode != "" {
+		reqArgs.AddEncodeSimple(root.ParamFeeMode, codec.EncodeString(feeMode))
+	}
+
+	util.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return Client().PostRequest(
+			root.Interface.Hname(),
+			coretypes.Hn(root.FuncSetContractFee),
+			chainclient.PostRequestParams{
+				Args: reqArgs,
+			},
+		)
+	})
+}
+
+func feeInfoCmd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Fatal("Usage: %s chain fee-info <contract> [<entry-point>]", os.Args[0])
+	}
+	params := map[string]interface{}{
+		root.ParamHname: coretypes.Hn(args[0]),
+	}
+	if len(args) == 2 {
+		params[root.ParamEntryPoint] = coretypes.Hn(args[1])
+	}
+	r, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetFeeInfo, codec.MakeDict(params))
+	log.Check(err)
+
+	ret := kvdecoder.New(r)
+	feeColor, err := ret.GetColor(root.ParamFeeColor)
+	log.Check(err)
+	ownerFee, err := ret.GetInt64(root.ParamOwnerFee)
+	log.Check(err)
+	validatorFee, err := ret.GetInt64(root.ParamValidatorFee)
+	log.Check(err)
+	mode, err := ret.GetString(root.ParamFeeMode, root.FeeModeFlat)
+	log.Check(err)
+
+	basisPoints := mode == root.FeeModeBasisPoints
+	log.Printf("Owner fee: %s\n", formatFee(ownerFee, feeColor, basisPoints))
+	log.Printf("Validator fee: %s\n", formatFee(validatorFee, feeColor, basisPoints))
+}
+
+func formatFee(fee int64, feeColor balance.Color, basisPoints bool) string {
+	if basisPoints {
+		return fmt.Sprintf("%d bp of %s", fee, feeColor)
+	}
+	return fmt.Sprintf("%d %s", fee, feeColor)
+}
@@ -15,9 +15,6 @@ func listContractsCmd(args []string) {
 	contracts, err := root.DecodeContractRegistry(collections.NewMapReadOnly(info, root.VarContractRegistry))
 	log.Check(err)
 
-	feeColor, defaultOwnerFee, defaultValidatorFee, err := root.GetDefaultFeeInfo(info)
-	log.Check(err)
-
 	log.Printf("Total %d contracts in chain %s\n", len(contracts), GetCurrentChainID())
 
 	header := []string{
@@ -28,6 +25,7 @@ func listContractsCmd(args []string) {
 		"creator",
 		"owner fee",
 		"validator fee",
+		"entry point fees",
 	}
 	rows := make([][]string, len(contracts))
 	i := 0
@@ -37,14 +35,7 @@ func listContractsCmd(args []string) {
 			creator = c.Creator.String()
 		}
 
-		ownerFee := c.OwnerFee
-		if ownerFee == 0 {
-			ownerFee = defaultOwnerFee
-		}
-		validatorFee := c.ValidatorFee
-		if validatorFee == 0 {
-			validatorFee = defaultValidatorFee
-		}
+		feeColor, ownerFee, validatorFee, basisPoints := root.GetFeeInfoByContractRecord(info, c, 0)
 
 		rows[i] = []string{
 			hname.String(),
@@ -52,8 +43,9 @@ func listContractsCmd(args []string) {
 			c.Description,
 			c.ProgramHash.String(),
 			creator,
-			fmt.Sprintf("%d %s", ownerFee, feeColor),
-			fmt.Sprintf("%d %s", validatorFee, feeColor),
+			formatFee(ownerFee, feeColor, basisPoints),
+			formatFee(validatorFee, feeColor, basisPoints),
+			fmt.Sprintf("%d", len(c.EntryPointFees)),
 		}
 		i++
 	}
@@ -9,14 +9,37 @@ import (
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
 )
 
+var logBlock int64
+
+func initLogFlags(flags *pflag.FlagSet) {
+	flags.Int64VarP(&logBlock, "block", "", -1, "log: show records of the block with the index")
+}
+
 func logCmd(args []string) {
-	if len(args) != 1 {
-		log.Fatal("Usage: %s chain log <name>", os.Args[0])
+	switch {
+	case len(args) == 1 && eventsRequestID == "" && logBlock < 0:
+		logContractCmd(args[0])
+	case len(args) == 0 && eventsRequestID != "" && logBlock < 0:
+		reqID, err := coretypes.NewRequestIDFromBase58(eventsRequestID)
+		log.Check(err)
+		logIndexedCmd(eventlog.FuncGetRecordsByRequest, map[string]interface{}{
+			eventlog.ParamRequestID: reqID,
+		})
+	case len(args) == 0 && eventsRequestID == "" && logBlock >= 0:
+		logIndexedCmd(eventlog.FuncGetRecordsByBlock, map[string]interface{}{
+			eventlog.ParamBlockIndex: logBlock,
+		})
+	default:
+		log.Fatal("Usage: %s chain log <name> | --request=<id> | --block=<index>", os.Args[0])
 	}
+}
+
+func logContractCmd(name string) {
 	r, err := SCClient(eventlog.Interface.Hname()).CallView(eventlog.FuncGetRecords, codec.MakeDict(map[string]interface{}{
-		eventlog.ParamContractHname: codec.EncodeHname(coretypes.Hn(args[0])),
+		eventlog.ParamContractHname: codec.EncodeHname(coretypes.Hn(name)),
 	}))
 	log.Check(err)
 
@@ -28,3 +51,15 @@ func logCmd(args []string) {
 		log.Printf("%s %s\n", time.Unix(0, rec.Timestamp), string(rec.Data))
 	}
 }
+
+func logIndexedCmd(fname string, params map[string]interface{}) {
+	r, err := SCClient(eventlog.Interface.Hname()).CallView(fname, codec.MakeDict(params))
+	log.Check(err)
+
+	records := collections.NewArrayReadOnly(r, eventlog.ParamRecords)
+	for i := uint16(0); i < records.MustLen(); i++ {
+		rec, err := eventlog.DecodeRecord(records.MustGetAt(i))
+		log.Check(err)
+		log.Printf("%s %s\n", time.Unix(0, rec.Timestamp), rec.String())
+	}
+}
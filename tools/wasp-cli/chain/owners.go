@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/iotaledger/wasp/tools/wasp-cli/util"
+)
+
+func ownerProposalsCmd(args []string) {
+	if len(args) != 0 {
+		log.Fatal("Usage: %s chain owner-proposals", os.Args[0])
+	}
+	r, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetOwnerProposals, nil)
+	log.Check(err)
+
+	owners, err := root.DecodeChainOwners(r.MustGet(root.ParamChainOwners))
+	log.Check(err)
+	quorum, _, err := codec.DecodeInt64(r.MustGet(root.ParamOwnerQuorum))
+	log.Check(err)
+	if len(owners) == 0 {
+		log.Printf("The chain is governed by the single chain owner\n")
+		return
+	}
+	log.Printf("Owner set: %d of %d\n", quorum, len(owners))
+	for _, o := range owners {
+		log.Printf("  %s\n", o.String())
+	}
+
+	arr := collections.NewArrayReadOnly(r, root.ParamProposals)
+	header := []string{"id", "contract", "entry point", "proposer", "deadline", "approvals"}
+	rows := make([][]string, arr.MustLen())
+	for i := range rows {
+		p, err := root.DecodeOwnerProposal(arr.MustGetAt(uint16(i)))
+		log.Check(err)
+		rows[i] = []string{
+			fmt.Sprintf("%d", p.ID),
+			p.Contract.String(),
+			p.Target.String(),
+			p.Proposer.String(),
+			time.Unix(0, p.Deadline).UTC().Format(time.RFC3339),
+			fmt.Sprintf("%d/%d", len(p.Approvals), quorum),
+		}
+	}
+	log.PrintTable(header, rows)
+}
+
+func proposeOwnerActionCmd(args []string) {
+	if len(args) < 1 {
+		log.Fatal("Usage: %s chain owner-propose [<contract>.]<funcname> [params]", os.Args[0])
+	}
+	// the 'root' is the default target contract
+	contract, funcName := root.Interface.Name, args[0]
+	if i := strings.Index(funcName, "."); i >= 0 {
+		contract, funcName = funcName[:i], funcName[i+1:]
+	}
+	util.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return Client().PostRequest(
+			root.Interface.Hname(),
+			coretypes.Hn(root.FuncProposeOwnerAction),
+			chainclient.PostRequestParams{
+				Args: requestargs.New().
+					AddEncodeSimpleMany(util.EncodeParams(args[1:])).
+					AddEncodeSimple(root.ParamProposalContract, codec.EncodeHname(coretypes.Hn(contract))).
+					AddEncodeSimple(root.ParamProposalTarget, codec.EncodeHname(coretypes.Hn(funcName))),
+			},
+		)
+	})
+}
+
+func approveOwnerActionCmd(args []string) {
+	if len(args) != 1 {
+		log.Fatal("Usage: %s chain owner-approve <proposal id>", os.Args[0])
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	log.Check(err)
+	util.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return Client().PostRequest(
+			root.Interface.Hname(),
+			coretypes.Hn(root.FuncApproveOwnerAction),
+			chainclient.PostRequestParams{
+				Args: requestargs.New().AddEncodeSimple(root.ParamProposalID, codec.EncodeInt64(id)),
+			},
+		)
+	})
+}
@@ -0,0 +1,30 @@
+package chain
+
+import (
+	"os"
+
+	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/iotaledger/wasp/tools/wasp-cli/wallet"
+)
+
+// rotateCommitteeCmd hands over the chain to the committee given by the --committee and --quorum flags
+func rotateCommitteeCmd(args []string) {
+	if len(args) != 0 {
+		log.Fatal("Usage: %s chain rotate-committee --committee=<indices of the new committee> --quorum=<quorum>", os.Args[0])
+	}
+	addr, err := apilib.RotateCommittee(apilib.RotateCommitteeParams{
+		Node:                      config.GoshimmerClient(),
+		ChainID:                   GetCurrentChainID(),
+		CommitteeApiHosts:         config.CommitteeApi(chainCommittee()),
+		NextCommitteeApiHosts:     config.CommitteeApi(committee),
+		NextCommitteePeeringHosts: config.CommitteePeering(committee),
+		T:                         uint16(quorum),
+		OwnerSigScheme:            wallet.Load().SignatureScheme(),
+		Textout:                   os.Stdout,
+		Prefix:                    "",
+	})
+	log.Check(err)
+	log.Printf("Address of the new committee: %s\n", addr.String())
+}